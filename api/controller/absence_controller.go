@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultAbsenceRange is how far ahead absences are listed without a to.
+const defaultAbsenceRange = 90 * 24 * time.Hour
+
+type AbsenceController struct {
+	AbsenceUsecase      domain.AbsenceUsecase
+	AvailabilityUsecase domain.AvailabilityUsecase
+	DoctorUsecase       domain.DoctorUsecase
+	AuditService        auditservice.Service
+}
+
+func NewAbsenceController(abu domain.AbsenceUsecase, avu domain.AvailabilityUsecase, du domain.DoctorUsecase, as auditservice.Service) *AbsenceController {
+	return &AbsenceController{
+		AbsenceUsecase:      abu,
+		AvailabilityUsecase: avu,
+		DoctorUsecase:       du,
+		AuditService:        as,
+	}
+}
+
+// Create records an absence and returns the appointments it falls on, which
+// are left for Rebook.
+func (abc *AbsenceController) Create(c *gin.Context) {
+	doctor, ok := editableDoctor(c, abc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	var absence domain.DoctorAbsence
+	if err := c.ShouldBind(&absence); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	absence.DoctorID = doctor.ID
+	absence.CreatedBy, _ = currentUser(c)
+
+	result, err := abc.AbsenceUsecase.Create(c, &absence)
+	if err != nil {
+		respondAbsenceError(c, err)
+		return
+	}
+
+	if !auditAction(c, abc.AuditService, "ABSENCE_CREATE", fmt.Sprintf("Absence %s created for doctor %s affecting %d appointments", absence.ID, doctor.ID, len(result.Affected))) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// FetchByDoctorID lists absences between from and to, which accept RFC 3339
+// timestamps or YYYY-MM-DD dates (to being inclusive). Without them the next
+// ninety days are listed.
+func (abc *AbsenceController) FetchByDoctorID(c *gin.Context) {
+	doctor, ok := doctorFromParam(c, abc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	loc, err := abc.AvailabilityUsecase.Location(c, doctor.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	from := time.Now()
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseRangeBound(value, false, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid from, expected RFC 3339 timestamp or YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+
+	to := from.Add(defaultAbsenceRange)
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseRangeBound(value, true, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid to, expected RFC 3339 timestamp or YYYY-MM-DD"})
+			return
+		}
+		to = parsed
+	}
+
+	absences, err := abc.AbsenceUsecase.FetchByDoctorID(c, doctor.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if absences == nil {
+		absences = []domain.DoctorAbsence{}
+	}
+
+	c.JSON(http.StatusOK, absences)
+}
+
+func (abc *AbsenceController) Delete(c *gin.Context) {
+	doctor, ok := editableDoctor(c, abc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	absenceID, ok := absenceFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := abc.AbsenceUsecase.Delete(c, doctor.ID, absenceID); err != nil {
+		respondAbsenceError(c, err)
+		return
+	}
+
+	if !auditAction(c, abc.AuditService, "ABSENCE_DELETE", fmt.Sprintf("Deleted absence %s of doctor %s", absenceID, doctor.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// FetchAffected lists the requested and confirmed appointments the absence
+// still falls on.
+func (abc *AbsenceController) FetchAffected(c *gin.Context) {
+	doctor, ok := editableDoctor(c, abc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	absenceID, ok := absenceFromParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := abc.AbsenceUsecase.FetchAffected(c, doctor.ID, absenceID)
+	if err != nil {
+		respondAbsenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Rebook cancels the affected appointments or moves them to another doctor,
+// reporting what happened to each.
+func (abc *AbsenceController) Rebook(c *gin.Context) {
+	doctor, ok := doctorFromParam(c, abc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	absenceID, ok := absenceFromParam(c)
+	if !ok {
+		return
+	}
+
+	var request domain.RebookRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	affected, err := abc.AbsenceUsecase.FetchAffected(c, doctor.ID, absenceID)
+	if err != nil {
+		respondAbsenceError(c, err)
+		return
+	}
+	if !reserveAudit(c, len(affected.Affected)-1) {
+		return
+	}
+
+	actorID, actorRole := currentUser(c)
+	outcomes, err := abc.AbsenceUsecase.Rebook(c, doctor.ID, absenceID, request, actorID, actorRole)
+	if err != nil {
+		respondAbsenceError(c, err)
+		return
+	}
+
+	for _, outcome := range outcomes {
+		switch outcome.Outcome {
+		case domain.RebookCanceled:
+			if !auditPatientAccess(c, abc.AuditService, "APPOINTMENT_CANCEL", domain.ResourceAppointment, outcome.AppointmentID, outcome.PatientID, fmt.Sprintf("Appointment %s canceled for absence %s", outcome.AppointmentID, absenceID)) {
+				return
+			}
+		case domain.RebookMoved:
+			if !auditPatientAccess(c, abc.AuditService, "APPOINTMENT_REASSIGN", domain.ResourceAppointment, outcome.AppointmentID, outcome.PatientID, fmt.Sprintf("Appointment %s moved to doctor %s for absence %s", outcome.AppointmentID, outcome.DoctorID, absenceID)) {
+				return
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, outcomes)
+}
+
+func absenceFromParam(c *gin.Context) (uuid.UUID, bool) {
+	absenceID, err := uuid.Parse(c.Param("absence_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid absence id format"})
+		return uuid.Nil, false
+	}
+	return absenceID, true
+}
+
+func respondAbsenceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAbsenceNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Absence not found"})
+	case errors.Is(err, domain.ErrInvalidAbsence), errors.Is(err, domain.ErrInvalidRebook):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/pdfreport"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccessLogController struct {
+	AuditLogUsecase domain.AuditLogUsecase
+	PatientUsecase  domain.PatientUsecase
+	AuditService    auditservice.Service
+}
+
+func NewAccessLogController(alu domain.AuditLogUsecase, pu domain.PatientUsecase, as auditservice.Service) *AccessLogController {
+	return &AccessLogController{
+		AuditLogUsecase: alu,
+		PatientUsecase:  pu,
+		AuditService:    as,
+	}
+}
+
+func (alc *AccessLogController) FetchMine(c *gin.Context) {
+	userIDCtx, _ := c.Get("x-user-id")
+	userID, ok := userIDCtx.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "User id not found in context"})
+		return
+	}
+
+	patient, err := alc.PatientUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if patient.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient profile not found for this user"})
+		return
+	}
+
+	entries, err := alc.AuditLogUsecase.FetchAccessLogByPatientID(c, patient.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if entries == nil {
+		entries = []domain.AccessLogEntry{}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (alc *AccessLogController) FetchByPatientID(c *gin.Context) {
+	patientID := c.Param("id")
+	if patientID == "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "patient id is required"})
+		return
+	}
+
+	parsedID, err := uuid.Parse(patientID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "invalid patient id"})
+		return
+	}
+
+	patient, err := alc.PatientUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if patient.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient not found"})
+		return
+	}
+
+	entries, err := alc.AuditLogUsecase.FetchAccessLogByPatientID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if !auditPatientAccess(c, alc.AuditService, "ACCESS_LOG_EXPORT", domain.ResourceAccessLog, parsedID, parsedID, fmt.Sprintf("Access log exported for patient ID: %s (format: %s)", parsedID, c.DefaultQuery("format", "json"))) {
+		return
+	}
+
+	if c.Query("format") != "pdf" {
+		if entries == nil {
+			entries = []domain.AccessLogEntry{}
+		}
+		c.JSON(http.StatusOK, entries)
+		return
+	}
+
+	var buf bytes.Buffer
+	err = pdfreport.Render(&buf, accessLogReport(patient, entries))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"access-log-%s.pdf\"", parsedID))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+func accessLogReport(patient domain.Patient, entries []domain.AccessLogEntry) pdfreport.Report {
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			entry.AccessedAt.UTC().Format("2006-01-02 15:04:05"),
+			entry.Username,
+			string(entry.UserRole),
+			entry.Action,
+			entry.ResourceType,
+			entry.Purpose,
+			entry.Description,
+		})
+	}
+
+	return pdfreport.Report{
+		Title: "Accounting of Disclosures",
+		Subtitle: []string{
+			fmt.Sprintf("Patient ID: %s", patient.ID),
+			fmt.Sprintf("CPF: %s", patient.CPF),
+			fmt.Sprintf("Generated at: %s UTC", time.Now().UTC().Format("2006-01-02 15:04:05")),
+			fmt.Sprintf("Entries: %d", len(entries)),
+		},
+		Columns: []pdfreport.Column{
+			{Header: "When (UTC)", Width: 90},
+			{Header: "Who", Width: 100},
+			{Header: "Role", Width: 50},
+			{Header: "Action", Width: 150},
+			{Header: "Resource", Width: 80},
+			{Header: "Purpose", Width: 80},
+			{Header: "Details", Width: 220},
+		},
+		Rows:   rows,
+		Footer: "LGPD accounting of disclosures",
+	}
+}
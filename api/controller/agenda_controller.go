@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AgendaController struct {
+	AgendaUsecase domain.AgendaUsecase
+	DoctorUsecase domain.DoctorUsecase
+	AuditService  auditservice.Service
+}
+
+func NewAgendaController(agu domain.AgendaUsecase, du domain.DoctorUsecase, as auditservice.Service) *AgendaController {
+	return &AgendaController{
+		AgendaUsecase: agu,
+		DoctorUsecase: du,
+		AuditService:  as,
+	}
+}
+
+// FetchMine returns the calling doctor's agenda for the date query, a
+// YYYY-MM-DD date in their facility's timezone, or for today.
+func (agc *AgendaController) FetchMine(c *gin.Context) {
+	userID, _ := currentUser(c)
+
+	doctor, err := agc.DoctorUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if doctor.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Doctor profile not found for this user"})
+		return
+	}
+
+	agenda, err := agc.AgendaUsecase.FetchByDoctorID(c, doctor.ID, c.Query("date"))
+	if errors.Is(err, domain.ErrInvalidAgendaDate) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	var listed listedAccess
+	for _, entry := range agenda.Appointments {
+		listed.add(entry.Appointment.ID, entry.Appointment.PatientID)
+	}
+	if !auditListAccess(c, agc.AuditService, "AGENDA_FETCH", domain.ResourceAppointment, listed, fmt.Sprintf("Appointment listed on the agenda of doctor %s for %s", doctor.ID, agenda.Date)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, agenda)
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AllergyController struct {
+	AllergyUsecase domain.AllergyUsecase
+	AuditService   auditservice.Service
+}
+
+func NewAllergyController(au domain.AllergyUsecase, as auditservice.Service) *AllergyController {
+	return &AllergyController{
+		AllergyUsecase: au,
+		AuditService:   as,
+	}
+}
+
+func (ac *AllergyController) Create(c *gin.Context) {
+	patientID, ok := uuidParam(c, "id", "Invalid patient id format")
+	if !ok {
+		return
+	}
+
+	var allergy domain.PatientAllergy
+	if err := c.ShouldBind(&allergy); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	allergy.PatientID = patientID
+	allergy.RecordedBy = &userID
+
+	if err := ac.AllergyUsecase.Create(c, &allergy); err != nil {
+		respondAllergyError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ac.AuditService, "ALLERGY_CREATE", domain.ResourceAllergy, allergy.ID, allergy.PatientID, fmt.Sprintf("Allergy to %s recorded with ID: %s", allergy.Substance, allergy.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, allergy)
+}
+
+func (ac *AllergyController) FetchByPatientID(c *gin.Context) {
+	patientID, ok := uuidParam(c, "id", "Invalid patient id format")
+	if !ok {
+		return
+	}
+
+	allergies, err := ac.AllergyUsecase.FetchByPatientID(c, patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	var listed listedAccess
+	for _, allergy := range allergies {
+		listed.add(allergy.ID, allergy.PatientID)
+	}
+	if !auditListAccess(c, ac.AuditService, "ALLERGY_FETCH_BY_PATIENT", domain.ResourceAllergy, listed, fmt.Sprintf("Allergy listed for patient ID: %s", patientID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, allergies)
+}
+
+func (ac *AllergyController) FetchByID(c *gin.Context) {
+	parsedID, ok := uuidParam(c, "id", "Invalid allergy id format")
+	if !ok {
+		return
+	}
+
+	allergy, err := ac.AllergyUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		respondAllergyError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ac.AuditService, "ALLERGY_FETCH_BY_ID", domain.ResourceAllergy, allergy.ID, allergy.PatientID, fmt.Sprintf("Allergy fetched with ID: %s", allergy.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, allergy)
+}
+
+// Update changes an allergy, including its status: allergies are resolved or
+// marked entered_in_error instead of being deleted.
+func (ac *AllergyController) Update(c *gin.Context) {
+	parsedID, ok := uuidParam(c, "id", "Invalid allergy id format")
+	if !ok {
+		return
+	}
+
+	var change domain.AllergyChange
+	if err := c.ShouldBind(&change); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	allergy, err := ac.AllergyUsecase.Update(c, parsedID, change)
+	if err != nil {
+		respondAllergyError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ac.AuditService, "ALLERGY_UPDATE", domain.ResourceAllergy, allergy.ID, allergy.PatientID, fmt.Sprintf("Allergy updated with ID: %s (status: %s)", allergy.ID, allergy.Status)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, allergy)
+}
+
+func respondAllergyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAllergyNotFound), errors.Is(err, domain.ErrPatientNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrInvalidAllergy):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
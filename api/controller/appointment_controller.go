@@ -1,11 +1,13 @@
 package controller
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"hms-api/domain"
 	"hms-api/internal/auditservice"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,12 +15,16 @@ import (
 
 type AppointmentController struct {
 	AppointmentUsecase domain.AppointmentUsecase
+	PatientUsecase     domain.PatientUsecase
+	DoctorUsecase      domain.DoctorUsecase
 	AuditService  auditservice.Service
 }
 
-func NewAppointmentController(usecase domain.AppointmentUsecase, as auditservice.Service) *AppointmentController {
+func NewAppointmentController(usecase domain.AppointmentUsecase, pu domain.PatientUsecase, du domain.DoctorUsecase, as auditservice.Service) *AppointmentController {
 	return &AppointmentController{
 		AppointmentUsecase: usecase,
+		PatientUsecase:     pu,
+		DoctorUsecase:      du,
 		AuditService: as,
 	}
 }
@@ -33,21 +39,29 @@ func (ac *AppointmentController) Create(c *gin.Context){
 		return
 	}
 
-	err = ac.AppointmentUsecase.Create(c, &appointment)
+	// Patients always book for themselves, whatever the body says.
+	userID, role := currentUser(c)
+	if role == domain.PatientRole {
+		patient, err := ac.PatientUsecase.FetchByUserID(c, userID)
 		if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+		if patient.ID == uuid.Nil {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient profile not found for this user"})
+			return
+		}
+		appointment.PatientID = patient.ID
+	}
+
+	err = ac.AppointmentUsecase.Create(c, &appointment, userID, role)
+	if err != nil {
+		respondAppointmentError(c, err)
 		return
 	}
 
-	if ac.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = ac.AuditService.Log(context.Background(), userID, "APPOINTMENT_CREATE", fmt.Sprintf("Appointment created with ID: %s", appointment.ID.String()))
-		}()
+	if !auditPatientAccess(c, ac.AuditService, "APPOINTMENT_CREATE", domain.ResourceAppointment, appointment.ID, appointment.PatientID, fmt.Sprintf("Appointment created with ID: %s", appointment.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusCreated, appointment)
@@ -61,6 +75,14 @@ func (ac *AppointmentController) Fetch(c *gin.Context){
 		return
 	}
 
+	var listed listedAccess
+	for _, appointment := range appointments {
+		listed.add(appointment.ID, appointment.PatientID)
+	}
+	if !auditListAccess(c, ac.AuditService, "APPOINTMENT_FETCH", domain.ResourceAppointment, listed, "Appointment listed") {
+		return
+	}
+
 	c.JSON(http.StatusOK, appointments)
 }
 
@@ -89,15 +111,8 @@ func (ac *AppointmentController) FetchByID(c *gin.Context){
 		return
 	}
 
-	if ac.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = ac.AuditService.Log(context.Background(), userID, "DOCTOR_CREATE", fmt.Sprintf("Appointment fetched with ID: %s", appointmentID))
-		}()
+	if !auditPatientAccess(c, ac.AuditService, "APPOINTMENT_FETCH_BY_ID", domain.ResourceAppointment, appointment.ID, appointment.PatientID, fmt.Sprintf("Appointment fetched with ID: %s", appointmentID)) {
+		return
 	}
 
 	c.JSON(http.StatusOK, appointment)
@@ -127,6 +142,14 @@ func (ac *AppointmentController) FetchByPatientID(c *gin.Context){
 		return
 	}
 
+	var listed listedAccess
+	for _, appointment := range appointments {
+		listed.add(appointment.ID, appointment.PatientID)
+	}
+	if !auditListAccess(c, ac.AuditService, "APPOINTMENT_FETCH_BY_PATIENT", domain.ResourceAppointment, listed, fmt.Sprintf("Appointment listed for patient ID: %s", patientID)) {
+		return
+	}
+
 	c.JSON(http.StatusOK, appointments)
 }
 
@@ -154,6 +177,14 @@ func (ac *AppointmentController) FetchByDoctorID(c *gin.Context){
 		return
 	}
 
+	var listed listedAccess
+	for _, appointment := range appointments {
+		listed.add(appointment.ID, appointment.PatientID)
+	}
+	if !auditListAccess(c, ac.AuditService, "APPOINTMENT_FETCH_BY_DOCTOR", domain.ResourceAppointment, listed, fmt.Sprintf("Appointment listed for doctor ID: %s", doctorID)) {
+		return
+	}
+
 	c.JSON(http.StatusOK, appointments)
 }
 
@@ -171,30 +202,21 @@ func (ac *AppointmentController) Update(c *gin.Context){
 		return
 	}
 
-	var appointment domain.Appointment
-	err = c.ShouldBind(&appointment)
+	var change domain.AppointmentChange
+	err = c.ShouldBind(&change)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	appointment.ID = parsedID
-
-	err = ac.AppointmentUsecase.Update(c, &appointment)
+	appointment, err := ac.AppointmentUsecase.Update(c, parsedID, change)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		respondAppointmentError(c, err)
 		return
 	}
 
-	if ac.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = ac.AuditService.Log(context.Background(), userID, "APPOINTMENT_UPDATE", fmt.Sprintf("Appointment updated with ID: %s", appointment.ID.String()))
-		}()
+	if !auditPatientAccess(c, ac.AuditService, "APPOINTMENT_UPDATE", domain.ResourceAppointment, appointment.ID, appointment.PatientID, fmt.Sprintf("Appointment updated with ID: %s", appointment.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusOK, appointment)
@@ -214,22 +236,252 @@ func (ac *AppointmentController) Delete(c *gin.Context){
 		return
 	}
 
+	appointment, err := ac.AppointmentUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if appointment.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+		return
+	}
+
 	err = ac.AppointmentUsecase.Delete(c, parsedID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	if ac.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = ac.AuditService.Log(context.Background(), userID, "APPOINTMENT_DELETE", fmt.Sprintf("Appointment deleted with ID: %s", appointmentID))
-		}()
+	if !auditPatientAccess(c, ac.AuditService, "APPOINTMENT_DELETE", domain.ResourceAppointment, parsedID, appointment.PatientID, fmt.Sprintf("Appointment deleted with ID: %s", appointmentID)) {
+		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+// Transition returns a handler that applies the given lifecycle action, e.g.
+// "check-in" or "cancel", to the appointment in the path.
+func (ac *AppointmentController) Transition(action string) gin.HandlerFunc {
+	entryAction := "APPOINTMENT_" + strings.ToUpper(strings.ReplaceAll(action, "-", "_"))
+
+	return func(c *gin.Context) {
+		parsedID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid appointment id"})
+			return
+		}
+
+		var request domain.TransitionRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBind(&request); err != nil {
+				c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+				return
+			}
+		}
+
+		appointment, err := ac.AppointmentUsecase.FetchByID(c, parsedID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+
+		if appointment.ID == uuid.Nil {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+			return
+		}
+
+		if !ensureParticipant(c, ac.PatientUsecase, ac.DoctorUsecase, appointment.PatientID, appointment.DoctorID) {
+			return
+		}
+
+		userID, role := currentUser(c)
+		updated, err := ac.AppointmentUsecase.Transition(c, parsedID, action, userID, role, request.Reason)
+		if err != nil {
+			respondTransitionError(c, err, updated.Status)
+			return
+		}
+
+		if !auditPatientAccess(c, ac.AuditService, entryAction, domain.ResourceAppointment, updated.ID, updated.PatientID, fmt.Sprintf("Appointment %s moved from %s to %s", updated.ID, appointment.Status, updated.Status)) {
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+func (ac *AppointmentController) FetchStatusHistory(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid appointment id"})
+		return
+	}
+
+	appointment, err := ac.AppointmentUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if appointment.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+		return
+	}
+
+	if !ensureParticipant(c, ac.PatientUsecase, ac.DoctorUsecase, appointment.PatientID, appointment.DoctorID) {
+		return
+	}
+
+	history, err := ac.AppointmentUsecase.FetchStatusHistory(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if history == nil {
+		history = []domain.AppointmentStatusChange{}
+	}
+
+	if !auditPatientAccess(c, ac.AuditService, "APPOINTMENT_FETCH_HISTORY", domain.ResourceAppointment, appointment.ID, appointment.PatientID, fmt.Sprintf("Status history fetched for appointment ID: %s", parsedID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// Reschedule moves the appointment in the path to the requested time. Patients
+// are held to the reschedule policy; staff aren't.
+func (ac *AppointmentController) Reschedule(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid appointment id"})
+		return
+	}
+
+	var request domain.RescheduleRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	appointment, err := ac.AppointmentUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if appointment.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+		return
+	}
+
+	if !ensureParticipant(c, ac.PatientUsecase, ac.DoctorUsecase, appointment.PatientID, appointment.DoctorID) {
+		return
+	}
+
+	userID, role := currentUser(c)
+	updated, err := ac.AppointmentUsecase.Reschedule(c, parsedID, request, userID, role)
+	if err != nil {
+		respondRescheduleError(c, err, updated.Status)
+		return
+	}
+
+	if !auditPatientAccess(c, ac.AuditService, "APPOINTMENT_RESCHEDULE", domain.ResourceAppointment, updated.ID, updated.PatientID, fmt.Sprintf("Appointment %s moved from %s to %s", updated.ID, appointment.AppointmentDate.Format(time.RFC3339), updated.AppointmentDate.Format(time.RFC3339))) {
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (ac *AppointmentController) FetchReschedules(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid appointment id"})
+		return
+	}
+
+	appointment, err := ac.AppointmentUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if appointment.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+		return
+	}
+
+	if !ensureParticipant(c, ac.PatientUsecase, ac.DoctorUsecase, appointment.PatientID, appointment.DoctorID) {
+		return
+	}
+
+	reschedules, err := ac.AppointmentUsecase.FetchReschedules(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if reschedules == nil {
+		reschedules = []domain.AppointmentReschedule{}
+	}
+
+	if !auditPatientAccess(c, ac.AuditService, "APPOINTMENT_FETCH_RESCHEDULES", domain.ResourceAppointment, appointment.ID, appointment.PatientID, fmt.Sprintf("Reschedule history fetched for appointment ID: %s", parsedID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, reschedules)
+}
+
+func respondRescheduleError(c *gin.Context, err error, current domain.AppointmentStatus) {
+	switch {
+	case errors.Is(err, domain.ErrRescheduleInPast):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrRescheduleTooLate), errors.Is(err, domain.ErrRescheduleLimit):
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrRescheduleNotAllowed):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: fmt.Sprintf("%s (current status: %s)", err.Error(), current)})
+	case errors.Is(err, domain.ErrOutsideAvailability):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		respondAppointmentError(c, err)
+	}
+}
+
+func respondTransitionError(c *gin.Context, err error, current domain.AppointmentStatus) {
+	switch {
+	case errors.Is(err, domain.ErrAppointmentNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+	case errors.Is(err, domain.ErrTransitionForbidden), errors.Is(err, domain.ErrApprovalRequired):
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrReasonRequired):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: fmt.Sprintf("%s (current status: %s)", err.Error(), current)})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
+
+// respondAppointmentError answers booking conflicts with 409 and the nearest
+// free slots, and invalid times or types with 400.
+func respondAppointmentError(c *gin.Context, err error) {
+	var conflict *domain.AppointmentConflictError
+	switch {
+	case errors.As(err, &conflict):
+		suggestions := conflict.Suggestions
+		if suggestions == nil {
+			suggestions = []domain.Slot{}
+		}
+		c.JSON(http.StatusConflict, domain.AppointmentConflictResponse{
+			Message:     conflict.Error(),
+			Conflict:    conflict.With,
+			Suggestions: suggestions,
+		})
+	case errors.Is(err, domain.ErrInvalidAppointmentTime), errors.Is(err, domain.ErrAppointmentTypeNotFound), errors.Is(err, domain.ErrAppointmentFieldLocked):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrAppointmentNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
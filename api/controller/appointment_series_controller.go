@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AppointmentSeriesController struct {
+	SeriesUsecase  domain.AppointmentSeriesUsecase
+	PatientUsecase domain.PatientUsecase
+	DoctorUsecase  domain.DoctorUsecase
+	AuditService   auditservice.Service
+}
+
+func NewAppointmentSeriesController(usecase domain.AppointmentSeriesUsecase, pu domain.PatientUsecase, du domain.DoctorUsecase, as auditservice.Service) *AppointmentSeriesController {
+	return &AppointmentSeriesController{
+		SeriesUsecase:  usecase,
+		PatientUsecase: pu,
+		DoctorUsecase:  du,
+		AuditService:   as,
+	}
+}
+
+func (sc *AppointmentSeriesController) Create(c *gin.Context) {
+	var series domain.AppointmentSeries
+
+	if err := c.ShouldBind(&series); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	userID, role := currentUser(c)
+	result, err := sc.SeriesUsecase.Create(c, &series, userID, role)
+	if err != nil {
+		respondSeriesError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, sc.AuditService, "APPOINTMENT_SERIES_CREATE", domain.ResourceAppointmentSeries, result.Series.ID, result.Series.PatientID, fmt.Sprintf("Appointment series created with %d occurrences (%d skipped)", len(result.Appointments), len(result.Skipped))) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (sc *AppointmentSeriesController) FetchByID(c *gin.Context) {
+	result, ok := sc.fetchSeries(c)
+	if !ok {
+		return
+	}
+
+	if !auditPatientAccess(c, sc.AuditService, "APPOINTMENT_SERIES_FETCH", domain.ResourceAppointmentSeries, result.Series.ID, result.Series.PatientID, fmt.Sprintf("Appointment series fetched with ID: %s", result.Series.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (sc *AppointmentSeriesController) Update(c *gin.Context) {
+	var request domain.SeriesUpdateRequest
+
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	current, ok := sc.fetchSeries(c)
+	if !ok {
+		return
+	}
+
+	userID, role := currentUser(c)
+	result, err := sc.SeriesUsecase.Update(c, current.Series.ID, request, userID, role)
+	if err != nil {
+		respondSeriesError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, sc.AuditService, "APPOINTMENT_SERIES_UPDATE", domain.ResourceAppointmentSeries, current.Series.ID, current.Series.PatientID, fmt.Sprintf("Appointment series %s updated with scope %s", current.Series.ID, request.Scope)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (sc *AppointmentSeriesController) Cancel(c *gin.Context) {
+	var request domain.SeriesCancelRequest
+
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	current, ok := sc.fetchSeries(c)
+	if !ok {
+		return
+	}
+
+	userID, role := currentUser(c)
+	result, err := sc.SeriesUsecase.Cancel(c, current.Series.ID, request, userID, role)
+	if err != nil {
+		respondSeriesError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, sc.AuditService, "APPOINTMENT_SERIES_CANCEL", domain.ResourceAppointmentSeries, current.Series.ID, current.Series.PatientID, fmt.Sprintf("Appointment series %s canceled with scope %s", current.Series.ID, request.Scope)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// fetchSeries loads the series in the path and checks the caller takes part
+// in it, answering the request itself when it can't go on.
+func (sc *AppointmentSeriesController) fetchSeries(c *gin.Context) (domain.AppointmentSeriesResult, bool) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid series id"})
+		return domain.AppointmentSeriesResult{}, false
+	}
+
+	result, err := sc.SeriesUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		respondSeriesError(c, err)
+		return domain.AppointmentSeriesResult{}, false
+	}
+
+	if !ensureParticipant(c, sc.PatientUsecase, sc.DoctorUsecase, result.Series.PatientID, result.Series.DoctorID) {
+		return domain.AppointmentSeriesResult{}, false
+	}
+
+	return result, true
+}
+
+func respondSeriesError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSeriesNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment series not found"})
+	case errors.Is(err, domain.ErrInvalidRecurrence),
+		errors.Is(err, domain.ErrInvalidScope),
+		errors.Is(err, domain.ErrInvalidSchedule),
+		errors.Is(err, domain.ErrReasonRequired):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrOutsideAvailability), errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrRescheduleNotAllowed):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		respondAppointmentError(c, err)
+	}
+}
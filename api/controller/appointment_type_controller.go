@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AppointmentTypeController struct {
+	AppointmentTypeUsecase domain.AppointmentTypeUsecase
+	AuditService           auditservice.Service
+}
+
+func NewAppointmentTypeController(tu domain.AppointmentTypeUsecase, as auditservice.Service) *AppointmentTypeController {
+	return &AppointmentTypeController{
+		AppointmentTypeUsecase: tu,
+		AuditService:           as,
+	}
+}
+
+func (tc *AppointmentTypeController) Create(c *gin.Context) {
+	var appointmentType domain.AppointmentType
+
+	if err := c.ShouldBind(&appointmentType); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := tc.AppointmentTypeUsecase.Create(c, &appointmentType); err != nil {
+		respondAppointmentTypeError(c, err)
+		return
+	}
+
+	if !auditAction(c, tc.AuditService, "APPOINTMENT_TYPE_CREATE", fmt.Sprintf("Appointment type created with ID: %s", appointmentType.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointmentType)
+}
+
+func (tc *AppointmentTypeController) Fetch(c *gin.Context) {
+	appointmentTypes, err := tc.AppointmentTypeUsecase.Fetch(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if appointmentTypes == nil {
+		appointmentTypes = []domain.AppointmentType{}
+	}
+
+	c.JSON(http.StatusOK, appointmentTypes)
+}
+
+func (tc *AppointmentTypeController) FetchByID(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid appointment type id"})
+		return
+	}
+
+	appointmentType, err := tc.AppointmentTypeUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		respondAppointmentTypeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appointmentType)
+}
+
+func (tc *AppointmentTypeController) Update(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid appointment type id"})
+		return
+	}
+
+	var appointmentType domain.AppointmentType
+	if err := c.ShouldBind(&appointmentType); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	appointmentType.ID = parsedID
+
+	if err := tc.AppointmentTypeUsecase.Update(c, &appointmentType); err != nil {
+		respondAppointmentTypeError(c, err)
+		return
+	}
+
+	if !auditAction(c, tc.AuditService, "APPOINTMENT_TYPE_UPDATE", fmt.Sprintf("Updated appointment type with ID: %s", appointmentType.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, appointmentType)
+}
+
+func (tc *AppointmentTypeController) Delete(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid appointment type id"})
+		return
+	}
+
+	if err := tc.AppointmentTypeUsecase.Delete(c, parsedID); err != nil {
+		respondAppointmentTypeError(c, err)
+		return
+	}
+
+	if !auditAction(c, tc.AuditService, "APPOINTMENT_TYPE_DELETE", fmt.Sprintf("Deactivated appointment type with ID: %s", parsedID)) {
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func respondAppointmentTypeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAppointmentTypeNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment type not found"})
+	case errors.Is(err, domain.ErrInvalidAppointmentType):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrDuplicateAppointmentType):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
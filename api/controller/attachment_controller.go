@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead leaves room for the form's other fields and boundaries
+// when limiting the request body to the largest attachment allowed.
+const multipartOverhead = 1 << 20
+
+type AttachmentController struct {
+	AttachmentUsecase domain.AttachmentUsecase
+	PatientUsecase    domain.PatientUsecase
+	AuditService      auditservice.Service
+	MaxSize           int64
+}
+
+func NewAttachmentController(attachmentUsecase domain.AttachmentUsecase, pu domain.PatientUsecase, as auditservice.Service, maxSize int64) *AttachmentController {
+	return &AttachmentController{
+		AttachmentUsecase: attachmentUsecase,
+		PatientUsecase:    pu,
+		AuditService:      as,
+		MaxSize:           maxSize,
+	}
+}
+
+func (atc *AttachmentController) UploadForPatient(c *gin.Context) {
+	patientID, ok := uuidParam(c, "id", "Invalid patient id format")
+	if !ok {
+		return
+	}
+	atc.upload(c, domain.Attachment{PatientID: patientID})
+}
+
+func (atc *AttachmentController) UploadForMedicalRecord(c *gin.Context) {
+	recordID, ok := uuidParam(c, "id", "Invalid record id format")
+	if !ok {
+		return
+	}
+	atc.upload(c, domain.Attachment{MedicalRecordID: &recordID})
+}
+
+func (atc *AttachmentController) UploadForPrescription(c *gin.Context) {
+	prescriptionID, ok := uuidParam(c, "id", "Invalid prescription id format")
+	if !ok {
+		return
+	}
+	atc.upload(c, domain.Attachment{PrescriptionID: &prescriptionID})
+}
+
+func (atc *AttachmentController) FetchByPatientID(c *gin.Context) {
+	patientID, ok := uuidParam(c, "id", "Invalid patient id format")
+	if !ok {
+		return
+	}
+	if !atc.ensureOwnPatient(c, patientID) {
+		return
+	}
+
+	attachments, err := atc.AttachmentUsecase.FetchByPatientID(c, patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	atc.respondList(c, attachments, "ATTACHMENT_FETCH_BY_PATIENT", fmt.Sprintf("Attachment listed for patient ID: %s", patientID))
+}
+
+func (atc *AttachmentController) FetchByMedicalRecordID(c *gin.Context) {
+	recordID, ok := uuidParam(c, "id", "Invalid record id format")
+	if !ok {
+		return
+	}
+
+	attachments, err := atc.AttachmentUsecase.FetchByMedicalRecordID(c, recordID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	atc.respondList(c, attachments, "ATTACHMENT_FETCH_BY_MEDICAL_RECORD", fmt.Sprintf("Attachment listed for medical record ID: %s", recordID))
+}
+
+func (atc *AttachmentController) FetchByPrescriptionID(c *gin.Context) {
+	prescriptionID, ok := uuidParam(c, "id", "Invalid prescription id format")
+	if !ok {
+		return
+	}
+
+	attachments, err := atc.AttachmentUsecase.FetchByPrescriptionID(c, prescriptionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	atc.respondList(c, attachments, "ATTACHMENT_FETCH_BY_PRESCRIPTION", fmt.Sprintf("Attachment listed for prescription ID: %s", prescriptionID))
+}
+
+func (atc *AttachmentController) FetchByID(c *gin.Context) {
+	attachment, ok := atc.attachmentFromParam(c)
+	if !ok {
+		return
+	}
+
+	if !auditPatientAccess(c, atc.AuditService, "ATTACHMENT_FETCH_BY_ID", domain.ResourceAttachment, attachment.ID, attachment.PatientID, fmt.Sprintf("Attachment fetched with ID: %s", attachment.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// Download streams the attachment's content as a file, with its checksum as
+// the ETag so clients can verify it.
+func (atc *AttachmentController) Download(c *gin.Context) {
+	attachment, ok := atc.attachmentFromParam(c)
+	if !ok {
+		return
+	}
+
+	content, err := atc.AttachmentUsecase.Open(c, attachment)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	if !auditPatientAccess(c, atc.AuditService, "ATTACHMENT_DOWNLOAD", domain.ResourceAttachment, attachment.ID, attachment.PatientID, fmt.Sprintf("Attachment downloaded with ID: %s", attachment.ID)) {
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"ETag":                   strconv.Quote(attachment.SHA256),
+		"X-Checksum-Sha256":      attachment.SHA256,
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+func (atc *AttachmentController) upload(c *gin.Context, attachment domain.Attachment) {
+	if atc.MaxSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, atc.MaxSize+multipartOverhead)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, domain.ErrorResponse{Message: domain.ErrAttachmentTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "file is required"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	userID, _ := currentUser(c)
+	attachment.FileName = header.Filename
+	attachment.Description = c.PostForm("description")
+	attachment.Size = header.Size
+	attachment.UploadedBy = userID
+
+	if err := atc.AttachmentUsecase.Upload(c, &attachment, file); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, atc.AuditService, "ATTACHMENT_UPLOAD", domain.ResourceAttachment, attachment.ID, attachment.PatientID, fmt.Sprintf("Attachment %s uploaded with ID: %s", attachment.FileName, attachment.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// respondList lists attachments, which patients only see when they are their
+// own.
+func (atc *AttachmentController) respondList(c *gin.Context, attachments []domain.Attachment, action string, description string) {
+	for _, attachment := range attachments {
+		if !atc.ensureOwnPatient(c, attachment.PatientID) {
+			return
+		}
+	}
+
+	var listed listedAccess
+	for _, attachment := range attachments {
+		listed.add(attachment.ID, attachment.PatientID)
+	}
+	if !auditListAccess(c, atc.AuditService, action, domain.ResourceAttachment, listed, description) {
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+func (atc *AttachmentController) attachmentFromParam(c *gin.Context) (*domain.Attachment, bool) {
+	parsedID, ok := uuidParam(c, "id", "Invalid attachment id format")
+	if !ok {
+		return nil, false
+	}
+
+	attachment, err := atc.AttachmentUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return nil, false
+	}
+
+	if attachment == nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Attachment not found"})
+		return nil, false
+	}
+
+	if !atc.ensureOwnPatient(c, attachment.PatientID) {
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+// ensureOwnPatient makes sure patients only reach their own attachments.
+// Admins and doctors can reach any.
+func (atc *AttachmentController) ensureOwnPatient(c *gin.Context, patientID uuid.UUID) bool {
+	userID, role := currentUser(c)
+	if role != domain.PatientRole {
+		return true
+	}
+
+	patient, err := atc.PatientUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return false
+	}
+	if patient.ID == uuid.Nil || patient.ID != patientID {
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "Patients can only access their own attachments"})
+		return false
+	}
+
+	return true
+}
+
+func uuidParam(c *gin.Context, name string, message string) (uuid.UUID, bool) {
+	parsedID, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: message})
+		return uuid.Nil, false
+	}
+	return parsedID, true
+}
+
+func respondAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAttachmentNotFound), errors.Is(err, domain.ErrMedicalRecordNotFound),
+		errors.Is(err, domain.ErrPrescriptionNotFound), errors.Is(err, domain.ErrPatientNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrUnsupportedAttachmentType):
+		c.JSON(http.StatusUnsupportedMediaType, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrInvalidAttachment):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrMedicalRecordEnteredInError):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultReportRange is how far back the no-show report goes without from.
+const defaultReportRange = 30 * 24 * time.Hour
+
+type AttendanceController struct {
+	AttendanceUsecase domain.AttendanceUsecase
+	PatientUsecase    domain.PatientUsecase
+	AuditService      auditservice.Service
+}
+
+func NewAttendanceController(atu domain.AttendanceUsecase, pu domain.PatientUsecase, as auditservice.Service) *AttendanceController {
+	return &AttendanceController{
+		AttendanceUsecase: atu,
+		PatientUsecase:    pu,
+		AuditService:      as,
+	}
+}
+
+func (atc *AttendanceController) FetchMine(c *gin.Context) {
+	userID, _ := currentUser(c)
+
+	patient, err := atc.PatientUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if patient.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient profile not found for this user"})
+		return
+	}
+
+	attendance, err := atc.AttendanceUsecase.FetchAttendance(c, patient.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
+
+func (atc *AttendanceController) FetchByPatientID(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "invalid patient id"})
+		return
+	}
+
+	patient, err := atc.PatientUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if patient.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient not found"})
+		return
+	}
+
+	attendance, err := atc.AttendanceUsecase.FetchAttendance(c, patient.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if !auditPatientAccess(c, atc.AuditService, "PATIENT_ATTENDANCE_FETCH", domain.ResourcePatient, patient.ID, patient.ID, fmt.Sprintf("Attendance fetched for patient ID: %s", patient.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
+
+// FetchNoShowRates reports no-show rates grouped by ?group_by=doctor (the
+// default) or specialty, for appointments between from and to, which accept
+// RFC 3339 timestamps or YYYY-MM-DD dates. Without them the last 30 days are
+// covered.
+func (atc *AttendanceController) FetchNoShowRates(c *gin.Context) {
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseRangeBound(value, true, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid to, expected RFC 3339 timestamp or YYYY-MM-DD"})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultReportRange)
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseRangeBound(value, false, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid from, expected RFC 3339 timestamp or YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+
+	rates, err := atc.AttendanceUsecase.FetchNoShowRates(c, c.Query("group_by"), from, to)
+	if errors.Is(err, domain.ErrInvalidReportGroup) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if rates == nil {
+		rates = []domain.NoShowRate{}
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// auditPatientAccess records a read or change of patient data with the actor's
+// role, the resource touched and the purpose of use, so it can be reported
+// back to the patient in their accounting of disclosures. It reports false,
+// having answered 503, when the entry couldn't be recorded; callers must then
+// return without writing their response.
+func auditPatientAccess(c *gin.Context, as auditservice.Service, action string, resourceType string, resourceID uuid.UUID, patientID uuid.UUID, description string) bool {
+	if as == nil {
+		return true
+	}
+
+	userIDCtx, _ := c.Get("x-user-id")
+	userID := uuid.Nil
+	if id, ok := userIDCtx.(uuid.UUID); ok {
+		userID = id
+	}
+
+	roleCtx, _ := c.Get("x-user-role")
+	role, _ := roleCtx.(domain.UserRole)
+
+	entry := domain.AuditLog{
+		UserID:       userID,
+		UserRole:     role,
+		Action:       action,
+		Description:  description,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		PatientID:    patientID,
+		Purpose:      accessPurpose(c, role),
+		IPAddress:    c.ClientIP(),
+	}
+
+	return logAudit(c, as, entry)
+}
+
+// listedAccess gathers the rows a list handler returns by patient.
+type listedAccess struct {
+	patients  []uuid.UUID
+	resources map[uuid.UUID][]uuid.UUID
+}
+
+func (la *listedAccess) add(resourceID uuid.UUID, patientID uuid.UUID) {
+	if la.resources == nil {
+		la.resources = make(map[uuid.UUID][]uuid.UUID)
+	}
+	if _, ok := la.resources[patientID]; !ok {
+		la.patients = append(la.patients, patientID)
+	}
+	la.resources[patientID] = append(la.resources[patientID], resourceID)
+}
+
+// auditListAccess records a listing with one entry per patient, naming the
+// resources of theirs it returned, so a long list doesn't flood the audit
+// queue with an entry per row. The entry only points at a resource when it
+// is the patient's only one.
+func auditListAccess(c *gin.Context, as auditservice.Service, action string, resourceType string, listed listedAccess, description string) bool {
+	for _, patientID := range listed.patients {
+		resourceIDs := listed.resources[patientID]
+
+		resourceID := uuid.Nil
+		if len(resourceIDs) == 1 {
+			resourceID = resourceIDs[0]
+		}
+
+		ids := make([]string, len(resourceIDs))
+		for i, id := range resourceIDs {
+			ids[i] = id.String()
+		}
+
+		if !auditPatientAccess(c, as, action, resourceType, resourceID, patientID, fmt.Sprintf("%s (%s)", description, strings.Join(ids, ", "))) {
+			return false
+		}
+	}
+	return true
+}
+
+// auditAction records an action that doesn't touch patient data, such as a
+// change to the clinic's catalogs. Like auditPatientAccess, it reports false
+// when the entry couldn't be recorded.
+func auditAction(c *gin.Context, as auditservice.Service, action string, description string) bool {
+	if as == nil {
+		return true
+	}
+
+	userID, _ := currentUser(c)
+	return logAudit(c, as, domain.AuditLog{UserID: userID, Action: action, Description: description})
+}
+
+// logAudit records entry in the room the request reserved before changing
+// anything (see middleware.AuditReservationMiddleware). By then the change
+// is made, so an entry that can't be recorded is only logged; a request
+// without a reservation, which changes nothing, answers 503 instead.
+func logAudit(c *gin.Context, as auditservice.Service, entry domain.AuditLog) bool {
+	reservation, ok := auditReservation(c)
+	if !ok {
+		return audited(c, entry.Action, as.LogEntry(c.Request.Context(), entry))
+	}
+
+	if err := reservation.LogEntry(c.Request.Context(), entry); err != nil {
+		log.Printf("[ERROR] Audit: failed to record %s after the change was made: %v\n", entry.Action, err)
+	}
+	return true
+}
+
+// reserveAudit reserves room for n more entries than the one every change
+// gets, for handlers that record several. Call it before making the change;
+// it reports false, having answered 503, when the audit log can't take them.
+func reserveAudit(c *gin.Context, n int) bool {
+	reservation, ok := auditReservation(c)
+	if !ok || n <= 0 {
+		return true
+	}
+
+	return audited(c, "reservation", reservation.Add(c.Request.Context(), n))
+}
+
+func auditReservation(c *gin.Context) (*auditservice.Reservation, bool) {
+	reservationCtx, _ := c.Get("x-audit-reservation")
+	reservation, ok := reservationCtx.(*auditservice.Reservation)
+	return reservation, ok
+}
+
+// audited answers 503 when err kept an audit entry from being queued, so no
+// audited operation succeeds without its record.
+func audited(c *gin.Context, action string, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	log.Printf("[ERROR] Audit: failed to record %s: %v\n", action, err)
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, domain.ErrorResponse{Message: "The audit log is unavailable, try again later"})
+	return false
+}
+
+// accessPurpose reads the purpose of use from the X-Access-Purpose header and
+// falls back to the usual purpose for the caller's role.
+func accessPurpose(c *gin.Context, role domain.UserRole) string {
+	if purpose := strings.TrimSpace(c.GetHeader("X-Access-Purpose")); purpose != "" {
+		return purpose
+	}
+
+	switch role {
+	case domain.DoctorRole:
+		return "treatment"
+	case domain.AdminRole:
+		return "administration"
+	case domain.PatientRole:
+		return "self-access"
+	}
+
+	return ""
+}
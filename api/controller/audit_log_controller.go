@@ -4,16 +4,19 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"hms-api/domain"
+	"hms-api/internal/auditservice"
 	"net/http"
 )
 
 type AuditLogController struct {
 	AuditLogUsecase domain.AuditLogUsecase
+	AuditService    auditservice.Service
 }
 
-func NewAuditLogController(usecase domain.AuditLogUsecase) *AuditLogController {
+func NewAuditLogController(usecase domain.AuditLogUsecase, as auditservice.Service) *AuditLogController {
 	return &AuditLogController{
 		AuditLogUsecase: usecase,
+		AuditService:    as,
 	}
 }
 
@@ -44,6 +47,10 @@ func (alc *AuditLogController) Fetch(c *gin.Context) {
 	c.JSON(http.StatusOK, auditLogs)
 }
 
+func (alc *AuditLogController) Stats(c *gin.Context) {
+	c.JSON(http.StatusOK, alc.AuditService.Stats())
+}
+
 func (alc *AuditLogController) FetchByID(c *gin.Context) {
 	auditLogID := c.Param("id")
 	if auditLogID == "" {
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"errors"
+	"hms-api/domain"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const defaultSlotRange = 7 * 24 * time.Hour
+
+type AvailabilityController struct {
+	AvailabilityUsecase domain.AvailabilityUsecase
+	DoctorUsecase       domain.DoctorUsecase
+}
+
+func NewAvailabilityController(avu domain.AvailabilityUsecase, du domain.DoctorUsecase) *AvailabilityController {
+	return &AvailabilityController{
+		AvailabilityUsecase: avu,
+		DoctorUsecase:       du,
+	}
+}
+
+type weeklyAvailabilityRequest struct {
+	Weekly []domain.WeeklyAvailability `json:"weekly" binding:"dive"`
+}
+
+func (avc *AvailabilityController) FetchByDoctorID(c *gin.Context) {
+	doctor, ok := doctorFromParam(c, avc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	availability, err := avc.AvailabilityUsecase.FetchByDoctorID(c, doctor.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+func (avc *AvailabilityController) ReplaceWeekly(c *gin.Context) {
+	doctor, ok := editableDoctor(c, avc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	var request weeklyAvailabilityRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	err := avc.AvailabilityUsecase.ReplaceWeekly(c, doctor.ID, request.Weekly)
+	if errors.Is(err, domain.ErrInvalidSchedule) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, request.Weekly)
+}
+
+func (avc *AvailabilityController) SaveException(c *gin.Context) {
+	doctor, ok := editableDoctor(c, avc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	var exception domain.AvailabilityException
+	if err := c.ShouldBind(&exception); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	exception.DoctorID = doctor.ID
+
+	err := avc.AvailabilityUsecase.SaveException(c, &exception)
+	if errors.Is(err, domain.ErrInvalidSchedule) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, exception)
+}
+
+func (avc *AvailabilityController) DeleteException(c *gin.Context) {
+	doctor, ok := editableDoctor(c, avc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	exceptionID, err := uuid.Parse(c.Param("exception_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid exception id format"})
+		return
+	}
+
+	if err := avc.AvailabilityUsecase.DeleteException(c, doctor.ID, exceptionID); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// FetchSlots lists free slots between from and to, which accept RFC 3339
+// timestamps or YYYY-MM-DD dates (to being inclusive). Without them the next
+// seven days are returned.
+func (avc *AvailabilityController) FetchSlots(c *gin.Context) {
+	doctor, ok := doctorFromParam(c, avc.DoctorUsecase)
+	if !ok {
+		return
+	}
+
+	// Dates are the doctor's, in their facility's timezone.
+	loc, err := avc.AvailabilityUsecase.Location(c, doctor.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	from := time.Now()
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseRangeBound(value, false, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid from, expected RFC 3339 timestamp or YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+
+	to := from.Add(defaultSlotRange)
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseRangeBound(value, true, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid to, expected RFC 3339 timestamp or YYYY-MM-DD"})
+			return
+		}
+		to = parsed
+	}
+
+	slots, err := avc.AvailabilityUsecase.FetchSlots(c, doctor.ID, from, to)
+	if errors.Is(err, domain.ErrInvalidSchedule) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+func doctorFromParam(c *gin.Context, du domain.DoctorUsecase) (domain.Doctor, bool) {
+	doctorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid doctor id format"})
+		return domain.Doctor{}, false
+	}
+
+	doctor, err := du.FetchByID(c, doctorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return domain.Doctor{}, false
+	}
+
+	if doctor.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Doctor not found"})
+		return domain.Doctor{}, false
+	}
+
+	return doctor, true
+}
+
+// editableDoctor loads the doctor in the path and makes sure a doctor caller
+// only changes their own schedule.
+func editableDoctor(c *gin.Context, du domain.DoctorUsecase) (domain.Doctor, bool) {
+	doctor, ok := doctorFromParam(c, du)
+	if !ok {
+		return domain.Doctor{}, false
+	}
+
+	roleCtx, _ := c.Get("x-user-role")
+	if role, _ := roleCtx.(domain.UserRole); role == domain.DoctorRole {
+		userIDCtx, _ := c.Get("x-user-id")
+		if userID, _ := userIDCtx.(uuid.UUID); userID != doctor.UserId {
+			c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "Doctors can only change their own schedule"})
+			return domain.Doctor{}, false
+		}
+	}
+
+	return doctor, true
+}
+
+// parseRangeBound accepts an RFC 3339 timestamp or a YYYY-MM-DD date in loc.
+// A date used as an end bound covers the whole day.
+func parseRangeBound(value string, end bool, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
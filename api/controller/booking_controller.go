@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BookingController struct {
+	BookingUsecase domain.BookingUsecase
+	PatientUsecase domain.PatientUsecase
+	AuditService   auditservice.Service
+}
+
+func NewBookingController(bu domain.BookingUsecase, pu domain.PatientUsecase, as auditservice.Service) *BookingController {
+	return &BookingController{
+		BookingUsecase: bu,
+		PatientUsecase: pu,
+		AuditService:   as,
+	}
+}
+
+type confirmHoldRequest struct {
+	Notes string `json:"notes"`
+}
+
+// Search lists free slots by ?specialty, optionally narrowed by ?facility,
+// ?type_id and a ?from/?to range (RFC 3339 or YYYY-MM-DD).
+func (bc *BookingController) Search(c *gin.Context) {
+	search := domain.BookingSearch{
+		Specialty: c.Query("specialty"),
+		Facility:  c.Query("facility"),
+	}
+
+	if value := c.Query("type_id"); value != "" {
+		typeID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid type_id"})
+			return
+		}
+		search.TypeID = &typeID
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := parseRangeBound(value, false, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid from, expected RFC 3339 or YYYY-MM-DD"})
+			return
+		}
+		search.From = from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := parseRangeBound(value, true, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid to, expected RFC 3339 or YYYY-MM-DD"})
+			return
+		}
+		search.To = to
+	}
+
+	slots, err := bc.BookingUsecase.Search(c, search)
+	if err != nil {
+		respondBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+// Hold reserves a slot for the calling patient for a few minutes.
+func (bc *BookingController) Hold(c *gin.Context) {
+	var request domain.HoldRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	patientID, ok := bc.currentPatientID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := bc.BookingUsecase.Hold(c, patientID, request)
+	if err != nil {
+		respondBookingError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, bc.AuditService, "SLOT_HOLD_CREATE", domain.ResourceSlotHold, hold.ID, patientID, fmt.Sprintf("Held slot at %s with doctor %s", hold.SlotStart.Format("2006-01-02 15:04"), hold.DoctorID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+func (bc *BookingController) FetchHold(c *gin.Context) {
+	patientID, holdID, ok := bc.holdParams(c)
+	if !ok {
+		return
+	}
+
+	hold, err := bc.BookingUsecase.FetchHold(c, patientID, holdID)
+	if err != nil {
+		respondBookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+func (bc *BookingController) Release(c *gin.Context) {
+	patientID, holdID, ok := bc.holdParams(c)
+	if !ok {
+		return
+	}
+
+	if err := bc.BookingUsecase.Release(c, patientID, holdID); err != nil {
+		respondBookingError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, bc.AuditService, "SLOT_HOLD_RELEASE", domain.ResourceSlotHold, holdID, patientID, fmt.Sprintf("Released slot hold %s", holdID)) {
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Confirm books the held slot for the calling patient.
+func (bc *BookingController) Confirm(c *gin.Context) {
+	var request confirmHoldRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBind(&request); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	patientID, holdID, ok := bc.holdParams(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := currentUser(c)
+	appointment, err := bc.BookingUsecase.Confirm(c, patientID, holdID, request.Notes, userID)
+	if err != nil {
+		respondBookingError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, bc.AuditService, "APPOINTMENT_CREATE", domain.ResourceAppointment, appointment.ID, appointment.PatientID, fmt.Sprintf("Appointment booked from slot hold %s", holdID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+func (bc *BookingController) holdParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid hold id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	patientID, ok := bc.currentPatientID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return patientID, holdID, true
+}
+
+// currentPatientID returns the patient profile of the caller; holds always
+// belong to the patient in the token.
+func (bc *BookingController) currentPatientID(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := currentUser(c)
+
+	patient, err := bc.PatientUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return uuid.Nil, false
+	}
+	if patient.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient profile not found for this user"})
+		return uuid.Nil, false
+	}
+
+	return patient.ID, true
+}
+
+func respondBookingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBookingSearch), errors.Is(err, domain.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrHoldNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Slot hold not found"})
+	case errors.Is(err, domain.ErrSlotUnavailable), errors.Is(err, domain.ErrHoldClosed):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		respondAppointmentError(c, err)
+	}
+}
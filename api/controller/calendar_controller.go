@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CalendarController struct {
+	CalendarUsecase domain.CalendarUsecase
+	AuditService    auditservice.Service
+}
+
+func NewCalendarController(cu domain.CalendarUsecase, as auditservice.Service) *CalendarController {
+	return &CalendarController{
+		CalendarUsecase: cu,
+		AuditService:    as,
+	}
+}
+
+// CreateFeed issues a new calendar subscription URL. It is only shown once.
+func (cc *CalendarController) CreateFeed(c *gin.Context) {
+	userID, role := currentUser(c)
+
+	feed, err := cc.CalendarUsecase.CreateFeed(c, userID, role)
+	if err != nil {
+		respondCalendarError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+func (cc *CalendarController) FetchFeeds(c *gin.Context) {
+	userID, _ := currentUser(c)
+
+	feeds, err := cc.CalendarUsecase.FetchFeeds(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if feeds == nil {
+		feeds = []domain.CalendarFeed{}
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+func (cc *CalendarController) RevokeFeed(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid feed id"})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	if err := cc.CalendarUsecase.RevokeFeed(c, parsedID, userID); err != nil {
+		respondCalendarError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Feed serves the .ics file of a subscription. The secret token in the path
+// stands in for authentication, since calendar apps can't log in.
+func (cc *CalendarController) Feed(c *gin.Context) {
+	feed, body, err := cc.CalendarUsecase.Render(c, c.Param("token"))
+	if err != nil {
+		respondCalendarError(c, err)
+		return
+	}
+
+	if cc.AuditService != nil {
+		entry := domain.AuditLog{
+			UserID:       feed.UserID,
+			Action:       "CALENDAR_FEED_FETCH",
+			Description:  fmt.Sprintf("Calendar feed %s fetched", feed.ID),
+			ResourceType: domain.ResourceCalendarFeed,
+			ResourceID:   feed.ID,
+			IPAddress:    c.ClientIP(),
+		}
+		if !logAudit(c, cc.AuditService, entry) {
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+func respondCalendarError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCalendarFeedNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Calendar feed not found"})
+	case errors.Is(err, domain.ErrCalendarUnavailable):
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"hms-api/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentUser returns the authenticated user's id and role as set by the JWT
+// middleware, or zero values when the request isn't authenticated.
+func currentUser(c *gin.Context) (uuid.UUID, domain.UserRole) {
+	userIDCtx, _ := c.Get("x-user-id")
+	userID, _ := userIDCtx.(uuid.UUID)
+
+	roleCtx, _ := c.Get("x-user-role")
+	role, _ := roleCtx.(domain.UserRole)
+
+	return userID, role
+}
+
+// ensureParticipant makes sure patients and doctors only act on their own
+// appointments, given the appointment's patient and doctor. Admins can act on
+// any appointment.
+func ensureParticipant(c *gin.Context, pu domain.PatientUsecase, du domain.DoctorUsecase, patientID uuid.UUID, doctorID uuid.UUID) bool {
+	userID, role := currentUser(c)
+
+	switch role {
+	case domain.PatientRole:
+		patient, err := pu.FetchByUserID(c, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return false
+		}
+		if patient.ID == uuid.Nil || patient.ID != patientID {
+			c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "Patients can only manage their own appointments"})
+			return false
+		}
+	case domain.DoctorRole:
+		doctor, err := du.FetchByUserID(c, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return false
+		}
+		if doctor.ID == uuid.Nil || doctor.ID != doctorID {
+			c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "Doctors can only manage their own appointments"})
+			return false
+		}
+	}
+
+	return true
+}
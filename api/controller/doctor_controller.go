@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"context" // Added for audit logging
+	"errors"
 	"fmt"     // Added for audit logging descriptions
 	"hms-api/domain"
 	"hms-api/internal/auditservice"
@@ -36,19 +36,12 @@ func (dc *DoctorController) Create(c *gin.Context) {
 
 	err = dc.DoctorUsecase.Create(c, &doctor)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		respondDoctorError(c, err)
 		return
 	}
 
-	if dc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = dc.AuditService.Log(context.Background(), userID, "DOCTOR_CREATE", fmt.Sprintf("Doctor created with ID: %s", doctor.ID.String()))
-		}()
+	if !auditAction(c, dc.AuditService, "DOCTOR_CREATE", fmt.Sprintf("Doctor created with ID: %s", doctor.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusCreated, doctor)
@@ -88,15 +81,8 @@ func (dc *DoctorController) FetchByID(c *gin.Context) {
 		return
 	}
 
-	if dc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = dc.AuditService.Log(context.Background(), userID, "DOCTOR_FETCH_BY_ID", fmt.Sprintf("Fetched doctor with ID: %s", doctor.ID.String()))
-		}()
+	if !auditAction(c, dc.AuditService, "DOCTOR_FETCH_BY_ID", fmt.Sprintf("Fetched doctor with ID: %s", doctor.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusOK, doctor)
@@ -126,20 +112,13 @@ func (dc *DoctorController) Update(c *gin.Context) {
 
 	err = dc.DoctorUsecase.Update(c, &doctor)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		respondDoctorError(c, err)
 		return
 	}
 
 	// Audit Log
-	if dc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = dc.AuditService.Log(context.Background(), userID, "DOCTOR_UPDATE", fmt.Sprintf("Updated doctor with ID: %s", doctor.ID.String()))
-		}()
+	if !auditAction(c, dc.AuditService, "DOCTOR_UPDATE", fmt.Sprintf("Updated doctor with ID: %s", doctor.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusOK, doctor)
@@ -165,16 +144,18 @@ func (dc *DoctorController) Delete(c *gin.Context) {
 	}
 
 	// Audit Log
-	if dc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = dc.AuditService.Log(context.Background(), userID, "DOCTOR_DELETE", fmt.Sprintf("Deleted doctor with ID: %s", parsedID.String()))
-		}()
+	if !auditAction(c, dc.AuditService, "DOCTOR_DELETE", fmt.Sprintf("Deleted doctor with ID: %s", parsedID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+// respondDoctorError answers a facility that doesn't exist with 400.
+func respondDoctorError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrFacilityNotFound) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+}
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EncounterController struct {
+	EncounterUsecase domain.EncounterUsecase
+	DoctorUsecase    domain.DoctorUsecase
+	AuditService     auditservice.Service
+}
+
+func NewEncounterController(eu domain.EncounterUsecase, du domain.DoctorUsecase, as auditservice.Service) *EncounterController {
+	return &EncounterController{
+		EncounterUsecase: eu,
+		DoctorUsecase:    du,
+		AuditService:     as,
+	}
+}
+
+// Open opens an encounter for the calling doctor, from a checked-in
+// appointment or for a walk-in.
+func (ec *EncounterController) Open(c *gin.Context) {
+	var request domain.OpenEncounterRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	doctorID, ok := ec.currentDoctor(c)
+	if !ok {
+		return
+	}
+
+	userID, role := currentUser(c)
+	encounter, err := ec.EncounterUsecase.Open(c, request, doctorID, userID, role)
+	if err != nil {
+		respondEncounterError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ec.AuditService, "ENCOUNTER_OPEN", domain.ResourceEncounter, encounter.ID, encounter.PatientID, fmt.Sprintf("Encounter opened with ID: %s", encounter.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, encounter)
+}
+
+func (ec *EncounterController) FetchByID(c *gin.Context) {
+	parsedID, ok := uuidParam(c, "id", "Invalid encounter id format")
+	if !ok {
+		return
+	}
+
+	encounter, err := ec.EncounterUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		respondEncounterError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ec.AuditService, "ENCOUNTER_FETCH_BY_ID", domain.ResourceEncounter, encounter.ID, encounter.PatientID, fmt.Sprintf("Encounter fetched with ID: %s", encounter.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, encounter)
+}
+
+func (ec *EncounterController) FetchByPatientID(c *gin.Context) {
+	patientID, ok := uuidParam(c, "id", "Invalid patient id format")
+	if !ok {
+		return
+	}
+
+	encounters, err := ec.EncounterUsecase.FetchByPatientID(c, patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	var listed listedAccess
+	for _, encounter := range encounters {
+		listed.add(encounter.ID, encounter.PatientID)
+	}
+	if !auditListAccess(c, ec.AuditService, "ENCOUNTER_FETCH_BY_PATIENT", domain.ResourceEncounter, listed, fmt.Sprintf("Encounter listed for patient ID: %s", patientID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, encounters)
+}
+
+// Close closes the encounter and completes its appointment.
+func (ec *EncounterController) Close(c *gin.Context) {
+	parsedID, ok := uuidParam(c, "id", "Invalid encounter id format")
+	if !ok {
+		return
+	}
+
+	var request domain.CloseEncounterRequest
+	if err := c.ShouldBind(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	doctorID, ok := ec.currentDoctor(c)
+	if !ok {
+		return
+	}
+
+	userID, role := currentUser(c)
+	encounter, err := ec.EncounterUsecase.Close(c, parsedID, request.Notes, doctorID, userID, role)
+	if err != nil {
+		respondEncounterError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ec.AuditService, "ENCOUNTER_CLOSE", domain.ResourceEncounter, encounter.ID, encounter.PatientID, fmt.Sprintf("Encounter closed with ID: %s", encounter.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, encounter)
+}
+
+func (ec *EncounterController) AddMedicalRecord(c *gin.Context) {
+	parsedID, ok := uuidParam(c, "id", "Invalid encounter id format")
+	if !ok {
+		return
+	}
+
+	var record domain.MedicalRecord
+	if err := c.ShouldBind(&record); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	doctorID, ok := ec.currentDoctor(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := currentUser(c)
+	if err := ec.EncounterUsecase.AddMedicalRecord(c, parsedID, &record, doctorID, userID); err != nil {
+		respondEncounterError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ec.AuditService, "MEDICAL_RECORD_CREATE", domain.ResourceMedicalRecord, record.ID, record.PatientID, fmt.Sprintf("Medical Record created with ID: %s in encounter %s", record.ID, parsedID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, record)
+}
+
+func (ec *EncounterController) AddPrescription(c *gin.Context) {
+	parsedID, ok := uuidParam(c, "id", "Invalid encounter id format")
+	if !ok {
+		return
+	}
+
+	var prescription domain.Prescription
+	if err := c.ShouldBind(&prescription); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	doctorID, ok := ec.currentDoctor(c)
+	if !ok {
+		return
+	}
+
+	if !reserveAllergyOverrideAudit(c) {
+		return
+	}
+
+	if err := ec.EncounterUsecase.AddPrescription(c, parsedID, &prescription, doctorID); err != nil {
+		respondEncounterError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ec.AuditService, "PRESCRIPTION_CREATE", domain.ResourcePrescription, prescription.ID, prescription.PatientID, fmt.Sprintf("Prescription created with ID: %s in encounter %s", prescription.ID, parsedID)) {
+		return
+	}
+	if !auditAllergyOverride(c, ec.AuditService, &prescription) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, prescription)
+}
+
+func (ec *EncounterController) AddOrder(c *gin.Context) {
+	parsedID, ok := uuidParam(c, "id", "Invalid encounter id format")
+	if !ok {
+		return
+	}
+
+	var order domain.EncounterOrder
+	if err := c.ShouldBind(&order); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	doctorID, ok := ec.currentDoctor(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := currentUser(c)
+	if err := ec.EncounterUsecase.AddOrder(c, parsedID, &order, doctorID, userID); err != nil {
+		respondEncounterError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, ec.AuditService, "ENCOUNTER_ORDER_CREATE", domain.ResourceEncounter, parsedID, order.PatientID, fmt.Sprintf("%s order %s created in encounter %s", order.Kind, order.ID, parsedID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// currentDoctor resolves the calling user's doctor profile.
+func (ec *EncounterController) currentDoctor(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := currentUser(c)
+
+	doctor, err := ec.DoctorUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return uuid.Nil, false
+	}
+
+	if doctor.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Doctor profile not found for this user"})
+		return uuid.Nil, false
+	}
+
+	return doctor.ID, true
+}
+
+func respondEncounterError(c *gin.Context, err error) {
+	var allergyConflict *domain.AllergyConflictError
+	switch {
+	case errors.As(err, &allergyConflict):
+		respondPrescriptionError(c, err)
+	case errors.Is(err, domain.ErrEncounterNotFound), errors.Is(err, domain.ErrPatientNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrEncounterForbidden):
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrEncounterClosed), errors.Is(err, domain.ErrEncounterExists):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrInvalidEncounter):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrInvalidMedicalRecord), errors.Is(err, domain.ErrInvalidDiagnosis), errors.Is(err, domain.ErrMedicalRecordEnteredInError):
+		respondMedicalRecordError(c, err)
+	case errors.Is(err, domain.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		respondTransitionError(c, err, "")
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FacilityController struct {
+	FacilityUsecase domain.FacilityUsecase
+	TimezoneUsecase domain.TimezoneUsecase
+	AuditService    auditservice.Service
+}
+
+func NewFacilityController(fu domain.FacilityUsecase, tu domain.TimezoneUsecase, as auditservice.Service) *FacilityController {
+	return &FacilityController{
+		FacilityUsecase: fu,
+		TimezoneUsecase: tu,
+		AuditService:    as,
+	}
+}
+
+func (fc *FacilityController) Create(c *gin.Context) {
+	var facility domain.Facility
+
+	if err := c.ShouldBind(&facility); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := fc.FacilityUsecase.Create(c, &facility); err != nil {
+		respondFacilityError(c, err)
+		return
+	}
+
+	if !auditAction(c, fc.AuditService, "FACILITY_CREATE", fmt.Sprintf("Facility created with ID: %s", facility.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, facility)
+}
+
+func (fc *FacilityController) Fetch(c *gin.Context) {
+	facilities, err := fc.FacilityUsecase.Fetch(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if facilities == nil {
+		facilities = []domain.Facility{}
+	}
+
+	c.JSON(http.StatusOK, facilities)
+}
+
+func (fc *FacilityController) FetchByID(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid facility id"})
+		return
+	}
+
+	facility, err := fc.FacilityUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		respondFacilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, facility)
+}
+
+func (fc *FacilityController) Update(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid facility id"})
+		return
+	}
+
+	var facility domain.Facility
+	if err := c.ShouldBind(&facility); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	facility.ID = parsedID
+
+	if err := fc.FacilityUsecase.Update(c, &facility); err != nil {
+		respondFacilityError(c, err)
+		return
+	}
+
+	if !auditAction(c, fc.AuditService, "FACILITY_UPDATE", fmt.Sprintf("Updated facility with ID: %s", facility.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, facility)
+}
+
+func (fc *FacilityController) Delete(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid facility id"})
+		return
+	}
+
+	if err := fc.FacilityUsecase.Delete(c, parsedID); err != nil {
+		respondFacilityError(c, err)
+		return
+	}
+
+	if !auditAction(c, fc.AuditService, "FACILITY_DELETE", fmt.Sprintf("Deleted facility with ID: %s", parsedID)) {
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// FetchMyTimezone returns the caller's timezone, empty when they read times
+// in their appointments' facility time.
+func (fc *FacilityController) FetchMyTimezone(c *gin.Context) {
+	userID, _ := currentUser(c)
+
+	timezone, err := fc.TimezoneUsecase.Fetch(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.TimezoneRequest{Timezone: timezone})
+}
+
+func (fc *FacilityController) UpdateMyTimezone(c *gin.Context) {
+	var request domain.TimezoneRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	if err := fc.TimezoneUsecase.Update(c, userID, request.Timezone); err != nil {
+		respondFacilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+func respondFacilityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrFacilityNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Facility not found"})
+	case errors.Is(err, domain.ErrInvalidFacility), errors.Is(err, domain.ErrInvalidTimezone):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrDuplicateFacility), errors.Is(err, domain.ErrFacilityInUse):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"hms-api/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ICD10Controller struct {
+	Catalog domain.ICD10Catalog
+}
+
+func NewICD10Controller(ic domain.ICD10Catalog) *ICD10Controller {
+	return &ICD10Controller{
+		Catalog: ic,
+	}
+}
+
+// Search finds codes by code or by description, ignoring case and accents,
+// returning up to limit of them (20 by default, 100 at most).
+func (icc *ICD10Controller) Search(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "q is required"})
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	c.JSON(http.StatusOK, icc.Catalog.Search(query, limit))
+}
@@ -1,11 +1,11 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"hms-api/bootstrap"
 	"hms-api/domain"
 	"hms-api/internal/auditservice"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -43,9 +43,15 @@ func (lc *LoginController) Login(c *gin.Context) {
 
 	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)) != nil {
 		if lc.AuditService != nil {
-			go func() {
-				_ = lc.AuditService.Log(context.Background(), user.ID, "USER_LOGIN_FAILED", fmt.Sprintf("Failed login attempt for email: %s", request.Email))
-			}()
+			if err := lc.AuditService.LogEntry(c.Request.Context(), domain.AuditLog{
+				UserID:      user.ID,
+				UserRole:    user.Role,
+				Action:      "USER_LOGIN_FAILED",
+				Description: fmt.Sprintf("Failed login attempt for email: %s", request.Email),
+				IPAddress:   c.ClientIP(),
+			}); err != nil {
+				log.Printf("[ERROR] Audit: failed to record USER_LOGIN_FAILED: %v\n", err)
+			}
 		}
 		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "Invalid credentials"})
 		return
@@ -64,9 +70,16 @@ func (lc *LoginController) Login(c *gin.Context) {
 	}
 
 	if lc.AuditService != nil {
-		go func() {
-			_ = lc.AuditService.Log(context.Background(), user.ID, "USER_LOGIN_SUCCESS", fmt.Sprintf("User %s logged in successfully", user.Email))
-		}()
+		err := lc.AuditService.LogEntry(c.Request.Context(), domain.AuditLog{
+			UserID:      user.ID,
+			UserRole:    user.Role,
+			Action:      "USER_LOGIN_SUCCESS",
+			Description: fmt.Sprintf("User %s logged in successfully", user.Email),
+			IPAddress:   c.ClientIP(),
+		})
+		if !audited(c, "USER_LOGIN_SUCCESS", err) {
+			return
+		}
 	}
 
 	loginResponse := domain.LoginResponse{
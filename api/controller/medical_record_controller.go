@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"hms-api/domain"
 	"hms-api/internal/auditservice"
@@ -32,21 +32,20 @@ func (mrc *MedicalRecordController) Create(c *gin.Context) {
 		return
 	}
 
+	userID, _ := currentUser(c)
+	record.UpdatedBy = &userID
+	// Records are attached to encounters through the encounter, which checks
+	// it's open.
+	record.EncounterID = nil
+
 	err = mrc.MedicalRecordUsecase.Create(c, &record)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		respondMedicalRecordError(c, err)
 		return
 	}
 
-	if mrc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = mrc.AuditService.Log(context.Background(), userID, "MEDICAL_RECORD_CREATE", fmt.Sprintf("Medical Record created with ID: %s", record.ID.String()))
-		}()
+	if !auditPatientAccess(c, mrc.AuditService, "MEDICAL_RECORD_CREATE", domain.ResourceMedicalRecord, record.ID, record.PatientID, fmt.Sprintf("Medical Record created with ID: %s", record.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusCreated, record)
@@ -59,6 +58,14 @@ func (mrc *MedicalRecordController) Fetch(c *gin.Context) {
 		return
 	}
 
+	var listed listedAccess
+	for _, record := range records {
+		listed.add(record.ID, record.PatientID)
+	}
+	if !auditListAccess(c, mrc.AuditService, "MEDICAL_RECORD_FETCH", domain.ResourceMedicalRecord, listed, "Medical Record listed") {
+		return
+	}
+
 	c.JSON(http.StatusOK, records)
 }
 
@@ -81,20 +88,13 @@ func (mrc *MedicalRecordController) FetchByID(c *gin.Context) {
 		return
 	}
 
-	if record.ID == uuid.Nil {
+	if record == nil {
 		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Record not found"})
 		return
 	}
 
-	if mrc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = mrc.AuditService.Log(context.Background(), userID, "MEDICAL_RECORD_FETCH_BY_ID", fmt.Sprintf("Medical Record fetched with ID: %s", parsedID.String()))
-		}()
+	if !auditPatientAccess(c, mrc.AuditService, "MEDICAL_RECORD_FETCH_BY_ID", domain.ResourceMedicalRecord, parsedID, record.PatientID, fmt.Sprintf("Medical Record fetched with ID: %s", parsedID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusOK, record)
@@ -123,80 +123,183 @@ func (mrc *MedicalRecordController) FetchByDoctorID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "No records found for this doctor"})
 		return
 	}
+
+	var listed listedAccess
+	for _, record := range records {
+		listed.add(record.ID, record.PatientID)
+	}
+	if !auditListAccess(c, mrc.AuditService, "MEDICAL_RECORD_FETCH_BY_DOCTOR", domain.ResourceMedicalRecord, listed, fmt.Sprintf("Medical Record listed for doctor ID: %s", doctorID)) {
+		return
+	}
 	
 	c.JSON(http.StatusOK, records)
 }
 
 func (mrc *MedicalRecordController) Update(c *gin.Context) {
-	recordID := c.Param("id")
-	if recordID == "" {
-		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "record id is required"})
+	parsedID, ok := medicalRecordFromParam(c)
+	if !ok {
+		return
 	}
 
-	parsedID, err := uuid.Parse(recordID)
+	var change domain.MedicalRecordChange
+	err := c.ShouldBind(&change)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid record id format"})
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	var record domain.MedicalRecord
-	err = c.ShouldBind(&record)
+	userID, _ := currentUser(c)
+	record, err := mrc.MedicalRecordUsecase.Update(c, parsedID, change, userID)
 	if err != nil {
+		respondMedicalRecordError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, mrc.AuditService, "MEDICAL_RECORD_UPDATE", domain.ResourceMedicalRecord, record.ID, record.PatientID, fmt.Sprintf("Medical Record %s updated to version %d: %s", record.ID, record.Version, change.Reason)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// Amend adds a record referencing this one, which is left as it was.
+func (mrc *MedicalRecordController) Amend(c *gin.Context) {
+	parsedID, ok := medicalRecordFromParam(c)
+	if !ok {
+		return
+	}
+
+	var change domain.MedicalRecordChange
+	if err := c.ShouldBind(&change); err != nil {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	record.ID = parsedID
+	userID, _ := currentUser(c)
+	amendment, err := mrc.MedicalRecordUsecase.Amend(c, parsedID, change, userID)
+	if err != nil {
+		respondMedicalRecordError(c, err)
+		return
+	}
 
-	err = mrc.MedicalRecordUsecase.Update(c, &record)
+	if !auditPatientAccess(c, mrc.AuditService, "MEDICAL_RECORD_AMEND", domain.ResourceMedicalRecord, amendment.ID, amendment.PatientID, fmt.Sprintf("Medical Record %s amends %s: %s", amendment.ID, parsedID, change.Reason)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, amendment)
+}
+
+func (mrc *MedicalRecordController) FetchVersions(c *gin.Context) {
+	record, ok := mrc.recordFromParam(c)
+	if !ok {
+		return
+	}
+
+	versions, err := mrc.MedicalRecordUsecase.FetchVersions(c, record.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		respondMedicalRecordError(c, err)
 		return
 	}
 
-	if mrc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = mrc.AuditService.Log(context.Background(), userID, "MEDICAL_RECORD_UPDATE", fmt.Sprintf("Medical Record updated with ID: %s", record.ID.String()))
-		}()
+	if !auditPatientAccess(c, mrc.AuditService, "MEDICAL_RECORD_FETCH_VERSIONS", domain.ResourceMedicalRecord, record.ID, record.PatientID, fmt.Sprintf("Medical Record versions fetched for ID: %s", record.ID)) {
+		return
 	}
 
-	c.JSON(http.StatusOK, record)
+	c.JSON(http.StatusOK, versions)
 }
 
+func (mrc *MedicalRecordController) FetchAmendments(c *gin.Context) {
+	record, ok := mrc.recordFromParam(c)
+	if !ok {
+		return
+	}
+
+	amendments, err := mrc.MedicalRecordUsecase.FetchAmendments(c, record.ID)
+	if err != nil {
+		respondMedicalRecordError(c, err)
+		return
+	}
+
+	if amendments == nil {
+		amendments = []domain.MedicalRecord{}
+	}
+
+	var listed listedAccess
+	for _, amendment := range amendments {
+		listed.add(amendment.ID, amendment.PatientID)
+	}
+	if !auditListAccess(c, mrc.AuditService, "MEDICAL_RECORD_FETCH_AMENDMENTS", domain.ResourceMedicalRecord, listed, fmt.Sprintf("Medical Record listed as an amendment of ID: %s", record.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, amendments)
+}
+
+// Delete marks the record as entered in error. Records are never removed.
 func (mrc *MedicalRecordController) Delete(c *gin.Context) {
-	recordID := c.Param("id")
-	if recordID == "" {
-		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "record id is required"})
+	parsedID, ok := medicalRecordFromParam(c)
+	if !ok {
 		return
 	}
 
-	parsedID, err := uuid.Parse(recordID)
+	var request domain.EnteredInErrorRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	record, err := mrc.MedicalRecordUsecase.MarkEnteredInError(c, parsedID, request.Reason, userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid record id format"})
+		respondMedicalRecordError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, mrc.AuditService, "MEDICAL_RECORD_ENTERED_IN_ERROR", domain.ResourceMedicalRecord, parsedID, record.PatientID, fmt.Sprintf("Medical Record %s marked as entered in error: %s", parsedID, request.Reason)) {
 		return
 	}
 
-	err = mrc.MedicalRecordUsecase.Delete(c, parsedID)
+	c.JSON(http.StatusOK, record)
+}
+
+func (mrc *MedicalRecordController) recordFromParam(c *gin.Context) (*domain.MedicalRecord, bool) {
+	parsedID, ok := medicalRecordFromParam(c)
+	if !ok {
+		return nil, false
+	}
+
+	record, err := mrc.MedicalRecordUsecase.FetchByID(c, parsedID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
-		return
+		return nil, false
 	}
 
-	if mrc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = mrc.AuditService.Log(context.Background(), userID, "MEDICAL_RECORD_DELETE", fmt.Sprintf("Medical Record deleted with ID: %s", parsedID.String()))
-		}()
+	if record == nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Record not found"})
+		return nil, false
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	return record, true
+}
+
+func medicalRecordFromParam(c *gin.Context) (uuid.UUID, bool) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid record id format"})
+		return uuid.Nil, false
+	}
+	return parsedID, true
+}
+
+func respondMedicalRecordError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrMedicalRecordNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Record not found"})
+	case errors.Is(err, domain.ErrInvalidMedicalRecord), errors.Is(err, domain.ErrInvalidDiagnosis):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrMedicalRecordEnteredInError):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
 }
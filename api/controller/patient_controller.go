@@ -1,7 +1,6 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"hms-api/domain"
 	"hms-api/internal/auditservice"
@@ -93,15 +92,8 @@ func (pc *PatientController) Create(c *gin.Context) {
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PATIENT_CREATE", fmt.Sprintf("Patient created with ID: %s", patient.ID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PATIENT_CREATE", domain.ResourcePatient, patient.ID, patient.ID, fmt.Sprintf("Patient created with ID: %s", patient.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusCreated, patient)
@@ -114,6 +106,14 @@ func (pc *PatientController) Fetch(c *gin.Context) {
 		return
 	}
 
+	var listed listedAccess
+	for _, patient := range patients {
+		listed.add(patient.ID, patient.ID)
+	}
+	if !auditListAccess(c, pc.AuditService, "PATIENT_FETCH", domain.ResourcePatient, listed, "Patient listed") {
+		return
+	}
+
 	c.JSON(http.StatusOK, patients)
 }
 
@@ -141,15 +141,8 @@ func (pc *PatientController) FetchByID(c *gin.Context) {
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PATIENT_FETCH_BY_ID", fmt.Sprintf("Patient fetched with ID: %s", patient.ID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PATIENT_FETCH_BY_ID", domain.ResourcePatient, patient.ID, patient.ID, fmt.Sprintf("Patient fetched with ID: %s", patient.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusOK, patient)
@@ -179,6 +172,14 @@ func (pc *PatientController) FetchByDoctorID(c *gin.Context){
 		return
 	}
 
+	var listed listedAccess
+	for _, patient := range patients {
+		listed.add(patient.ID, patient.ID)
+	}
+	if !auditListAccess(c, pc.AuditService, "PATIENT_FETCH_BY_DOCTOR", domain.ResourcePatient, listed, fmt.Sprintf("Patient listed for doctor ID: %s", doctorID)) {
+		return
+	}
+
 	c.JSON(http.StatusOK, patients)
 }
 
@@ -216,15 +217,8 @@ func (pc *PatientController) Update(c *gin.Context) {
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PATIENT_UPDATE", fmt.Sprintf("Patient updated with ID: %s", patient.ID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PATIENT_UPDATE", domain.ResourcePatient, patient.ID, patient.ID, fmt.Sprintf("Patient updated with ID: %s", patient.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusOK, patient)
@@ -250,15 +244,8 @@ func (pc *PatientController) Delete(c *gin.Context) {
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PATIENT_DELETE", fmt.Sprintf("Patient deleted with ID: %s", parsedID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PATIENT_DELETE", domain.ResourcePatient, parsedID, parsedID, fmt.Sprintf("Patient deleted with ID: %s", parsedID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
@@ -1,11 +1,12 @@
 package controller
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"hms-api/domain"
 	"hms-api/internal/auditservice"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -32,21 +33,25 @@ func (pc *PrescriptionController) Create(c *gin.Context) {
 		return
 	}
 
+	// Prescriptions are attached to encounters through the encounter, which
+	// checks it's open.
+	prescription.EncounterID = nil
+
+	if !reserveAllergyOverrideAudit(c) {
+		return
+	}
+
 	err = pc.PrescriptionUsecase.Create(c, &prescription)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		respondPrescriptionError(c, err)
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PRESCRIPTION_CREATE", fmt.Sprintf("Doctor created with ID: %s", prescription.ID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PRESCRIPTION_CREATE", domain.ResourcePrescription, prescription.ID, prescription.PatientID, fmt.Sprintf("Doctor created with ID: %s", prescription.ID.String())) {
+		return
+	}
+	if !auditAllergyOverride(c, pc.AuditService, &prescription) {
+		return
 	}
 
 	c.JSON(http.StatusCreated, prescription)
@@ -56,6 +61,15 @@ func (pc *PrescriptionController) Fetch(c *gin.Context) {
 	prescriptions, err := pc.PrescriptionUsecase.Fetch(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	var listed listedAccess
+	for _, prescription := range prescriptions {
+		listed.add(prescription.ID, prescription.PatientID)
+	}
+	if !auditListAccess(c, pc.AuditService, "PRESCRIPTION_FETCH", domain.ResourcePrescription, listed, "Prescription listed") {
+		return
 	}
 
 	c.JSON(http.StatusOK, prescriptions)
@@ -80,20 +94,13 @@ func (pc *PrescriptionController) FetchByID(c *gin.Context) {
 		return
 	}
 
-	if prescription.ID == uuid.Nil {
+	if prescription == nil {
 		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Prescription not found"})
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PRESCRIPTION_FETCH_BY_ID", fmt.Sprintf("Prescription fetched with ID: %s", prescription.ID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PRESCRIPTION_FETCH_BY_ID", domain.ResourcePrescription, prescription.ID, prescription.PatientID, fmt.Sprintf("Prescription fetched with ID: %s", prescription.ID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusOK, prescription)
@@ -120,6 +127,15 @@ func (pc *PrescriptionController) FetchByPatientID(c *gin.Context) {
 
 	if prescriptions == nil {
 		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "No prescriptions found for this patient"})
+		return
+	}
+
+	var listed listedAccess
+	for _, prescription := range prescriptions {
+		listed.add(prescription.ID, prescription.PatientID)
+	}
+	if !auditListAccess(c, pc.AuditService, "PRESCRIPTION_FETCH_BY_PATIENT", domain.ResourcePrescription, listed, fmt.Sprintf("Prescription listed for patient ID: %s", patientID)) {
+		return
 	}
 
 	c.JSON(http.StatusOK, prescriptions)
@@ -149,6 +165,14 @@ func (pc *PrescriptionController) FetchByDoctorID(c *gin.Context) {
 		return
 	}
 
+	var listed listedAccess
+	for _, prescription := range prescriptions {
+		listed.add(prescription.ID, prescription.PatientID)
+	}
+	if !auditListAccess(c, pc.AuditService, "PRESCRIPTION_FETCH_BY_DOCTOR", domain.ResourcePrescription, listed, fmt.Sprintf("Prescription listed for doctor ID: %s", doctorID)) {
+		return
+	}
+
 	c.JSON(http.StatusOK, prescriptions)
 }
 
@@ -169,25 +193,26 @@ func (pc *PrescriptionController) Update(c *gin.Context) {
 	err = c.ShouldBind(&prescription)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
 	}
 
 	prescription.ID = parsedID
 
+	if !reserveAllergyOverrideAudit(c) {
+		return
+	}
+
 	err = pc.PrescriptionUsecase.Update(c, &prescription)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		respondPrescriptionError(c, err)
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PRESCRIPTION_UPDATE", fmt.Sprintf("Prescription updated with ID: %s", prescription.ID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PRESCRIPTION_UPDATE", domain.ResourcePrescription, prescription.ID, prescription.PatientID, fmt.Sprintf("Prescription updated with ID: %s", prescription.ID.String())) {
+		return
+	}
+	if !auditAllergyOverride(c, pc.AuditService, &prescription) {
+		return
 	}
 
 	c.JSON(http.StatusOK, prescription)
@@ -206,22 +231,67 @@ func (pc *PrescriptionController) Delete(c *gin.Context) {
 		return
 	}
 
+	prescription, err := pc.PrescriptionUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if prescription == nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Prescription not found"})
+		return
+	}
+
 	err = pc.PrescriptionUsecase.Delete(c, parsedID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	if pc.AuditService != nil {
-		userIDCtx, _ := c.Get("x-user-id")
-		userID := uuid.Nil
-		if id, ok := userIDCtx.(uuid.UUID); ok {
-			userID = id
-		}
-		go func() {
-			_ = pc.AuditService.Log(context.Background(), userID, "PRESCRIPTION_DELETE", fmt.Sprintf("Prescription deleted with ID: %s", parsedID.String()))
-		}()
+	if !auditPatientAccess(c, pc.AuditService, "PRESCRIPTION_DELETE", domain.ResourcePrescription, parsedID, prescription.PatientID, fmt.Sprintf("Prescription deleted with ID: %s", parsedID.String())) {
+		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// reserveAllergyOverrideAudit reserves room for the override entry a
+// prescription may need before it's stored, since whether it does is only
+// known once the usecase has checked it. It reports false like reserveAudit.
+func reserveAllergyOverrideAudit(c *gin.Context) bool {
+	return reserveAudit(c, 1)
+}
+
+// auditAllergyOverride records that a prescription was made despite matching
+// the patient's active allergies, and why, in the room reserved by
+// reserveAllergyOverrideAudit. The reason and warnings themselves are stored
+// with the prescription. It reports false like auditPatientAccess.
+func auditAllergyOverride(c *gin.Context, as auditservice.Service, prescription *domain.Prescription) bool {
+	if len(prescription.AllergyWarnings) == 0 {
+		return true
+	}
+
+	substances := make([]string, 0, len(prescription.AllergyWarnings))
+	for _, warning := range prescription.AllergyWarnings {
+		substances = append(substances, fmt.Sprintf("%s (%s, matched %q)", warning.Substance, warning.Severity, warning.Matched))
+	}
+
+	return auditPatientAccess(c, as, "PRESCRIPTION_ALLERGY_OVERRIDE", domain.ResourcePrescription, prescription.ID, prescription.PatientID, fmt.Sprintf("Prescription %s overrode allergies to %s: %s", prescription.ID, strings.Join(substances, ", "), prescription.AllergyOverrideReason))
+}
+
+// respondPrescriptionError answers prescriptions matching the patient's active
+// allergies with 409 and the warnings to override.
+func respondPrescriptionError(c *gin.Context, err error) {
+	var conflict *domain.AllergyConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, domain.AllergyConflictResponse{
+			Message:  conflict.Error(),
+			Warnings: conflict.Warnings,
+		})
+	case errors.Is(err, domain.ErrPrescriptionNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
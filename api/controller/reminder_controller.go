@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReminderController struct {
+	ReminderUsecase domain.ReminderUsecase
+	PatientUsecase  domain.PatientUsecase
+	AuditService    auditservice.Service
+}
+
+func NewReminderController(ru domain.ReminderUsecase, pu domain.PatientUsecase, as auditservice.Service) *ReminderController {
+	return &ReminderController{
+		ReminderUsecase: ru,
+		PatientUsecase:  pu,
+		AuditService:    as,
+	}
+}
+
+// reminderPage is what patients see when they open a reminder link, and
+// after they submit it, filled from the notification templates in the
+// patient's language. Form is only set while the action is still to be
+// confirmed.
+var reminderPage = template.Must(template.New("reminder").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{with .Form}}<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Button}}</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+type reminderPageData struct {
+	Language string
+	Title    string
+	Message  string
+	Form     *reminderForm
+}
+
+type reminderForm struct {
+	Action string
+	Token  string
+	Button string
+}
+
+// reminderFailure is why a reminder link couldn't be shown or applied: the
+// status to answer with, the message API clients get and the reason the page
+// gives the patient.
+type reminderFailure struct {
+	status  int
+	message string
+	reason  string
+}
+
+var missingReminderToken = reminderFailure{status: http.StatusBadRequest, message: "token is required", reason: notification.LinkMissing}
+
+// Page shows what a confirm or cancel link will do and asks the patient to
+// submit it. Opening the link changes nothing, so mail scanners and link
+// previews that fetch it can't confirm or cancel appointments.
+func (rc *ReminderController) Page(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			rc.renderFailure(c, uuid.Nil, missingReminderToken)
+			return
+		}
+
+		appointment, err := rc.ReminderUsecase.Preview(c, token, action)
+		if err != nil {
+			rc.renderFailure(c, appointment.PatientID, reminderFailureFor(err, appointment))
+			return
+		}
+
+		name := notification.ReminderConfirmPage
+		if action == domain.ReminderCancel {
+			name = notification.ReminderCancelPage
+		}
+		data := notification.ReminderPageData{Start: appointment.AppointmentDate.In(domain.Location(appointment.Timezone))}
+		form := &reminderForm{Action: c.Request.URL.Path, Token: token}
+		renderReminderPage(c, http.StatusOK, rc.ReminderUsecase.PageLanguage(c, appointment.PatientID), name, data, form)
+	}
+}
+
+// Respond applies a confirm or cancel link. The signed token, sent as the
+// token form field or in ?token=, stands in for authentication. Browsers
+// submitting the page get a page back; other clients get JSON.
+func (rc *ReminderController) Respond(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.PostForm("token")
+		if token == "" {
+			token = c.Query("token")
+		}
+		if token == "" {
+			rc.respondFailure(c, uuid.Nil, missingReminderToken)
+			return
+		}
+
+		appointment, err := rc.ReminderUsecase.Respond(c, token, action)
+		if err != nil {
+			failure := reminderFailureFor(err, appointment)
+			if failure.status == http.StatusInternalServerError && !wantsHTML(c) {
+				respondAppointmentError(c, err)
+				return
+			}
+			rc.respondFailure(c, appointment.PatientID, failure)
+			return
+		}
+
+		if !rc.auditResponse(c, action, appointment) {
+			return
+		}
+
+		if wantsHTML(c) {
+			name := notification.ReminderConfirmedPage
+			if action == domain.ReminderCancel {
+				name = notification.ReminderCanceledPage
+			}
+			renderReminderPage(c, http.StatusOK, rc.ReminderUsecase.PageLanguage(c, appointment.PatientID), name, notification.ReminderPageData{}, nil)
+			return
+		}
+		c.JSON(http.StatusOK, appointment)
+	}
+}
+
+func (rc *ReminderController) respondFailure(c *gin.Context, patientID uuid.UUID, failure reminderFailure) {
+	if wantsHTML(c) {
+		rc.renderFailure(c, patientID, failure)
+		return
+	}
+	c.JSON(failure.status, domain.ErrorResponse{Message: failure.message})
+}
+
+// renderFailure shows the failure page in the language of the patient the
+// link is for, or the default one when that isn't known.
+func (rc *ReminderController) renderFailure(c *gin.Context, patientID uuid.UUID, failure reminderFailure) {
+	language := rc.ReminderUsecase.PageLanguage(c, patientID)
+	renderReminderPage(c, failure.status, language, notification.ReminderLinkFailed, notification.ReminderFailureData{Reason: failure.reason}, nil)
+}
+
+// reminderFailureFor maps an error showing or applying a reminder link to
+// the failure to answer with.
+func reminderFailureFor(err error, appointment domain.Appointment) reminderFailure {
+	switch {
+	case errors.Is(err, domain.ErrInvalidReminderLink):
+		return reminderFailure{status: http.StatusUnauthorized, message: err.Error(), reason: notification.LinkInvalid}
+	case errors.Is(err, domain.ErrStaleReminderLink):
+		return reminderFailure{status: http.StatusGone, message: "The appointment changed after this reminder was sent; use the links of its latest reminder", reason: notification.LinkStale}
+	case errors.Is(err, domain.ErrAppointmentNotFound):
+		return reminderFailure{status: http.StatusNotFound, message: err.Error(), reason: notification.LinkNotFound}
+	case errors.Is(err, domain.ErrApprovalRequired):
+		return reminderFailure{status: http.StatusForbidden, message: err.Error(), reason: notification.LinkApprovalRequired}
+	case errors.Is(err, domain.ErrInvalidTransition):
+		return reminderFailure{status: http.StatusConflict, message: fmt.Sprintf("The appointment is %s and can no longer be changed from a reminder", appointment.Status), reason: notification.LinkUnchangeable}
+	default:
+		return reminderFailure{status: http.StatusInternalServerError, message: err.Error(), reason: notification.LinkError}
+	}
+}
+
+func wantsHTML(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+}
+
+// renderReminderPage fills the page with the named notification template in
+// language. The template's short text labels the form's button.
+func renderReminderPage(c *gin.Context, status int, language string, name string, data interface{}, form *reminderForm) {
+	message, err := notification.Render(language, name, data)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if form != nil {
+		form.Button = message.Short
+	}
+
+	var page bytes.Buffer
+	err = reminderPage.Execute(&page, reminderPageData{Language: language, Title: message.Subject, Message: message.Body, Form: form})
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
+
+func (rc *ReminderController) FetchPreference(c *gin.Context) {
+	patient, ok := rc.currentPatient(c)
+	if !ok {
+		return
+	}
+
+	preference, err := rc.ReminderUsecase.FetchPreference(c, patient.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+func (rc *ReminderController) UpdatePreference(c *gin.Context) {
+	var preference domain.NotificationPreference
+
+	if err := c.ShouldBind(&preference); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	patient, ok := rc.currentPatient(c)
+	if !ok {
+		return
+	}
+	preference.PatientID = patient.ID
+
+	if err := rc.ReminderUsecase.UpdatePreference(c, &preference); err != nil {
+		if errors.Is(err, domain.ErrUnsupportedLanguage) || errors.Is(err, domain.ErrInvalidNotificationChannel) {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+func (rc *ReminderController) currentPatient(c *gin.Context) (domain.Patient, bool) {
+	userID, _ := currentUser(c)
+
+	patient, err := rc.PatientUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return domain.Patient{}, false
+	}
+	if patient.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient profile not found for this user"})
+		return domain.Patient{}, false
+	}
+
+	return patient, true
+}
+
+// auditResponse records a reminder link use as the patient, since the request
+// carries no session to take the user from.
+func (rc *ReminderController) auditResponse(c *gin.Context, action string, appointment domain.Appointment) bool {
+	if rc.AuditService == nil {
+		return true
+	}
+
+	patient, err := rc.PatientUsecase.FetchByID(c, appointment.PatientID)
+	if err != nil {
+		log.Printf("[ERROR] Audit: failed to load patient for reminder %s: %v\n", action, err)
+	}
+
+	entry := domain.AuditLog{
+		UserID:       patient.UserId,
+		UserRole:     domain.PatientRole,
+		Action:       "APPOINTMENT_REMINDER_" + strings.ToUpper(action),
+		Description:  fmt.Sprintf("Appointment %s %s from a reminder link", appointment.ID, appointment.Status),
+		ResourceType: domain.ResourceAppointment,
+		ResourceID:   appointment.ID,
+		PatientID:    appointment.PatientID,
+		Purpose:      "self-access",
+		IPAddress:    c.ClientIP(),
+	}
+
+	return logAudit(c, rc.AuditService, entry)
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ResourceController struct {
+	ResourceUsecase domain.ResourceUsecase
+	AuditService    auditservice.Service
+}
+
+func NewResourceController(ru domain.ResourceUsecase, as auditservice.Service) *ResourceController {
+	return &ResourceController{
+		ResourceUsecase: ru,
+		AuditService:    as,
+	}
+}
+
+func (rc *ResourceController) Create(c *gin.Context) {
+	var resource domain.Resource
+
+	if err := c.ShouldBind(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := rc.ResourceUsecase.Create(c, &resource); err != nil {
+		respondResourceError(c, err)
+		return
+	}
+
+	if !auditAction(c, rc.AuditService, "RESOURCE_CREATE", fmt.Sprintf("Resource created with ID: %s", resource.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, resource)
+}
+
+func (rc *ResourceController) Fetch(c *gin.Context) {
+	resources, err := rc.ResourceUsecase.Fetch(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if resources == nil {
+		resources = []domain.Resource{}
+	}
+
+	c.JSON(http.StatusOK, resources)
+}
+
+func (rc *ResourceController) FetchByID(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid resource id"})
+		return
+	}
+
+	resource, err := rc.ResourceUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		respondResourceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+func (rc *ResourceController) Update(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid resource id"})
+		return
+	}
+
+	var resource domain.Resource
+	if err := c.ShouldBind(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	resource.ID = parsedID
+
+	if err := rc.ResourceUsecase.Update(c, &resource); err != nil {
+		respondResourceError(c, err)
+		return
+	}
+
+	if !auditAction(c, rc.AuditService, "RESOURCE_UPDATE", fmt.Sprintf("Updated resource with ID: %s", resource.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+func (rc *ResourceController) Delete(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid resource id"})
+		return
+	}
+
+	if err := rc.ResourceUsecase.Delete(c, parsedID); err != nil {
+		respondResourceError(c, err)
+		return
+	}
+
+	if !auditAction(c, rc.AuditService, "RESOURCE_DELETE", fmt.Sprintf("Deactivated resource with ID: %s", parsedID)) {
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func respondResourceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrResourceNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Resource not found"})
+	case errors.Is(err, domain.ErrInvalidResource):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	}
+}
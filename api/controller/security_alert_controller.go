@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SecurityAlertController struct {
+	SecurityAlertUsecase domain.SecurityAlertUsecase
+	AuditService         auditservice.Service
+}
+
+func NewSecurityAlertController(sau domain.SecurityAlertUsecase, as auditservice.Service) *SecurityAlertController {
+	return &SecurityAlertController{
+		SecurityAlertUsecase: sau,
+		AuditService:         as,
+	}
+}
+
+func (sac *SecurityAlertController) Fetch(c *gin.Context) {
+	status := domain.AlertStatus(c.Query("status"))
+	switch status {
+	case "", domain.AlertStatusOpen, domain.AlertStatusAcknowledged, domain.AlertStatusResolved:
+	default:
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid status, expected open, acknowledged or resolved"})
+		return
+	}
+
+	alerts, err := sac.SecurityAlertUsecase.Fetch(c, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if alerts == nil {
+		alerts = []domain.SecurityAlert{}
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (sac *SecurityAlertController) FetchByID(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid alert id format"})
+		return
+	}
+
+	alert, err := sac.SecurityAlertUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if alert == nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Alert not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+func (sac *SecurityAlertController) Acknowledge(c *gin.Context) {
+	sac.transition(c, "SECURITY_ALERT_ACKNOWLEDGE", func(id uuid.UUID, userID uuid.UUID) error {
+		return sac.SecurityAlertUsecase.Acknowledge(c, id, userID)
+	})
+}
+
+func (sac *SecurityAlertController) Resolve(c *gin.Context) {
+	var request domain.ResolveAlertRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	sac.transition(c, "SECURITY_ALERT_RESOLVE", func(id uuid.UUID, userID uuid.UUID) error {
+		return sac.SecurityAlertUsecase.Resolve(c, id, userID, request.Resolution)
+	})
+}
+
+func (sac *SecurityAlertController) transition(c *gin.Context, action string, apply func(id uuid.UUID, userID uuid.UUID) error) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid alert id format"})
+		return
+	}
+
+	userIDCtx, _ := c.Get("x-user-id")
+	userID, ok := userIDCtx.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "User id not found in context"})
+		return
+	}
+
+	alert, err := sac.SecurityAlertUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if alert == nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Alert not found"})
+		return
+	}
+
+	err = apply(parsedID, userID)
+	if errors.Is(err, domain.ErrInvalidAlertTransition) {
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: fmt.Sprintf("Alert is already %s", alert.Status)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if !auditPatientAccess(c, sac.AuditService, action, domain.ResourceSecurityAlert, parsedID, uuid.Nil, fmt.Sprintf("Security alert %s (%s) moved from %s", parsedID, alert.Rule, alert.Status)) {
+		return
+	}
+
+	alert, err = sac.SecurityAlertUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
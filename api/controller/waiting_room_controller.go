@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// heartbeatInterval is how often an idle stream gets a comment line, so
+// proxies don't close it.
+const heartbeatInterval = 15 * time.Second
+
+type WaitingRoomController struct {
+	WaitingRoomUsecase domain.WaitingRoomUsecase
+	AppointmentUsecase domain.AppointmentUsecase
+	PatientUsecase     domain.PatientUsecase
+	DoctorUsecase      domain.DoctorUsecase
+	AuditService       auditservice.Service
+}
+
+func NewWaitingRoomController(wru domain.WaitingRoomUsecase, au domain.AppointmentUsecase, pu domain.PatientUsecase, du domain.DoctorUsecase, as auditservice.Service) *WaitingRoomController {
+	return &WaitingRoomController{
+		WaitingRoomUsecase: wru,
+		AppointmentUsecase: au,
+		PatientUsecase:     pu,
+		DoctorUsecase:      du,
+		AuditService:       as,
+	}
+}
+
+type callRequest struct {
+	Room string `json:"room"`
+}
+
+// Stream sends waiting room events as Server-Sent Events until the client
+// disconnects. doctor_id narrows the stream to one doctor, facility to the
+// doctors working there, and view=board sends only what waiting room
+// displays show. Doctors only get the staff
+// view of their own appointments. Clients resuming after a disconnect send
+// the last ID they got in Last-Event-ID, or last_event_id where they can't
+// set headers.
+func (wrc *WaitingRoomController) Stream(c *gin.Context) {
+	view := c.DefaultQuery("view", domain.StaffView)
+	if view != domain.StaffView && view != domain.BoardView {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: domain.ErrInvalidWaitingRoomView.Error()})
+		return
+	}
+
+	var doctorID uuid.UUID
+	if value := c.Query("doctor_id"); value != "" {
+		parsedID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "invalid doctor id"})
+			return
+		}
+		doctorID = parsedID
+	}
+
+	facility := strings.TrimSpace(c.Query("facility"))
+
+	userID, role := currentUser(c)
+	if role == domain.DoctorRole && view == domain.StaffView {
+		doctor, err := wrc.DoctorUsecase.FetchByUserID(c, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+		if doctor.ID == uuid.Nil || (doctorID != uuid.Nil && doctorID != doctor.ID) {
+			c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "Doctors can only follow their own patients"})
+			return
+		}
+		doctorID = doctor.ID
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var since int64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "invalid Last-Event-ID"})
+			return
+		}
+		since = parsed
+	}
+
+	if view == domain.StaffView {
+		description := fmt.Sprintf("Opened waiting room stream for doctor %s", doctorID)
+		if facility != "" {
+			description += fmt.Sprintf(" at facility %s", facility)
+		}
+		if !auditAction(c, wrc.AuditService, "WAITING_ROOM_STREAM", description) {
+			return
+		}
+	}
+
+	replay, events, unsubscribe := wrc.WaitingRoomUsecase.Subscribe(since)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// atFacility remembers which doctors work at the facility, so each is
+	// only looked up once per stream.
+	atFacility := make(map[uuid.UUID]bool)
+	send := func(event domain.WaitingRoomEvent) error {
+		if doctorID != uuid.Nil && event.DoctorID != doctorID {
+			return nil
+		}
+		if facility != "" {
+			matches, ok := atFacility[event.DoctorID]
+			if !ok {
+				doctor, err := wrc.DoctorUsecase.FetchByID(c, event.DoctorID)
+				if err != nil {
+					return err
+				}
+				matches = doctor.AtFacility(facility)
+				atFacility[event.DoctorID] = matches
+			}
+			if !matches {
+				return nil
+			}
+		}
+
+		var payload interface{} = event
+		if view == domain.BoardView {
+			payload = event.Board()
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		return err
+	}
+
+	for _, event := range replay {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and
+				// replays from its last event.
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// Call asks the checked in patient to come in. Doctors can only call their
+// own patients.
+func (wrc *WaitingRoomController) Call(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "invalid appointment id"})
+		return
+	}
+
+	var request callRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBind(&request); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	appointment, err := wrc.AppointmentUsecase.FetchByID(c, parsedID)
+	if err != nil {
+		respondAppointmentError(c, err)
+		return
+	}
+	if appointment.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Appointment not found"})
+		return
+	}
+	if !ensureParticipant(c, wrc.PatientUsecase, wrc.DoctorUsecase, appointment.PatientID, appointment.DoctorID) {
+		return
+	}
+
+	event, err := wrc.WaitingRoomUsecase.Call(c, parsedID, request.Room)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotCheckedIn):
+			c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+		default:
+			respondAppointmentError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
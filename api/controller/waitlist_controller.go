@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WaitlistController struct {
+	WaitlistUsecase domain.WaitlistUsecase
+	PatientUsecase  domain.PatientUsecase
+	AuditService    auditservice.Service
+}
+
+func NewWaitlistController(wu domain.WaitlistUsecase, pu domain.PatientUsecase, as auditservice.Service) *WaitlistController {
+	return &WaitlistController{
+		WaitlistUsecase: wu,
+		PatientUsecase:  pu,
+		AuditService:    as,
+	}
+}
+
+// Join adds a patient to the waitlist. Patients always join for themselves;
+// staff must name the patient.
+func (wc *WaitlistController) Join(c *gin.Context) {
+	var entry domain.WaitlistEntry
+
+	if err := c.ShouldBind(&entry); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	patientID, ok := wc.scopedPatientID(c)
+	if !ok {
+		return
+	}
+	if patientID != uuid.Nil {
+		entry.PatientID = patientID
+	}
+	if entry.PatientID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "patient_id is required"})
+		return
+	}
+
+	if err := wc.WaitlistUsecase.Join(c, &entry); err != nil {
+		respondWaitlistError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, wc.AuditService, "WAITLIST_JOIN", domain.ResourceWaitlistEntry, entry.ID, entry.PatientID, fmt.Sprintf("Patient %s joined the waitlist", entry.PatientID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// Fetch lists waitlist entries, optionally filtered by ?status. Patients only
+// see their own.
+func (wc *WaitlistController) Fetch(c *gin.Context) {
+	status := domain.WaitlistStatus(c.Query("status"))
+	switch status {
+	case "", domain.WaitlistWaiting, domain.WaitlistOffered, domain.WaitlistBooked, domain.WaitlistCanceled:
+	default:
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid status, expected waiting, offered, booked or canceled"})
+		return
+	}
+
+	patientID, ok := wc.scopedPatientID(c)
+	if !ok {
+		return
+	}
+
+	entries, err := wc.WaitlistUsecase.FetchEntries(c, patientID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if entries == nil {
+		entries = []domain.WaitlistEntry{}
+	}
+
+	var listed listedAccess
+	for _, entry := range entries {
+		listed.add(entry.ID, entry.PatientID)
+	}
+	if !auditListAccess(c, wc.AuditService, "WAITLIST_FETCH", domain.ResourceWaitlistEntry, listed, "Waitlist entry listed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (wc *WaitlistController) Leave(c *gin.Context) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid waitlist entry id"})
+		return
+	}
+
+	entry, err := wc.WaitlistUsecase.FetchEntryByID(c, parsedID)
+	if err != nil {
+		respondWaitlistError(c, err)
+		return
+	}
+
+	if !wc.ensureOwner(c, entry.PatientID) {
+		return
+	}
+
+	if err := wc.WaitlistUsecase.Leave(c, parsedID); err != nil {
+		respondWaitlistError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, wc.AuditService, "WAITLIST_LEAVE", domain.ResourceWaitlistEntry, entry.ID, entry.PatientID, fmt.Sprintf("Patient %s left the waitlist", entry.PatientID)) {
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// FetchOffers lists slot offers, optionally filtered by ?status. Patients only
+// see their own.
+func (wc *WaitlistController) FetchOffers(c *gin.Context) {
+	status := domain.OfferStatus(c.Query("status"))
+	switch status {
+	case "", domain.OfferPending, domain.OfferAccepted, domain.OfferDeclined, domain.OfferExpired:
+	default:
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid status, expected pending, accepted, declined or expired"})
+		return
+	}
+
+	patientID, ok := wc.scopedPatientID(c)
+	if !ok {
+		return
+	}
+
+	offers, err := wc.WaitlistUsecase.FetchOffers(c, patientID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if offers == nil {
+		offers = []domain.WaitlistOffer{}
+	}
+
+	var listed listedAccess
+	for _, offer := range offers {
+		listed.add(offer.ID, offer.PatientID)
+	}
+	if !auditListAccess(c, wc.AuditService, "WAITLIST_OFFER_FETCH", domain.ResourceWaitlistOffer, listed, "Waitlist offer listed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+func (wc *WaitlistController) Accept(c *gin.Context) {
+	offer, ok := wc.fetchOffer(c)
+	if !ok {
+		return
+	}
+
+	userID, role := currentUser(c)
+	appointment, err := wc.WaitlistUsecase.Accept(c, offer.ID, userID, role)
+	if err != nil {
+		respondWaitlistError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, wc.AuditService, "WAITLIST_OFFER_ACCEPT", domain.ResourceAppointment, appointment.ID, appointment.PatientID, fmt.Sprintf("Waitlist offer %s accepted", offer.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+func (wc *WaitlistController) Decline(c *gin.Context) {
+	offer, ok := wc.fetchOffer(c)
+	if !ok {
+		return
+	}
+
+	if err := wc.WaitlistUsecase.Decline(c, offer.ID); err != nil {
+		respondWaitlistError(c, err)
+		return
+	}
+
+	if !auditPatientAccess(c, wc.AuditService, "WAITLIST_OFFER_DECLINE", domain.ResourceWaitlistOffer, offer.ID, offer.PatientID, fmt.Sprintf("Waitlist offer %s declined", offer.ID)) {
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (wc *WaitlistController) fetchOffer(c *gin.Context) (domain.WaitlistOffer, bool) {
+	parsedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid offer id"})
+		return domain.WaitlistOffer{}, false
+	}
+
+	offer, err := wc.WaitlistUsecase.FetchOfferByID(c, parsedID)
+	if err != nil {
+		respondWaitlistError(c, err)
+		return domain.WaitlistOffer{}, false
+	}
+
+	if !wc.ensureOwner(c, offer.PatientID) {
+		return domain.WaitlistOffer{}, false
+	}
+
+	return offer, true
+}
+
+// scopedPatientID returns the caller's own patient id when they are a
+// patient, and uuid.Nil for staff.
+func (wc *WaitlistController) scopedPatientID(c *gin.Context) (uuid.UUID, bool) {
+	userID, role := currentUser(c)
+	if role != domain.PatientRole {
+		return uuid.Nil, true
+	}
+
+	patient, err := wc.PatientUsecase.FetchByUserID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return uuid.Nil, false
+	}
+	if patient.ID == uuid.Nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Patient profile not found for this user"})
+		return uuid.Nil, false
+	}
+
+	return patient.ID, true
+}
+
+func (wc *WaitlistController) ensureOwner(c *gin.Context, patientID uuid.UUID) bool {
+	ownID, ok := wc.scopedPatientID(c)
+	if !ok {
+		return false
+	}
+	if ownID != uuid.Nil && ownID != patientID {
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "Patients can only manage their own waitlist entries"})
+		return false
+	}
+	return true
+}
+
+func respondWaitlistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrWaitlistEntryNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Waitlist entry not found"})
+	case errors.Is(err, domain.ErrOfferNotFound):
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Offer not found"})
+	case errors.Is(err, domain.ErrInvalidWaitlistEntry):
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, domain.ErrOfferClosed), errors.Is(err, domain.ErrOfferExpired):
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+	default:
+		respondAppointmentError(c, err)
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AccessDeniedAuditMiddleware records every request that ends in a 403, so
+// repeated authorization failures show up in the audit trail and can be
+// picked up by the anomaly detector.
+func AccessDeniedAuditMiddleware(as auditservice.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if as == nil || c.Writer.Status() != http.StatusForbidden {
+			return
+		}
+
+		userIDCtx, _ := c.Get("x-user-id")
+		userID, _ := userIDCtx.(uuid.UUID)
+
+		roleCtx, _ := c.Get("x-user-role")
+		role, _ := roleCtx.(domain.UserRole)
+
+		entry := domain.AuditLog{
+			UserID:      userID,
+			UserRole:    role,
+			Action:      "ACCESS_DENIED",
+			Description: fmt.Sprintf("Access denied to %s %s", c.Request.Method, c.Request.URL.Path),
+			IPAddress:   c.ClientIP(),
+		}
+
+		if err := as.LogEntry(c.Request.Context(), entry); err != nil {
+			log.Printf("[ERROR] Audit: failed to record ACCESS_DENIED: %v\n", err)
+		}
+	}
+}
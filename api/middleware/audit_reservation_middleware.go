@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditReservationMiddleware reserves room in the audit queue for the entry
+// of every request that may change data, before its handler runs. When the
+// audit log can't take it the request fails with 503 with nothing changed,
+// rather than after the change was made, when a retry would repeat it.
+func AuditReservationMiddleware(as auditservice.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if as == nil || !changesData(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		reservation, err := as.Reserve(c.Request.Context(), 1)
+		if err != nil {
+			log.Printf("[ERROR] Audit: failed to reserve room for %s %s: %v\n", c.Request.Method, c.Request.URL.Path, err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, domain.ErrorResponse{Message: "The audit log is unavailable, try again later"})
+			return
+		}
+		defer reservation.Release()
+
+		c.Set("x-audit-reservation", reservation)
+		c.Next()
+	}
+}
+
+func changesData(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
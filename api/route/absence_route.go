@@ -0,0 +1,37 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAbsenceRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup) {
+	abr := repository.NewAbsenceRepository(db)
+	ar := repository.NewAppointmentRepository(db)
+	dr := repository.NewDoctorRepository(db)
+	wr := repository.NewWaitlistRepository(db)
+	rr := repository.NewReminderRepository(db)
+	avu := usecase.NewAvailabilityUsecase(repository.NewAvailabilityRepository(db), ar, wr, abr, timeout)
+	wu := newWaitlistUsecase(env, timeout, db, nd)
+	an := usecase.NewAppointmentNotifier(rr, nd, env.NotificationLanguage, timeout)
+	wru := usecase.NewWaitingRoomUsecase(ar, rr, wb, timeout)
+	abu := usecase.NewAbsenceUsecase(abr, ar, dr, avu, wu, an, wru, timeout)
+	abc := controller.NewAbsenceController(abu, avu, usecase.NewDoctorUsecase(dr, timeout), as)
+
+	group.POST("/doctors/:id/absences", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), abc.Create)
+	group.GET("/doctors/:id/absences", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), abc.FetchByDoctorID)
+	group.DELETE("/doctors/:id/absences/:absence_id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), abc.Delete)
+	group.GET("/doctors/:id/absences/:absence_id/appointments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), abc.FetchAffected)
+	group.POST("/doctors/:id/absences/:absence_id/rebook", middleware.RBACMiddleware(domain.AdminRole), abc.Rebook)
+}
@@ -0,0 +1,24 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAccessLogRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	alr := repository.NewAuditLogRepository(db)
+	pr := repository.NewPatientRepository(db)
+	alc := controller.NewAccessLogController(usecase.NewAuditLogUsecase(alr, timeout), usecase.NewPatientUsecase(pr, timeout), as)
+
+	group.GET("/me/access-log", middleware.RBACMiddleware(domain.PatientRole), alc.FetchMine)
+	group.GET("/patients/:id/access-log", middleware.RBACMiddleware(domain.AdminRole), alc.FetchByPatientID)
+}
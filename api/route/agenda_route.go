@@ -0,0 +1,22 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAgendaRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	agu := usecase.NewAgendaUsecase(repository.NewAgendaRepository(db), repository.NewAvailabilityRepository(db), timeout)
+	agc := controller.NewAgendaController(agu, usecase.NewDoctorUsecase(repository.NewDoctorRepository(db), timeout), as)
+
+	group.GET("/me/agenda", middleware.RBACMiddleware(domain.DoctorRole), agc.FetchMine)
+}
@@ -0,0 +1,25 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAllergyRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	au := usecase.NewAllergyUsecase(repository.NewAllergyRepository(db), repository.NewPatientRepository(db), timeout)
+	ac := controller.NewAllergyController(au, as)
+
+	group.POST("/patients/:id/allergies", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ac.Create)
+	group.GET("/patients/:id/allergies", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ac.FetchByPatientID)
+	group.GET("/allergies/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ac.FetchByID)
+	group.PATCH("/allergies/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ac.Update)
+}
@@ -7,6 +7,8 @@ import (
 	"hms-api/bootstrap"
 	"hms-api/domain"
 	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
 	"hms-api/repository"
 	"hms-api/usecase"
 	"time"
@@ -14,12 +16,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewAppointmentRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup){
-	ar := repository.NewAppointmentRepository(db)
-	alr := repository.NewAuditLogRepository(db)
-	alu := usecase.NewAuditLogUsecase(alr, timeout)
-	as := auditservice.NewService(alu)
-	ac := controller.NewAppointmentController(usecase.NewAppointmentUsecase(ar, timeout), as)
+func NewAppointmentRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup){
+	pu := usecase.NewPatientUsecase(repository.NewPatientRepository(db), timeout)
+	du := usecase.NewDoctorUsecase(repository.NewDoctorRepository(db), timeout)
+	ac := controller.NewAppointmentController(newAppointmentUsecase(env, timeout, db, nd, wb), pu, du, as)
 
 	group.POST("/appointments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole),ac.Create)
 	group.GET("/appointments", middleware.RBACMiddleware(domain.AdminRole), ac.Fetch)
@@ -28,4 +28,35 @@ func NewAppointmentRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB,
 	group.GET("/appointments/doctor/:doctor_id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ac.FetchByDoctorID)
 	group.PATCH("/appointments/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ac.Update)
 	group.DELETE("/appointments/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ac.Delete)
-}
\ No newline at end of file
+	group.GET("/appointments/:id/history", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), ac.FetchStatusHistory)
+	group.POST("/appointments/:id/reschedule", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), ac.Reschedule)
+	group.GET("/appointments/:id/reschedules", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), ac.FetchReschedules)
+
+	for action, transition := range domain.AppointmentTransitions {
+		group.POST("/appointments/:id/"+action, middleware.RBACMiddleware(transition.Roles...), ac.Transition(action))
+	}
+}
+
+// newAppointmentUsecase builds the appointment usecase with everything it
+// tells about changes: the waitlist, the patient and doctor by email, and the
+// waiting room stream.
+func newAppointmentUsecase(env *bootstrap.Env, timeout time.Duration, db *sql.DB, nd notification.Dispatcher, wb waitingroom.Broker) domain.AppointmentUsecase {
+	ar := repository.NewAppointmentRepository(db)
+	wr := repository.NewWaitlistRepository(db)
+	rr := repository.NewReminderRepository(db)
+	avu := usecase.NewAvailabilityUsecase(repository.NewAvailabilityRepository(db), ar, wr, repository.NewAbsenceRepository(db), timeout)
+	attu := newAttendanceUsecase(env, timeout, db)
+	wu := newWaitlistUsecase(env, timeout, db, nd)
+	an := usecase.NewAppointmentNotifier(rr, nd, env.NotificationLanguage, timeout)
+	wru := usecase.NewWaitingRoomUsecase(ar, rr, wb, timeout)
+	return usecase.NewAppointmentUsecase(ar, repository.NewAppointmentTypeRepository(db), avu, wu, attu, an, wru, ReschedulePolicy(env), timeout)
+}
+
+// ReschedulePolicy reads the patient reschedule limits from env, leaving unset
+// ones to the usecase defaults.
+func ReschedulePolicy(env *bootstrap.Env) domain.ReschedulePolicy {
+	return domain.ReschedulePolicy{
+		MinNotice:             time.Duration(env.RescheduleNoticeHours) * time.Hour,
+		MaxPatientReschedules: env.PatientRescheduleLimit,
+	}
+}
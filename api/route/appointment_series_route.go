@@ -0,0 +1,37 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAppointmentSeriesRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup) {
+	ar := repository.NewAppointmentRepository(db)
+	dr := repository.NewDoctorRepository(db)
+	wr := repository.NewWaitlistRepository(db)
+	rr := repository.NewReminderRepository(db)
+	avu := usecase.NewAvailabilityUsecase(repository.NewAvailabilityRepository(db), ar, wr, repository.NewAbsenceRepository(db), timeout)
+	wu := newWaitlistUsecase(env, timeout, db, nd)
+	an := usecase.NewAppointmentNotifier(rr, nd, env.NotificationLanguage, timeout)
+	wru := usecase.NewWaitingRoomUsecase(ar, rr, wb, timeout)
+	su := usecase.NewAppointmentSeriesUsecase(repository.NewAppointmentSeriesRepository(db), ar, avu, wu, an, wru, timeout)
+	pu := usecase.NewPatientUsecase(repository.NewPatientRepository(db), timeout)
+	du := usecase.NewDoctorUsecase(dr, timeout)
+	sc := controller.NewAppointmentSeriesController(su, pu, du, as)
+
+	group.POST("/appointment_series", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), sc.Create)
+	group.GET("/appointment_series/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), sc.FetchByID)
+	group.PATCH("/appointment_series/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), sc.Update)
+	group.POST("/appointment_series/:id/cancel", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), sc.Cancel)
+}
@@ -0,0 +1,25 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAppointmentTypeRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	tc := controller.NewAppointmentTypeController(usecase.NewAppointmentTypeUsecase(repository.NewAppointmentTypeRepository(db), timeout), as)
+
+	group.POST("/appointment_types", middleware.RBACMiddleware(domain.AdminRole), tc.Create)
+	group.GET("/appointment_types", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), tc.Fetch)
+	group.GET("/appointment_types/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), tc.FetchByID)
+	group.PATCH("/appointment_types/:id", middleware.RBACMiddleware(domain.AdminRole), tc.Update)
+	group.DELETE("/appointment_types/:id", middleware.RBACMiddleware(domain.AdminRole), tc.Delete)
+}
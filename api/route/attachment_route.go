@@ -0,0 +1,71 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/blobstore"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAttachmentMaxSizeMB = 10
+	defaultAttachmentTypes     = "application/pdf,image/jpeg,image/png,image/webp"
+)
+
+func NewAttachmentRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, bs blobstore.Store, group *gin.RouterGroup) {
+	policy := AttachmentPolicy(env)
+	pr := repository.NewPatientRepository(db)
+	au := usecase.NewAttachmentUsecase(
+		repository.NewAttachmentRepository(db),
+		repository.NewMedicalRecordRepository(db),
+		repository.NewPrescriptionRepository(db),
+		pr,
+		bs,
+		policy,
+		timeout,
+	)
+	atc := controller.NewAttachmentController(au, usecase.NewPatientUsecase(pr, timeout), as, policy.MaxSize)
+
+	group.POST("/patients/:id/attachments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), atc.UploadForPatient)
+	group.GET("/patients/:id/attachments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), atc.FetchByPatientID)
+	group.POST("/medical_records/:id/attachments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), atc.UploadForMedicalRecord)
+	group.GET("/medical_records/:id/attachments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), atc.FetchByMedicalRecordID)
+	group.POST("/prescriptions/:id/attachments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), atc.UploadForPrescription)
+	group.GET("/prescriptions/:id/attachments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), atc.FetchByPrescriptionID)
+	group.GET("/attachments/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), atc.FetchByID)
+	group.GET("/attachments/:id/content", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), atc.Download)
+}
+
+// AttachmentPolicy reads the upload limits from the environment, allowing PDFs
+// and common images up to 10 MB unless configured.
+func AttachmentPolicy(env *bootstrap.Env) domain.AttachmentPolicy {
+	maxSizeMB := env.AttachmentMaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultAttachmentMaxSizeMB
+	}
+	types := env.AttachmentTypes
+	if strings.TrimSpace(types) == "" {
+		types = defaultAttachmentTypes
+	}
+
+	var allowed []string
+	for _, contentType := range strings.Split(types, ",") {
+		if contentType = strings.TrimSpace(contentType); contentType != "" {
+			allowed = append(allowed, contentType)
+		}
+	}
+
+	return domain.AttachmentPolicy{
+		MaxSize:      int64(maxSizeMB) << 20,
+		AllowedTypes: allowed,
+	}
+}
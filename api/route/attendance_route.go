@@ -0,0 +1,40 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAttendanceRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	pu := usecase.NewPatientUsecase(repository.NewPatientRepository(db), timeout)
+	atc := controller.NewAttendanceController(newAttendanceUsecase(env, timeout, db), pu, as)
+
+	group.GET("/me/attendance", middleware.RBACMiddleware(domain.PatientRole), atc.FetchMine)
+	group.GET("/patients/:id/attendance", middleware.RBACMiddleware(domain.AdminRole), atc.FetchByPatientID)
+	group.GET("/reports/no_shows", middleware.RBACMiddleware(domain.AdminRole), atc.FetchNoShowRates)
+}
+
+func newAttendanceUsecase(env *bootstrap.Env, timeout time.Duration, db *sql.DB) domain.AttendanceUsecase {
+	return usecase.NewAttendanceUsecase(repository.NewAttendanceRepository(db), AttendancePolicy(env), timeout)
+}
+
+// AttendancePolicy reads the no-show and late cancellation rules from env,
+// leaving unset ones to the usecase defaults.
+func AttendancePolicy(env *bootstrap.Env) domain.AttendancePolicy {
+	return domain.AttendancePolicy{
+		NoShowGrace:      time.Duration(env.NoShowGraceMinutes) * time.Minute,
+		LateCancelWindow: time.Duration(env.LateCancelHours) * time.Hour,
+		NoShowLimit:      env.NoShowLimit,
+		LateCancelLimit:  env.LateCancelLimit,
+		Lookback:         time.Duration(env.AttendanceLookbackDays) * 24 * time.Hour,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"hms-api/api/middleware"
 	"hms-api/bootstrap"
 	"hms-api/domain"
+	"hms-api/internal/auditservice"
 	"hms-api/repository"
 	"hms-api/usecase"
 	"time"
@@ -13,12 +14,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewAuditLogRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup) {
+func NewAuditLogRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
 	alr := repository.NewAuditLogRepository(db)
-	alc := controller.NewAuditLogController(usecase.NewAuditLogUsecase(alr, timeout))
+	alc := controller.NewAuditLogController(usecase.NewAuditLogUsecase(alr, timeout), as)
 
 	group.POST("/audit_logs", alc.Create)
 	group.GET("/audit_logs",  middleware.RBACMiddleware(domain.AdminRole), alc.Fetch)
+	group.GET("/audit_logs/stats", middleware.RBACMiddleware(domain.AdminRole), alc.Stats)
 	group.GET("/audit_logs/:id", middleware.RBACMiddleware(domain.AdminRole), alc.FetchByID)
 	group.PATCH("/audit_logs/:id",  middleware.RBACMiddleware(domain.AdminRole), alc.Update)
 	group.DELETE("/audit_logs/:id",  middleware.RBACMiddleware(domain.AdminRole), alc.Delete)
@@ -0,0 +1,28 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAvailabilityRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup) {
+	avr := repository.NewAvailabilityRepository(db)
+	ar := repository.NewAppointmentRepository(db)
+	dr := repository.NewDoctorRepository(db)
+	wr := repository.NewWaitlistRepository(db)
+	avc := controller.NewAvailabilityController(usecase.NewAvailabilityUsecase(avr, ar, wr, repository.NewAbsenceRepository(db), timeout), usecase.NewDoctorUsecase(dr, timeout))
+
+	group.GET("/doctors/:id/availability", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), avc.FetchByDoctorID)
+	group.PUT("/doctors/:id/availability", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), avc.ReplaceWeekly)
+	group.POST("/doctors/:id/availability/exceptions", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), avc.SaveException)
+	group.DELETE("/doctors/:id/availability/exceptions/:exception_id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), avc.DeleteException)
+	group.GET("/doctors/:id/slots", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), avc.FetchSlots)
+}
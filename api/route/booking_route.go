@@ -0,0 +1,39 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewBookingRoute registers the self-service booking flow: patients search
+// free slots by specialty, hold one and confirm it.
+func NewBookingRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup) {
+	ar := repository.NewAppointmentRepository(db)
+	avu := usecase.NewAvailabilityUsecase(repository.NewAvailabilityRepository(db), ar, repository.NewWaitlistRepository(db), repository.NewAbsenceRepository(db), timeout)
+	bu := usecase.NewBookingUsecase(
+		repository.NewBookingRepository(db),
+		repository.NewAppointmentTypeRepository(db),
+		avu,
+		newAppointmentUsecase(env, timeout, db, nd, wb),
+		time.Duration(env.BookingHoldMinutes)*time.Minute,
+		timeout,
+	)
+	bc := controller.NewBookingController(bu, usecase.NewPatientUsecase(repository.NewPatientRepository(db), timeout), as)
+
+	group.GET("/booking/slots", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), bc.Search)
+	group.POST("/booking/holds", middleware.RBACMiddleware(domain.PatientRole), bc.Hold)
+	group.GET("/booking/holds/:id", middleware.RBACMiddleware(domain.PatientRole), bc.FetchHold)
+	group.DELETE("/booking/holds/:id", middleware.RBACMiddleware(domain.PatientRole), bc.Release)
+	group.POST("/booking/holds/:id/confirm", middleware.RBACMiddleware(domain.PatientRole), bc.Confirm)
+}
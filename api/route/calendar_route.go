@@ -0,0 +1,45 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewCalendarRoute registers the public .ics subscription URLs. The secret
+// token in the path authenticates the request.
+func NewCalendarRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	cc := newCalendarController(env, timeout, db, as)
+
+	group.GET("/calendar/:token", cc.Feed)
+}
+
+func NewCalendarFeedRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	cc := newCalendarController(env, timeout, db, as)
+
+	group.POST("/calendar_feeds", middleware.RBACMiddleware(domain.DoctorRole, domain.PatientRole), cc.CreateFeed)
+	group.GET("/calendar_feeds", middleware.RBACMiddleware(domain.DoctorRole, domain.PatientRole), cc.FetchFeeds)
+	group.DELETE("/calendar_feeds/:id", middleware.RBACMiddleware(domain.DoctorRole, domain.PatientRole), cc.RevokeFeed)
+}
+
+func newCalendarController(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service) *controller.CalendarController {
+	cu := usecase.NewCalendarUsecase(
+		repository.NewCalendarFeedRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewDoctorRepository(db),
+		repository.NewPatientRepository(db),
+		repository.NewAppointmentRepository(db),
+		env.PublicBaseURL,
+		env.NotificationLanguage,
+		timeout,
+	)
+	return controller.NewCalendarController(cu, as)
+}
@@ -14,11 +14,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewDoctorRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup) {
+func NewDoctorRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
 	dr := repository.NewDoctorRepository(db)
-	alr := repository.NewAuditLogRepository(db) 
-	alu := usecase.NewAuditLogUsecase(alr, timeout) 
-	as := auditservice.NewService(alu)        
 	dc := controller.NewDoctorController(usecase.NewDoctorUsecase(dr, timeout), as)
 
 	group.POST("/doctors", middleware.RBACMiddleware(domain.AdminRole), dc.Create)
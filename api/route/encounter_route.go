@@ -0,0 +1,37 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewEncounterRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, ic domain.ICD10Catalog, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup) {
+	eu := usecase.NewEncounterUsecase(
+		repository.NewEncounterRepository(db),
+		newAppointmentUsecase(env, timeout, db, nd, wb),
+		repository.NewPatientRepository(db),
+		usecase.NewMedicalRecordUsecase(repository.NewMedicalRecordRepository(db), ic, timeout),
+		usecase.NewPrescriptionUsecase(repository.NewPrescriptionRepository(db), repository.NewAllergyRepository(db), timeout),
+		timeout,
+	)
+	ec := controller.NewEncounterController(eu, usecase.NewDoctorUsecase(repository.NewDoctorRepository(db), timeout), as)
+
+	group.POST("/encounters", middleware.RBACMiddleware(domain.DoctorRole), ec.Open)
+	group.GET("/encounters/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ec.FetchByID)
+	group.POST("/encounters/:id/close", middleware.RBACMiddleware(domain.DoctorRole), ec.Close)
+	group.POST("/encounters/:id/medical_records", middleware.RBACMiddleware(domain.DoctorRole), ec.AddMedicalRecord)
+	group.POST("/encounters/:id/prescriptions", middleware.RBACMiddleware(domain.DoctorRole), ec.AddPrescription)
+	group.POST("/encounters/:id/orders", middleware.RBACMiddleware(domain.DoctorRole), ec.AddOrder)
+	group.GET("/patients/:id/encounters", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), ec.FetchByPatientID)
+}
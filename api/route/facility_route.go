@@ -0,0 +1,34 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewFacilityRoute registers facilities, whose timezones doctors' hours are
+// written in, and the caller's own timezone.
+func NewFacilityRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	fc := controller.NewFacilityController(
+		usecase.NewFacilityUsecase(repository.NewFacilityRepository(db), timeout),
+		usecase.NewTimezoneUsecase(repository.NewUserRepository(db), timeout),
+		as,
+	)
+
+	group.POST("/facilities", middleware.RBACMiddleware(domain.AdminRole), fc.Create)
+	group.GET("/facilities", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), fc.Fetch)
+	group.GET("/facilities/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), fc.FetchByID)
+	group.PATCH("/facilities/:id", middleware.RBACMiddleware(domain.AdminRole), fc.Update)
+	group.DELETE("/facilities/:id", middleware.RBACMiddleware(domain.AdminRole), fc.Delete)
+
+	group.GET("/me/timezone", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), fc.FetchMyTimezone)
+	group.PUT("/me/timezone", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), fc.UpdateMyTimezone)
+}
@@ -0,0 +1,15 @@
+package route
+
+import (
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewICD10Route(ic domain.ICD10Catalog, group *gin.RouterGroup) {
+	icc := controller.NewICD10Controller(ic)
+
+	group.GET("/icd10", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), icc.Search)
+}
@@ -12,11 +12,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewLoginRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup) {
+func NewLoginRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
 	ur := repository.NewUserRepository(db)
-	alr := repository.NewAuditLogRepository(db) 
-	alu := usecase.NewAuditLogUsecase(alr, timeout) 
-	as := auditservice.NewService(alu)            
 
 	lc := controller.NewLoginController( 
 		usecase.NewLoginUsecase(ur, timeout),
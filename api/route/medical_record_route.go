@@ -14,12 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewMedicalRecordRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup) {
+func NewMedicalRecordRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, ic domain.ICD10Catalog, group *gin.RouterGroup) {
 	mrr := repository.NewMedicalRecordRepository(db)
-	alr := repository.NewAuditLogRepository(db)
-	alu := usecase.NewAuditLogUsecase(alr, timeout)
-	as := auditservice.NewService(alu)
-	mrc := controller.NewMedicalRecordController(usecase.NewMedicalRecordUsecase(mrr, timeout), as)
+	mrc := controller.NewMedicalRecordController(usecase.NewMedicalRecordUsecase(mrr, ic, timeout), as)
 
 	group.POST("/medical_records", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), mrc.Create)
 	group.GET("/medical_records", middleware.RBACMiddleware(domain.AdminRole), mrc.Fetch)
@@ -27,4 +24,7 @@ func NewMedicalRecordRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB
 	group.GET("/medical_records/doctor/:doctor_id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), mrc.FetchByDoctorID)
 	group.PATCH("/medical_records/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), mrc.Update)
 	group.DELETE("/medical_records/:id", middleware.RBACMiddleware(domain.AdminRole), mrc.Delete)
+	group.GET("/medical_records/:id/versions", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), mrc.FetchVersions)
+	group.POST("/medical_records/:id/amendments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), mrc.Amend)
+	group.GET("/medical_records/:id/amendments", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), mrc.FetchAmendments)
 }
@@ -14,11 +14,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewPatientRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup){
+func NewPatientRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup){
 	pr := repository.NewPatientRepository(db)
-	alr := repository.NewAuditLogRepository(db)
-	alu := usecase.NewAuditLogUsecase(alr, timeout)
-	as := auditservice.NewService(alu)
 	pc := controller.NewPatientController(usecase.NewPatientUsecase(pr, timeout), as)
 
 	group.POST("/patients", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), pc.Create)
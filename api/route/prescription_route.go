@@ -14,12 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewPrescriptionRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, group *gin.RouterGroup) {
+func NewPrescriptionRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
 	pr := repository.NewPrescriptionRepository(db)
-	alr := repository.NewAuditLogRepository(db)
-	alu := usecase.NewAuditLogUsecase(alr, timeout)
-	as := auditservice.NewService(alu)	
-	pc := controller.NewPrescriptionController(usecase.NewPrescriptionUsecase(pr, timeout), as)
+	pc := controller.NewPrescriptionController(usecase.NewPrescriptionUsecase(pr, repository.NewAllergyRepository(db), timeout), as)
 
 	group.POST("/prescriptions", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), pc.Create)
 	group.GET("/prescriptions", middleware.RBACMiddleware(domain.AdminRole), pc.Fetch)
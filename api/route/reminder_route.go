@@ -0,0 +1,53 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewReminderRoute registers the confirm and cancel links sent in reminders.
+// They are public: the signed token in the link authenticates the patient.
+// Opening a link only shows a page; submitting it applies the action.
+func NewReminderRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup) {
+	rc := newReminderController(env, timeout, db, as, nd, wb)
+
+	group.GET("/reminders/"+domain.ReminderConfirm, rc.Page(domain.ReminderConfirm))
+	group.POST("/reminders/"+domain.ReminderConfirm, rc.Respond(domain.ReminderConfirm))
+	group.GET("/reminders/"+domain.ReminderCancel, rc.Page(domain.ReminderCancel))
+	group.POST("/reminders/"+domain.ReminderCancel, rc.Respond(domain.ReminderCancel))
+}
+
+func NewNotificationPreferenceRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup) {
+	rc := newReminderController(env, timeout, db, as, nd, wb)
+
+	group.GET("/notification_preferences", middleware.RBACMiddleware(domain.PatientRole), rc.FetchPreference)
+	group.PUT("/notification_preferences", middleware.RBACMiddleware(domain.PatientRole), rc.UpdatePreference)
+}
+
+func newReminderController(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker) *controller.ReminderController {
+	ar := repository.NewAppointmentRepository(db)
+	pr := repository.NewPatientRepository(db)
+	rr := repository.NewReminderRepository(db)
+	attu := newAttendanceUsecase(env, timeout, db)
+	an := usecase.NewAppointmentNotifier(rr, nd, env.NotificationLanguage, timeout)
+	au := newAppointmentUsecase(env, timeout, db, nd, wb)
+
+	config := usecase.ReminderConfig{
+		BaseURL:         env.PublicBaseURL,
+		LinkSecret:      env.ReminderLinkSecret,
+		DefaultLanguage: env.NotificationLanguage,
+	}
+	ru := usecase.NewReminderUsecase(rr, ar, pr, au, attu, an, nd, config, timeout)
+	return controller.NewReminderController(ru, usecase.NewPatientUsecase(pr, timeout), as)
+}
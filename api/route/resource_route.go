@@ -0,0 +1,25 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewResourceRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	rc := controller.NewResourceController(usecase.NewResourceUsecase(repository.NewResourceRepository(db), timeout), as)
+
+	group.POST("/resources", middleware.RBACMiddleware(domain.AdminRole), rc.Create)
+	group.GET("/resources", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), rc.Fetch)
+	group.GET("/resources/:id", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), rc.FetchByID)
+	group.PATCH("/resources/:id", middleware.RBACMiddleware(domain.AdminRole), rc.Update)
+	group.DELETE("/resources/:id", middleware.RBACMiddleware(domain.AdminRole), rc.Delete)
+}
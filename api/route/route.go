@@ -4,26 +4,55 @@ import (
 	"database/sql"
 	"hms-api/api/middleware"
 	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/blobstore"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Setup(env *bootstrap.Env, timeout time.Duration, db *sql.DB, gin *gin.Engine) {
+func Setup(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, ic domain.ICD10Catalog, bs blobstore.Store, gin *gin.Engine) {
 	publicRouter := gin.Group("")
+	publicRouter.Use(middleware.AuditReservationMiddleware(as))
 
 	NewRegisterRoute(env, timeout, db, publicRouter)
-	NewLoginRoute(env, timeout, db, publicRouter)
+	NewLoginRoute(env, timeout, db, as, publicRouter)
 	NewRefreshTokenRouter(env, timeout, db, publicRouter)
+	NewReminderRoute(env, timeout, db, as, nd, wb, publicRouter)
+	NewCalendarRoute(env, timeout, db, as, publicRouter)
 
 	protectedRouter := gin.Group("")
 
 	protectedRouter.Use(middleware.JwtAuthMiddleware(env.AccessTokenSecret))
+	protectedRouter.Use(middleware.AccessDeniedAuditMiddleware(as))
+	protectedRouter.Use(middleware.AuditReservationMiddleware(as))
 
-	NewDoctorRoute(env, timeout, db, protectedRouter)
-	NewPatientRoute(env, timeout, db, protectedRouter)
-	NewAppointmentRoute(env, timeout, db, protectedRouter)
-	NewPrescriptionRoute(env, timeout, db, protectedRouter)
-	NewMedicalRecordRoute(env, timeout, db, protectedRouter)
-	NewAuditLogRoute(env, timeout, db, protectedRouter)
+	NewFacilityRoute(env, timeout, db, as, protectedRouter)
+	NewDoctorRoute(env, timeout, db, as, protectedRouter)
+	NewAvailabilityRoute(env, timeout, db, protectedRouter)
+	NewAbsenceRoute(env, timeout, db, as, nd, wb, protectedRouter)
+	NewPatientRoute(env, timeout, db, as, protectedRouter)
+	NewAppointmentTypeRoute(env, timeout, db, as, protectedRouter)
+	NewResourceRoute(env, timeout, db, as, protectedRouter)
+	NewAppointmentRoute(env, timeout, db, as, nd, wb, protectedRouter)
+	NewAgendaRoute(env, timeout, db, as, protectedRouter)
+	NewWaitingRoomRoute(env, timeout, db, as, nd, wb, protectedRouter)
+	NewBookingRoute(env, timeout, db, as, nd, wb, protectedRouter)
+	NewAppointmentSeriesRoute(env, timeout, db, as, nd, wb, protectedRouter)
+	NewWaitlistRoute(env, timeout, db, as, nd, protectedRouter)
+	NewNotificationPreferenceRoute(env, timeout, db, as, nd, wb, protectedRouter)
+	NewCalendarFeedRoute(env, timeout, db, as, protectedRouter)
+	NewAttendanceRoute(env, timeout, db, as, protectedRouter)
+	NewPrescriptionRoute(env, timeout, db, as, protectedRouter)
+	NewICD10Route(ic, protectedRouter)
+	NewMedicalRecordRoute(env, timeout, db, as, ic, protectedRouter)
+	NewAttachmentRoute(env, timeout, db, as, bs, protectedRouter)
+	NewEncounterRoute(env, timeout, db, as, ic, nd, wb, protectedRouter)
+	NewAllergyRoute(env, timeout, db, as, protectedRouter)
+	NewAuditLogRoute(env, timeout, db, as, protectedRouter)
+	NewAccessLogRoute(env, timeout, db, as, protectedRouter)
+	NewSecurityAlertRoute(env, timeout, db, as, protectedRouter)
 }
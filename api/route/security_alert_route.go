@@ -0,0 +1,25 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewSecurityAlertRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, group *gin.RouterGroup) {
+	sar := repository.NewSecurityAlertRepository(db)
+	sac := controller.NewSecurityAlertController(usecase.NewSecurityAlertUsecase(sar, timeout), as)
+
+	group.GET("/security_alerts", middleware.RBACMiddleware(domain.AdminRole), sac.Fetch)
+	group.GET("/security_alerts/:id", middleware.RBACMiddleware(domain.AdminRole), sac.FetchByID)
+	group.POST("/security_alerts/:id/acknowledge", middleware.RBACMiddleware(domain.AdminRole), sac.Acknowledge)
+	group.POST("/security_alerts/:id/resolve", middleware.RBACMiddleware(domain.AdminRole), sac.Resolve)
+}
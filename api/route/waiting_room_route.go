@@ -0,0 +1,27 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/internal/waitingroom"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewWaitingRoomRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, wb waitingroom.Broker, group *gin.RouterGroup) {
+	wru := usecase.NewWaitingRoomUsecase(repository.NewAppointmentRepository(db), repository.NewReminderRepository(db), wb, timeout)
+	pu := usecase.NewPatientUsecase(repository.NewPatientRepository(db), timeout)
+	du := usecase.NewDoctorUsecase(repository.NewDoctorRepository(db), timeout)
+	wrc := controller.NewWaitingRoomController(wru, newAppointmentUsecase(env, timeout, db, nd, wb), pu, du, as)
+
+	group.GET("/waiting_room/events", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), wrc.Stream)
+	group.POST("/appointments/:id/call", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole), wrc.Call)
+}
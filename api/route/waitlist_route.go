@@ -0,0 +1,44 @@
+package route
+
+import (
+	"database/sql"
+	"hms-api/api/controller"
+	"hms-api/api/middleware"
+	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/notification"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewWaitlistRoute(env *bootstrap.Env, timeout time.Duration, db *sql.DB, as auditservice.Service, nd notification.Dispatcher, group *gin.RouterGroup) {
+	wu := newWaitlistUsecase(env, timeout, db, nd)
+	pu := usecase.NewPatientUsecase(repository.NewPatientRepository(db), timeout)
+	wc := controller.NewWaitlistController(wu, pu, as)
+
+	group.POST("/waitlist", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), wc.Join)
+	group.GET("/waitlist", middleware.RBACMiddleware(domain.AdminRole, domain.DoctorRole, domain.PatientRole), wc.Fetch)
+	group.DELETE("/waitlist/:id", middleware.RBACMiddleware(domain.AdminRole, domain.PatientRole), wc.Leave)
+	group.GET("/waitlist/offers", middleware.RBACMiddleware(domain.AdminRole, domain.PatientRole), wc.FetchOffers)
+	group.POST("/waitlist/offers/:id/accept", middleware.RBACMiddleware(domain.AdminRole, domain.PatientRole), wc.Accept)
+	group.POST("/waitlist/offers/:id/decline", middleware.RBACMiddleware(domain.AdminRole, domain.PatientRole), wc.Decline)
+}
+
+// newWaitlistUsecase builds the waitlist usecase, which tells patients about
+// the slots held for them through nd.
+func newWaitlistUsecase(env *bootstrap.Env, timeout time.Duration, db *sql.DB, nd notification.Dispatcher) domain.WaitlistUsecase {
+	an := usecase.NewAppointmentNotifier(repository.NewReminderRepository(db), nd, env.NotificationLanguage, timeout)
+	return usecase.NewWaitlistUsecase(
+		repository.NewWaitlistRepository(db),
+		repository.NewAppointmentRepository(db),
+		repository.NewDoctorRepository(db),
+		newAttendanceUsecase(env, timeout, db),
+		an,
+		time.Duration(env.WaitlistHoldMinutes)*time.Minute,
+		timeout,
+	)
+}
@@ -2,12 +2,14 @@ package bootstrap
 
 import (
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Env struct {
 	ServerAddress          string `mapstructure:"SERVER_ADDRESS"`
+	PublicBaseURL          string `mapstructure:"PUBLIC_BASE_URL"`
 	ContextTimeout         int    `mapstructure:"CONTEXT_TIMEOUT"`
 	DBHost                 string `mapstructure:"DB_HOST"`
 	DBPort                 string `mapstructure:"DB_PORT"`
@@ -18,6 +20,56 @@ type Env struct {
 	RefreshTokenExpiryHour int    `mapstructure:"REFRESH_TOKEN_EXPIRY_HOUR"`
 	AccessTokenSecret      string `mapstructure:"ACCESS_TOKEN_SECRET"`
 	RefreshTokenSecret     string `mapstructure:"REFRESH_TOKEN_SECRET"`
+	ShutdownTimeout        int    `mapstructure:"SHUTDOWN_TIMEOUT"`
+	AuditQueueSize         int    `mapstructure:"AUDIT_QUEUE_SIZE"`
+	AuditBatchSize         int    `mapstructure:"AUDIT_BATCH_SIZE"`
+	AuditFlushIntervalMs   int    `mapstructure:"AUDIT_FLUSH_INTERVAL_MS"`
+	AuditMaxRetries        int    `mapstructure:"AUDIT_MAX_RETRIES"`
+	AuditEnqueueTimeoutMs  int    `mapstructure:"AUDIT_ENQUEUE_TIMEOUT_MS"`
+	AuditDeadLetterPath    string `mapstructure:"AUDIT_DEAD_LETTER_PATH"`
+	AuditArchiveDir        string `mapstructure:"AUDIT_ARCHIVE_DIR"`
+	AuditArchiveHours      int    `mapstructure:"AUDIT_ARCHIVE_INTERVAL_HOURS"`
+	AuditHotMonths         int    `mapstructure:"AUDIT_HOT_MONTHS"`
+	AuditRetentionDays     string `mapstructure:"AUDIT_RETENTION_DAYS"`
+	AuditSinks             string `mapstructure:"AUDIT_SINKS"`
+	AuditFilePath          string `mapstructure:"AUDIT_FILE_PATH"`
+	AuditFileMaxSizeMB     int    `mapstructure:"AUDIT_FILE_MAX_SIZE_MB"`
+	AuditFileMaxBackups    int    `mapstructure:"AUDIT_FILE_MAX_BACKUPS"`
+	AuditSyslogNetwork     string `mapstructure:"AUDIT_SYSLOG_NETWORK"`
+	AuditSyslogAddress     string `mapstructure:"AUDIT_SYSLOG_ADDRESS"`
+	AuditWebhookURL        string `mapstructure:"AUDIT_WEBHOOK_URL"`
+	AuditWebhookSecret     string `mapstructure:"AUDIT_WEBHOOK_SECRET"`
+	AuditWebhookTimeout    int    `mapstructure:"AUDIT_WEBHOOK_TIMEOUT_SECONDS"`
+	AnomalyWindowMinutes   int    `mapstructure:"ANOMALY_WINDOW_MINUTES"`
+	AnomalyBulkRead        string `mapstructure:"ANOMALY_BULK_READ_THRESHOLDS"`
+	AnomalyAccessDenied    string `mapstructure:"ANOMALY_ACCESS_DENIED_THRESHOLDS"`
+	AnomalyOffHours        string `mapstructure:"ANOMALY_OFF_HOURS"`
+	WaitlistHoldMinutes    int    `mapstructure:"WAITLIST_HOLD_MINUTES"`
+	ReminderOffsets        string `mapstructure:"REMINDER_OFFSETS"`
+	ReminderLinkSecret     string `mapstructure:"REMINDER_LINK_SECRET"`
+	NotificationLanguage   string `mapstructure:"NOTIFICATION_LANGUAGE"`
+	NotificationOutbox     string `mapstructure:"NOTIFICATION_OUTBOX"`
+	RescheduleNoticeHours  int    `mapstructure:"RESCHEDULE_MIN_NOTICE_HOURS"`
+	PatientRescheduleLimit int    `mapstructure:"RESCHEDULE_MAX_PATIENT_RESCHEDULES"`
+	NoShowGraceMinutes     int    `mapstructure:"NO_SHOW_GRACE_MINUTES"`
+	LateCancelHours        int    `mapstructure:"LATE_CANCEL_HOURS"`
+	NoShowLimit            int    `mapstructure:"NO_SHOW_APPROVAL_LIMIT"`
+	LateCancelLimit        int    `mapstructure:"LATE_CANCEL_APPROVAL_LIMIT"`
+	AttendanceLookbackDays int    `mapstructure:"ATTENDANCE_LOOKBACK_DAYS"`
+	WaitingRoomHistory     int    `mapstructure:"WAITING_ROOM_HISTORY_SIZE"`
+	BookingHoldMinutes     int    `mapstructure:"BOOKING_HOLD_MINUTES"`
+	DefaultTimezone        string `mapstructure:"DEFAULT_TIMEZONE"`
+	ICD10File              string `mapstructure:"ICD10_FILE"`
+	BlobStore              string `mapstructure:"BLOB_STORE"`
+	BlobLocalDir           string `mapstructure:"BLOB_LOCAL_DIR"`
+	S3Endpoint             string `mapstructure:"S3_ENDPOINT"`
+	S3Region               string `mapstructure:"S3_REGION"`
+	S3Bucket               string `mapstructure:"S3_BUCKET"`
+	S3AccessKey            string `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey            string `mapstructure:"S3_SECRET_KEY"`
+	S3PathStyle            bool   `mapstructure:"S3_PATH_STYLE"`
+	AttachmentMaxSizeMB    int    `mapstructure:"ATTACHMENT_MAX_SIZE_MB"`
+	AttachmentTypes        string `mapstructure:"ATTACHMENT_TYPES"`
 }
 
 func NewEnv() *Env {
@@ -36,5 +88,15 @@ func NewEnv() *Env {
 		log.Fatal("Environment can't be loaded: ", err)
 	}
 
+	// Doctors without a facility and users without a timezone read times in
+	// the default timezone, the server's unless configured.
+	if env.DefaultTimezone != "" {
+		loc, err := time.LoadLocation(env.DefaultTimezone)
+		if err != nil {
+			log.Fatal("Invalid DEFAULT_TIMEZONE: ", err)
+		}
+		time.Local = loc
+	}
+
 	return &env
 }
\ No newline at end of file
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hms-api/bootstrap"
+	"hms-api/internal/auditarchive"
+	"hms-api/repository"
+	"log"
+	"os"
+	"time"
+)
+
+const usage = `Usage: auditctl <command> [arguments]
+
+Commands:
+  archive          create upcoming partitions, archive old ones and apply retention
+  verify <file>    check an archive file against its .sha256 checksum
+  restore <file>   verify an archive and load it into audit_logs_restored`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	if command == "verify" {
+		if len(os.Args) != 3 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		if err := auditarchive.Verify(os.Args[2]); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%s: OK\n", os.Args[2])
+		return
+	}
+
+	app := bootstrap.App()
+	env := app.Env
+	defer app.CloseDBConnection()
+
+	retention, err := auditarchive.ParseRetention(env.AuditRetentionDays)
+	if err != nil {
+		log.Fatal("Invalid AUDIT_RETENTION_DAYS: ", err)
+	}
+
+	archiver := auditarchive.NewArchiver(repository.NewAuditArchiveRepository(app.DB), auditarchive.Config{
+		Dir:           env.AuditArchiveDir,
+		HotMonths:     env.AuditHotMonths,
+		RetentionDays: retention,
+	})
+
+	ctx := context.Background()
+
+	switch command {
+	case "archive":
+		if err := archiver.Run(ctx, time.Now().UTC()); err != nil {
+			log.Fatal(err)
+		}
+	case "restore":
+		if len(os.Args) != 3 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		restored, err := archiver.Restore(ctx, os.Args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Restored %d entries from %s into audit_logs_restored\n", restored, os.Args[2])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -1,15 +1,36 @@
 package main
 
 import (
+	"context"
 	"hms-api/api/route"
 	"hms-api/bootstrap"
+	"hms-api/domain"
+	"hms-api/internal/anomaly"
+	"hms-api/internal/auditarchive"
+	"hms-api/internal/auditservice"
+	"hms-api/internal/blobstore"
+	"hms-api/internal/icd10"
+	"hms-api/internal/noshow"
+	"hms-api/internal/notification"
+	"hms-api/internal/reminder"
+	"hms-api/internal/waitingroom"
+	"hms-api/internal/waitlist"
+	"hms-api/repository"
+	"hms-api/usecase"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	
+
 	app := bootstrap.App()
 
 	env := app.Env
@@ -19,9 +40,238 @@ func main() {
 
 	timeout := time.Duration(env.ContextTimeout) * time.Second
 
+	alu := usecase.NewAuditLogUsecase(repository.NewAuditLogRepository(db), timeout)
+	sau := usecase.NewSecurityAlertUsecase(repository.NewSecurityAlertRepository(db), timeout)
+
+	as := auditservice.NewService(
+		alu,
+		auditservice.Config{
+			QueueSize:      env.AuditQueueSize,
+			BatchSize:      env.AuditBatchSize,
+			FlushInterval:  time.Duration(env.AuditFlushIntervalMs) * time.Millisecond,
+			MaxRetries:     env.AuditMaxRetries,
+			EnqueueTimeout: time.Duration(env.AuditEnqueueTimeoutMs) * time.Millisecond,
+			DeadLetterPath: env.AuditDeadLetterPath,
+		},
+		append(auditSinks(env), anomalyDetector(env, sau, alu))...,
+	)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if env.AuditArchiveHours > 0 {
+		retention, err := auditarchive.ParseRetention(env.AuditRetentionDays)
+		if err != nil {
+			log.Fatal("Invalid AUDIT_RETENTION_DAYS: ", err)
+		}
+		archiver := auditarchive.NewArchiver(repository.NewAuditArchiveRepository(db), auditarchive.Config{
+			Dir:           env.AuditArchiveDir,
+			HotMonths:     env.AuditHotMonths,
+			RetentionDays: retention,
+		})
+		go archiver.Start(jobsCtx, time.Duration(env.AuditArchiveHours)*time.Hour)
+	}
+
+	ar := repository.NewAppointmentRepository(db)
+	attu := usecase.NewAttendanceUsecase(repository.NewAttendanceRepository(db), route.AttendancePolicy(env), timeout)
+	go noshow.NewMarker(attu).Start(jobsCtx, time.Minute)
+
+	nd := notificationDispatcher(env)
+	wb := waitingroom.NewBroker(env.WaitingRoomHistory)
+	rr := repository.NewReminderRepository(db)
+	an := usecase.NewAppointmentNotifier(rr, nd, env.NotificationLanguage, timeout)
+
+	wr := repository.NewWaitlistRepository(db)
+	wu := usecase.NewWaitlistUsecase(
+		wr,
+		ar,
+		repository.NewDoctorRepository(db),
+		attu,
+		an,
+		time.Duration(env.WaitlistHoldMinutes)*time.Minute,
+		timeout,
+	)
+	go waitlist.NewExpirer(wu).Start(jobsCtx, time.Minute)
+
+	if env.ReminderLinkSecret == "" {
+		log.Fatal("REMINDER_LINK_SECRET must be set: it signs the confirm and cancel links of appointment reminders")
+	}
+	offsets, err := reminder.ParseOffsets(env.ReminderOffsets)
+	if err != nil {
+		log.Fatal("Invalid REMINDER_OFFSETS: ", err)
+	}
+	ru := usecase.NewReminderUsecase(
+		rr,
+		ar,
+		repository.NewPatientRepository(db),
+		usecase.NewAppointmentUsecase(ar, repository.NewAppointmentTypeRepository(db), usecase.NewAvailabilityUsecase(repository.NewAvailabilityRepository(db), ar, wr, repository.NewAbsenceRepository(db), timeout), wu, attu, an, usecase.NewWaitingRoomUsecase(ar, rr, wb, timeout), route.ReschedulePolicy(env), timeout),
+		attu,
+		an,
+		nd,
+		usecase.ReminderConfig{
+			Offsets:         offsets,
+			BaseURL:         env.PublicBaseURL,
+			LinkSecret:      env.ReminderLinkSecret,
+			DefaultLanguage: env.NotificationLanguage,
+		},
+		timeout,
+	)
+	go reminder.NewScheduler(ru).Start(jobsCtx, time.Minute)
+
+	ic, err := icd10.Load(env.ICD10File)
+	if err != nil {
+		log.Fatal("Error loading ICD10_FILE: ", err)
+	}
+
+	bs := blobStore(env)
+
 	gin := gin.Default()
-	
-	route.Setup(env, timeout, db, gin)
 
-	gin.Run(env.ServerAddress)
-}
\ No newline at end of file
+	route.Setup(env, timeout, db, as, nd, wb, ic, bs, gin)
+
+	srv := &http.Server{
+		Addr:    env.ServerAddress,
+		Handler: gin,
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Error starting server: ", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+	stopJobs()
+
+	shutdownTimeout := time.Duration(env.ShutdownTimeout) * time.Second
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println("Error shutting down server:", err)
+	}
+
+	if err := as.Close(ctx); err != nil {
+		log.Println("Error flushing audit logs:", err)
+	}
+}
+
+// auditSinks builds the extra audit destinations listed in AUDIT_SINKS. The
+// database is always written to and doesn't need to be listed.
+func auditSinks(env *bootstrap.Env) []auditservice.Sink {
+	var sinks []auditservice.Sink
+
+	for _, name := range strings.Split(env.AuditSinks, ",") {
+		var sink auditservice.Sink
+		var err error
+
+		switch strings.TrimSpace(name) {
+		case "":
+			continue
+		case "file":
+			path := env.AuditFilePath
+			if path == "" {
+				path = "logs/audit.jsonl"
+			}
+			sink, err = auditservice.NewFileSink(path, env.AuditFileMaxSizeMB, env.AuditFileMaxBackups)
+		case "syslog":
+			network := env.AuditSyslogNetwork
+			if network == "" {
+				network = "udp"
+			}
+			sink, err = auditservice.NewSyslogSink(network, env.AuditSyslogAddress, "hms-api")
+		case "webhook":
+			sink, err = auditservice.NewWebhookSink(env.AuditWebhookURL, env.AuditWebhookSecret, time.Duration(env.AuditWebhookTimeout)*time.Second)
+		default:
+			log.Fatalf("Unknown audit sink %q in AUDIT_SINKS", name)
+		}
+
+		if err != nil {
+			log.Fatalf("Error configuring %s audit sink: %v", name, err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks
+}
+
+// notificationDispatcher sends notifications through the local fake senders,
+// which write them to NOTIFICATION_OUTBOX, or to the log when it isn't set.
+func notificationDispatcher(env *bootstrap.Env) notification.Dispatcher {
+	out := log.Writer()
+
+	if env.NotificationOutbox != "" {
+		if err := os.MkdirAll(filepath.Dir(env.NotificationOutbox), 0o750); err != nil {
+			log.Fatal("Error creating notification outbox directory: ", err)
+		}
+		file, err := os.OpenFile(env.NotificationOutbox, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+		if err != nil {
+			log.Fatal("Error opening notification outbox: ", err)
+		}
+		out = file
+	}
+
+	return notification.NewDispatcher(notification.NewFakeSenders(out)...)
+}
+
+// blobStore keeps attachments in BLOB_LOCAL_DIR, or in an S3-compatible bucket
+// when BLOB_STORE is s3.
+func blobStore(env *bootstrap.Env) blobstore.Store {
+	var store blobstore.Store
+	var err error
+
+	switch strings.TrimSpace(env.BlobStore) {
+	case "", "local":
+		dir := env.BlobLocalDir
+		if dir == "" {
+			dir = "data/blobs"
+		}
+		store, err = blobstore.NewLocalStore(dir)
+	case "s3":
+		store, err = blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:  env.S3Endpoint,
+			Region:    env.S3Region,
+			Bucket:    env.S3Bucket,
+			AccessKey: env.S3AccessKey,
+			SecretKey: env.S3SecretKey,
+			PathStyle: env.S3PathStyle,
+		})
+	default:
+		log.Fatalf("Unknown blob store %q in BLOB_STORE", env.BlobStore)
+	}
+
+	if err != nil {
+		log.Fatal("Error configuring blob store: ", err)
+	}
+	return store
+}
+
+func anomalyDetector(env *bootstrap.Env, sau domain.SecurityAlertUsecase, alu domain.AuditLogUsecase) auditservice.Sink {
+	bulkRead, err := anomaly.ParseThresholds(env.AnomalyBulkRead)
+	if err != nil {
+		log.Fatal("Invalid ANOMALY_BULK_READ_THRESHOLDS: ", err)
+	}
+	accessDenied, err := anomaly.ParseThresholds(env.AnomalyAccessDenied)
+	if err != nil {
+		log.Fatal("Invalid ANOMALY_ACCESS_DENIED_THRESHOLDS: ", err)
+	}
+	offHoursStart, offHoursEnd, err := anomaly.ParseOffHours(env.AnomalyOffHours)
+	if err != nil {
+		log.Fatal("Invalid ANOMALY_OFF_HOURS: ", err)
+	}
+
+	return anomaly.NewDetector(sau, alu, anomaly.Config{
+		Window:                 time.Duration(env.AnomalyWindowMinutes) * time.Minute,
+		BulkReadThresholds:     bulkRead,
+		AccessDeniedThresholds: accessDenied,
+		OffHoursStart:          offHoursStart,
+		OffHoursEnd:            offHoursEnd,
+	})
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AbsenceKind string
+
+const (
+	AbsenceSick     AbsenceKind = "sick"
+	AbsenceVacation AbsenceKind = "vacation"
+	AbsenceHoliday  AbsenceKind = "holiday"
+	AbsenceOther    AbsenceKind = "other"
+)
+
+// RebookAction is what happens to the appointments an absence falls on.
+type RebookAction string
+
+const (
+	RebookCancel RebookAction = "cancel"
+	RebookMove   RebookAction = "move"
+)
+
+// Outcomes of rebooking an appointment.
+const (
+	RebookCanceled = "canceled"
+	RebookMoved    = "moved"
+	RebookFailed   = "failed"
+)
+
+var (
+	ErrAbsenceNotFound = errors.New("absence not found")
+	ErrInvalidAbsence  = errors.New("invalid absence")
+	ErrInvalidRebook   = errors.New("invalid rebooking")
+	ErrNoCoverDoctor   = errors.New("no other doctor of the specialty is free at this time")
+)
+
+// DoctorAbsence is a span of time a doctor doesn't see patients, whatever
+// their weekly template says. No slots are offered during it.
+type DoctorAbsence struct {
+	ID        uuid.UUID   `json:"absence_id"`
+	DoctorID  uuid.UUID   `json:"doctor_id"`
+	Kind      AbsenceKind `json:"kind"`
+	StartsAt  time.Time   `json:"starts_at" binding:"required"`
+	EndsAt    time.Time   `json:"ends_at" binding:"required"`
+	Reason    string      `json:"reason"`
+	CreatedBy uuid.UUID   `json:"created_by"`
+	CreatedAt time.Time   `json:"created_at,omitempty"`
+}
+
+// Overlaps reports whether the absence covers any of [start, end).
+func (a DoctorAbsence) Overlaps(start time.Time, end time.Time) bool {
+	return a.StartsAt.Before(end) && a.EndsAt.After(start)
+}
+
+// AbsenceResult is an absence along with the requested and confirmed
+// appointments it falls on, which still have to be rebooked.
+type AbsenceResult struct {
+	Absence  DoctorAbsence `json:"absence"`
+	Affected []Appointment `json:"affected_appointments"`
+}
+
+// RebookRequest cancels or moves the appointments an absence falls on, or only
+// those in AppointmentIDs. Moved appointments keep their time and go to
+// DoctorID or, without one, to the first free doctor of the same specialty,
+// preferring the same facility.
+type RebookRequest struct {
+	Action         RebookAction `json:"action" binding:"required"`
+	DoctorID       *uuid.UUID   `json:"doctor_id"`
+	AppointmentIDs []uuid.UUID  `json:"appointment_ids"`
+	Reason         string       `json:"reason"`
+}
+
+// RebookOutcome reports what happened to one appointment. Failed appointments
+// are left as they were.
+type RebookOutcome struct {
+	AppointmentID uuid.UUID  `json:"appointment_id"`
+	PatientID     uuid.UUID  `json:"patient_id"`
+	Outcome       string     `json:"outcome"`
+	DoctorID      *uuid.UUID `json:"doctor_id,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+type AbsenceRepository interface {
+	Create(c context.Context, absence *DoctorAbsence) error
+	// FetchByDoctorID lists the doctor's absences overlapping [from, to).
+	FetchByDoctorID(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]DoctorAbsence, error)
+	FetchByID(c context.Context, doctorID uuid.UUID, id uuid.UUID) (DoctorAbsence, error)
+	Delete(c context.Context, doctorID uuid.UUID, id uuid.UUID) error
+}
+
+type AbsenceUsecase interface {
+	Create(c context.Context, absence *DoctorAbsence) (AbsenceResult, error)
+	FetchByDoctorID(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]DoctorAbsence, error)
+	Delete(c context.Context, doctorID uuid.UUID, id uuid.UUID) error
+	FetchAffected(c context.Context, doctorID uuid.UUID, id uuid.UUID) (AbsenceResult, error)
+	Rebook(c context.Context, doctorID uuid.UUID, id uuid.UUID, request RebookRequest, actorID uuid.UUID, actorRole UserRole) ([]RebookOutcome, error)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutstandingPrescriptionDays is how far back a prescription counts as still
+// being taken on the agenda.
+const OutstandingPrescriptionDays = 90
+
+var ErrInvalidAgendaDate = errors.New("invalid date, expected YYYY-MM-DD")
+
+// Agenda is a doctor's appointments for one day, in their facility's
+// timezone, with what they need to know about each patient.
+type Agenda struct {
+	DoctorID     uuid.UUID     `json:"doctor_id"`
+	Date         string        `json:"date"`
+	Timezone     string        `json:"timezone"`
+	Appointments []AgendaEntry `json:"appointments"`
+}
+
+type AgendaEntry struct {
+	Appointment              Appointment          `json:"appointment"`
+	PatientName              string               `json:"patient_name"`
+	PatientDateBirth         time.Time            `json:"patient_date_birth"`
+	PatientAge               int                  `json:"patient_age"`
+	Allergies                []AgendaAllergy      `json:"allergies"`
+	LastVisit                *AgendaLastVisit     `json:"last_visit"`
+	OutstandingPrescriptions []AgendaPrescription `json:"outstanding_prescriptions"`
+}
+
+// AgendaAllergy is one of the patient's active allergies.
+type AgendaAllergy struct {
+	Substance string `json:"substance"`
+	Reaction  string `json:"reaction"`
+	Severity  string `json:"severity"`
+}
+
+// AgendaLastVisit summarizes the patient's last completed appointment before
+// the day, with any doctor, and their latest medical record.
+type AgendaLastVisit struct {
+	Date       time.Time `json:"date"`
+	DoctorName string    `json:"doctor_name"`
+	Diagnosis  string    `json:"diagnosis,omitempty"`
+	Treatment  string    `json:"treatment,omitempty"`
+}
+
+type AgendaPrescription struct {
+	ID                uuid.UUID `json:"prescription_id"`
+	DoctorID          uuid.UUID `json:"doctor_id"`
+	MedicationDetails string    `json:"medication_details"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// Age is the number of whole years from birth to on.
+func Age(birth time.Time, on time.Time) int {
+	age := on.Year() - birth.Year()
+	if on.Month() < birth.Month() || (on.Month() == birth.Month() && on.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+type AgendaRepository interface {
+	// FetchByDoctorID lists the doctor's appointments starting in [from, to),
+	// other than canceled ones, with their patients' details. Prescriptions
+	// issued since prescribedSince are outstanding.
+	FetchByDoctorID(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time, prescribedSince time.Time) ([]AgendaEntry, error)
+}
+
+type AgendaUsecase interface {
+	// FetchByDoctorID returns the doctor's agenda for date, a YYYY-MM-DD date
+	// in their facility's timezone, or for today when it's empty.
+	FetchByDoctorID(c context.Context, doctorID uuid.UUID, date string) (Agenda, error)
+}
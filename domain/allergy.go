@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AllergyKind string
+
+const (
+	Allergy     AllergyKind = "allergy"
+	Intolerance AllergyKind = "intolerance"
+)
+
+type AllergySeverity string
+
+const (
+	MildAllergy     AllergySeverity = "mild"
+	ModerateAllergy AllergySeverity = "moderate"
+	SevereAllergy   AllergySeverity = "severe"
+)
+
+type AllergyStatus string
+
+const (
+	AllergyActive         AllergyStatus = "active"
+	AllergyResolved       AllergyStatus = "resolved"
+	AllergyEnteredInError AllergyStatus = "entered_in_error"
+)
+
+var (
+	ErrAllergyNotFound = errors.New("allergy not found")
+	ErrInvalidAllergy  = errors.New("invalid allergy")
+)
+
+// PatientAllergy is a substance the patient is allergic or intolerant to.
+// Only active ones are checked against new prescriptions.
+type PatientAllergy struct {
+	ID         uuid.UUID       `json:"allergy_id"`
+	PatientID  uuid.UUID       `json:"patient_id"`
+	Substance  string          `json:"substance" binding:"required"`
+	Kind       AllergyKind     `json:"kind"`
+	Reaction   string          `json:"reaction,omitempty"`
+	Severity   AllergySeverity `json:"severity" binding:"required"`
+	Status     AllergyStatus   `json:"status"`
+	RecordedBy *uuid.UUID      `json:"recorded_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// AllergyChange updates the fields of an allergy that are given.
+type AllergyChange struct {
+	Substance *string          `json:"substance"`
+	Kind      *AllergyKind     `json:"kind"`
+	Reaction  *string          `json:"reaction"`
+	Severity  *AllergySeverity `json:"severity"`
+	Status    *AllergyStatus   `json:"status"`
+}
+
+// AllergyWarning is an active allergy a prescription's medication matches.
+// Matched is the name in the prescription that matched, which may be a drug
+// of the same class as the substance.
+type AllergyWarning struct {
+	AllergyID uuid.UUID       `json:"allergy_id"`
+	Substance string          `json:"substance"`
+	Kind      AllergyKind     `json:"kind"`
+	Reaction  string          `json:"reaction,omitempty"`
+	Severity  AllergySeverity `json:"severity"`
+	Matched   string          `json:"matched"`
+}
+
+// AllergyConflictError is returned when a prescription matches the patient's
+// active allergies and no override reason was given.
+type AllergyConflictError struct {
+	Warnings []AllergyWarning
+}
+
+func (e *AllergyConflictError) Error() string {
+	return "the prescription matches the patient's active allergies; give an allergy_override_reason to prescribe it anyway"
+}
+
+type AllergyConflictResponse struct {
+	Message  string           `json:"message"`
+	Warnings []AllergyWarning `json:"warnings"`
+}
+
+type AllergyRepository interface {
+	Create(c context.Context, allergy *PatientAllergy) error
+	FetchByID(c context.Context, id uuid.UUID) (*PatientAllergy, error)
+	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]PatientAllergy, error)
+	FetchActiveByPatientID(c context.Context, patientID uuid.UUID) ([]PatientAllergy, error)
+	Update(c context.Context, allergy *PatientAllergy) error
+}
+
+type AllergyUsecase interface {
+	Create(c context.Context, allergy *PatientAllergy) error
+	FetchByID(c context.Context, id uuid.UUID) (*PatientAllergy, error)
+	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]PatientAllergy, error)
+	Update(c context.Context, id uuid.UUID, change AllergyChange) (*PatientAllergy, error)
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,38 +12,211 @@ import (
 type AppointmentStatus string
 
 const (
-	Scheduled AppointmentStatus = "scheduled"
-	Completed AppointmentStatus = "completed"
-	Canceled  AppointmentStatus = "canceled"
+	Requested  AppointmentStatus = "requested"
+	Confirmed  AppointmentStatus = "confirmed"
+	CheckedIn  AppointmentStatus = "checked_in"
+	InProgress AppointmentStatus = "in_progress"
+	Completed  AppointmentStatus = "completed"
+	Canceled   AppointmentStatus = "canceled"
+	NoShow     AppointmentStatus = "no_show"
 )
 
+// AppointmentTransition is one edge of the appointment lifecycle: the action
+// that triggers it, the statuses it may start from and the roles allowed to
+// take it.
+type AppointmentTransition struct {
+	Action         string
+	From           []AppointmentStatus
+	To             AppointmentStatus
+	Roles          []UserRole
+	RequiresReason bool
+}
+
+// AppointmentTransitions is the appointment state machine:
+//
+//	requested -> confirmed -> checked_in -> in_progress -> completed
+//
+// An appointment can be canceled before it starts, and marked as a no-show
+// once confirmed if the patient never checks in.
+var AppointmentTransitions = map[string]AppointmentTransition{
+	"confirm": {
+		Action: "confirm",
+		From:   []AppointmentStatus{Requested},
+		To:     Confirmed,
+		Roles:  []UserRole{AdminRole, DoctorRole},
+	},
+	"check-in": {
+		Action: "check-in",
+		From:   []AppointmentStatus{Confirmed},
+		To:     CheckedIn,
+		Roles:  []UserRole{AdminRole},
+	},
+	"start": {
+		Action: "start",
+		From:   []AppointmentStatus{CheckedIn},
+		To:     InProgress,
+		Roles:  []UserRole{DoctorRole},
+	},
+	"complete": {
+		Action: "complete",
+		From:   []AppointmentStatus{InProgress},
+		To:     Completed,
+		Roles:  []UserRole{DoctorRole},
+	},
+	"cancel": {
+		Action:         "cancel",
+		From:           []AppointmentStatus{Requested, Confirmed, CheckedIn},
+		To:             Canceled,
+		Roles:          []UserRole{AdminRole, DoctorRole, PatientRole},
+		RequiresReason: true,
+	},
+	"no-show": {
+		Action: "no-show",
+		From:   []AppointmentStatus{Confirmed},
+		To:     NoShow,
+		Roles:  []UserRole{AdminRole, DoctorRole},
+	},
+}
+
+var (
+	ErrAppointmentNotFound = errors.New("appointment not found")
+	ErrInvalidTransition   = errors.New("appointment can't move to the requested status from its current status")
+	ErrTransitionForbidden = errors.New("your role can't perform this appointment transition")
+	ErrReasonRequired      = errors.New("a reason is required for this transition")
+)
+
+// AppointmentStatusChange is one entry of an appointment's status history.
+// FromStatus is empty for the entry recorded when the appointment is created.
+type AppointmentStatusChange struct {
+	ID            uuid.UUID         `json:"id"`
+	AppointmentID uuid.UUID         `json:"appointment_id"`
+	FromStatus    AppointmentStatus `json:"from_status"`
+	ToStatus      AppointmentStatus `json:"to_status"`
+	ChangedBy     uuid.UUID         `json:"changed_by"`
+	ChangedByRole UserRole          `json:"changed_by_role"`
+	Reason        string            `json:"reason,omitempty"`
+	ChangedAt     time.Time         `json:"changed_at"`
+}
+
+type TransitionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ErrAppointmentFieldLocked is returned when an update tries to change an
+// appointment's time, doctor or patient.
+var ErrAppointmentFieldLocked = errors.New("only notes can be updated; move the appointment through POST /appointments/:id/reschedule")
+
+// AppointmentChange updates an appointment's notes. The time, the doctor and
+// the patient are only there to reject requests that send them: times change
+// through Reschedule, which checks availability and keeps the history.
+type AppointmentChange struct {
+	Notes           *string    `json:"notes"`
+	PatientID       *uuid.UUID `json:"patient_id"`
+	DoctorID        *uuid.UUID `json:"doctor_id"`
+	AppointmentDate *time.Time `json:"appointment_date"`
+	EndDate         *time.Time `json:"end_date"`
+}
+
+// DefaultAppointmentDuration is used when an appointment is booked without an
+// end date.
+const DefaultAppointmentDuration = 30 * time.Minute
+
+var ErrInvalidAppointmentTime = errors.New("end_date must be after appointment_date")
+
+// AppointmentConflictError is returned when an appointment would overlap
+// another active appointment of the same doctor or patient, or when none of
+// the resources its type needs is free ("resource"). Suggestions holds the
+// nearest free slots of the same length.
+type AppointmentConflictError struct {
+	With        string
+	Suggestions []Slot
+}
+
+func (e *AppointmentConflictError) Error() string {
+	if e.With == "resource" {
+		return "no room or equipment the appointment needs is free at this time"
+	}
+	return fmt.Sprintf("the %s already has an appointment at this time", e.With)
+}
+
+type AppointmentConflictResponse struct {
+	Message     string `json:"message"`
+	Conflict    string `json:"conflict"`
+	Suggestions []Slot `json:"suggestions"`
+}
+
 type Appointment struct {
     ID              uuid.UUID         `json:"appointment_id"`
     PatientID       uuid.UUID         `json:"patient_id"`
     DoctorID        uuid.UUID         `json:"doctor_id"`
     AppointmentDate time.Time         `json:"appointment_date"`
+    EndDate         time.Time         `json:"end_date"`
     Status          AppointmentStatus `json:"status"`
     Notes           string            `json:"notes"`
+    SeriesID        *uuid.UUID        `json:"series_id,omitempty"`
+    TypeID          *uuid.UUID        `json:"type_id,omitempty"`
+    Resources       []AppointmentResource `json:"resources,omitempty"`
+    CancelReason    string            `json:"cancel_reason,omitempty"`
+    ConfirmedAt     *time.Time        `json:"confirmed_at,omitempty"`
+    CheckedInAt     *time.Time        `json:"checked_in_at,omitempty"`
+    StartedAt       *time.Time        `json:"started_at,omitempty"`
+    CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+    CanceledAt      *time.Time        `json:"canceled_at,omitempty"`
+    NoShowAt        *time.Time        `json:"no_show_at,omitempty"`
+    TicketNumber    *int              `json:"ticket_number,omitempty"`
+    Sequence        int               `json:"sequence"`
+    // Timezone is the doctor's facility timezone; the local dates are the
+    // appointment's times on its clocks.
+    Timezone             string       `json:"timezone,omitempty"`
+    LocalAppointmentDate string       `json:"local_appointment_date,omitempty"`
+    LocalEndDate         string       `json:"local_end_date,omitempty"`
     CreatedAt       time.Time         `json:"created_at,omitempty"`
     UpdatedAt       time.Time         `json:"updated_at,omitempty"`
 }
 
+// Localize reports the appointment's times in UTC and in timezone, falling
+// back to the server's timezone when it's empty.
+func (a *Appointment) Localize(timezone string) {
+	loc := Location(timezone)
+	a.AppointmentDate = a.AppointmentDate.UTC()
+	a.EndDate = a.EndDate.UTC()
+	a.Timezone = TimezoneName(loc)
+	a.LocalAppointmentDate = a.AppointmentDate.In(loc).Format(time.RFC3339)
+	a.LocalEndDate = a.EndDate.In(loc).Format(time.RFC3339)
+}
+
 type AppointmentRepository interface {
-    Create(c context.Context, appointment *Appointment) error
+    Create(c context.Context, appointment *Appointment, change *AppointmentStatusChange) error
     Fetch(c context.Context) ([]Appointment, error)
     FetchByID(c context.Context, id uuid.UUID) (Appointment, error)
     FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Appointment, error)
     FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]Appointment, error)
-    Update(c context.Context, appointment *Appointment) error
+    FetchActiveByDoctorIDBetween(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]Appointment, error)
+    FetchActiveByPatientIDBetween(c context.Context, patientID uuid.UUID, from time.Time, to time.Time) ([]Appointment, error)
+    // UpdateNotes replaces the appointment's notes.
+    UpdateNotes(c context.Context, appointment *Appointment) error
+    UpdateStatus(c context.Context, appointment *Appointment, from AppointmentStatus, change *AppointmentStatusChange) (bool, error)
+    FetchStatusHistory(c context.Context, appointmentID uuid.UUID) ([]AppointmentStatusChange, error)
+    // Reschedule moves the appointment to reschedule's new times and doctor
+    // if it still has one of the given statuses, filling in its previous
+    // times and doctor and recording the change. It reports false otherwise.
+    Reschedule(c context.Context, appointment *Appointment, statuses []AppointmentStatus, reschedule *AppointmentReschedule) (bool, error)
+    FetchReschedules(c context.Context, appointmentID uuid.UUID) ([]AppointmentReschedule, error)
+    CountReschedules(c context.Context, appointmentID uuid.UUID, role UserRole) (int, error)
+    FetchResources(c context.Context, appointmentID uuid.UUID) ([]AppointmentResource, error)
     Delete(c context.Context, id uuid.UUID) error
 }
 
 type AppointmentUsecase interface {
-    Create(c context.Context, appointment *Appointment) error
+    Create(c context.Context, appointment *Appointment, actorID uuid.UUID, actorRole UserRole) error
     Fetch(c context.Context) ([]Appointment, error)
     FetchByID(c context.Context, id uuid.UUID) (Appointment, error)
     FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Appointment, error)
     FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]Appointment, error)
-    Update(c context.Context, appointment *Appointment) error
+    Update(c context.Context, id uuid.UUID, change AppointmentChange) (Appointment, error)
+    Transition(c context.Context, id uuid.UUID, action string, actorID uuid.UUID, actorRole UserRole, reason string) (Appointment, error)
+    FetchStatusHistory(c context.Context, appointmentID uuid.UUID) ([]AppointmentStatusChange, error)
+    Reschedule(c context.Context, id uuid.UUID, request RescheduleRequest, actorID uuid.UUID, actorRole UserRole) (Appointment, error)
+    FetchReschedules(c context.Context, appointmentID uuid.UUID) ([]AppointmentReschedule, error)
     Delete(c context.Context, id uuid.UUID) error
 }
\ No newline at end of file
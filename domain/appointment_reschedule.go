@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Defaults for ReschedulePolicy fields left unset.
+const (
+	DefaultRescheduleMinNotice   = 24 * time.Hour
+	DefaultMaxPatientReschedules = 2
+)
+
+var (
+	ErrRescheduleNotAllowed = errors.New("only requested or confirmed appointments can be rescheduled")
+	ErrRescheduleInPast     = errors.New("an appointment can't be moved to the past")
+	ErrRescheduleTooLate    = errors.New("the appointment is too close to be rescheduled")
+	ErrRescheduleLimit      = errors.New("the appointment was already rescheduled the maximum number of times")
+)
+
+// ReschedulePolicy limits how patients reschedule. Staff aren't bound by it.
+type ReschedulePolicy struct {
+	// MinNotice is how long before the appointment's current time it can
+	// still be rescheduled.
+	MinNotice time.Duration
+	// MaxPatientReschedules is how many times patients can reschedule the
+	// same appointment.
+	MaxPatientReschedules int
+}
+
+type RescheduleRequest struct {
+	AppointmentDate time.Time `json:"appointment_date" binding:"required"`
+	EndDate         time.Time `json:"end_date"`
+	Reason          string    `json:"reason"`
+}
+
+// AppointmentReschedule is one entry of an appointment's reschedule history.
+// The doctors differ when the appointment was given to another doctor, as
+// when rebooking around an absence.
+type AppointmentReschedule struct {
+	ID                uuid.UUID `json:"id"`
+	AppointmentID     uuid.UUID `json:"appointment_id"`
+	PreviousStart     time.Time `json:"previous_start"`
+	PreviousEnd       time.Time `json:"previous_end"`
+	NewStart          time.Time `json:"new_start"`
+	NewEnd            time.Time `json:"new_end"`
+	PreviousDoctorID  uuid.UUID `json:"previous_doctor_id"`
+	NewDoctorID       uuid.UUID `json:"new_doctor_id"`
+	RescheduledBy     uuid.UUID `json:"rescheduled_by"`
+	RescheduledByRole UserRole  `json:"rescheduled_by_role"`
+	Reason            string    `json:"reason,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
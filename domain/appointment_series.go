@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SeriesStatus string
+
+const (
+	SeriesActive   SeriesStatus = "active"
+	SeriesCanceled SeriesStatus = "canceled"
+)
+
+// SeriesScope selects which occurrences of a series an edit or cancellation
+// applies to.
+type SeriesScope string
+
+const (
+	ScopeSingle    SeriesScope = "single"
+	ScopeFollowing SeriesScope = "following"
+	ScopeAll       SeriesScope = "all"
+)
+
+// MaxSeriesOccurrences bounds how many appointments a single series can book.
+const MaxSeriesOccurrences = 104
+
+var (
+	ErrInvalidRecurrence   = errors.New("invalid recurrence")
+	ErrSeriesNotFound      = errors.New("appointment series not found")
+	ErrInvalidScope        = errors.New("invalid scope")
+	ErrOutsideAvailability = errors.New("the doctor isn't available at this time")
+)
+
+// Reasons an occurrence of a new series wasn't booked.
+const (
+	SkipExcluded    = "excluded"
+	SkipPast        = "past"
+	SkipUnavailable = "outside_availability"
+	SkipDoctorBusy  = "doctor_conflict"
+	SkipPatientBusy = "patient_conflict"
+)
+
+// AppointmentSeries is a recurring appointment. StartDate is the first
+// occurrence (DTSTART) and RRule an RFC 5545 recurrence rule, such as
+// "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=20". ExDates lists "YYYY-MM-DD" dates left
+// out of the series. Occurrences are booked as regular appointments linked to
+// the series.
+type AppointmentSeries struct {
+	ID              uuid.UUID    `json:"series_id"`
+	PatientID       uuid.UUID    `json:"patient_id" binding:"required"`
+	DoctorID        uuid.UUID    `json:"doctor_id" binding:"required"`
+	StartDate       time.Time    `json:"start_date" binding:"required"`
+	DurationMinutes int          `json:"duration_minutes"`
+	RRule           string       `json:"rrule" binding:"required"`
+	ExDates         []string     `json:"exdates"`
+	Notes           string       `json:"notes"`
+	Status          SeriesStatus `json:"status"`
+	CreatedBy       uuid.UUID    `json:"created_by"`
+	CreatedAt       time.Time    `json:"created_at,omitempty"`
+	UpdatedAt       time.Time    `json:"updated_at,omitempty"`
+}
+
+type SkippedOccurrence struct {
+	Start  time.Time `json:"start"`
+	Reason string    `json:"reason"`
+}
+
+type AppointmentSeriesResult struct {
+	Series       AppointmentSeries   `json:"series"`
+	Appointments []Appointment       `json:"appointments"`
+	Skipped      []SkippedOccurrence `json:"skipped,omitempty"`
+}
+
+// SeriesUpdateRequest changes the occurrences selected by Scope, counted from
+// AppointmentID for the single and following scopes. StartTime ("HH:MM")
+// moves the occurrences to a new time on the same dates; unset fields are
+// left unchanged.
+type SeriesUpdateRequest struct {
+	Scope           SeriesScope `json:"scope" binding:"required"`
+	AppointmentID   uuid.UUID   `json:"appointment_id"`
+	StartTime       string      `json:"start_time"`
+	DurationMinutes int         `json:"duration_minutes"`
+	DoctorID        uuid.UUID   `json:"doctor_id"`
+	Notes           *string     `json:"notes"`
+}
+
+type SeriesCancelRequest struct {
+	Scope         SeriesScope `json:"scope" binding:"required"`
+	AppointmentID uuid.UUID   `json:"appointment_id"`
+	Reason        string      `json:"reason" binding:"required"`
+}
+
+type AppointmentSeriesRepository interface {
+	Create(c context.Context, series *AppointmentSeries, appointments []Appointment, change AppointmentStatusChange) error
+	FetchByID(c context.Context, id uuid.UUID) (AppointmentSeries, error)
+	FetchOccurrences(c context.Context, seriesID uuid.UUID) ([]Appointment, error)
+	// Update saves the series and the given occurrences. Those with an entry
+	// in reschedules are moved like AppointmentRepository.Reschedule does, and
+	// if one no longer has one of statuses nothing is saved and it returns
+	// ErrRescheduleNotAllowed.
+	Update(c context.Context, series *AppointmentSeries, split *AppointmentSeries, appointments []Appointment, statuses []AppointmentStatus, reschedules map[uuid.UUID]*AppointmentReschedule) error
+	Cancel(c context.Context, series *AppointmentSeries, appointmentIDs []uuid.UUID, change AppointmentStatusChange) error
+}
+
+type AppointmentSeriesUsecase interface {
+	Create(c context.Context, series *AppointmentSeries, actorID uuid.UUID, actorRole UserRole) (AppointmentSeriesResult, error)
+	FetchByID(c context.Context, id uuid.UUID) (AppointmentSeriesResult, error)
+	Update(c context.Context, id uuid.UUID, request SeriesUpdateRequest, actorID uuid.UUID, actorRole UserRole) (AppointmentSeriesResult, error)
+	Cancel(c context.Context, id uuid.UUID, request SeriesCancelRequest, actorID uuid.UUID, actorRole UserRole) (AppointmentSeriesResult, error)
+}
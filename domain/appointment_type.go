@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAppointmentTypeNotFound  = errors.New("appointment type not found")
+	ErrInvalidAppointmentType   = errors.New("invalid appointment type")
+	ErrDuplicateAppointmentType = errors.New("an appointment type with this code already exists")
+)
+
+// AppointmentType describes a kind of appointment, e.g. a first visit or a
+// procedure. Appointments booked with a type and no end date last
+// DurationMinutes, and get one free resource of each RequiredResources
+// category reserved alongside the doctor.
+type AppointmentType struct {
+	ID                uuid.UUID `json:"type_id"`
+	Code              string    `json:"code" binding:"required"`
+	Name              string    `json:"name" binding:"required"`
+	DurationMinutes   int       `json:"duration_minutes" binding:"required"`
+	Telehealth        bool      `json:"telehealth"`
+	RequiredResources []string  `json:"required_resources"`
+	Active            bool      `json:"active"`
+	CreatedAt         time.Time `json:"created_at,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at,omitempty"`
+}
+
+// Duration is how long appointments of the type last by default.
+func (t AppointmentType) Duration() time.Duration {
+	return time.Duration(t.DurationMinutes) * time.Minute
+}
+
+type AppointmentTypeRepository interface {
+	Create(c context.Context, appointmentType *AppointmentType) error
+	Fetch(c context.Context) ([]AppointmentType, error)
+	FetchByID(c context.Context, id uuid.UUID) (AppointmentType, error)
+	Update(c context.Context, appointmentType *AppointmentType) error
+	// Deactivate takes the type out of new bookings, reporting false when it
+	// doesn't exist.
+	Deactivate(c context.Context, id uuid.UUID) (bool, error)
+}
+
+type AppointmentTypeUsecase interface {
+	Create(c context.Context, appointmentType *AppointmentType) error
+	Fetch(c context.Context) ([]AppointmentType, error)
+	FetchByID(c context.Context, id uuid.UUID) (AppointmentType, error)
+	Update(c context.Context, appointmentType *AppointmentType) error
+	Delete(c context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentNotFound        = errors.New("attachment not found")
+	ErrAttachmentTooLarge        = errors.New("attachment is too large")
+	ErrUnsupportedAttachmentType = errors.New("unsupported attachment type")
+	ErrInvalidAttachment         = errors.New("invalid attachment")
+)
+
+// Attachment is a document, such as an exam result or a scanned referral,
+// kept in the blob store. It always belongs to a patient and may also be
+// linked to one of their medical records or prescriptions.
+type Attachment struct {
+	ID              uuid.UUID  `json:"attachment_id"`
+	PatientID       uuid.UUID  `json:"patient_id"`
+	MedicalRecordID *uuid.UUID `json:"medical_record_id,omitempty"`
+	PrescriptionID  *uuid.UUID `json:"prescription_id,omitempty"`
+	FileName        string     `json:"file_name"`
+	Description     string     `json:"description,omitempty"`
+	// ContentType is sniffed from the content, whatever the client declared.
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	StorageKey  string    `json:"-"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentPolicy limits what can be uploaded.
+type AttachmentPolicy struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+type AttachmentRepository interface {
+	Create(c context.Context, attachment *Attachment) error
+	FetchByID(c context.Context, id uuid.UUID) (*Attachment, error)
+	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Attachment, error)
+	FetchByMedicalRecordID(c context.Context, medicalRecordID uuid.UUID) ([]Attachment, error)
+	FetchByPrescriptionID(c context.Context, prescriptionID uuid.UUID) ([]Attachment, error)
+}
+
+type AttachmentUsecase interface {
+	// Upload stores the attachment's Size bytes of content, filling in its
+	// patient from the linked record or prescription, its type and checksum.
+	Upload(c context.Context, attachment *Attachment, content io.Reader) error
+	FetchByID(c context.Context, id uuid.UUID) (*Attachment, error)
+	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Attachment, error)
+	FetchByMedicalRecordID(c context.Context, medicalRecordID uuid.UUID) ([]Attachment, error)
+	FetchByPrescriptionID(c context.Context, prescriptionID uuid.UUID) ([]Attachment, error)
+	// Open reads the attachment's content, which the caller must close.
+	Open(c context.Context, attachment *Attachment) (io.ReadCloser, error)
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SystemRole marks status changes made by background jobs instead of a user.
+const SystemRole UserRole = "system"
+
+// Defaults for AttendancePolicy fields left unset.
+const (
+	DefaultNoShowGrace        = time.Hour
+	DefaultLateCancelWindow   = 24 * time.Hour
+	DefaultNoShowLimit        = 3
+	DefaultLateCancelLimit    = 5
+	DefaultAttendanceLookback = 365 * 24 * time.Hour
+)
+
+// Groupings of the no-show report.
+const (
+	GroupByDoctor    = "doctor"
+	GroupBySpecialty = "specialty"
+)
+
+var (
+	ErrApprovalRequired   = errors.New("this patient's appointments must be approved by the front desk")
+	ErrInvalidReportGroup = errors.New("group_by must be doctor or specialty")
+)
+
+// AttendancePolicy sets when appointments become no-shows and when patients
+// lose the ability to have appointments confirmed without an admin.
+type AttendancePolicy struct {
+	// NoShowGrace is how long after its end a confirmed appointment the
+	// patient never checked in to is marked as a no-show.
+	NoShowGrace time.Duration
+	// LateCancelWindow is how close to the appointment a cancellation by the
+	// patient counts as late.
+	LateCancelWindow time.Duration
+	// NoShowLimit and LateCancelLimit are how many no-shows or late
+	// cancellations within Lookback put the patient under approval.
+	NoShowLimit     int
+	LateCancelLimit int
+	Lookback        time.Duration
+}
+
+// PatientAttendance counts the patient's no-shows and late cancellations of
+// appointments since Since.
+type PatientAttendance struct {
+	PatientID         uuid.UUID `json:"patient_id"`
+	Since             time.Time `json:"since"`
+	NoShows           int       `json:"no_shows"`
+	LateCancellations int       `json:"late_cancellations"`
+	RequiresApproval  bool      `json:"requires_approval"`
+}
+
+// NoShowRate is one row of the no-show report. Appointments counts those the
+// patient was expected at: checked in, in progress, completed or no-show.
+type NoShowRate struct {
+	DoctorID     *uuid.UUID `json:"doctor_id,omitempty"`
+	DoctorName   string     `json:"doctor_name,omitempty"`
+	Specialty    string     `json:"specialty"`
+	Appointments int        `json:"appointments"`
+	NoShows      int        `json:"no_shows"`
+	Rate         float64    `json:"rate"`
+}
+
+type AttendanceRepository interface {
+	// MarkNoShows moves confirmed appointments that ended before cutoff to
+	// no_show, recording reason in their history, and returns how many it
+	// moved.
+	MarkNoShows(c context.Context, cutoff time.Time, reason string) (int, error)
+	FetchAttendance(c context.Context, patientID uuid.UUID, since time.Time, lateCancelWindow time.Duration) (PatientAttendance, error)
+	FetchNoShowRates(c context.Context, groupBy string, from time.Time, to time.Time) ([]NoShowRate, error)
+}
+
+type AttendanceUsecase interface {
+	MarkNoShows(c context.Context, now time.Time) (int, error)
+	FetchAttendance(c context.Context, patientID uuid.UUID) (PatientAttendance, error)
+	// RequiresApproval reports whether the patient's appointments can only be
+	// confirmed by an admin.
+	RequiresApproval(c context.Context, patientID uuid.UUID) (bool, error)
+	FetchNoShowRates(c context.Context, groupBy string, from time.Time, to time.Time) ([]NoShowRate, error)
+}
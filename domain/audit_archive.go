@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// AuditCategory groups audit actions that share a retention period.
+type AuditCategory struct {
+	Name           string
+	ActionPrefixes []string
+}
+
+const DefaultAuditCategory = "default"
+
+var AuditCategories = []AuditCategory{
+	{Name: "authentication", ActionPrefixes: []string{"USER_LOGIN"}},
+	{Name: "clinical", ActionPrefixes: []string{"MEDICAL_RECORD_", "PRESCRIPTION_"}},
+	{Name: "patient_data", ActionPrefixes: []string{"PATIENT_", "APPOINTMENT_", "ACCESS_LOG_"}},
+}
+
+// AuditCategoryFor returns the category an action belongs to, falling back to
+// DefaultAuditCategory when no prefix matches.
+func AuditCategoryFor(action string) string {
+	for _, category := range AuditCategories {
+		for _, prefix := range category.ActionPrefixes {
+			if strings.HasPrefix(action, prefix) {
+				return category.Name
+			}
+		}
+	}
+	return DefaultAuditCategory
+}
+
+// AuditPartition is one monthly range partition of audit_logs.
+type AuditPartition struct {
+	Name string    `json:"name"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type AuditArchiveRepository interface {
+	FetchPartitions(c context.Context) ([]AuditPartition, error)
+	CreatePartition(c context.Context, month time.Time) error
+	StreamPartition(c context.Context, name string, fn func(log AuditLog) error) error
+	DropPartition(c context.Context, name string) error
+	DeleteByCategoryBefore(c context.Context, category string, before time.Time) (int64, error)
+	CreateRestored(c context.Context, logs []AuditLog, source string) error
+}
@@ -7,26 +7,68 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ResourcePatient           = "patient"
+	ResourceAppointment       = "appointment"
+	ResourceAppointmentSeries = "appointment_series"
+	ResourceWaitlistEntry     = "waitlist_entry"
+	ResourceWaitlistOffer     = "waitlist_offer"
+	ResourceMedicalRecord     = "medical_record"
+	ResourcePrescription      = "prescription"
+	ResourceAccessLog         = "access_log"
+	ResourceCalendarFeed      = "calendar_feed"
+	ResourceSlotHold          = "slot_hold"
+	ResourceAttachment        = "attachment"
+	ResourceEncounter         = "encounter"
+	ResourceAllergy           = "allergy"
+)
+
 type AuditLog struct {
-	ID          uuid.UUID `json:"id"`
-	UserID      uuid.UUID `json:"user_id"`
-	Action      string    `json:"action"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at,omitempty"`
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
+	UserRole     UserRole  `json:"user_role,omitempty"`
+	Action       string    `json:"action"`
+	Description  string    `json:"description"`
+	ResourceType string    `json:"resource_type,omitempty"`
+	ResourceID   uuid.UUID `json:"resource_id,omitempty"`
+	PatientID    uuid.UUID `json:"patient_id,omitempty"`
+	Purpose      string    `json:"purpose,omitempty"`
+	IPAddress    string    `json:"ip_address,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+}
+
+// AccessLogEntry is one line of a patient's accounting of disclosures: who
+// touched which part of their data, when and why.
+type AccessLogEntry struct {
+	AccessedAt   time.Time `json:"accessed_at"`
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	UserRole     UserRole  `json:"user_role"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   uuid.UUID `json:"resource_id"`
+	Purpose      string    `json:"purpose"`
+	Description  string    `json:"description"`
 }
 
 type AuditLogRepository interface {
 	Create(c context.Context, log *AuditLog) error
+	CreateBatch(c context.Context, logs []AuditLog) error
 	Fetch(c context.Context) ([]AuditLog, error)
 	FetchByID(c context.Context, id uuid.UUID) (AuditLog, error)
+	FetchAccessLogByPatientID(c context.Context, patientID uuid.UUID) ([]AccessLogEntry, error)
+	FetchLoginIPs(c context.Context, userID uuid.UUID, before time.Time) ([]string, error)
 	Update(c context.Context, log *AuditLog) error
 	Delete(c context.Context, id uuid.UUID) error
 }
 
 type AuditLogUsecase interface {
 	Create(c context.Context, log *AuditLog) error
+	CreateBatch(c context.Context, logs []AuditLog) error
 	Fetch(c context.Context) ([]AuditLog, error)
 	FetchByID(c context.Context, id uuid.UUID) (AuditLog, error)
+	FetchAccessLogByPatientID(c context.Context, patientID uuid.UUID) ([]AccessLogEntry, error)
+	FetchLoginIPs(c context.Context, userID uuid.UUID, before time.Time) ([]string, error)
 	Update(c context.Context, log *AuditLog) error
 	Delete(c context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSchedule is returned when an availability template or exception
+// has malformed or inconsistent hours.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// TimeRange is a span of wall-clock time within a day, as "HH:MM", in the
+// timezone of the doctor's facility.
+type TimeRange struct {
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+}
+
+// WeeklyAvailability is one working period of a doctor's weekly template.
+// Weekday follows time.Weekday, so 0 is Sunday.
+type WeeklyAvailability struct {
+	ID          uuid.UUID   `json:"availability_id"`
+	DoctorID    uuid.UUID   `json:"doctor_id"`
+	Weekday     int         `json:"weekday"`
+	StartTime   string      `json:"start_time" binding:"required"`
+	EndTime     string      `json:"end_time" binding:"required"`
+	SlotMinutes int         `json:"slot_minutes" binding:"required"`
+	Breaks      []TimeRange `json:"breaks"`
+}
+
+// AvailabilityException overrides the weekly template on a single date. When
+// Available is false the doctor doesn't work that day; otherwise its hours
+// replace the template's.
+type AvailabilityException struct {
+	ID          uuid.UUID   `json:"exception_id"`
+	DoctorID    uuid.UUID   `json:"doctor_id"`
+	Date        string      `json:"date" binding:"required"`
+	Available   bool        `json:"available"`
+	StartTime   string      `json:"start_time,omitempty"`
+	EndTime     string      `json:"end_time,omitempty"`
+	SlotMinutes int         `json:"slot_minutes,omitempty"`
+	Breaks      []TimeRange `json:"breaks"`
+	Reason      string      `json:"reason"`
+	CreatedAt   time.Time   `json:"created_at,omitempty"`
+}
+
+type DoctorAvailability struct {
+	DoctorID   uuid.UUID               `json:"doctor_id"`
+	Timezone   string                  `json:"timezone"`
+	Weekly     []WeeklyAvailability    `json:"weekly"`
+	Exceptions []AvailabilityException `json:"exceptions"`
+}
+
+type Slot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type AvailabilityRepository interface {
+	FetchWeekly(c context.Context, doctorID uuid.UUID) ([]WeeklyAvailability, error)
+	ReplaceWeekly(c context.Context, doctorID uuid.UUID, weekly []WeeklyAvailability) error
+	FetchExceptions(c context.Context, doctorID uuid.UUID, from string, to string) ([]AvailabilityException, error)
+	SaveException(c context.Context, exception *AvailabilityException) error
+	DeleteException(c context.Context, doctorID uuid.UUID, id uuid.UUID) error
+	FetchTimezone(c context.Context, doctorID uuid.UUID) (string, error)
+}
+
+type AvailabilityUsecase interface {
+	FetchByDoctorID(c context.Context, doctorID uuid.UUID) (DoctorAvailability, error)
+	ReplaceWeekly(c context.Context, doctorID uuid.UUID, weekly []WeeklyAvailability) error
+	SaveException(c context.Context, exception *AvailabilityException) error
+	DeleteException(c context.Context, doctorID uuid.UUID, id uuid.UUID) error
+	FetchSlots(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]Slot, error)
+	OutsideWorkingHours(c context.Context, doctorID uuid.UUID, spans []Slot) ([]Slot, error)
+	// Location is the timezone the doctor's hours are written in: their
+	// facility's, or the server's when they have none.
+	Location(c context.Context, doctorID uuid.UUID) (*time.Location, error)
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "held"
+	HoldBooked   HoldStatus = "booked"
+	HoldReleased HoldStatus = "released"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// DefaultBookingHold is how long a held slot stays reserved for the patient.
+const DefaultBookingHold = 10 * time.Minute
+
+var (
+	ErrInvalidBookingSearch = errors.New("invalid booking search")
+	ErrSlotUnavailable      = errors.New("the slot is no longer available")
+	ErrHoldNotFound         = errors.New("slot hold not found")
+	ErrHoldClosed           = errors.New("the slot is no longer held")
+)
+
+// BookingSearch looks for free slots with any doctor of a specialty,
+// optionally at one facility. With a TypeID only starts with enough free time
+// for the type's duration are returned.
+type BookingSearch struct {
+	Specialty string
+	Facility  string
+	TypeID    *uuid.UUID
+	From      time.Time
+	To        time.Time
+}
+
+// BookingDoctor is a doctor patients can book with.
+type BookingDoctor struct {
+	DoctorID   uuid.UUID `json:"doctor_id"`
+	DoctorName string    `json:"doctor_name"`
+	Specialty  string    `json:"specialty"`
+	Facility   string    `json:"facility,omitempty"`
+	Timezone   string    `json:"timezone,omitempty"`
+}
+
+// BookableSlot is a free slot found by a booking search. Its times carry the
+// offset of the doctor's facility timezone.
+type BookableSlot struct {
+	BookingDoctor
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type HoldRequest struct {
+	DoctorID uuid.UUID  `json:"doctor_id" binding:"required"`
+	Start    time.Time  `json:"start" binding:"required"`
+	End      time.Time  `json:"end"`
+	TypeID   *uuid.UUID `json:"type_id"`
+}
+
+// SlotHold reserves a doctor's time for a patient while they finish booking.
+// Until ExpiresAt nobody else can book or hold it; a patient has at most one
+// hold at a time.
+type SlotHold struct {
+	ID            uuid.UUID  `json:"hold_id"`
+	PatientID     uuid.UUID  `json:"patient_id"`
+	DoctorID      uuid.UUID  `json:"doctor_id"`
+	TypeID        *uuid.UUID `json:"type_id,omitempty"`
+	SlotStart     time.Time  `json:"slot_start"`
+	SlotEnd       time.Time  `json:"slot_end"`
+	Status        HoldStatus `json:"status"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	AppointmentID *uuid.UUID `json:"appointment_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+}
+
+// Active reports whether the hold still reserves its slot at now.
+func (h SlotHold) Active(now time.Time) bool {
+	return h.Status == HoldActive && now.Before(h.ExpiresAt)
+}
+
+type BookingRepository interface {
+	FetchDoctors(c context.Context, specialty string, facility string) ([]BookingDoctor, error)
+	// FetchActiveHolds lists the unexpired holds of the doctors overlapping
+	// [from, to).
+	FetchActiveHolds(c context.Context, doctorIDs []uuid.UUID, from time.Time, to time.Time) ([]SlotHold, error)
+	// CreateHold releases the patient's other holds and stores hold, answering
+	// ErrSlotUnavailable when another patient holds overlapping time.
+	CreateHold(c context.Context, hold *SlotHold) error
+	FetchHoldByID(c context.Context, id uuid.UUID) (SlotHold, error)
+	// CloseHold moves an active hold to status, reporting false when it
+	// already wasn't active.
+	CloseHold(c context.Context, hold *SlotHold, status HoldStatus) (bool, error)
+}
+
+type BookingUsecase interface {
+	Search(c context.Context, search BookingSearch) ([]BookableSlot, error)
+	Hold(c context.Context, patientID uuid.UUID, request HoldRequest) (SlotHold, error)
+	FetchHold(c context.Context, patientID uuid.UUID, id uuid.UUID) (SlotHold, error)
+	Release(c context.Context, patientID uuid.UUID, id uuid.UUID) error
+	// Confirm books the held slot for the patient.
+	Confirm(c context.Context, patientID uuid.UUID, id uuid.UUID, notes string, actorID uuid.UUID) (Appointment, error)
+}
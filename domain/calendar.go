@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+	ErrCalendarUnavailable  = errors.New("calendar feeds are only available to doctors and patients")
+)
+
+// CalendarFeed is a secret iCalendar subscription URL for a user's
+// appointments. Only a hash of the token is stored, so Token and URL are set
+// only when the feed is created.
+type CalendarFeed struct {
+	ID        uuid.UUID  `json:"feed_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Token     string     `json:"token,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CalendarFeedRepository interface {
+	Create(c context.Context, feed *CalendarFeed, tokenHash string) error
+	FetchByUserID(c context.Context, userID uuid.UUID) ([]CalendarFeed, error)
+	FetchActiveByTokenHash(c context.Context, tokenHash string) (CalendarFeed, error)
+	Revoke(c context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+}
+
+type CalendarUsecase interface {
+	CreateFeed(c context.Context, userID uuid.UUID, role UserRole) (CalendarFeed, error)
+	FetchFeeds(c context.Context, userID uuid.UUID) ([]CalendarFeed, error)
+	RevokeFeed(c context.Context, id uuid.UUID, userID uuid.UUID) error
+	// Render builds the .ics file of the feed with the given token.
+	Render(c context.Context, token string) (CalendarFeed, []byte, error)
+}
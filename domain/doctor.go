@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,13 +13,21 @@ type Doctor struct {
 	UserId uuid.UUID `json:"user_id"`
 	CRM string `json:"crm"`
 	Specialty string `json:"specialty"`
+	Facility string `json:"facility"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 }
 
+// AtFacility reports whether the doctor works at facility. Names are compared
+// ignoring case, and doctors without a facility are at none.
+func (d Doctor) AtFacility(facility string) bool {
+	return d.Facility != "" && strings.EqualFold(d.Facility, facility)
+}
+
 type DoctorRepository interface {
 	Create(c context.Context, doctor *Doctor) error
 	Fetch(c context.Context) ([]Doctor, error)
 	FetchByID(c context.Context, id uuid.UUID) (Doctor, error)
+	FetchByUserID(c context.Context, userID uuid.UUID) (Doctor, error)
 	Update(c context.Context, doctor *Doctor) error
 	Delete(c context.Context, id uuid.UUID) error
 }
@@ -27,6 +36,7 @@ type DoctorUsecase interface {
 	Create(c context.Context, doctor *Doctor) error
 	Fetch(c context.Context) ([]Doctor, error)
 	FetchByID(c context.Context, id uuid.UUID) (Doctor, error)
+	FetchByUserID(c context.Context, userID uuid.UUID) (Doctor, error)
 	Update(c context.Context, doctor *Doctor) error
 	Delete(c context.Context, id uuid.UUID) error
 }
\ No newline at end of file
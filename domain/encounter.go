@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EncounterStatus string
+
+const (
+	EncounterOpen   EncounterStatus = "open"
+	EncounterClosed EncounterStatus = "closed"
+)
+
+type OrderKind string
+
+const (
+	LabOrder       OrderKind = "lab"
+	ImagingOrder   OrderKind = "imaging"
+	ProcedureOrder OrderKind = "procedure"
+	ReferralOrder  OrderKind = "referral"
+)
+
+var (
+	ErrEncounterNotFound  = errors.New("encounter not found")
+	ErrEncounterClosed    = errors.New("encounter is closed")
+	ErrEncounterExists    = errors.New("the appointment already has an encounter")
+	ErrEncounterForbidden = errors.New("only the encounter's doctor can change it")
+	ErrInvalidEncounter   = errors.New("invalid encounter")
+)
+
+// Encounter is a visit of a patient to a doctor, opened from a checked-in
+// appointment or for a walk-in, which has no appointment. The medical
+// records, prescriptions and orders made during the visit are attached to it.
+type Encounter struct {
+	ID            uuid.UUID       `json:"encounter_id"`
+	PatientID     uuid.UUID       `json:"patient_id"`
+	DoctorID      uuid.UUID       `json:"doctor_id"`
+	AppointmentID *uuid.UUID      `json:"appointment_id"`
+	Status        EncounterStatus `json:"status"`
+	// Reason is the patient's chief complaint.
+	Reason    string     `json:"reason,omitempty"`
+	Notes     string     `json:"notes,omitempty"`
+	OpenedBy  uuid.UUID  `json:"opened_by"`
+	OpenedAt  time.Time  `json:"opened_at"`
+	ClosedBy  *uuid.UUID `json:"closed_by,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	MedicalRecords []MedicalRecord  `json:"medical_records,omitempty"`
+	Prescriptions  []Prescription   `json:"prescriptions,omitempty"`
+	Orders         []EncounterOrder `json:"orders,omitempty"`
+}
+
+// EncounterOrder is an exam, procedure or referral ordered during an
+// encounter.
+type EncounterOrder struct {
+	ID          uuid.UUID `json:"order_id"`
+	EncounterID uuid.UUID `json:"encounter_id"`
+	PatientID   uuid.UUID `json:"patient_id"`
+	Kind        OrderKind `json:"kind" binding:"required"`
+	Description string    `json:"description" binding:"required"`
+	Notes       string    `json:"notes,omitempty"`
+	OrderedBy   uuid.UUID `json:"ordered_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OpenEncounterRequest opens an encounter from an appointment, or for a
+// walk-in of the patient when there's none.
+type OpenEncounterRequest struct {
+	AppointmentID *uuid.UUID `json:"appointment_id"`
+	PatientID     *uuid.UUID `json:"patient_id"`
+	Reason        string     `json:"reason"`
+	Notes         string     `json:"notes"`
+}
+
+type CloseEncounterRequest struct {
+	Notes string `json:"notes"`
+}
+
+type EncounterRepository interface {
+	Create(c context.Context, encounter *Encounter) error
+	FetchByID(c context.Context, id uuid.UUID) (*Encounter, error)
+	FetchByAppointmentID(c context.Context, appointmentID uuid.UUID) (*Encounter, error)
+	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Encounter, error)
+	// Close closes the encounter if it's still open, reporting false
+	// otherwise.
+	Close(c context.Context, encounter *Encounter) (bool, error)
+	CreateOrder(c context.Context, order *EncounterOrder) error
+	FetchOrders(c context.Context, encounterID uuid.UUID) ([]EncounterOrder, error)
+}
+
+type EncounterUsecase interface {
+	// Open starts the appointment if it's checked in. Only its doctor can
+	// open it.
+	Open(c context.Context, request OpenEncounterRequest, doctorID uuid.UUID, actorID uuid.UUID, actorRole UserRole) (*Encounter, error)
+	// FetchByID returns the encounter with its records, prescriptions and
+	// orders.
+	FetchByID(c context.Context, id uuid.UUID) (*Encounter, error)
+	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Encounter, error)
+	// Close completes the encounter's appointment along with it.
+	Close(c context.Context, id uuid.UUID, notes string, doctorID uuid.UUID, actorID uuid.UUID, actorRole UserRole) (*Encounter, error)
+	AddMedicalRecord(c context.Context, id uuid.UUID, record *MedicalRecord, doctorID uuid.UUID, actorID uuid.UUID) error
+	// AddPrescription adds a prescription for one of the encounter's records.
+	AddPrescription(c context.Context, id uuid.UUID, prescription *Prescription, doctorID uuid.UUID) error
+	AddOrder(c context.Context, id uuid.UUID, order *EncounterOrder, doctorID uuid.UUID, actorID uuid.UUID) error
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFacilityNotFound  = errors.New("facility not found")
+	ErrInvalidFacility   = errors.New("invalid facility")
+	ErrDuplicateFacility = errors.New("a facility with this name already exists")
+	ErrFacilityInUse     = errors.New("the facility still has doctors")
+	ErrInvalidTimezone   = errors.New("invalid timezone, expected an IANA name such as America/Sao_Paulo")
+)
+
+// Facility is a place doctors see patients at. Its Timezone, an IANA name,
+// is the local time doctors' schedules there are written in.
+type Facility struct {
+	ID        uuid.UUID `json:"facility_id"`
+	Name      string    `json:"name" binding:"required"`
+	Timezone  string    `json:"timezone" binding:"required"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+type FacilityRepository interface {
+	Create(c context.Context, facility *Facility) error
+	Fetch(c context.Context) ([]Facility, error)
+	FetchByID(c context.Context, id uuid.UUID) (Facility, error)
+	Update(c context.Context, facility *Facility) error
+	Delete(c context.Context, id uuid.UUID) error
+}
+
+type FacilityUsecase interface {
+	Create(c context.Context, facility *Facility) error
+	Fetch(c context.Context) ([]Facility, error)
+	FetchByID(c context.Context, id uuid.UUID) (Facility, error)
+	Update(c context.Context, facility *Facility) error
+	Delete(c context.Context, id uuid.UUID) error
+}
+
+var locations sync.Map
+
+// Location loads the IANA timezone name, falling back to the server's
+// default timezone when name is empty or unknown. Loaded zones are cached.
+func Location(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// WallClock returns the given local time in loc. Around DST changes it
+// follows RFC 5545: a time skipped by the change moves forward by the gap, and
+// a time that happens twice is its first occurrence. time.Date resolves both
+// with whichever offset it picks.
+func WallClock(year int, month time.Month, day int, hour int, min int, sec int, loc *time.Location) time.Time {
+	naive := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	// Offsets don't change twice within two days, so the ones on either side
+	// are those of the change, if any.
+	_, before := naive.Add(-48 * time.Hour).In(loc).Zone()
+	_, after := naive.Add(48 * time.Hour).In(loc).Zone()
+
+	first := naive.Add(-time.Duration(before) * time.Second).In(loc)
+	second := naive.Add(-time.Duration(after) * time.Second).In(loc)
+	_, firstOffset := first.Zone()
+	_, secondOffset := second.Zone()
+
+	switch {
+	case firstOffset == before && secondOffset == after:
+		if second.Before(first) {
+			return second
+		}
+		return first
+	case secondOffset == after:
+		return second
+	default:
+		// Either first is the only valid time, or the time was skipped and
+		// the offset before the gap places it after.
+		return first
+	}
+}
+
+// TimezoneName is the IANA name of loc, or "" for a server default that has
+// none.
+func TimezoneName(loc *time.Location) string {
+	if name := loc.String(); name != "Local" {
+		return name
+	}
+	return ""
+}
+
+// ValidTimezone reports whether name is a timezone Location can load.
+func ValidTimezone(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+)
+
+type DiagnosisType string
+
+const (
+	PrimaryDiagnosis   DiagnosisType = "primary"
+	SecondaryDiagnosis DiagnosisType = "secondary"
+)
+
+var ErrInvalidDiagnosis = errors.New("invalid diagnosis")
+
+type ICD10Code struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// CodedDiagnosis is an ICD-10 code given in a medical record. A record has at
+// most one primary diagnosis.
+type CodedDiagnosis struct {
+	Code        string        `json:"code"`
+	Description string        `json:"description"`
+	Type        DiagnosisType `json:"type"`
+}
+
+type ICD10Catalog interface {
+	// Search finds up to limit codes by code or by description, ignoring case
+	// and accents.
+	Search(query string, limit int) []ICD10Code
+	Lookup(code string) (ICD10Code, bool)
+}
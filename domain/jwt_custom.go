@@ -6,13 +6,24 @@ import (
 )
 
 type JwtCustomClaims struct {
-	Username string `json:"username"`
-	ID   uuid.UUID `json:"id"`
-	Role UserRole `json:"role"`
+	Username string    `json:"username"`
+	ID       uuid.UUID `json:"id"`
+	Role     UserRole  `json:"role"`
 	jwt.RegisteredClaims
 }
 
 type JwtCustomRefreshClaims struct {
-	ID uuid.UUID  `json:"id"`
+	ID uuid.UUID `json:"id"`
 	jwt.RegisteredClaims
-}
\ No newline at end of file
+}
+
+// ReminderClaims back the confirm and cancel links sent in appointment
+// reminders. They expire when the appointment starts, and only hold while
+// the appointment is at the Sequence it had when the reminder was sent.
+type ReminderClaims struct {
+	AppointmentID uuid.UUID `json:"appointment_id"`
+	PatientID     uuid.UUID `json:"patient_id"`
+	Sequence      int       `json:"sequence"`
+	Action        string    `json:"action"`
+	jwt.RegisteredClaims
+}
@@ -2,34 +2,101 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type MedicalRecordStatus string
+
+const (
+	MedicalRecordActive         MedicalRecordStatus = "active"
+	MedicalRecordEnteredInError MedicalRecordStatus = "entered_in_error"
+)
+
+var (
+	ErrMedicalRecordNotFound       = errors.New("medical record not found")
+	ErrMedicalRecordEnteredInError = errors.New("medical record was entered in error")
+	ErrInvalidMedicalRecord        = errors.New("invalid medical record")
+)
+
+// MedicalRecord is the latest version of a chart entry. Records are never
+// changed in place or deleted: each change is saved as a new version, and a
+// record made by mistake is marked entered in error instead.
 type MedicalRecord struct {
 	ID        uuid.UUID `json:"medical_record_id"`
 	PatientID uuid.UUID `json:"patient_id"`
 	DoctorID  uuid.UUID `json:"doctor_id"`
-	Diagnosis string    `json:"diagnosis"`
-	Treatment string    `json:"treatment"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
+	// Diagnosis holds free-text notes on the diagnosis, which is coded in
+	// Diagnoses.
+	Diagnosis string              `json:"diagnosis"`
+	Diagnoses []CodedDiagnosis    `json:"diagnoses"`
+	Treatment string              `json:"treatment"`
+	Status    MedicalRecordStatus `json:"status"`
+	Version   int                 `json:"version"`
+	// AmendsID is the record this one amends, which is left as it was.
+	AmendsID *uuid.UUID `json:"amends_id,omitempty"`
+	// EncounterID is the visit the record was made in, which amendments
+	// share with the original.
+	EncounterID *uuid.UUID `json:"encounter_id,omitempty"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+}
+
+// MedicalRecordVersion is a record as it was saved, along with who saved it
+// and why.
+type MedicalRecordVersion struct {
+	MedicalRecordID uuid.UUID           `json:"medical_record_id"`
+	Version         int                 `json:"version"`
+	Diagnosis       string              `json:"diagnosis"`
+	Diagnoses       []CodedDiagnosis    `json:"diagnoses"`
+	Treatment       string              `json:"treatment"`
+	Status          MedicalRecordStatus `json:"status"`
+	Reason          string              `json:"reason,omitempty"`
+	ChangedBy       *uuid.UUID          `json:"changed_by,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+// MedicalRecordChange revises a record or amends it. Only the fields given
+// change; the others keep the record's content. DoctorID only applies to
+// amendments, naming the amending doctor (the original's by default).
+type MedicalRecordChange struct {
+	DoctorID  *uuid.UUID        `json:"doctor_id"`
+	Diagnosis *string           `json:"diagnosis"`
+	Diagnoses *[]CodedDiagnosis `json:"diagnoses"`
+	Treatment *string           `json:"treatment"`
+	Reason    string            `json:"reason" binding:"required"`
+}
+
+type EnteredInErrorRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
 
 type MedicalRecordRepository interface {
-	Create(c context.Context, record *MedicalRecord) error
+	// Create saves the record as its first version.
+	Create(c context.Context, record *MedicalRecord, reason string) error
 	Fetch(c context.Context) ([]MedicalRecord, error)
 	FetchByID(c context.Context, id uuid.UUID) (*MedicalRecord, error)
 	FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]MedicalRecord, error)
-	Update(c context.Context, record *MedicalRecord) error
-	Delete(c context.Context, id uuid.UUID) error
+	FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]MedicalRecord, error)
+	// Revise saves the record's diagnoses, treatment and status as its next
+	// version, unless it was entered in error.
+	Revise(c context.Context, record *MedicalRecord, reason string) error
+	FetchVersions(c context.Context, id uuid.UUID) ([]MedicalRecordVersion, error)
+	FetchAmendments(c context.Context, id uuid.UUID) ([]MedicalRecord, error)
 }
 
 type MedicalRecordUsecase interface {
 	Create(c context.Context, record *MedicalRecord) error
 	Fetch(c context.Context) ([]MedicalRecord, error)
 	FetchByID(c context.Context, id uuid.UUID) (*MedicalRecord, error)
-	FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]MedicalRecord, error) 
-	Update(c context.Context, record *MedicalRecord) error
-	Delete(c context.Context, id uuid.UUID) error
+	FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]MedicalRecord, error)
+	FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]MedicalRecord, error)
+	Update(c context.Context, id uuid.UUID, change MedicalRecordChange, changedBy uuid.UUID) (*MedicalRecord, error)
+	Amend(c context.Context, id uuid.UUID, change MedicalRecordChange, changedBy uuid.UUID) (*MedicalRecord, error)
+	MarkEnteredInError(c context.Context, id uuid.UUID, reason string, changedBy uuid.UUID) (*MedicalRecord, error)
+	FetchVersions(c context.Context, id uuid.UUID) ([]MedicalRecordVersion, error)
+	FetchAmendments(c context.Context, id uuid.UUID) ([]MedicalRecord, error)
 }
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationChannel string
+
+const (
+	EmailChannel NotificationChannel = "email"
+	SMSChannel   NotificationChannel = "sms"
+	PushChannel  NotificationChannel = "push"
+)
+
+const (
+	LanguagePortuguese = "pt-BR"
+	LanguageEnglish    = "en"
+)
+
+// DefaultReminderOffsets are how long before an appointment reminders go out
+// when REMINDER_OFFSETS isn't set.
+var DefaultReminderOffsets = []time.Duration{24 * time.Hour, 2 * time.Hour}
+
+// Reminder link actions.
+const (
+	ReminderConfirm = "confirm"
+	ReminderCancel  = "cancel"
+)
+
+var (
+	ErrInvalidReminderLink        = errors.New("invalid or expired reminder link")
+	ErrStaleReminderLink          = errors.New("the appointment changed after this reminder was sent")
+	ErrInvalidNotificationChannel = errors.New("invalid notification channel")
+	ErrUnsupportedLanguage        = errors.New("unsupported language")
+)
+
+// Notification is one message to deliver through a channel. To is an email
+// address, a phone number or a push token depending on the channel.
+type Notification struct {
+	Channel     NotificationChannel      `json:"channel"`
+	To          string                   `json:"to"`
+	Subject     string                   `json:"subject,omitempty"`
+	Body        string                   `json:"body"`
+	Attachments []NotificationAttachment `json:"attachments,omitempty"`
+}
+
+// NotificationAttachment is a file sent along an email.
+type NotificationAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
+// NotificationPreference is how a patient wants to be reached. Patients
+// without preferences get email, and SMS when they have a phone number, in
+// the default language.
+type NotificationPreference struct {
+	PatientID uuid.UUID             `json:"patient_id"`
+	Language  string                `json:"language"`
+	Channels  []NotificationChannel `json:"channels"`
+	PushToken string                `json:"push_token,omitempty"`
+	UpdatedAt time.Time             `json:"updated_at,omitempty"`
+}
+
+// AppointmentContact is an appointment along with what's needed to notify its
+// patient about it.
+type AppointmentContact struct {
+	Appointment   Appointment
+	PatientName   string
+	PatientEmail  string
+	PatientPhone  string
+	DoctorName    string
+	DoctorEmail   string
+	Specialty     string
+	Preference    NotificationPreference
+	HasPreference bool
+	// PatientTimezone is the timezone the patient chose, if any.
+	PatientTimezone string
+}
+
+// PatientLocation is where the patient reads the appointment's times: their
+// own timezone, or else the appointment's facility's.
+func (c AppointmentContact) PatientLocation() *time.Location {
+	if c.PatientTimezone != "" {
+		return Location(c.PatientTimezone)
+	}
+	return Location(c.Appointment.Timezone)
+}
+
+// AppointmentReminder records a reminder sent, or attempted, through one
+// channel. Each appointment gets at most one per offset and channel.
+type AppointmentReminder struct {
+	ID            uuid.UUID           `json:"id"`
+	AppointmentID uuid.UUID           `json:"appointment_id"`
+	OffsetMinutes int                 `json:"offset_minutes"`
+	Channel       NotificationChannel `json:"channel"`
+	Recipient     string              `json:"recipient"`
+	Error         string              `json:"error,omitempty"`
+	SentAt        time.Time           `json:"sent_at"`
+}
+
+// NotificationSender delivers notifications through one channel.
+type NotificationSender interface {
+	Channel() NotificationChannel
+	Send(c context.Context, notification Notification) error
+}
+
+type ReminderRepository interface {
+	FetchDue(c context.Context, from time.Time, to time.Time, offsetMinutes int) ([]AppointmentContact, error)
+	FetchContact(c context.Context, appointmentID uuid.UUID) (AppointmentContact, error)
+	FetchPatientContact(c context.Context, patientID uuid.UUID, doctorID uuid.UUID) (AppointmentContact, error)
+	Record(c context.Context, reminder *AppointmentReminder) (bool, error)
+	FetchPreference(c context.Context, patientID uuid.UUID) (NotificationPreference, error)
+	UpsertPreference(c context.Context, preference *NotificationPreference) error
+}
+
+// AppointmentNotifier tells patients and doctors about changes to their
+// appointments.
+type AppointmentNotifier interface {
+	// Confirmed emails the patient a confirmation with the appointment as an
+	// .ics attachment.
+	Confirmed(c context.Context, appointment Appointment) error
+	// Rescheduled tells the party that didn't move the appointment about its
+	// new time: the doctor when the patient moved it, the patient otherwise.
+	Rescheduled(c context.Context, appointment Appointment, reschedule AppointmentReschedule) error
+	// Canceled tells the patient the clinic canceled the appointment, with its
+	// cancel reason.
+	Canceled(c context.Context, appointment Appointment) error
+	// Reassigned tells the patient the appointment is now with another doctor.
+	Reassigned(c context.Context, appointment Appointment, reason string) error
+	// WaitlistOffered tells the patient a slot is held for them, and until
+	// when they can accept or decline it.
+	WaitlistOffered(c context.Context, offer WaitlistOffer) error
+}
+
+type ReminderUsecase interface {
+	// SendDue sends the reminders due at now and returns how many went out.
+	SendDue(c context.Context, now time.Time) (int, error)
+	// Preview returns the appointment a confirm or cancel link is for
+	// without applying it.
+	Preview(c context.Context, token string, action string) (Appointment, error)
+	// Respond applies the action of a confirm or cancel link.
+	Respond(c context.Context, token string, action string) (Appointment, error)
+	// PageLanguage returns the language to show a patient the pages of
+	// reminder links in: the one their notifications use.
+	PageLanguage(c context.Context, patientID uuid.UUID) string
+	FetchPreference(c context.Context, patientID uuid.UUID) (NotificationPreference, error)
+	UpdatePreference(c context.Context, preference *NotificationPreference) error
+}
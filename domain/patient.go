@@ -2,11 +2,14 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrPatientNotFound = errors.New("patient not found")
+
 type Patient struct {
 	ID uuid.UUID `json:"patient_id"`
 	UserId uuid.UUID `json:"user_id"`
@@ -21,6 +24,7 @@ type PatientRepository interface {
 	Create(c context.Context, patient *Patient) error
 	Fetch(c context.Context) ([]Patient, error)
 	FetchByID(c context.Context, id uuid.UUID) (Patient, error)
+	FetchByUserID(c context.Context, userID uuid.UUID) (Patient, error)
 	FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]Patient, error) 
 	Update(c context.Context, patient *Patient) error
 	Delete(c context.Context, id uuid.UUID) error
@@ -30,6 +34,7 @@ type PatientUsecase interface {
 	Create(c context.Context, patient *Patient) error
 	Fetch(c context.Context) ([]Patient, error)
 	FetchByID(c context.Context, id uuid.UUID) (Patient, error)
+	FetchByUserID(c context.Context, userID uuid.UUID) (Patient, error)
 	FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]Patient, error) 
 	Update(c context.Context, patient *Patient) error
 	Delete(c context.Context, id uuid.UUID) error
@@ -2,18 +2,27 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrPrescriptionNotFound = errors.New("prescription not found")
+
 type Prescription struct {
-	ID                uuid.UUID `json:"prescription_id"`
-	PatientID         uuid.UUID `json:"patient_id"`
-	DoctorID          uuid.UUID `json:"doctor_id"`
-	MedicalRecordID   uuid.UUID `json:"medical_record_id"`
-	MedicationDetails string    `json:"medication_details"`
-	CreatedAt         time.Time `json:"created_at,omitempty"`
+	ID              uuid.UUID `json:"prescription_id"`
+	PatientID       uuid.UUID `json:"patient_id"`
+	DoctorID        uuid.UUID `json:"doctor_id"`
+	MedicalRecordID uuid.UUID `json:"medical_record_id"`
+	// EncounterID is the visit the prescription was made in.
+	EncounterID       *uuid.UUID `json:"encounter_id,omitempty"`
+	MedicationDetails string     `json:"medication_details"`
+	CreatedAt         time.Time  `json:"created_at,omitempty"`
+	// AllergyOverrideReason is why the doctor prescribes despite the allergy
+	// warnings, which are kept with the prescription as they were matched.
+	AllergyOverrideReason string           `json:"allergy_override_reason,omitempty"`
+	AllergyWarnings       []AllergyWarning `json:"allergy_warnings,omitempty"`
 }
 
 type PrescriptionRepository interface {
@@ -22,6 +31,7 @@ type PrescriptionRepository interface {
 	FetchByID(c context.Context, id uuid.UUID) (*Prescription, error)
 	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Prescription, error)
 	FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]Prescription, error)
+	FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]Prescription, error)
 	Update(c context.Context, prescription *Prescription) error
 	Delete(c context.Context, id uuid.UUID) error
 }
@@ -32,6 +42,7 @@ type PrescriptionUsecase interface {
 	FetchByID(c context.Context, id uuid.UUID) (*Prescription, error)
 	FetchByPatientID(c context.Context, patientID uuid.UUID) ([]Prescription, error)
 	FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]Prescription, error)
+	FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]Prescription, error)
 	Update(c context.Context, prescription *Prescription) error
 	Delete(c context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ResourceKind string
+
+const (
+	RoomResource      ResourceKind = "room"
+	EquipmentResource ResourceKind = "equipment"
+)
+
+var (
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrInvalidResource  = errors.New("invalid resource")
+)
+
+// Resource is a room or piece of equipment appointments can be booked in or
+// with. Category groups interchangeable resources, e.g. "exam_room", and is
+// what appointment types ask for. Deleted resources are only deactivated, so
+// past bookings keep pointing at them.
+type Resource struct {
+	ID        uuid.UUID    `json:"resource_id"`
+	Name      string       `json:"name" binding:"required"`
+	Kind      ResourceKind `json:"kind" binding:"required"`
+	Category  string       `json:"category" binding:"required"`
+	Active    bool         `json:"active"`
+	CreatedAt time.Time    `json:"created_at,omitempty"`
+	UpdatedAt time.Time    `json:"updated_at,omitempty"`
+}
+
+// AppointmentResource is a resource reserved for an appointment.
+type AppointmentResource struct {
+	ResourceID uuid.UUID    `json:"resource_id"`
+	Name       string       `json:"name"`
+	Kind       ResourceKind `json:"kind"`
+	Category   string       `json:"category"`
+}
+
+type ResourceRepository interface {
+	Create(c context.Context, resource *Resource) error
+	Fetch(c context.Context) ([]Resource, error)
+	FetchByID(c context.Context, id uuid.UUID) (Resource, error)
+	Update(c context.Context, resource *Resource) error
+	// Deactivate takes the resource out of new bookings, reporting false when
+	// it doesn't exist.
+	Deactivate(c context.Context, id uuid.UUID) (bool, error)
+}
+
+type ResourceUsecase interface {
+	Create(c context.Context, resource *Resource) error
+	Fetch(c context.Context) ([]Resource, error)
+	FetchByID(c context.Context, id uuid.UUID) (Resource, error)
+	Update(c context.Context, resource *Resource) error
+	Delete(c context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AlertStatus string
+
+const (
+	AlertStatusOpen         AlertStatus = "open"
+	AlertStatusAcknowledged AlertStatus = "acknowledged"
+	AlertStatusResolved     AlertStatus = "resolved"
+)
+
+type AlertSeverity string
+
+const (
+	AlertSeverityMedium AlertSeverity = "medium"
+	AlertSeverityHigh   AlertSeverity = "high"
+)
+
+const (
+	AlertRuleBulkPatientRead = "bulk_patient_read"
+	AlertRuleNewIPOffHours   = "new_ip_off_hours"
+	AlertRuleAccessDenied    = "repeated_access_denied"
+)
+
+const ResourceSecurityAlert = "security_alert"
+
+// ErrInvalidAlertTransition is returned when an alert is acknowledged or
+// resolved from a status that doesn't allow it.
+var ErrInvalidAlertTransition = errors.New("alert can't be moved to the requested status")
+
+// SecurityAlert is raised by the anomaly detector when a user's activity in
+// the audit trail crosses one of its rules. Alerts go from open to
+// acknowledged to resolved; an open alert can also be resolved directly.
+type SecurityAlert struct {
+	ID             uuid.UUID     `json:"alert_id"`
+	UserID         uuid.UUID     `json:"user_id"`
+	UserRole       UserRole      `json:"user_role,omitempty"`
+	Rule           string        `json:"rule"`
+	Severity       AlertSeverity `json:"severity"`
+	Description    string        `json:"description"`
+	EventCount     int           `json:"event_count"`
+	IPAddress      string        `json:"ip_address,omitempty"`
+	WindowStart    time.Time     `json:"window_start"`
+	WindowEnd      time.Time     `json:"window_end"`
+	Status         AlertStatus   `json:"status"`
+	AcknowledgedBy *uuid.UUID    `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty"`
+	ResolvedBy     *uuid.UUID    `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty"`
+	Resolution     string        `json:"resolution,omitempty"`
+	CreatedAt      time.Time     `json:"created_at,omitempty"`
+}
+
+type ResolveAlertRequest struct {
+	Resolution string `json:"resolution" binding:"required"`
+}
+
+type SecurityAlertRepository interface {
+	Create(c context.Context, alert *SecurityAlert) error
+	Fetch(c context.Context, status AlertStatus) ([]SecurityAlert, error)
+	FetchByID(c context.Context, id uuid.UUID) (*SecurityAlert, error)
+	Acknowledge(c context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+	Resolve(c context.Context, id uuid.UUID, userID uuid.UUID, resolution string) (bool, error)
+}
+
+type SecurityAlertUsecase interface {
+	Create(c context.Context, alert *SecurityAlert) error
+	Fetch(c context.Context, status AlertStatus) ([]SecurityAlert, error)
+	FetchByID(c context.Context, id uuid.UUID) (*SecurityAlert, error)
+	Acknowledge(c context.Context, id uuid.UUID, userID uuid.UUID) error
+	Resolve(c context.Context, id uuid.UUID, userID uuid.UUID, resolution string) error
+}
@@ -21,6 +21,7 @@ type User struct {
 	Email 	  string    `json:"email"`
 	Password  string    `json:"password"`
 	Role      UserRole  `json:"role"`
+	Timezone  string    `json:"timezone,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
@@ -30,4 +31,16 @@ type UserRepository interface {
 	Fetch(c context.Context) ([]User, error)
 	GetByEmail(c context.Context, email string) (User, error)
 	GetByID(c context.Context, id uuid.UUID) (User, error)
+	UpdateTimezone(c context.Context, id uuid.UUID, timezone string) error
+}
+
+type TimezoneRequest struct {
+	Timezone string `json:"timezone"`
+}
+
+// TimezoneUsecase manages the timezone a user reads times in. Users without
+// one get their appointments' facility time.
+type TimezoneUsecase interface {
+	Fetch(c context.Context, userID uuid.UUID) (string, error)
+	Update(c context.Context, userID uuid.UUID, timezone string) error
 }
\ No newline at end of file
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+type WaitingRoomEventType string
+
+const (
+	AppointmentCreatedEvent   WaitingRoomEventType = "appointment.created"
+	AppointmentUpdatedEvent   WaitingRoomEventType = "appointment.updated"
+	AppointmentCheckedInEvent WaitingRoomEventType = "appointment.checked_in"
+	AppointmentCalledEvent    WaitingRoomEventType = "appointment.called"
+)
+
+// Views of the waiting room stream. The board view is meant for display
+// screens in the waiting room and leaves out anything identifying the patient
+// besides their initials and ticket number.
+const (
+	StaffView = "staff"
+	BoardView = "board"
+)
+
+var (
+	ErrInvalidWaitingRoomView = errors.New("view must be staff or board")
+	ErrNotCheckedIn           = errors.New("only checked in patients can be called")
+)
+
+// WaitingRoomEvent is a change to an appointment pushed to the waiting room
+// stream. IDs increase with every event so clients can resume with
+// Last-Event-ID.
+type WaitingRoomEvent struct {
+	ID              int64                `json:"-"`
+	Type            WaitingRoomEventType `json:"type"`
+	AppointmentID   uuid.UUID            `json:"appointment_id"`
+	PatientID       uuid.UUID            `json:"patient_id"`
+	PatientName     string               `json:"patient_name"`
+	DoctorID        uuid.UUID            `json:"doctor_id"`
+	DoctorName      string               `json:"doctor_name"`
+	Status          AppointmentStatus    `json:"status"`
+	AppointmentDate time.Time            `json:"appointment_date"`
+	TicketNumber    *int                 `json:"ticket_number,omitempty"`
+	Room            string               `json:"room,omitempty"`
+	At              time.Time            `json:"at"`
+}
+
+// BoardEvent is a WaitingRoomEvent as shown on waiting room displays.
+type BoardEvent struct {
+	Type            WaitingRoomEventType `json:"type"`
+	PatientInitials string               `json:"patient_initials"`
+	TicketNumber    *int                 `json:"ticket_number,omitempty"`
+	DoctorName      string               `json:"doctor_name"`
+	Status          AppointmentStatus    `json:"status"`
+	Room            string               `json:"room,omitempty"`
+	At              time.Time            `json:"at"`
+}
+
+func (e WaitingRoomEvent) Board() BoardEvent {
+	return BoardEvent{
+		Type:            e.Type,
+		PatientInitials: Initials(e.PatientName),
+		TicketNumber:    e.TicketNumber,
+		DoctorName:      e.DoctorName,
+		Status:          e.Status,
+		Room:            e.Room,
+		At:              e.At,
+	}
+}
+
+// Initials returns the first letter of the first and last words of name,
+// e.g. "A.S." for "Ana Maria Silva".
+func Initials(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return ""
+	}
+	if len(words) > 1 {
+		words = []string{words[0], words[len(words)-1]}
+	}
+
+	var initials strings.Builder
+	for _, word := range words {
+		for _, r := range word {
+			initials.WriteRune(unicode.ToUpper(r))
+			initials.WriteByte('.')
+			break
+		}
+	}
+	return initials.String()
+}
+
+// WaitingRoomPublisher tells the waiting room stream about appointment
+// changes.
+type WaitingRoomPublisher interface {
+	Publish(c context.Context, eventType WaitingRoomEventType, appointment Appointment) error
+}
+
+type WaitingRoomUsecase interface {
+	WaitingRoomPublisher
+	// Call asks the checked in patient of the appointment to come in, to room
+	// or, when it's empty, to the room reserved for the appointment.
+	Call(c context.Context, appointmentID uuid.UUID, room string) (WaitingRoomEvent, error)
+	// Subscribe returns the kept events after lastEventID followed by a
+	// channel of new ones. The channel is closed when the subscriber falls too
+	// far behind; unsubscribe must be called once the caller is done.
+	Subscribe(lastEventID int64) (replay []WaitingRoomEvent, events <-chan WaitingRoomEvent, unsubscribe func())
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting  WaitlistStatus = "waiting"
+	WaitlistOffered  WaitlistStatus = "offered"
+	WaitlistBooked   WaitlistStatus = "booked"
+	WaitlistCanceled WaitlistStatus = "canceled"
+)
+
+type OfferStatus string
+
+const (
+	OfferPending  OfferStatus = "pending"
+	OfferAccepted OfferStatus = "accepted"
+	OfferDeclined OfferStatus = "declined"
+	OfferExpired  OfferStatus = "expired"
+)
+
+// DefaultWaitlistHold is how long a patient has to accept an offered slot.
+const DefaultWaitlistHold = 30 * time.Minute
+
+var (
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrInvalidWaitlistEntry  = errors.New("invalid waitlist entry")
+	ErrOfferNotFound         = errors.New("waitlist offer not found")
+	ErrOfferClosed           = errors.New("the offer is no longer pending")
+	ErrOfferExpired          = errors.New("the offer has expired")
+)
+
+// WaitlistEntry is a patient waiting for a slot with a given doctor, or with
+// any doctor of a specialty when DoctorID is unset. PreferredWindows limits the
+// slots offered to those that fit in one of the windows; when empty any slot
+// is offered.
+type WaitlistEntry struct {
+	ID               uuid.UUID      `json:"entry_id"`
+	PatientID        uuid.UUID      `json:"patient_id"`
+	DoctorID         *uuid.UUID     `json:"doctor_id,omitempty"`
+	Specialty        string         `json:"specialty,omitempty"`
+	PreferredWindows []Slot         `json:"preferred_windows"`
+	Notes            string         `json:"notes"`
+	Status           WaitlistStatus `json:"status"`
+	CreatedAt        time.Time      `json:"created_at,omitempty"`
+	UpdatedAt        time.Time      `json:"updated_at,omitempty"`
+}
+
+// Accepts reports whether slot fits in one of the entry's preferred windows.
+func (e WaitlistEntry) Accepts(slot Slot) bool {
+	if len(e.PreferredWindows) == 0 {
+		return true
+	}
+	for _, window := range e.PreferredWindows {
+		if !slot.Start.Before(window.Start) && !slot.End.After(window.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitlistOffer holds a freed slot for a waitlisted patient until ExpiresAt.
+// While it is pending nobody else can book the slot.
+type WaitlistOffer struct {
+	ID                  uuid.UUID   `json:"offer_id"`
+	EntryID             uuid.UUID   `json:"entry_id"`
+	PatientID           uuid.UUID   `json:"patient_id"`
+	DoctorID            uuid.UUID   `json:"doctor_id"`
+	SlotStart           time.Time   `json:"slot_start"`
+	SlotEnd             time.Time   `json:"slot_end"`
+	SourceAppointmentID *uuid.UUID  `json:"source_appointment_id,omitempty"`
+	AppointmentID       *uuid.UUID  `json:"appointment_id,omitempty"`
+	Status              OfferStatus `json:"status"`
+	ExpiresAt           time.Time   `json:"expires_at"`
+	RespondedAt         *time.Time  `json:"responded_at,omitempty"`
+	CreatedAt           time.Time   `json:"created_at,omitempty"`
+}
+
+type WaitlistRepository interface {
+	CreateEntry(c context.Context, entry *WaitlistEntry) error
+	FetchEntries(c context.Context, patientID uuid.UUID, status WaitlistStatus) ([]WaitlistEntry, error)
+	FetchEntryByID(c context.Context, id uuid.UUID) (WaitlistEntry, error)
+	FetchWaiting(c context.Context, doctorID uuid.UUID, specialty string) ([]WaitlistEntry, error)
+	CancelEntry(c context.Context, id uuid.UUID) (bool, error)
+	CreateOffer(c context.Context, offer *WaitlistOffer) (bool, error)
+	FetchOffers(c context.Context, patientID uuid.UUID, status OfferStatus) ([]WaitlistOffer, error)
+	FetchOfferByID(c context.Context, id uuid.UUID) (WaitlistOffer, error)
+	FetchPendingOffers(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]WaitlistOffer, error)
+	FetchPendingOfferByEntryID(c context.Context, entryID uuid.UUID) (WaitlistOffer, error)
+	FetchExpiredOffers(c context.Context, now time.Time) ([]WaitlistOffer, error)
+	FetchOfferedEntryIDs(c context.Context, doctorID uuid.UUID, slotStart time.Time) ([]uuid.UUID, error)
+	CloseOffer(c context.Context, offer *WaitlistOffer, status OfferStatus) (bool, error)
+	AcceptOffer(c context.Context, offer *WaitlistOffer, appointment *Appointment, change *AppointmentStatusChange) (bool, error)
+}
+
+type WaitlistUsecase interface {
+	Join(c context.Context, entry *WaitlistEntry) error
+	FetchEntries(c context.Context, patientID uuid.UUID, status WaitlistStatus) ([]WaitlistEntry, error)
+	FetchEntryByID(c context.Context, id uuid.UUID) (WaitlistEntry, error)
+	Leave(c context.Context, id uuid.UUID) error
+	FetchOffers(c context.Context, patientID uuid.UUID, status OfferStatus) ([]WaitlistOffer, error)
+	FetchOfferByID(c context.Context, id uuid.UUID) (WaitlistOffer, error)
+	Accept(c context.Context, offerID uuid.UUID, actorID uuid.UUID, actorRole UserRole) (Appointment, error)
+	Decline(c context.Context, offerID uuid.UUID) error
+	OfferSlot(c context.Context, canceled Appointment) error
+	ExpireOffers(c context.Context) (int, error)
+	CheckHold(c context.Context, appointment Appointment) error
+}
@@ -0,0 +1,101 @@
+// Package allergycheck matches the substances patients are allergic to
+// against the free text of prescriptions, in Portuguese or English, ignoring
+// case and accents. A substance also matches the drugs of its class, so an
+// allergy to penicillin flags amoxicillin.
+package allergycheck
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// classes groups the names of drugs that cross-react, along with the names of
+// the class itself, already folded.
+var classes = [][]string{
+	{
+		"penicilina", "penicillin", "betalactamico", "beta-lactam",
+		"amoxicilina", "amoxicillin", "ampicilina", "ampicillin",
+		"benzilpenicilina", "benzylpenicillin", "benzetacil",
+		"oxacilina", "oxacillin", "dicloxacilina", "dicloxacillin",
+		"piperacilina", "piperacillin",
+	},
+	{
+		"cefalosporina", "cephalosporin",
+		"cefalexina", "cephalexin", "cefazolina", "cefazolin",
+		"cefuroxima", "cefuroxime", "ceftriaxona", "ceftriaxone",
+		"cefepima", "cefepime",
+	},
+	{
+		"sulfa", "sulfonamida", "sulfonamide",
+		"sulfametoxazol", "sulfamethoxazole", "sulfadiazina", "sulfadiazine",
+	},
+	{
+		"aine", "nsaid", "anti-inflamatorio nao esteroidal",
+		"ibuprofeno", "ibuprofen", "diclofenaco", "diclofenac",
+		"naproxeno", "naproxen", "cetoprofeno", "ketoprofen",
+		"nimesulida", "nimesulide", "aspirina", "aspirin", "aas",
+		"acido acetilsalicilico", "acetylsalicylic acid",
+	},
+	{
+		"dipirona", "metamizol", "metamizole",
+	},
+}
+
+// Match returns the term of medication matching substance, such as
+// "amoxicilina" for an allergy to "Penicilina", and whether there is one.
+func Match(substance string, medication string) (string, bool) {
+	text := " " + strings.Join(words(medication), " ") + " "
+	for _, term := range terms(substance) {
+		if strings.Contains(text, " "+term+" ") || strings.Contains(text, " "+term+"s ") {
+			return term, true
+		}
+	}
+	return "", false
+}
+
+// terms lists the names to look for: the substance itself and, when it's a
+// drug class or one of its members, every name of the class.
+func terms(substance string) []string {
+	name := strings.Join(words(substance), " ")
+	if name == "" {
+		return nil
+	}
+
+	found := []string{name}
+	for _, class := range classes {
+		if inClass(name, class) {
+			found = append(found, class...)
+		}
+	}
+	return found
+}
+
+func inClass(name string, class []string) bool {
+	for _, member := range class {
+		if name == member || name == member+"s" || strings.HasPrefix(name, member+" ") || strings.HasSuffix(name, " "+member) {
+			return true
+		}
+	}
+	return false
+}
+
+// words folds s and splits it into words, keeping hyphens within them.
+func words(s string) []string {
+	return strings.FieldsFunc(fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+}
+
+// fold lower-cases s and strips its accents.
+func fold(s string) string {
+	accents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(accents, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
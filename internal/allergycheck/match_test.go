@@ -0,0 +1,47 @@
+package allergycheck
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		substance  string
+		medication string
+		matched    string
+		ok         bool
+	}{
+		{name: "same name", substance: "Dipirona", medication: "Dipirona 500mg 6/6h", matched: "dipirona", ok: true},
+		{name: "case", substance: "IBUPROFENO", medication: "ibuprofeno 400mg", matched: "ibuprofeno", ok: true},
+		{name: "accented medication", substance: "Dipirona", medication: "Dipirona sódica 1g", matched: "dipirona", ok: true},
+		{name: "accented substance", substance: "Ácido acetilsalicílico", medication: "acido acetilsalicilico 100mg", matched: "acido acetilsalicilico", ok: true},
+		{name: "accented class member", substance: "Ácido acetilsalicílico", medication: "AAS 100mg", matched: "aas", ok: true},
+		{name: "plural substance", substance: "Penicilinas", medication: "Amoxicilina 500mg", matched: "amoxicilina", ok: true},
+		{name: "plural medication", substance: "Cefalosporina", medication: "evitar cefalosporinas", matched: "cefalosporina", ok: true},
+		{name: "penicillin class", substance: "Penicilina", medication: "Amoxicilina 500mg 8/8h por 7 dias", matched: "amoxicilina", ok: true},
+		{name: "english class name", substance: "penicillin", medication: "Ampicillin 1g IV", matched: "ampicillin", ok: true},
+		{name: "cephalosporin class", substance: "Cefalosporinas", medication: "Ceftriaxona 1g IM", matched: "ceftriaxona", ok: true},
+		{name: "sulfonamide class", substance: "Sulfa", medication: "Sulfametoxazol + trimetoprima", matched: "sulfametoxazol", ok: true},
+		{name: "nsaid class", substance: "AINE", medication: "Ibuprofeno 600mg", matched: "ibuprofeno", ok: true},
+		{name: "class from a member", substance: "Diclofenaco", medication: "Naproxeno 250mg", matched: "naproxeno", ok: true},
+		{name: "qualified substance", substance: "Alergia a penicilina", medication: "Amoxicilina 875mg", matched: "amoxicilina", ok: true},
+		{name: "dipyrone synonym", substance: "Metamizol", medication: "Dipirona gotas", matched: "dipirona", ok: true},
+		{name: "substance outside any class", substance: "Látex", medication: "luvas de latex", matched: "latex", ok: true},
+
+		{name: "other class", substance: "Penicilina", medication: "Ceftriaxona 1g IM", ok: false},
+		{name: "unrelated drug", substance: "Dipirona", medication: "Paracetamol 750mg", ok: false},
+		{name: "part of a word", substance: "Sulfa", medication: "Sulfato ferroso 40mg", ok: false},
+		{name: "class member not named", substance: "Amoxicilina", medication: "Azitromicina 500mg", ok: false},
+		{name: "empty substance", substance: "", medication: "Amoxicilina 500mg", ok: false},
+		{name: "punctuation only substance", substance: " - ", medication: "Amoxicilina 500mg", ok: false},
+		{name: "empty medication", substance: "Penicilina", medication: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, ok := Match(tt.substance, tt.medication)
+			if matched != tt.matched || ok != tt.ok {
+				t.Errorf("Match(%q, %q) = %q, %v; want %q, %v", tt.substance, tt.medication, matched, ok, tt.matched, tt.ok)
+			}
+		})
+	}
+}
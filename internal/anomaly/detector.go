@@ -0,0 +1,364 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/auditservice"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config holds the detector rules. Thresholds are per role; a role with no
+// threshold is never alerted on for that rule.
+type Config struct {
+	Window                 time.Duration
+	BulkReadThresholds     map[domain.UserRole]int
+	AccessDeniedThresholds map[domain.UserRole]int
+	OffHoursStart          int
+	OffHoursEnd            int
+	Location               *time.Location
+}
+
+const (
+	defaultWindow        = 10 * time.Minute
+	defaultOffHoursStart = 22
+	defaultOffHoursEnd   = 6
+	maxPendingAlerts     = 1000
+)
+
+// DefaultBulkReadThresholds is the number of distinct patients a user may
+// read within the window before an alert is raised.
+var DefaultBulkReadThresholds = map[domain.UserRole]int{
+	domain.AdminRole:   500,
+	domain.DoctorRole:  200,
+	domain.PatientRole: 5,
+}
+
+// DefaultAccessDeniedThresholds is the number of 403 responses a user may
+// get within the window before an alert is raised.
+var DefaultAccessDeniedThresholds = map[domain.UserRole]int{
+	domain.AdminRole:   20,
+	domain.DoctorRole:  20,
+	domain.PatientRole: 10,
+}
+
+type patientRead struct {
+	at        time.Time
+	patientID uuid.UUID
+}
+
+type readWindow struct {
+	events []patientRead
+	counts map[uuid.UUID]int
+}
+
+// detector evaluates audit events in sliding windows per user. It is plugged
+// into the audit service as a sink, so it gets its own queue and a slow alert
+// store never holds up requests.
+type detector struct {
+	alertUsecase    domain.SecurityAlertUsecase
+	auditLogUsecase domain.AuditLogUsecase
+	config          Config
+
+	reads     map[uuid.UUID]*readWindow
+	denials   map[uuid.UUID][]time.Time
+	loginIPs  map[uuid.UUID]map[string]bool
+	cooldown  map[string]time.Time
+	pending   []domain.SecurityAlert
+	lastSweep time.Time
+}
+
+func NewDetector(alertUsecase domain.SecurityAlertUsecase, auditLogUsecase domain.AuditLogUsecase, config Config) auditservice.Sink {
+	if config.Window <= 0 {
+		config.Window = defaultWindow
+	}
+	config.BulkReadThresholds = withDefaults(config.BulkReadThresholds, DefaultBulkReadThresholds)
+	config.AccessDeniedThresholds = withDefaults(config.AccessDeniedThresholds, DefaultAccessDeniedThresholds)
+	if config.OffHoursStart == config.OffHoursEnd {
+		config.OffHoursStart = defaultOffHoursStart
+		config.OffHoursEnd = defaultOffHoursEnd
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+
+	return &detector{
+		alertUsecase:    alertUsecase,
+		auditLogUsecase: auditLogUsecase,
+		config:          config,
+		reads:           map[uuid.UUID]*readWindow{},
+		denials:         map[uuid.UUID][]time.Time{},
+		loginIPs:        map[uuid.UUID]map[string]bool{},
+		cooldown:        map[string]time.Time{},
+	}
+}
+
+// ParseThresholds reads a role=count list such as "admin=500,doctor=200".
+func ParseThresholds(spec string) (map[domain.UserRole]int, error) {
+	thresholds := map[domain.UserRole]int{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		role, count, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid threshold %q, expected role=count", part)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid threshold for %s: %q", role, count)
+		}
+		thresholds[domain.UserRole(strings.TrimSpace(role))] = n
+	}
+	return thresholds, nil
+}
+
+// ParseOffHours reads an hour range such as "22-6", which wraps midnight.
+func ParseOffHours(spec string) (int, int, error) {
+	if strings.TrimSpace(spec) == "" {
+		return defaultOffHoursStart, defaultOffHoursEnd, nil
+	}
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid off hours %q, expected start-end", spec)
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil || start < 0 || start > 23 {
+		return 0, 0, fmt.Errorf("invalid off hours start %q", from)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(to))
+	if err != nil || end < 0 || end > 23 {
+		return 0, 0, fmt.Errorf("invalid off hours end %q", to)
+	}
+	return start, end, nil
+}
+
+func (d *detector) Name() string {
+	return "anomaly"
+}
+
+// Write evaluates the entries and stores any alerts they raise. Alerts that
+// can't be stored are kept and retried on the next batch rather than failing
+// the batch, since re-evaluating the same entries would double count them.
+func (d *detector) Write(ctx context.Context, entries []domain.AuditLog) error {
+	for _, entry := range entries {
+		d.evaluate(ctx, entry)
+	}
+
+	d.sweep(time.Now())
+
+	for len(d.pending) > 0 {
+		if err := d.alertUsecase.Create(ctx, &d.pending[0]); err != nil {
+			log.Printf("[ERROR] Anomaly: failed to store %d security alerts, will retry: %v\n", len(d.pending), err)
+			break
+		}
+		d.pending = d.pending[1:]
+	}
+
+	return nil
+}
+
+func (d *detector) Close() error {
+	if len(d.pending) > 0 {
+		log.Printf("[ERROR] Anomaly: discarding %d unsaved security alerts on shutdown\n", len(d.pending))
+	}
+	return nil
+}
+
+func (d *detector) evaluate(ctx context.Context, entry domain.AuditLog) {
+	if entry.UserID == uuid.Nil {
+		return
+	}
+
+	switch {
+	case entry.Action == "USER_LOGIN_SUCCESS":
+		d.checkLogin(ctx, entry)
+	case entry.Action == "ACCESS_DENIED":
+		d.checkAccessDenied(entry)
+	case entry.PatientID != uuid.Nil && isRead(entry.Action):
+		d.checkBulkRead(entry)
+	}
+}
+
+func (d *detector) checkBulkRead(entry domain.AuditLog) {
+	threshold, ok := d.config.BulkReadThresholds[entry.UserRole]
+	if !ok {
+		return
+	}
+
+	w := d.reads[entry.UserID]
+	if w == nil {
+		w = &readWindow{counts: map[uuid.UUID]int{}}
+		d.reads[entry.UserID] = w
+	}
+
+	w.events = append(w.events, patientRead{at: entry.CreatedAt, patientID: entry.PatientID})
+	w.counts[entry.PatientID]++
+	d.expireReads(w, entry.CreatedAt)
+
+	if len(w.counts) < threshold {
+		return
+	}
+
+	d.raise(entry, domain.AlertRuleBulkPatientRead, domain.AlertSeverityHigh, len(w.counts), w.events[0].at,
+		fmt.Sprintf("Read records of %d distinct patients within %v (threshold %d for %s)", len(w.counts), d.config.Window, threshold, entry.UserRole))
+}
+
+func (d *detector) checkAccessDenied(entry domain.AuditLog) {
+	threshold, ok := d.config.AccessDeniedThresholds[entry.UserRole]
+	if !ok {
+		return
+	}
+
+	events := append(d.denials[entry.UserID], entry.CreatedAt)
+	events = expireTimes(events, entry.CreatedAt.Add(-d.config.Window))
+	d.denials[entry.UserID] = events
+
+	if len(events) < threshold {
+		return
+	}
+
+	d.raise(entry, domain.AlertRuleAccessDenied, domain.AlertSeverityMedium, len(events), events[0],
+		fmt.Sprintf("Received %d access denied responses within %v (threshold %d for %s)", len(events), d.config.Window, threshold, entry.UserRole))
+}
+
+// checkLogin alerts on a successful login from an address the user never
+// logged in from before, when it happens during off hours. A user's first
+// login ever isn't alerted on since every address is new to them.
+func (d *detector) checkLogin(ctx context.Context, entry domain.AuditLog) {
+	if entry.IPAddress == "" {
+		return
+	}
+
+	known, ok := d.loginIPs[entry.UserID]
+	if !ok {
+		ips, err := d.auditLogUsecase.FetchLoginIPs(ctx, entry.UserID, entry.CreatedAt)
+		if err != nil {
+			log.Printf("[ERROR] Anomaly: failed to load login history for user %s: %v\n", entry.UserID, err)
+			return
+		}
+		known = map[string]bool{}
+		for _, ip := range ips {
+			known[ip] = true
+		}
+		d.loginIPs[entry.UserID] = known
+	}
+
+	isNew := len(known) > 0 && !known[entry.IPAddress]
+	known[entry.IPAddress] = true
+
+	if !isNew || !d.isOffHours(entry.CreatedAt) {
+		return
+	}
+
+	d.raise(entry, domain.AlertRuleNewIPOffHours, domain.AlertSeverityHigh, 1, entry.CreatedAt,
+		fmt.Sprintf("Logged in from new address %s at %s", entry.IPAddress, entry.CreatedAt.In(d.config.Location).Format("15:04 MST")))
+}
+
+// raise queues an alert unless the same rule already fired for the user
+// within the current window.
+func (d *detector) raise(entry domain.AuditLog, rule string, severity domain.AlertSeverity, count int, windowStart time.Time, description string) {
+	key := entry.UserID.String() + "/" + rule
+	if until, ok := d.cooldown[key]; ok && entry.CreatedAt.Before(until) {
+		return
+	}
+	d.cooldown[key] = entry.CreatedAt.Add(d.config.Window)
+
+	alert := domain.SecurityAlert{
+		UserID:      entry.UserID,
+		UserRole:    entry.UserRole,
+		Rule:        rule,
+		Severity:    severity,
+		Description: description,
+		EventCount:  count,
+		IPAddress:   entry.IPAddress,
+		WindowStart: windowStart,
+		WindowEnd:   entry.CreatedAt,
+	}
+
+	log.Printf("[SECURITY-ALERT] %s user=%s role=%s: %s\n", rule, entry.UserID, entry.UserRole, description)
+
+	if len(d.pending) >= maxPendingAlerts {
+		log.Printf("[ERROR] Anomaly: alert backlog full, dropping %s alert for user %s\n", rule, entry.UserID)
+		return
+	}
+	d.pending = append(d.pending, alert)
+}
+
+func (d *detector) isOffHours(t time.Time) bool {
+	hour := t.In(d.config.Location).Hour()
+	if d.config.OffHoursStart < d.config.OffHoursEnd {
+		return hour >= d.config.OffHoursStart && hour < d.config.OffHoursEnd
+	}
+	return hour >= d.config.OffHoursStart || hour < d.config.OffHoursEnd
+}
+
+func (d *detector) expireReads(w *readWindow, now time.Time) {
+	cutoff := now.Add(-d.config.Window)
+	i := 0
+	for i < len(w.events) && w.events[i].at.Before(cutoff) {
+		id := w.events[i].patientID
+		if w.counts[id]--; w.counts[id] == 0 {
+			delete(w.counts, id)
+		}
+		i++
+	}
+	w.events = w.events[i:]
+}
+
+// sweep drops the windows of users that have gone quiet so memory doesn't
+// grow with every user that ever touched the system.
+func (d *detector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.config.Window {
+		return
+	}
+	d.lastSweep = now
+
+	for userID, w := range d.reads {
+		d.expireReads(w, now)
+		if len(w.events) == 0 {
+			delete(d.reads, userID)
+		}
+	}
+	for userID, events := range d.denials {
+		if events = expireTimes(events, now.Add(-d.config.Window)); len(events) == 0 {
+			delete(d.denials, userID)
+		} else {
+			d.denials[userID] = events
+		}
+	}
+	for key, until := range d.cooldown {
+		if now.After(until) {
+			delete(d.cooldown, key)
+		}
+	}
+}
+
+func expireTimes(events []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(events) && events[i].Before(cutoff) {
+		i++
+	}
+	return events[i:]
+}
+
+func isRead(action string) bool {
+	return strings.Contains(action, "_FETCH") || strings.HasSuffix(action, "_EXPORT")
+}
+
+func withDefaults(thresholds map[domain.UserRole]int, defaults map[domain.UserRole]int) map[domain.UserRole]int {
+	merged := map[domain.UserRole]int{}
+	for role, n := range defaults {
+		merged[role] = n
+	}
+	for role, n := range thresholds {
+		merged[role] = n
+	}
+	return merged
+}
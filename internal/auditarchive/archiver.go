@@ -0,0 +1,420 @@
+package auditarchive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hms-api/domain"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Archiver interface {
+	// Run creates upcoming partitions, archives and drops partitions older than
+	// the hot window and purges entries and archives past their retention.
+	Run(ctx context.Context, now time.Time) error
+	// Start calls Run every interval until ctx is done.
+	Start(ctx context.Context, interval time.Duration)
+	// Restore verifies an archive file and loads it into audit_logs_restored.
+	Restore(ctx context.Context, path string) (int, error)
+}
+
+type Config struct {
+	Dir             string
+	HotMonths       int
+	PartitionsAhead int
+	RetentionDays   map[string]int
+}
+
+const (
+	defaultDir             = "archive/audit"
+	defaultHotMonths       = 3
+	defaultPartitionsAhead = 2
+	restoreBatchSize       = 500
+)
+
+// DefaultRetentionDays keeps clinical trails for the 20 years required for
+// medical records and everything else for five years.
+var DefaultRetentionDays = map[string]int{
+	"authentication":            365,
+	"clinical":                  7300,
+	"patient_data":              1825,
+	domain.DefaultAuditCategory: 1825,
+}
+
+var rxArchiveFile = regexp.MustCompile(`^(audit_logs_(\d{4})_(\d{2}))\.([a-z_]+)\.ndjson\.gz$`)
+
+type archiver struct {
+	repository domain.AuditArchiveRepository
+	config     Config
+}
+
+func NewArchiver(repository domain.AuditArchiveRepository, config Config) Archiver {
+	if config.Dir == "" {
+		config.Dir = defaultDir
+	}
+	if config.HotMonths <= 0 {
+		config.HotMonths = defaultHotMonths
+	}
+	if config.PartitionsAhead <= 0 {
+		config.PartitionsAhead = defaultPartitionsAhead
+	}
+
+	retention := make(map[string]int, len(DefaultRetentionDays))
+	for category, days := range DefaultRetentionDays {
+		retention[category] = days
+	}
+	for category, days := range config.RetentionDays {
+		retention[category] = days
+	}
+	config.RetentionDays = retention
+
+	return &archiver{
+		repository: repository,
+		config:     config,
+	}
+}
+
+// ParseRetention parses a policy such as "authentication=365,clinical=7300".
+// Categories must be one of domain.AuditCategories or the default category.
+func ParseRetention(spec string) (map[string]int, error) {
+	retention := map[string]int{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		category, days, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid retention policy %q, expected category=days", part)
+		}
+		category = strings.TrimSpace(category)
+		if !knownCategory(category) {
+			return nil, fmt.Errorf("unknown audit category %q in retention policy", category)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(days))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid retention days for %s: %q", category, days)
+		}
+		retention[category] = n
+	}
+	return retention, nil
+}
+
+func knownCategory(name string) bool {
+	if name == domain.DefaultAuditCategory {
+		return true
+	}
+	for _, category := range domain.AuditCategories {
+		if category.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *archiver) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.Run(ctx, time.Now().UTC()); err != nil {
+			log.Printf("[ERROR] Audit archive: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *archiver) Run(ctx context.Context, now time.Time) error {
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// A partition that can't be created leaves its rows in the default
+	// partition, where retention still applies, so archival carries on.
+	for i := 0; i <= a.config.PartitionsAhead; i++ {
+		if err := a.repository.CreatePartition(ctx, currentMonth.AddDate(0, i, 0)); err != nil {
+			log.Printf("[ERROR] Audit archive: %v\n", err)
+		}
+	}
+
+	partitions, err := a.repository.FetchPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	hotStart := currentMonth.AddDate(0, -a.config.HotMonths, 0)
+	for _, partition := range partitions {
+		if !partition.To.After(hotStart) {
+			if err := a.archivePartition(ctx, partition); err != nil {
+				return err
+			}
+		}
+	}
+
+	for category, days := range a.config.RetentionDays {
+		deleted, err := a.repository.DeleteByCategoryBefore(ctx, category, now.AddDate(0, 0, -days))
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			log.Printf("Audit archive: purged %d %s entries past retention\n", deleted, category)
+		}
+	}
+
+	return a.purgeArchives(now)
+}
+
+// archivePartition writes one compressed file per category so each can be
+// deleted on its own retention schedule, and only drops the partition once
+// every file has been written and verified.
+func (a *archiver) archivePartition(ctx context.Context, partition domain.AuditPartition) error {
+	if err := os.MkdirAll(a.config.Dir, 0o750); err != nil {
+		return fmt.Errorf("error creating archive directory: %w", err)
+	}
+
+	writers := map[string]*archiveWriter{}
+	defer func() {
+		for _, w := range writers {
+			w.abort()
+		}
+	}()
+
+	err := a.repository.StreamPartition(ctx, partition.Name, func(entry domain.AuditLog) error {
+		category := domain.AuditCategoryFor(entry.Action)
+		w, ok := writers[category]
+		if !ok {
+			var err error
+			w, err = newArchiveWriter(filepath.Join(a.config.Dir, fmt.Sprintf("%s.%s.ndjson.gz", partition.Name, category)))
+			if err != nil {
+				return err
+			}
+			writers[category] = w
+		}
+		return w.write(entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for category, w := range writers {
+		if err := w.commit(); err != nil {
+			return err
+		}
+		if err := Verify(w.path); err != nil {
+			return err
+		}
+		total += w.count
+		delete(writers, category)
+	}
+
+	if err := a.repository.DropPartition(ctx, partition.Name); err != nil {
+		return err
+	}
+
+	log.Printf("Audit archive: archived %d entries from %s to %s\n", total, partition.Name, a.config.Dir)
+	return nil
+}
+
+func (a *archiver) purgeArchives(now time.Time) error {
+	files, err := os.ReadDir(a.config.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error listing archives: %w", err)
+	}
+
+	for _, file := range files {
+		match := rxArchiveFile.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+
+		days, ok := a.config.RetentionDays[match[4]]
+		if !ok {
+			days = a.config.RetentionDays[domain.DefaultAuditCategory]
+		}
+
+		year, _ := strconv.Atoi(match[2])
+		month, _ := strconv.Atoi(match[3])
+		partitionEnd := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+		if partitionEnd.AddDate(0, 0, days).After(now) {
+			continue
+		}
+
+		path := filepath.Join(a.config.Dir, file.Name())
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("error removing expired archive %s: %w", path, err)
+		}
+		_ = os.Remove(path + ".sha256")
+		log.Printf("Audit archive: removed %s past its retention\n", file.Name())
+	}
+
+	return nil
+}
+
+func (a *archiver) Restore(ctx context.Context, path string) (int, error) {
+	if err := Verify(path); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("error opening archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, fmt.Errorf("error reading archive: %w", err)
+	}
+	defer gz.Close()
+
+	source := filepath.Base(path)
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	restored := 0
+	batch := make([]domain.AuditLog, 0, restoreBatchSize)
+	for scanner.Scan() {
+		var entry domain.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return restored, fmt.Errorf("error decoding archive line %d: %w", restored+len(batch)+1, err)
+		}
+		batch = append(batch, entry)
+
+		if len(batch) == restoreBatchSize {
+			if err := a.repository.CreateRestored(ctx, batch, source); err != nil {
+				return restored, err
+			}
+			restored += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return restored, fmt.Errorf("error reading archive: %w", err)
+	}
+
+	if len(batch) > 0 {
+		if err := a.repository.CreateRestored(ctx, batch, source); err != nil {
+			return restored, err
+		}
+		restored += len(batch)
+	}
+
+	return restored, nil
+}
+
+// Verify checks an archive against its sha256sum-compatible sidecar file.
+func Verify(path string) error {
+	sidecar, err := os.ReadFile(path + ".sha256")
+	if err != nil {
+		return fmt.Errorf("error reading checksum for %s: %w", path, err)
+	}
+	fields := strings.Fields(string(sidecar))
+	if len(fields) == 0 {
+		return fmt.Errorf("empty checksum file for %s", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %w", err)
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return fmt.Errorf("error hashing archive: %w", err)
+	}
+
+	if sum := hex.EncodeToString(digest.Sum(nil)); sum != fields[0] {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, fields[0], sum)
+	}
+
+	return nil
+}
+
+// archiveWriter streams NDJSON through gzip into a temporary file and hashes
+// the compressed bytes as they are written.
+type archiveWriter struct {
+	path   string
+	tmp    *os.File
+	digest hash.Hash
+	gz     *gzip.Writer
+	enc    *json.Encoder
+	count  int
+}
+
+func newArchiveWriter(path string) (*archiveWriter, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("error creating archive file: %w", err)
+	}
+
+	digest := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(tmp, digest))
+
+	return &archiveWriter{
+		path:   path,
+		tmp:    tmp,
+		digest: digest,
+		gz:     gz,
+		enc:    json.NewEncoder(gz),
+	}, nil
+}
+
+func (w *archiveWriter) write(entry domain.AuditLog) error {
+	if err := w.enc.Encode(entry); err != nil {
+		return fmt.Errorf("error writing archive entry: %w", err)
+	}
+	w.count++
+	return nil
+}
+
+func (w *archiveWriter) commit() error {
+	if err := w.gz.Close(); err != nil {
+		return fmt.Errorf("error compressing archive: %w", err)
+	}
+	if err := w.tmp.Sync(); err != nil {
+		return fmt.Errorf("error syncing archive: %w", err)
+	}
+	if err := w.tmp.Close(); err != nil {
+		return fmt.Errorf("error closing archive: %w", err)
+	}
+	if err := os.Rename(w.tmp.Name(), w.path); err != nil {
+		return fmt.Errorf("error finalizing archive: %w", err)
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", hex.EncodeToString(w.digest.Sum(nil)), filepath.Base(w.path))
+	if err := os.WriteFile(w.path+".sha256", []byte(checksum), 0o640); err != nil {
+		return fmt.Errorf("error writing archive checksum: %w", err)
+	}
+
+	w.tmp = nil
+	return nil
+}
+
+func (w *archiveWriter) abort() {
+	if w.tmp == nil {
+		return
+	}
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+}
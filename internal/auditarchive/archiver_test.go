@@ -0,0 +1,38 @@
+package auditarchive
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[string]int
+		wantErr bool
+	}{
+		{spec: "", want: map[string]int{}},
+		{spec: "authentication=365, clinical = 7300,default=1825", want: map[string]int{"authentication": 365, "clinical": 7300, "default": 1825}},
+		{spec: "patient_data=1825,", want: map[string]int{"patient_data": 1825}},
+		{spec: "clinicial=7300", wantErr: true},
+		{spec: "Clinical=7300", wantErr: true},
+		{spec: "clinical", wantErr: true},
+		{spec: "clinical=0", wantErr: true},
+		{spec: "clinical=twenty", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRetention(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseRetention(%q) = %v, want an error", tt.spec, got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRetention(%q) = %v, %v; want %v", tt.spec, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -2,33 +2,261 @@ package auditservice
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"hms-api/domain"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrServiceClosed = errors.New("audit service is closed")
+	ErrQueueFull     = errors.New("audit queue is full")
+)
+
 type Service interface {
 	Log(ctx context.Context, userID uuid.UUID, action string, description string) error
+	LogEntry(ctx context.Context, entry domain.AuditLog) error
+	Reserve(ctx context.Context, n int) (*Reservation, error)
+	Stats() []SinkStats
+	Close(ctx context.Context) error
+}
+
+// Config controls the in-process queue that sits between the controllers and
+// each sink. Zero values fall back to the defaults below.
+type Config struct {
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxRetries    int
+	RetryBackoff  time.Duration
+	// EnqueueTimeout is how long a caller waits for room in the database
+	// queue before its entry goes to the dead letter file instead.
+	EnqueueTimeout time.Duration
+	// DeadLetterPath is the JSON lines file entries go to once a sink has
+	// given up on them.
+	DeadLetterPath string
 }
 
+const (
+	defaultQueueSize      = 1024
+	defaultBatchSize      = 50
+	defaultFlushInterval  = time.Second
+	defaultMaxRetries     = 5
+	defaultRetryBackoff   = 500 * time.Millisecond
+	defaultEnqueueTimeout = 200 * time.Millisecond
+
+	defaultDeadLetterPath = "logs/audit-dead-letter.jsonl"
+)
+
 type service struct {
-	auditLogUsecase domain.AuditLogUsecase
+	primary   *worker
+	secondary []*worker
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
 }
 
-func NewService(usecase domain.AuditLogUsecase) Service {
-	return &service{
-		auditLogUsecase: usecase,
+// NewService writes every entry to the database through usecase and fans it
+// out to any additional sinks. The database is the system of record, so when
+// its queue is full callers wait, for up to EnqueueTimeout, and then hand
+// the entry to the dead letter file; the other sinks each get their own queue
+// and drop entries instead, so a slow SIEM can never hold up a request.
+func NewService(usecase domain.AuditLogUsecase, config Config, sinks ...Sink) Service {
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaultRetryBackoff
+	}
+	if config.EnqueueTimeout <= 0 {
+		config.EnqueueTimeout = defaultEnqueueTimeout
+	}
+	if config.DeadLetterPath == "" {
+		config.DeadLetterPath = defaultDeadLetterPath
+	}
+
+	s := &service{
+		primary: newWorker(NewDatabaseSink(usecase), config, true),
 	}
+	for _, sink := range sinks {
+		s.secondary = append(s.secondary, newWorker(sink, config, false))
+	}
+
+	return s
 }
 
 func (s *service) Log(ctx context.Context, userID uuid.UUID, action string, description string) error {
-	logEntry := &domain.AuditLog{
+	return s.LogEntry(ctx, domain.AuditLog{
 		UserID:      userID,
 		Action:      action,
 		Description: description,
-		CreatedAt:   time.Now(), 
+	})
+}
+
+// LogEntry enqueues an entry for the background writers. When the database
+// queue is full the caller waits up to EnqueueTimeout for room, and past that
+// the entry is appended to the dead letter file, so a struggling database
+// slows requests down without holding them or dropping their audit trail.
+func (s *service) LogEntry(ctx context.Context, logEntry domain.AuditLog) error {
+	if err := s.primary.reserve(ctx, 1); err != nil {
+		if errors.Is(err, ErrQueueFull) {
+			return s.spill(logEntry, err)
+		}
+		s.primary.rejected.Add(1)
+		return err
+	}
+
+	return s.put(logEntry)
+}
+
+// Reserve takes room in the database queue for n entries, waiting no longer
+// than LogEntry would. Callers reserve before making the change the entries
+// record, so a full queue fails the request before anything is changed
+// rather than after.
+func (s *service) Reserve(ctx context.Context, n int) (*Reservation, error) {
+	s.mu.RLock()
+	closed := s.closed
+	s.mu.RUnlock()
+	if closed {
+		s.primary.rejected.Add(1)
+		return nil, ErrServiceClosed
+	}
+
+	if err := s.primary.reserve(ctx, n); err != nil {
+		s.primary.rejected.Add(1)
+		return nil, err
+	}
+
+	return &Reservation{service: s, left: n}, nil
+}
+
+// put queues an entry whose room in the database queue is already taken,
+// and offers it to the other sinks.
+func (s *service) put(logEntry domain.AuditLog) error {
+	if logEntry.CreatedAt.IsZero() {
+		logEntry.CreatedAt = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		s.primary.release(1)
+		return s.spill(logEntry, ErrServiceClosed)
+	}
+
+	s.primary.put(logEntry)
+	for _, w := range s.secondary {
+		_ = w.enqueue(context.Background(), logEntry)
+	}
+
+	return nil
+}
+
+// spill appends an entry the database queue couldn't take to the dead letter
+// file, and only fails when that can't be written either.
+func (s *service) spill(logEntry domain.AuditLog, cause error) error {
+	if logEntry.CreatedAt.IsZero() {
+		logEntry.CreatedAt = time.Now()
+	}
+	if err := s.primary.deadLetter(logEntry, cause); err != nil {
+		return fmt.Errorf("%w, and so is the dead letter file: %v", cause, err)
+	}
+	return nil
+}
+
+// Reservation is room held in the database queue for the entries of a
+// change, taken before the change is made.
+type Reservation struct {
+	service *service
+
+	mu   sync.Mutex
+	left int
+}
+
+// LogEntry records an entry in the reserved room. Once that's used up it
+// falls back to Service.LogEntry.
+func (r *Reservation) LogEntry(ctx context.Context, logEntry domain.AuditLog) error {
+	r.mu.Lock()
+	reserved := r.left > 0
+	if reserved {
+		r.left--
+	}
+	r.mu.Unlock()
+
+	if !reserved {
+		return r.service.LogEntry(ctx, logEntry)
+	}
+	return r.service.put(logEntry)
+}
+
+// Add reserves room for n more entries, for changes that record several.
+func (r *Reservation) Add(ctx context.Context, n int) error {
+	if err := r.service.primary.reserve(ctx, n); err != nil {
+		r.service.primary.rejected.Add(1)
+		return err
+	}
+
+	r.mu.Lock()
+	r.left += n
+	r.mu.Unlock()
+	return nil
+}
+
+// Release gives back the room that wasn't used.
+func (r *Reservation) Release() {
+	r.mu.Lock()
+	left := r.left
+	r.left = 0
+	r.mu.Unlock()
+
+	r.service.primary.release(left)
+}
+
+func (s *service) Stats() []SinkStats {
+	stats := []SinkStats{s.primary.stats()}
+	for _, w := range s.secondary {
+		stats = append(stats, w.stats())
+	}
+	return stats
+}
+
+// Close stops queueing new entries, which go to the dead letter file from
+// then on, and waits for every queue to be flushed.
+func (s *service) Close(ctx context.Context) error {
+	workers := append([]*worker{s.primary}, s.secondary...)
+
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		for _, w := range workers {
+			close(w.queue)
+		}
+		s.mu.Unlock()
+	})
+
+	for _, w := range workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			log.Printf("[ERROR] Audit: shutdown deadline reached with %d entries still queued for %s\n", len(w.queue), w.sink.Name())
+			return ctx.Err()
+		}
 	}
 
-	return s.auditLogUsecase.Create(ctx, logEntry)
+	return nil
 }
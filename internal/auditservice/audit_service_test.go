@@ -0,0 +1,228 @@
+package auditservice
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"hms-api/domain"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stuckUsecase holds every batch until release is closed, standing in for a
+// database that has stopped answering.
+type stuckUsecase struct {
+	domain.AuditLogUsecase
+
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	written []domain.AuditLog
+}
+
+func newStuckUsecase() *stuckUsecase {
+	return &stuckUsecase{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (u *stuckUsecase) CreateBatch(c context.Context, logs []domain.AuditLog) error {
+	u.once.Do(func() { close(u.started) })
+	<-u.release
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.written = append(u.written, logs...)
+	return nil
+}
+
+func (u *stuckUsecase) actions() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var actions []string
+	for _, entry := range u.written {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func readDeadLetters(t *testing.T, path string) []string {
+	t.Helper()
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var actions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record deadLetterRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		actions = append(actions, record.Entry.Action)
+	}
+	return actions
+}
+
+// newStuckService returns a service whose database queue has room for two
+// entries and is full: one entry is being written and two wait behind it.
+func newStuckService(t *testing.T) (Service, *stuckUsecase, string) {
+	t.Helper()
+	usecase := newStuckUsecase()
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	s := NewService(usecase, Config{
+		QueueSize:      2,
+		BatchSize:      1,
+		FlushInterval:  time.Hour,
+		EnqueueTimeout: 20 * time.Millisecond,
+		DeadLetterPath: deadLetterPath,
+	})
+
+	ctx := context.Background()
+	if err := s.Log(ctx, uuid.Nil, "WRITING", ""); err != nil {
+		t.Fatal(err)
+	}
+	<-usecase.started
+	for _, action := range []string{"QUEUED_1", "QUEUED_2"} {
+		if err := s.Log(ctx, uuid.Nil, action, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s, usecase, deadLetterPath
+}
+
+func TestLogEntrySpillsWhenQueueStaysFull(t *testing.T) {
+	s, usecase, deadLetterPath := newStuckService(t)
+
+	started := time.Now()
+	if err := s.Log(context.Background(), uuid.Nil, "SPILLED", ""); err != nil {
+		t.Fatalf("Log on a full queue: %v", err)
+	}
+	if waited := time.Since(started); waited > time.Second {
+		t.Errorf("Log waited %v on a full queue, want about the enqueue timeout", waited)
+	}
+	if got := readDeadLetters(t, deadLetterPath); len(got) != 1 || got[0] != "SPILLED" {
+		t.Errorf("dead letters = %v, want [SPILLED]", got)
+	}
+
+	if _, err := s.Reserve(context.Background(), 1); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Reserve on a full queue error = %v, want ErrQueueFull", err)
+	}
+
+	close(usecase.release)
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := usecase.actions(); len(got) != 3 {
+		t.Errorf("written = %v, want the three queued entries", got)
+	}
+}
+
+func TestReservationKeepsRoom(t *testing.T) {
+	usecase := newStuckUsecase()
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	s := NewService(usecase, Config{
+		QueueSize:      2,
+		BatchSize:      1,
+		FlushInterval:  time.Hour,
+		EnqueueTimeout: 20 * time.Millisecond,
+		DeadLetterPath: deadLetterPath,
+	})
+	ctx := context.Background()
+
+	if err := s.Log(ctx, uuid.Nil, "WRITING", ""); err != nil {
+		t.Fatal(err)
+	}
+	<-usecase.started
+
+	reservation, err := s.Reserve(ctx, 1)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	defer reservation.Release()
+
+	if err := s.Log(ctx, uuid.Nil, "QUEUED", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Log(ctx, uuid.Nil, "SPILLED", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := reservation.LogEntry(ctx, domain.AuditLog{Action: "RESERVED"}); err != nil {
+		t.Fatalf("LogEntry in reserved room: %v", err)
+	}
+	if err := reservation.Add(ctx, 1); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Add on a full queue error = %v, want ErrQueueFull", err)
+	}
+
+	close(usecase.release)
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := usecase.actions(); len(got) != 3 || got[2] != "RESERVED" {
+		t.Errorf("written = %v, want the reserved entry written last", got)
+	}
+	if got := readDeadLetters(t, deadLetterPath); len(got) != 1 || got[0] != "SPILLED" {
+		t.Errorf("dead letters = %v, want only the entry that found no room", got)
+	}
+}
+
+func TestReleaseGivesRoomBack(t *testing.T) {
+	usecase := newStuckUsecase()
+	close(usecase.release)
+	s := NewService(usecase, Config{
+		QueueSize:      2,
+		EnqueueTimeout: 20 * time.Millisecond,
+		DeadLetterPath: filepath.Join(t.TempDir(), "dead-letter.jsonl"),
+	})
+	defer s.Close(context.Background())
+	ctx := context.Background()
+
+	reservation, err := s.Reserve(ctx, 2)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if _, err := s.Reserve(ctx, 1); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Reserve past the queue size error = %v, want ErrQueueFull", err)
+	}
+
+	reservation.Release()
+	if _, err := s.Reserve(ctx, 2); err != nil {
+		t.Errorf("Reserve after Release: %v", err)
+	}
+}
+
+func TestLogEntryAfterCloseGoesToDeadLetter(t *testing.T) {
+	usecase := newStuckUsecase()
+	close(usecase.release)
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	s := NewService(usecase, Config{DeadLetterPath: deadLetterPath})
+
+	reservation, err := s.Reserve(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reservation.LogEntry(context.Background(), domain.AuditLog{Action: "AFTER_CLOSE"}); err != nil {
+		t.Errorf("LogEntry in reserved room after Close: %v", err)
+	}
+	if _, err := s.Reserve(context.Background(), 1); !errors.Is(err, ErrServiceClosed) {
+		t.Errorf("Reserve after Close error = %v, want ErrServiceClosed", err)
+	}
+	if got := readDeadLetters(t, deadLetterPath); len(got) != 1 || got[0] != "AFTER_CLOSE" {
+		t.Errorf("dead letters = %v, want [AFTER_CLOSE]", got)
+	}
+}
@@ -0,0 +1,133 @@
+package auditservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hms-api/domain"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	defaultFileMaxSize    = 100 * 1024 * 1024
+	defaultFileMaxBackups = 10
+)
+
+// fileSink appends entries as JSON lines and rotates the file once it grows
+// past maxSize, keeping at most maxBackups rotated files next to it.
+type fileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+func NewFileSink(path string, maxSizeMB int, maxBackups int) (Sink, error) {
+	fs := &fileSink{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if fs.maxSize <= 0 {
+		fs.maxSize = defaultFileMaxSize
+	}
+	if fs.maxBackups <= 0 {
+		fs.maxBackups = defaultFileMaxBackups
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("error creating audit log directory: %w", err)
+	}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+func (fs *fileSink) Name() string {
+	return "file"
+}
+
+func (fs *fileSink) Write(ctx context.Context, entries []domain.AuditLog) error {
+	if fs.file == nil {
+		if err := fs.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("error encoding audit entry: %w", err)
+		}
+		line = append(line, '\n')
+
+		if fs.size > 0 && fs.size+int64(len(line)) > fs.maxSize {
+			if err := fs.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := fs.file.Write(line)
+		fs.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("error writing audit file: %w", err)
+		}
+	}
+
+	return fs.file.Sync()
+}
+
+func (fs *fileSink) Close() error {
+	if fs.file == nil {
+		return nil
+	}
+	err := fs.file.Close()
+	fs.file = nil
+	return err
+}
+
+func (fs *fileSink) open() error {
+	file, err := os.OpenFile(fs.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("error opening audit file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error reading audit file: %w", err)
+	}
+
+	fs.file = file
+	fs.size = info.Size()
+	return nil
+}
+
+func (fs *fileSink) rotate() error {
+	if err := fs.Close(); err != nil {
+		return fmt.Errorf("error closing audit file: %w", err)
+	}
+
+	rotated := fmt.Sprintf("%s.%s", fs.path, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(fs.path, rotated); err != nil {
+		return fmt.Errorf("error rotating audit file: %w", err)
+	}
+
+	backups, err := filepath.Glob(fs.path + ".*")
+	if err == nil && len(backups) > fs.maxBackups {
+		// The timestamp suffix sorts chronologically.
+		sort.Strings(backups)
+		for _, old := range backups[:len(backups)-fs.maxBackups] {
+			os.Remove(old)
+		}
+	}
+
+	return fs.open()
+}
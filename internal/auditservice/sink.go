@@ -0,0 +1,37 @@
+package auditservice
+
+import (
+	"context"
+	"hms-api/domain"
+)
+
+// Sink is a destination for audit entries. Each sink is driven by its own
+// worker goroutine, so implementations don't need to be safe for concurrent
+// use, and Write may be retried with the same batch.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, entries []domain.AuditLog) error
+	Close() error
+}
+
+type databaseSink struct {
+	auditLogUsecase domain.AuditLogUsecase
+}
+
+func NewDatabaseSink(usecase domain.AuditLogUsecase) Sink {
+	return &databaseSink{
+		auditLogUsecase: usecase,
+	}
+}
+
+func (ds *databaseSink) Name() string {
+	return "database"
+}
+
+func (ds *databaseSink) Write(ctx context.Context, entries []domain.AuditLog) error {
+	return ds.auditLogUsecase.CreateBatch(ctx, entries)
+}
+
+func (ds *databaseSink) Close() error {
+	return nil
+}
@@ -0,0 +1,159 @@
+package auditservice
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	syslogFacilityAudit = 13
+	syslogSeverityWarn  = 4
+	syslogSeverityInfo  = 6
+	syslogEnterpriseID  = 32473
+	syslogDialTimeout   = 5 * time.Second
+	syslogWriteTimeout  = 5 * time.Second
+)
+
+// syslogSink sends RFC 5424 messages over UDP or TCP. TCP messages use
+// octet-counting framing (RFC 6587); a failed connection is re-dialed on the
+// next attempt, so entries may be delivered more than once.
+type syslogSink struct {
+	network  string
+	address  string
+	hostname string
+	appName  string
+	procID   string
+
+	conn net.Conn
+}
+
+func NewSyslogSink(network string, address string, appName string) (Sink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog network %q, expected udp or tcp", network)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	if appName == "" {
+		appName = "hms-api"
+	}
+
+	return &syslogSink{
+		network:  network,
+		address:  address,
+		hostname: syslogHeaderField(hostname, 255),
+		appName:  syslogHeaderField(appName, 48),
+		procID:   fmt.Sprint(os.Getpid()),
+	}, nil
+}
+
+func (ss *syslogSink) Name() string {
+	return "syslog"
+}
+
+func (ss *syslogSink) Write(ctx context.Context, entries []domain.AuditLog) error {
+	if ss.conn == nil {
+		conn, err := net.DialTimeout(ss.network, ss.address, syslogDialTimeout)
+		if err != nil {
+			return fmt.Errorf("error connecting to syslog: %w", err)
+		}
+		ss.conn = conn
+	}
+
+	for _, entry := range entries {
+		message := ss.format(entry)
+		if ss.network == "tcp" {
+			message = fmt.Sprintf("%d %s", len(message), message)
+		}
+
+		ss.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+		if _, err := ss.conn.Write([]byte(message)); err != nil {
+			ss.Close()
+			return fmt.Errorf("error writing to syslog: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (ss *syslogSink) Close() error {
+	if ss.conn == nil {
+		return nil
+	}
+	err := ss.conn.Close()
+	ss.conn = nil
+	return err
+}
+
+func (ss *syslogSink) format(entry domain.AuditLog) string {
+	severity := syslogSeverityInfo
+	if strings.HasSuffix(entry.Action, "_FAILED") {
+		severity = syslogSeverityWarn
+	}
+
+	params := []string{
+		syslogParam("userId", uuidOrEmpty(entry.UserID)),
+		syslogParam("role", string(entry.UserRole)),
+		syslogParam("resourceType", entry.ResourceType),
+		syslogParam("resourceId", uuidOrEmpty(entry.ResourceID)),
+		syslogParam("patientId", uuidOrEmpty(entry.PatientID)),
+		syslogParam("purpose", entry.Purpose),
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s [audit@%d%s] \ufeff%s",
+		syslogFacilityAudit*8+severity,
+		entry.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		ss.hostname,
+		ss.appName,
+		ss.procID,
+		syslogHeaderField(entry.Action, 32),
+		syslogEnterpriseID,
+		strings.Join(params, ""),
+		entry.Description,
+	)
+}
+
+// syslogHeaderField restricts header fields to printable US-ASCII as RFC 5424
+// requires, using the NILVALUE for empty fields.
+func syslogHeaderField(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func syslogParam(name string, value string) string {
+	if value == "" {
+		return ""
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+	return fmt.Sprintf(` %s="%s"`, name, escaped)
+}
+
+func uuidOrEmpty(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
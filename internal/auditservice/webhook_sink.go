@@ -0,0 +1,98 @@
+package auditservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hms-api/domain"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// webhookSink posts each batch as JSON. When a secret is configured the body
+// is signed so the receiver can verify it with
+//
+//	X-HMS-Signature: sha256=hex(HMAC-SHA256(secret, X-HMS-Timestamp + "." + body))
+//
+// Non-2xx responses are returned as errors and retried by the worker.
+type webhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+type webhookPayload struct {
+	Events []domain.AuditLog `json:"events"`
+}
+
+func NewWebhookSink(url string, secret string, timeout time.Duration) (Sink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	return &webhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (ws *webhookSink) Name() string {
+	return "webhook"
+}
+
+func (ws *webhookSink) Write(ctx context.Context, entries []domain.AuditLog) error {
+	body, err := json.Marshal(webhookPayload{Events: entries})
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ws.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-HMS-Timestamp", timestamp)
+	if len(ws.secret) > 0 {
+		req.Header.Set("X-HMS-Signature", "sha256="+ws.sign(timestamp, body))
+	}
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (ws *webhookSink) Close() error {
+	ws.client.CloseIdleConnections()
+	return nil
+}
+
+func (ws *webhookSink) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, ws.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
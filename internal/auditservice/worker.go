@@ -0,0 +1,287 @@
+package auditservice
+
+import (
+	"context"
+	"encoding/json"
+	"hms-api/domain"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SinkStats exposes the queue counters of one sink so operators can see
+// backpressure and delivery failures before entries start going missing.
+type SinkStats struct {
+	Name          string `json:"name"`
+	QueueDepth    int    `json:"queue_depth"`
+	QueueCapacity int    `json:"queue_capacity"`
+	Enqueued      uint64 `json:"enqueued"`
+	Written       uint64 `json:"written"`
+	Blocked       uint64 `json:"blocked"`
+	Rejected      uint64 `json:"rejected"`
+	Dropped       uint64 `json:"dropped"`
+	Retries       uint64 `json:"retries"`
+	DeadLettered  uint64 `json:"dead_lettered"`
+	// DeadLetterLost counts entries that could not be written to the dead
+	// letter file either, and only reached the server log.
+	DeadLetterLost uint64 `json:"dead_letter_lost"`
+}
+
+// worker owns the queue of a single sink and batches entries into it.
+type worker struct {
+	sink     Sink
+	config   Config
+	blocking bool
+
+	queue chan domain.AuditLog
+	// room holds a token for every entry queued or reserved on a blocking
+	// worker, so reserved entries always fit in the queue.
+	room chan struct{}
+	done chan struct{}
+
+	enqueued       atomic.Uint64
+	written        atomic.Uint64
+	blocked        atomic.Uint64
+	rejected       atomic.Uint64
+	dropped        atomic.Uint64
+	retries        atomic.Uint64
+	deadLettered   atomic.Uint64
+	deadLetterLost atomic.Uint64
+}
+
+func newWorker(sink Sink, config Config, blocking bool) *worker {
+	w := &worker{
+		sink:     sink,
+		config:   config,
+		blocking: blocking,
+		queue:    make(chan domain.AuditLog, config.QueueSize),
+		done:     make(chan struct{}),
+	}
+	if blocking {
+		w.room = make(chan struct{}, config.QueueSize)
+	}
+
+	go w.run()
+
+	return w
+}
+
+// enqueue offers an entry to a non-blocking worker, dropping it when the
+// queue is full.
+func (w *worker) enqueue(ctx context.Context, entry domain.AuditLog) error {
+	select {
+	case w.queue <- entry:
+		w.enqueued.Add(1)
+	default:
+		w.dropped.Add(1)
+	}
+	return nil
+}
+
+// reserve takes room for n entries in a blocking worker's queue, waiting up
+// to EnqueueTimeout for it, and holds none of it when it fails.
+func (w *worker) reserve(ctx context.Context, n int) error {
+	if n > cap(w.room) {
+		return ErrQueueFull
+	}
+
+	var timeout <-chan time.Time
+	for i := 0; i < n; i++ {
+		select {
+		case w.room <- struct{}{}:
+			continue
+		default:
+		}
+
+		if timeout == nil {
+			w.blocked.Add(1)
+			timer := time.NewTimer(w.config.EnqueueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case w.room <- struct{}{}:
+		case <-timeout:
+			w.release(i)
+			return ErrQueueFull
+		case <-ctx.Done():
+			w.release(i)
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// release gives back room for n entries that won't be queued.
+func (w *worker) release(n int) {
+	for i := 0; i < n; i++ {
+		<-w.room
+	}
+}
+
+// put queues an entry whose room was reserved, which never blocks.
+func (w *worker) put(entry domain.AuditLog) {
+	w.queue <- entry
+	w.enqueued.Add(1)
+}
+
+func (w *worker) stats() SinkStats {
+	return SinkStats{
+		Name:           w.sink.Name(),
+		QueueDepth:     len(w.queue),
+		QueueCapacity:  cap(w.queue),
+		Enqueued:       w.enqueued.Load(),
+		Written:        w.written.Load(),
+		Blocked:        w.blocked.Load(),
+		Rejected:       w.rejected.Load(),
+		Dropped:        w.dropped.Load(),
+		Retries:        w.retries.Load(),
+		DeadLettered:   w.deadLettered.Load(),
+		DeadLetterLost: w.deadLetterLost.Load(),
+	}
+}
+
+func (w *worker) run() {
+	defer close(w.done)
+	defer func() {
+		if err := w.sink.Close(); err != nil {
+			log.Printf("[ERROR] Audit: closing %s sink: %v\n", w.sink.Name(), err)
+		}
+	}()
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.AuditLog, 0, w.config.BatchSize)
+	for {
+		select {
+		case entry, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			if w.room != nil {
+				<-w.room
+			}
+			batch = append(batch, entry)
+			if len(batch) >= w.config.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (w *worker) flush(batch []domain.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var err error
+	backoff := w.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err = w.sink.Write(context.Background(), batch)
+		if err == nil {
+			w.written.Add(uint64(len(batch)))
+			return
+		}
+
+		if attempt > w.config.MaxRetries {
+			log.Printf("[ERROR] Audit: %s sink failed a batch of %d entries after %d attempts: %v\n", w.sink.Name(), len(batch), attempt, err)
+			break
+		}
+
+		w.retries.Add(1)
+		log.Printf("[WARN] Audit: %s sink failed a batch of %d entries (attempt %d/%d), retrying in %v: %v\n", w.sink.Name(), len(batch), attempt, w.config.MaxRetries+1, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	if !w.blocking {
+		for _, entry := range batch {
+			w.deadLetter(entry, err)
+		}
+		return
+	}
+
+	// A single bad entry fails the whole batch, so fall back to writing them
+	// one by one and only dead-letter the ones that still fail.
+	for i := range batch {
+		if err := w.sink.Write(context.Background(), batch[i:i+1]); err != nil {
+			w.deadLetter(batch[i], err)
+			continue
+		}
+		w.written.Add(1)
+	}
+}
+
+// deadLetterMu serializes appends to the dead letter file, which every
+// worker shares.
+var deadLetterMu sync.Mutex
+
+// deadLetterRecord is a line of the dead letter file: the entry and why the
+// sink refused it, so it can be replayed once the sink is back.
+type deadLetterRecord struct {
+	Sink     string          `json:"sink"`
+	Cause    string          `json:"cause"`
+	FailedAt time.Time       `json:"failed_at"`
+	Entry    domain.AuditLog `json:"entry"`
+}
+
+// deadLetter appends an entry its sink gave up on to the dead letter file,
+// synced to disk, and only falls back to the server log if that fails too,
+// returning why.
+func (w *worker) deadLetter(entry domain.AuditLog, cause error) error {
+	payload, err := json.Marshal(deadLetterRecord{
+		Sink:     w.sink.Name(),
+		Cause:    cause.Error(),
+		FailedAt: time.Now(),
+		Entry:    entry,
+	})
+	if err == nil {
+		err = appendDeadLetter(w.config.DeadLetterPath, payload)
+	}
+	if err == nil {
+		w.deadLettered.Add(1)
+		return nil
+	}
+
+	w.deadLetterLost.Add(1)
+	if payload == nil {
+		payload = []byte(entry.Action + ": " + entry.Description)
+	}
+	log.Printf("[AUDIT-DEAD-LETTER] %s (cause: %v, dead letter file: %v)\n", payload, cause, err)
+	return err
+}
+
+func appendDeadLetter(path string, payload []byte) error {
+	deadLetterMu.Lock()
+	defer deadLetterMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(payload, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
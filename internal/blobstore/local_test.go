@@ -0,0 +1,174 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestLocalStore(t *testing.T) (Store, string) {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "blobs")
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, dir
+}
+
+// assertNoFiles fails when anything but directories is left under dir.
+func assertNoFiles(t *testing.T, dir string) {
+	t.Helper()
+	filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			t.Errorf("left %s behind", path)
+		}
+		return nil
+	})
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	store, _ := newTestLocalStore(t)
+	ctx := context.Background()
+	key := "attachments/patient/report.pdf"
+
+	if err := store.Put(ctx, key, strings.NewReader("first"), 5, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, key, strings.NewReader("second"), 6, "application/pdf"); err != nil {
+		t.Fatalf("Put replacing the blob: %v", err)
+	}
+
+	content, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(content)
+	content.Close()
+	if string(got) != "second" {
+		t.Errorf("Get = %q, want %q", got, "second")
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing blob: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideDir(t *testing.T) {
+	store, dir := newTestLocalStore(t)
+	ctx := context.Background()
+
+	outside := filepath.Join(filepath.Dir(dir), "outside")
+	if err := os.WriteFile(outside, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{
+		"",
+		"../outside",
+		"attachments/../../outside",
+		"attachments/../report",
+		"/etc/passwd",
+		"attachments//report",
+		"attachments/./report",
+		"attachments/",
+		".",
+		"..",
+		`attachments\..\..\outside`,
+	}
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			if err := store.Put(ctx, key, strings.NewReader("evil"), 4, ""); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+			}
+			if _, err := store.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Get(%q) error = %v, want ErrInvalidKey", key, err)
+			}
+			if err := store.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Delete(%q) error = %v, want ErrInvalidKey", key, err)
+			}
+		})
+	}
+
+	if content, err := os.ReadFile(outside); err != nil || string(content) != "keep" {
+		t.Errorf("file outside the store = %q, %v; want it untouched", content, err)
+	}
+	assertNoFiles(t, dir)
+}
+
+type failingReader struct {
+	content io.Reader
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestLocalStoreCleansUpFailedUploads(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		content io.Reader
+		size    int64
+	}{
+		{name: "shorter than announced", ctx: context.Background(), content: strings.NewReader("short"), size: 10},
+		{name: "longer than announced", ctx: context.Background(), content: strings.NewReader("longer than that"), size: 4},
+		{name: "read error", ctx: context.Background(), content: failingReader{strings.NewReader("partial")}, size: 7},
+		{name: "canceled", ctx: canceled, content: strings.NewReader("content"), size: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, dir := newTestLocalStore(t)
+			key := "attachments/patient/report.pdf"
+
+			if err := store.Put(tt.ctx, key, tt.content, tt.size, ""); err == nil {
+				t.Fatal("Put: got no error")
+			}
+			if _, err := store.Get(context.Background(), key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get after a failed Put error = %v, want ErrNotFound", err)
+			}
+			assertNoFiles(t, dir)
+		})
+	}
+}
+
+func TestLocalStoreFailedUploadKeepsPreviousBlob(t *testing.T) {
+	store, _ := newTestLocalStore(t)
+	ctx := context.Background()
+	key := "attachments/patient/report.pdf"
+
+	if err := store.Put(ctx, key, strings.NewReader("original"), 8, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, key, strings.NewReader("short"), 10, ""); err == nil {
+		t.Fatal("Put with a size mismatch: got no error")
+	}
+
+	content, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer content.Close()
+	if got, _ := io.ReadAll(content); string(got) != "original" {
+		t.Errorf("Get = %q, want the blob from before the failed Put", got)
+	}
+}
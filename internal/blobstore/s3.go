@@ -0,0 +1,267 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Timeout = 60 * time.Second
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body.
+var emptyPayloadHash = hex.EncodeToString(sha256.New().Sum(nil))
+
+type S3Config struct {
+	// Endpoint is the service's base URL, such as https://s3.amazonaws.com
+	// or http://localhost:9000 for a local stand-in like MinIO.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses objects as endpoint/bucket/key rather than
+	// bucket.endpoint/key, as most stand-ins need.
+	PathStyle bool
+	// Timeout is how long to wait for the service to start answering a
+	// request. Transfers themselves are only bounded by the request's
+	// context, so large files aren't cut off.
+	Timeout time.Duration
+}
+
+// s3Store keeps blobs as objects of an S3-compatible bucket, signing requests
+// with AWS Signature Version 4. Uploads are streamed unsigned, so the body
+// isn't read twice.
+type s3Store struct {
+	endpoint *url.URL
+	config   S3Config
+	client   *http.Client
+}
+
+func NewS3Store(config S3Config) (Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("s3 endpoint, bucket, access key and secret key are required")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultS3Timeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = config.Timeout
+
+	return &s3Store{
+		endpoint: endpoint,
+		config:   config,
+		client:   &http.Client{Transport: transport},
+	}, nil
+}
+
+func (ss *s3Store) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	req, err := ss.request(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	ss.sign(req, unsignedPayload, time.Now())
+
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error uploading blob: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return responseError(resp, "uploading")
+}
+
+func (ss *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := ss.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	ss.sign(req, emptyPayloadHash, time.Now())
+
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading blob: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := responseError(resp, "downloading"); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (ss *s3Store) Delete(ctx context.Context, key string) error {
+	req, err := ss.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	ss.sign(req, emptyPayloadHash, time.Now())
+
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error deleting blob: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return responseError(resp, "deleting")
+}
+
+func (ss *s3Store) request(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	target := *ss.endpoint
+	path := "/" + key
+	if ss.config.PathStyle {
+		path = "/" + ss.config.Bucket + path
+	} else {
+		target.Host = ss.config.Bucket + "." + target.Host
+	}
+	target.Path = strings.TrimRight(ss.endpoint.Path, "/") + path
+	target.RawPath = escapePath(target.Path)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("error building s3 request: %w", err)
+	}
+	return req, nil
+}
+
+// sign adds the Signature Version 4 authorization header, signing the host,
+// the x-amz-* headers and the content type.
+func (ss *s3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + ss.config.Region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+ss.config.SecretKey), date)
+	key = hmacSHA256(key, ss.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		ss.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath percent-encodes each segment of a key as Signature Version 4
+// expects, leaving only unreserved characters and slashes.
+func escapePath(key string) string {
+	return escape(key, true)
+}
+
+// canonicalQuery sorts and encodes the query parameters as Signature
+// Version 4 expects, with an empty value for parameters given without one.
+func canonicalQuery(query url.Values) string {
+	var pairs [][2]string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{escape(name, false), escape(value, false)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	encoded := make([]string, len(pairs))
+	for i, pair := range pairs {
+		encoded[i] = pair[0] + "=" + pair[1]
+	}
+	return strings.Join(encoded, "&")
+}
+
+func escape(value string, keepSlash bool) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/' && keepSlash:
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+func responseError(resp *http.Response, action string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("error %s blob: s3 returned %s: %s", action, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,132 @@
+// Package ical writes RFC 5545 iCalendar files for appointment feeds and
+// invitations.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"hms-api/domain"
+	"strings"
+	"time"
+)
+
+const (
+	productID   = "-//hms-api//Appointments//EN"
+	uidDomain   = "hms-api"
+	maxLineSize = 75
+	dateTime    = "20060102T150405Z"
+)
+
+// Event statuses.
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is one VEVENT. UID stays the same across updates of the event and
+// Sequence grows with each of them, so calendar clients replace their copy.
+type Event struct {
+	UID         string
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Status      string
+	Updated     time.Time
+}
+
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// AppointmentEvent turns an appointment into an event. Canceled appointments
+// stay in the calendar as cancelled events, so subscribers drop them.
+func AppointmentEvent(appointment domain.Appointment, summary string, description string) Event {
+	status := StatusConfirmed
+	switch appointment.Status {
+	case domain.Requested:
+		status = StatusTentative
+	case domain.Canceled:
+		status = StatusCancelled
+	}
+
+	return Event{
+		UID:         appointment.ID.String() + "@" + uidDomain,
+		Sequence:    appointment.Sequence,
+		Start:       appointment.AppointmentDate,
+		End:         appointment.EndDate,
+		Summary:     summary,
+		Description: description,
+		Status:      status,
+		Updated:     appointment.UpdatedAt,
+	}
+}
+
+// Bytes renders the calendar with CRLF line endings and long lines folded.
+func (cal Calendar) Bytes() []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+productID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if cal.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escape(cal.Name))
+	}
+
+	for _, event := range cal.Events {
+		updated := event.Updated
+		if updated.IsZero() {
+			updated = time.Now()
+		}
+
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+event.UID)
+		writeLine(&buf, "DTSTAMP:"+updated.UTC().Format(dateTime))
+		writeLine(&buf, "LAST-MODIFIED:"+updated.UTC().Format(dateTime))
+		writeLine(&buf, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		writeLine(&buf, "DTSTART:"+event.Start.UTC().Format(dateTime))
+		writeLine(&buf, "DTEND:"+event.End.UTC().Format(dateTime))
+		writeLine(&buf, "SUMMARY:"+escape(event.Summary))
+		if event.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escape(event.Description))
+		}
+		writeLine(&buf, "STATUS:"+event.Status)
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(text string) string {
+	return textEscaper.Replace(text)
+}
+
+// writeLine folds line into chunks of at most 75 octets, continuing each
+// with a leading space, without splitting UTF-8 sequences.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineSize
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineSize - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
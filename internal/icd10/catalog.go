@@ -0,0 +1,204 @@
+// Package icd10 is an in-memory catalog of ICD-10 (CID-10) codes searchable
+// by code or by Portuguese description, ignoring case and accents.
+package icd10
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// bundled holds the most common codes of primary care. A full catalog in the
+// same format can be loaded with Load instead.
+//
+//go:embed cid10.csv
+var bundled []byte
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type entry struct {
+	code        domain.ICD10Code
+	key         string
+	description string
+}
+
+type Catalog struct {
+	entries []entry
+	byKey   map[string]int
+}
+
+// Load reads the catalog at path, or the bundled one when path is empty. The
+// file is UTF-8 with a header row and semicolon separated code and
+// description columns, such as "J45.9;Asma não especificada".
+func Load(path string) (*Catalog, error) {
+	data := bundled
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return parse(bytes.NewReader(data))
+}
+
+func parse(r io.Reader) (*Catalog, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = 2
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("error reading ICD-10 catalog header: %w", err)
+	}
+
+	catalog := &Catalog{byKey: map[string]int{}}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading ICD-10 catalog: %w", err)
+		}
+
+		code := domain.ICD10Code{Code: strings.ToUpper(strings.TrimSpace(record[0])), Description: strings.TrimSpace(record[1])}
+		key := codeKey(code.Code)
+		if key == "" || code.Description == "" {
+			return nil, fmt.Errorf("invalid ICD-10 catalog line %q", strings.Join(record, ";"))
+		}
+		if _, ok := catalog.byKey[key]; ok {
+			return nil, fmt.Errorf("duplicate ICD-10 code %s", code.Code)
+		}
+
+		catalog.byKey[key] = len(catalog.entries)
+		catalog.entries = append(catalog.entries, entry{code: code, key: key, description: fold(code.Description)})
+	}
+
+	if len(catalog.entries) == 0 {
+		return nil, errors.New("empty ICD-10 catalog")
+	}
+
+	sort.Slice(catalog.entries, func(i, j int) bool { return catalog.entries[i].key < catalog.entries[j].key })
+	for i, e := range catalog.entries {
+		catalog.byKey[e.key] = i
+	}
+	return catalog, nil
+}
+
+// Lookup finds a code written with or without its dot, in any case.
+func (c *Catalog) Lookup(code string) (domain.ICD10Code, bool) {
+	i, ok := c.byKey[codeKey(code)]
+	if !ok {
+		return domain.ICD10Code{}, false
+	}
+	return c.entries[i].code, true
+}
+
+// Search returns up to limit codes matching query: the code itself, codes
+// starting with it, then those whose description has every word of it,
+// words starting the description or one of its words first.
+func (c *Catalog) Search(query string, limit int) []domain.ICD10Code {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	words := strings.Fields(fold(query))
+	if len(words) == 0 {
+		return []domain.ICD10Code{}
+	}
+	key := codeKey(query)
+
+	type match struct {
+		rank  int
+		index int
+	}
+	var matches []match
+	for i, e := range c.entries {
+		var rank int
+		switch {
+		case key != "" && e.key == key:
+			rank = 0
+		case key != "" && strings.HasPrefix(e.key, key):
+			rank = 1
+		default:
+			rank = descriptionRank(e.description, words)
+		}
+		if rank >= 0 {
+			matches = append(matches, match{rank: rank, index: i})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].rank < matches[j].rank })
+
+	codes := []domain.ICD10Code{}
+	for _, m := range matches {
+		if len(codes) == limit {
+			break
+		}
+		codes = append(codes, c.entries[m.index].code)
+	}
+	return codes
+}
+
+// descriptionRank ranks a description having every word: 2 when it starts
+// with the first, 3 when one of its words does and 4 otherwise, or -1 when a
+// word is missing.
+func descriptionRank(description string, words []string) int {
+	for _, word := range words {
+		if !strings.Contains(description, word) {
+			return -1
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(description, words[0]):
+		return 2
+	case strings.Contains(" "+description, " "+words[0]):
+		return 3
+	}
+	return 4
+}
+
+// codeKey normalizes a code to upper case without its dot, or returns "" when
+// s doesn't look like one.
+func codeKey(s string) string {
+	key := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), ".", ""))
+	if len(key) < 1 || len(key) > 5 || key[0] < 'A' || key[0] > 'Z' {
+		return ""
+	}
+	for _, r := range key[1:] {
+		if !unicode.IsDigit(r) && !(r >= 'A' && r <= 'Z') {
+			return ""
+		}
+	}
+	return key
+}
+
+// fold lower-cases s and strips its accents, so "cefaleia" matches
+// "Cefaléia".
+func fold(s string) string {
+	accents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(accents, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
@@ -0,0 +1,44 @@
+package noshow
+
+import (
+	"context"
+	"hms-api/domain"
+	"log"
+	"time"
+)
+
+// Marker marks confirmed appointments the patient never checked in to as
+// no-shows once their grace period is over.
+type Marker interface {
+	// Start marks overdue appointments every interval until ctx is done.
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type marker struct {
+	usecase domain.AttendanceUsecase
+}
+
+func NewMarker(usecase domain.AttendanceUsecase) Marker {
+	return &marker{usecase: usecase}
+}
+
+func (m *marker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		marked, err := m.usecase.MarkNoShows(ctx, time.Now())
+		if err != nil {
+			log.Printf("[ERROR] No-show: %v\n", err)
+		}
+		if marked > 0 {
+			log.Printf("[NO-SHOW] Marked %d appointments as no-shows\n", marked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+)
+
+// Dispatcher sends each notification through the sender registered for its
+// channel.
+type Dispatcher interface {
+	Send(c context.Context, notification domain.Notification) error
+	// Supports reports whether a sender is registered for channel.
+	Supports(channel domain.NotificationChannel) bool
+}
+
+type dispatcher struct {
+	senders map[domain.NotificationChannel]domain.NotificationSender
+}
+
+func NewDispatcher(senders ...domain.NotificationSender) Dispatcher {
+	d := &dispatcher{senders: make(map[domain.NotificationChannel]domain.NotificationSender, len(senders))}
+	for _, sender := range senders {
+		d.senders[sender.Channel()] = sender
+	}
+	return d
+}
+
+func (d *dispatcher) Send(c context.Context, notification domain.Notification) error {
+	sender, ok := d.senders[notification.Channel]
+	if !ok {
+		return fmt.Errorf("%w: no sender for %s", domain.ErrInvalidNotificationChannel, notification.Channel)
+	}
+	return sender.Send(c, notification)
+}
+
+func (d *dispatcher) Supports(channel domain.NotificationChannel) bool {
+	_, ok := d.senders[channel]
+	return ok
+}
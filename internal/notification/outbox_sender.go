@@ -0,0 +1,57 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hms-api/domain"
+	"io"
+	"sync"
+	"time"
+)
+
+// outboxSender is a stand-in for a real email, SMS or push provider. It
+// writes each notification as a JSON line to w, so they can be read during
+// local development instead of reaching patients.
+type outboxSender struct {
+	channel domain.NotificationChannel
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewOutboxSender(channel domain.NotificationChannel, w io.Writer) domain.NotificationSender {
+	return &outboxSender{channel: channel, w: w}
+}
+
+// NewFakeSenders returns an outbox sender for every channel, all writing to w.
+func NewFakeSenders(w io.Writer) []domain.NotificationSender {
+	return []domain.NotificationSender{
+		NewOutboxSender(domain.EmailChannel, w),
+		NewOutboxSender(domain.SMSChannel, w),
+		NewOutboxSender(domain.PushChannel, w),
+	}
+}
+
+func (ob *outboxSender) Channel() domain.NotificationChannel {
+	return ob.channel
+}
+
+func (ob *outboxSender) Send(c context.Context, notification domain.Notification) error {
+	line, err := json.Marshal(struct {
+		domain.Notification
+		SentAt time.Time `json:"sent_at"`
+	}{notification, time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("error encoding %s notification: %w", ob.channel, err)
+	}
+
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if _, err := ob.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing %s notification: %w", ob.channel, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,372 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"hms-api/domain"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Template names.
+const (
+	AppointmentReminder     = "appointment_reminder"
+	AppointmentConfirmation = "appointment_confirmation"
+	AppointmentRescheduled  = "appointment_rescheduled"
+	AppointmentCanceled     = "appointment_canceled"
+	AppointmentReassigned   = "appointment_reassigned"
+	WaitlistOffer           = "waitlist_offer"
+
+	// The pages reminder links open. Their subject is the page title, the
+	// body its text and short the label of its button.
+	ReminderConfirmPage   = "reminder_confirm_page"
+	ReminderCancelPage    = "reminder_cancel_page"
+	ReminderConfirmedPage = "reminder_confirmed_page"
+	ReminderCanceledPage  = "reminder_canceled_page"
+	ReminderLinkFailed    = "reminder_link_failed"
+)
+
+// Reasons a reminder link can fail, for the ReminderLinkFailed page.
+const (
+	LinkMissing          = "missing"
+	LinkInvalid          = "invalid"
+	LinkStale            = "stale"
+	LinkNotFound         = "not_found"
+	LinkApprovalRequired = "approval_required"
+	LinkUnchangeable     = "unchangeable"
+	LinkError            = "error"
+)
+
+// Message is a rendered template. Body is meant for email, Short for SMS and
+// push notifications.
+type Message struct {
+	Subject string
+	Body    string
+	Short   string
+}
+
+// ReminderData fills the appointment reminder template.
+type ReminderData struct {
+	PatientName string
+	DoctorName  string
+	Specialty   string
+	Start       time.Time
+	ConfirmURL  string
+	CancelURL   string
+}
+
+// ConfirmationData fills the appointment confirmation template.
+type ConfirmationData struct {
+	PatientName string
+	DoctorName  string
+	Specialty   string
+	Start       time.Time
+}
+
+// RescheduleData fills the appointment rescheduled template. ForDoctor is set
+// when the patient moved the appointment and the doctor is being told.
+type RescheduleData struct {
+	PatientName string
+	DoctorName  string
+	Specialty   string
+	Previous    time.Time
+	Start       time.Time
+	Reason      string
+	ForDoctor   bool
+}
+
+// ClinicChangeData fills the templates telling a patient the clinic canceled
+// their appointment or gave it to another doctor, named in DoctorName.
+type ClinicChangeData struct {
+	PatientName string
+	DoctorName  string
+	Specialty   string
+	Start       time.Time
+	Reason      string
+}
+
+// WaitlistOfferData fills the template offering a waitlisted patient a slot
+// held for them until ExpiresAt.
+type WaitlistOfferData struct {
+	PatientName string
+	DoctorName  string
+	Specialty   string
+	Start       time.Time
+	ExpiresAt   time.Time
+}
+
+// ReminderPageData fills the pages of reminder links.
+type ReminderPageData struct {
+	Start time.Time
+}
+
+// ReminderFailureData fills the page of a reminder link that couldn't be
+// shown or applied, for one of the Link reasons.
+type ReminderFailureData struct {
+	Reason string
+}
+
+type messageTemplate struct {
+	subject string
+	body    string
+	short   string
+}
+
+var sources = map[string]map[string]messageTemplate{
+	domain.LanguagePortuguese: {
+		AppointmentReminder: {
+			subject: "Lembrete: consulta em {{date .Start}} às {{time .Start}}",
+			body: `Olá, {{.PatientName}}.
+
+Lembramos que você tem uma consulta com {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} em {{date .Start}} às {{time .Start}}.
+
+Para confirmar sua presença, acesse: {{.ConfirmURL}}
+Se não puder comparecer, cancele por aqui: {{.CancelURL}}
+
+Até breve!`,
+			short: "Consulta com {{.DoctorName}} em {{date .Start}} às {{time .Start}}. Confirmar: {{.ConfirmURL}} Cancelar: {{.CancelURL}}",
+		},
+		AppointmentConfirmation: {
+			subject: "Consulta confirmada: {{date .Start}} às {{time .Start}}",
+			body: `Olá, {{.PatientName}}.
+
+Sua consulta com {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} está confirmada para {{date .Start}} às {{time .Start}}.
+
+O convite em anexo adiciona a consulta à sua agenda.`,
+			short: "Consulta com {{.DoctorName}} confirmada para {{date .Start}} às {{time .Start}}.",
+		},
+		AppointmentRescheduled: {
+			subject: "Consulta remarcada para {{date .Start}} às {{time .Start}}",
+			body: `{{if .ForDoctor}}Olá, {{.DoctorName}}.
+
+{{.PatientName}} remarcou a consulta de {{date .Previous}} às {{time .Previous}} para {{date .Start}} às {{time .Start}}.{{else}}Olá, {{.PatientName}}.
+
+Sua consulta com {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} de {{date .Previous}} às {{time .Previous}} foi remarcada para {{date .Start}} às {{time .Start}}.{{end}}
+{{if .Reason}}
+Motivo: {{.Reason}}
+{{end}}{{if not .ForDoctor}}
+O convite em anexo atualiza a consulta na sua agenda.{{end}}`,
+			short: "Consulta de {{date .Previous}} às {{time .Previous}} remarcada para {{date .Start}} às {{time .Start}}.",
+		},
+		AppointmentCanceled: {
+			subject: "Consulta cancelada: {{date .Start}} às {{time .Start}}",
+			body: `Olá, {{.PatientName}}.
+
+Infelizmente sua consulta com {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} em {{date .Start}} às {{time .Start}} foi cancelada.
+{{if .Reason}}
+Motivo: {{.Reason}}
+{{end}}
+Pedimos desculpas pelo transtorno. Entre em contato ou agende um novo horário quando quiser.`,
+			short: "Sua consulta com {{.DoctorName}} em {{date .Start}} às {{time .Start}} foi cancelada.",
+		},
+		AppointmentReassigned: {
+			subject: "Sua consulta de {{date .Start}} será com {{.DoctorName}}",
+			body: `Olá, {{.PatientName}}.
+
+Sua consulta em {{date .Start}} às {{time .Start}} continua no mesmo horário, mas agora será com {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}}.
+{{if .Reason}}
+Motivo: {{.Reason}}
+{{end}}
+O convite em anexo atualiza a consulta na sua agenda.`,
+			short: "Sua consulta em {{date .Start}} às {{time .Start}} agora será com {{.DoctorName}}.",
+		},
+		WaitlistOffer: {
+			subject: "Horário disponível: {{date .Start}} às {{time .Start}}",
+			body: `Olá, {{.PatientName}}.
+
+Abriu um horário com {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} em {{date .Start}} às {{time .Start}}, e ele está reservado para você na lista de espera.
+
+Aceite ou recuse a oferta no aplicativo até {{date .ExpiresAt}} às {{time .ExpiresAt}}. Depois disso, o horário será oferecido ao próximo paciente da lista.`,
+			short: "Horário com {{.DoctorName}} em {{date .Start}} às {{time .Start}} reservado para você. Aceite ou recuse até {{date .ExpiresAt}} às {{time .ExpiresAt}}.",
+		},
+		ReminderConfirmPage: {
+			subject: "Confirme sua consulta",
+			body:    "Sua consulta é em {{date .Start}} às {{time .Start}}.",
+			short:   "Confirmar consulta",
+		},
+		ReminderCancelPage: {
+			subject: "Cancele sua consulta",
+			body:    "Sua consulta é em {{date .Start}} às {{time .Start}}.",
+			short:   "Cancelar consulta",
+		},
+		ReminderConfirmedPage: {
+			subject: "Pronto",
+			body:    "Sua consulta está confirmada.",
+			short:   "Consulta confirmada",
+		},
+		ReminderCanceledPage: {
+			subject: "Pronto",
+			body:    "Sua consulta foi cancelada.",
+			short:   "Consulta cancelada",
+		},
+		ReminderLinkFailed: {
+			subject: `{{if eq .Reason "missing" "invalid"}}Link inválido{{else}}Nada foi alterado{{end}}`,
+			body: `{{if eq .Reason "missing"}}O link está incompleto. Abra-o de novo a partir do lembrete.
+{{- else if eq .Reason "invalid"}}O link é inválido ou expirou.
+{{- else if eq .Reason "stale"}}A consulta mudou depois que este lembrete foi enviado. Use os links do lembrete mais recente.
+{{- else if eq .Reason "not_found"}}A consulta não foi encontrada.
+{{- else if eq .Reason "approval_required"}}Sua consulta precisa ser confirmada pela clínica. Entre em contato conosco.
+{{- else if eq .Reason "unchangeable"}}A consulta não pode mais ser alterada por um lembrete.
+{{- else}}Algo deu errado. Tente de novo mais tarde.{{end}}`,
+			short: `{{if eq .Reason "missing" "invalid"}}Link inválido{{else}}Nada foi alterado{{end}}`,
+		},
+	},
+	domain.LanguageEnglish: {
+		AppointmentReminder: {
+			subject: "Reminder: appointment on {{date .Start}} at {{time .Start}}",
+			body: `Hello, {{.PatientName}}.
+
+This is a reminder of your appointment with {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} on {{date .Start}} at {{time .Start}}.
+
+To confirm you're coming, open: {{.ConfirmURL}}
+If you can't make it, cancel here: {{.CancelURL}}
+
+See you soon!`,
+			short: "Appointment with {{.DoctorName}} on {{date .Start}} at {{time .Start}}. Confirm: {{.ConfirmURL}} Cancel: {{.CancelURL}}",
+		},
+		AppointmentConfirmation: {
+			subject: "Appointment confirmed: {{date .Start}} at {{time .Start}}",
+			body: `Hello, {{.PatientName}}.
+
+Your appointment with {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} is confirmed for {{date .Start}} at {{time .Start}}.
+
+Open the attached invitation to add it to your calendar.`,
+			short: "Appointment with {{.DoctorName}} confirmed for {{date .Start}} at {{time .Start}}.",
+		},
+		AppointmentRescheduled: {
+			subject: "Appointment moved to {{date .Start}} at {{time .Start}}",
+			body: `{{if .ForDoctor}}Hello, {{.DoctorName}}.
+
+{{.PatientName}} moved their appointment on {{date .Previous}} at {{time .Previous}} to {{date .Start}} at {{time .Start}}.{{else}}Hello, {{.PatientName}}.
+
+Your appointment with {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} on {{date .Previous}} at {{time .Previous}} was moved to {{date .Start}} at {{time .Start}}.{{end}}
+{{if .Reason}}
+Reason: {{.Reason}}
+{{end}}{{if not .ForDoctor}}
+Open the attached invitation to update it in your calendar.{{end}}`,
+			short: "Appointment on {{date .Previous}} at {{time .Previous}} moved to {{date .Start}} at {{time .Start}}.",
+		},
+		AppointmentCanceled: {
+			subject: "Appointment canceled: {{date .Start}} at {{time .Start}}",
+			body: `Hello, {{.PatientName}}.
+
+Unfortunately your appointment with {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} on {{date .Start}} at {{time .Start}} was canceled.
+{{if .Reason}}
+Reason: {{.Reason}}
+{{end}}
+We're sorry for the inconvenience. Get in touch or book a new time whenever you like.`,
+			short: "Your appointment with {{.DoctorName}} on {{date .Start}} at {{time .Start}} was canceled.",
+		},
+		AppointmentReassigned: {
+			subject: "Your appointment on {{date .Start}} is now with {{.DoctorName}}",
+			body: `Hello, {{.PatientName}}.
+
+Your appointment on {{date .Start}} at {{time .Start}} keeps its time, but will now be with {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}}.
+{{if .Reason}}
+Reason: {{.Reason}}
+{{end}}
+Open the attached invitation to update it in your calendar.`,
+			short: "Your appointment on {{date .Start}} at {{time .Start}} is now with {{.DoctorName}}.",
+		},
+		WaitlistOffer: {
+			subject: "Slot available: {{date .Start}} at {{time .Start}}",
+			body: `Hello, {{.PatientName}}.
+
+A slot opened up with {{.DoctorName}}{{if .Specialty}} ({{.Specialty}}){{end}} on {{date .Start}} at {{time .Start}}, and it's being held for you from the waitlist.
+
+Accept or decline the offer in the app by {{date .ExpiresAt}} at {{time .ExpiresAt}}. After that, the slot goes to the next patient on the list.`,
+			short: "Slot with {{.DoctorName}} on {{date .Start}} at {{time .Start}} held for you. Accept or decline by {{date .ExpiresAt}} at {{time .ExpiresAt}}.",
+		},
+		ReminderConfirmPage: {
+			subject: "Confirm your appointment",
+			body:    "Your appointment is on {{date .Start}} at {{time .Start}}.",
+			short:   "Confirm appointment",
+		},
+		ReminderCancelPage: {
+			subject: "Cancel your appointment",
+			body:    "Your appointment is on {{date .Start}} at {{time .Start}}.",
+			short:   "Cancel appointment",
+		},
+		ReminderConfirmedPage: {
+			subject: "Done",
+			body:    "Your appointment is confirmed.",
+			short:   "Appointment confirmed",
+		},
+		ReminderCanceledPage: {
+			subject: "Done",
+			body:    "Your appointment is canceled.",
+			short:   "Appointment canceled",
+		},
+		ReminderLinkFailed: {
+			subject: `{{if eq .Reason "missing" "invalid"}}Invalid link{{else}}Nothing was changed{{end}}`,
+			body: `{{if eq .Reason "missing"}}The link is incomplete. Open it again from the reminder.
+{{- else if eq .Reason "invalid"}}The link is invalid or has expired.
+{{- else if eq .Reason "stale"}}The appointment changed after this reminder was sent. Use the links in its latest reminder.
+{{- else if eq .Reason "not_found"}}The appointment wasn't found.
+{{- else if eq .Reason "approval_required"}}Your appointment has to be confirmed by the clinic. Please get in touch.
+{{- else if eq .Reason "unchangeable"}}The appointment can no longer be changed from a reminder.
+{{- else}}Something went wrong. Please try again later.{{end}}`,
+			short: `{{if eq .Reason "missing" "invalid"}}Invalid link{{else}}Nothing was changed{{end}}`,
+		},
+	},
+}
+
+var layouts = map[string]struct{ date, time string }{
+	domain.LanguagePortuguese: {date: "02/01/2006", time: "15:04 MST"},
+	domain.LanguageEnglish:    {date: "Jan 2, 2006", time: "3:04 PM MST"},
+}
+
+var templates = parseTemplates()
+
+func parseTemplates() map[string]map[string]*template.Template {
+	parsed := make(map[string]map[string]*template.Template, len(sources))
+
+	for language, byName := range sources {
+		layout := layouts[language]
+		funcs := template.FuncMap{
+			"date": func(t time.Time) string { return t.Format(layout.date) },
+			"time": func(t time.Time) string { return t.Format(layout.time) },
+		}
+
+		parsed[language] = make(map[string]*template.Template, len(byName))
+		for name, source := range byName {
+			tmpl := template.New(name).Funcs(funcs)
+			template.Must(tmpl.New("subject").Parse(source.subject))
+			template.Must(tmpl.New("body").Parse(source.body))
+			template.Must(tmpl.New("short").Parse(source.short))
+			parsed[language][name] = tmpl
+		}
+	}
+
+	return parsed
+}
+
+// SupportedLanguage reports whether there are templates in language.
+func SupportedLanguage(language string) bool {
+	_, ok := templates[language]
+	return ok
+}
+
+// Render fills the named template in language with data.
+func Render(language string, name string, data interface{}) (Message, error) {
+	byName, ok := templates[language]
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", domain.ErrUnsupportedLanguage, language)
+	}
+	tmpl, ok := byName[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown notification template %q", name)
+	}
+
+	var message Message
+	for part, dest := range map[string]*string{"subject": &message.Subject, "body": &message.Body, "short": &message.Short} {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, part, data); err != nil {
+			return Message{}, fmt.Errorf("error rendering %s %s: %w", name, part, err)
+		}
+		*dest = strings.TrimSpace(buf.String())
+	}
+
+	return message, nil
+}
@@ -0,0 +1,172 @@
+package pdfreport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report is a titled table rendered on A4 landscape pages with the standard
+// Helvetica fonts, which is all the compliance exports need.
+type Report struct {
+	Title    string
+	Subtitle []string
+	Columns  []Column
+	Rows     [][]string
+	Footer   string
+}
+
+type Column struct {
+	Header string
+	Width  float64
+}
+
+const (
+	pageWidth    = 842.0
+	pageHeight   = 595.0
+	margin       = 36.0
+	titleSize    = 14.0
+	textSize     = 9.0
+	rowSize      = 8.0
+	lineHeight   = 12.0
+	avgCharWidth = 0.5
+)
+
+func Render(w io.Writer, report Report) error {
+	pages := layout(report)
+
+	var objects []string
+	// 1: catalog, 2: page tree, 3: regular font, 4: bold font, then one page
+	// and one content stream per page.
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range pages {
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+i*2,
+		))
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func layout(report Report) []string {
+	var pages []string
+	var page strings.Builder
+	pageNumber := 0
+	y := 0.0
+
+	newPage := func() {
+		if pageNumber > 0 {
+			pages = append(pages, page.String())
+			page.Reset()
+		}
+		pageNumber++
+		y = pageHeight - margin
+
+		if pageNumber == 1 {
+			writeText(&page, "F2", titleSize, margin, y-titleSize, report.Title)
+			y -= titleSize + 8
+			for _, line := range report.Subtitle {
+				writeText(&page, "F1", textSize, margin, y-textSize, line)
+				y -= lineHeight
+			}
+			y -= 6
+		}
+
+		x := margin
+		for _, col := range report.Columns {
+			writeText(&page, "F2", rowSize, x, y-rowSize, fit(col.Header, col.Width, rowSize))
+			x += col.Width
+		}
+		y -= lineHeight
+		fmt.Fprintf(&page, "%.2f %.2f m %.2f %.2f l S\n", margin, y+3, pageWidth-margin, y+3)
+
+		if report.Footer != "" {
+			writeText(&page, "F1", rowSize, margin, margin-rowSize, fmt.Sprintf("%s - page %d", report.Footer, pageNumber))
+		}
+	}
+
+	newPage()
+	for _, row := range report.Rows {
+		if y-lineHeight < margin+lineHeight {
+			newPage()
+		}
+		x := margin
+		for i, col := range report.Columns {
+			if i < len(row) {
+				writeText(&page, "F1", rowSize, x, y-rowSize, fit(row[i], col.Width, rowSize))
+			}
+			x += col.Width
+		}
+		y -= lineHeight
+	}
+	pages = append(pages, page.String())
+
+	return pages
+}
+
+func writeText(b *strings.Builder, font string, size float64, x float64, y float64, text string) {
+	fmt.Fprintf(b, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(text))
+}
+
+// fit truncates text that would overflow its column, using Helvetica's
+// average glyph width as an approximation.
+func fit(text string, width float64, size float64) string {
+	maxChars := int((width - 4) / (size * avgCharWidth))
+	runes := []rune(text)
+	if maxChars <= 0 || len(runes) <= maxChars {
+		return text
+	}
+	if maxChars <= 3 {
+		return string(runes[:maxChars])
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
+
+// escape converts text to WinAnsi bytes and escapes PDF string delimiters.
+// Latin-1 covers Portuguese accents; anything else becomes '?'.
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
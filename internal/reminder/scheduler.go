@@ -0,0 +1,69 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"log"
+	"strings"
+	"time"
+)
+
+// Scheduler sends the appointment reminders that came due.
+type Scheduler interface {
+	// Start sends due reminders every interval until ctx is done.
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type scheduler struct {
+	usecase domain.ReminderUsecase
+}
+
+func NewScheduler(usecase domain.ReminderUsecase) Scheduler {
+	return &scheduler{usecase: usecase}
+}
+
+func (s *scheduler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := s.usecase.SendDue(ctx, time.Now())
+		if err != nil {
+			log.Printf("[ERROR] Reminder: %v\n", err)
+		}
+		if sent > 0 {
+			log.Printf("[REMINDER] Sent %d reminders\n", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ParseOffsets reads a comma-separated list of durations such as "24h,2h".
+// An empty spec returns nil so the defaults apply.
+func ParseOffsets(spec string) ([]time.Duration, error) {
+	var offsets []time.Duration
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		offset, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reminder offset %q: %w", part, err)
+		}
+		if offset < time.Minute {
+			return nil, fmt.Errorf("invalid reminder offset %q: must be at least one minute", part)
+		}
+		offsets = append(offsets, offset)
+	}
+
+	return offsets, nil
+}
@@ -0,0 +1,381 @@
+// Package rrule parses and expands the subset of RFC 5545 recurrence rules
+// used for appointment series: DAILY, WEEKLY and MONTHLY frequencies with
+// INTERVAL, COUNT, UNTIL, BYDAY and BYMONTHDAY, and the dates left out of a
+// series with EXDATE.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// ErrUnbounded is returned when expanding a rule with neither COUNT nor
+// UNTIL.
+var ErrUnbounded = errors.New("recurrence rule must end: set COUNT or UNTIL")
+
+// maxIterations bounds the expansion loop for rules that rarely match, such as
+// the 31st of every other month.
+const maxIterations = 10000
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Day is a BYDAY entry. Ordinal is only meaningful for MONTHLY rules, where
+// 2TU is the second Tuesday and -1FR the last Friday of the month; zero means
+// every such weekday.
+type Day struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []Day
+	ByMonthDay []int
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=12". The
+// "RRULE:" prefix is optional. A floating UNTIL, without a trailing Z, is
+// read in loc.
+func Parse(spec string, loc *time.Location) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	spec = strings.TrimPrefix(strings.TrimSpace(spec), "RRULE:")
+	if spec == "" {
+		return Rule{}, errors.New("recurrence rule is empty")
+	}
+
+	for _, part := range strings.Split(spec, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				return Rule{}, fmt.Errorf("unsupported FREQ %q, expected DAILY, WEEKLY or MONTHLY", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positive(name, value)
+		case "COUNT":
+			rule.Count, err = positive(name, value)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value, loc)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(value)
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return Rule{}, errors.New("only WKST=MO is supported")
+			}
+		default:
+			return Rule{}, fmt.Errorf("unsupported recurrence rule part %q", name)
+		}
+		if err != nil {
+			return Rule{}, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, errors.New("recurrence rule is missing FREQ")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, errors.New("COUNT and UNTIL can't be used together")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return Rule{}, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	if len(rule.ByMonthDay) > 0 && len(rule.ByDay) > 0 {
+		return Rule{}, errors.New("combining BYDAY and BYMONTHDAY isn't supported")
+	}
+	for _, day := range rule.ByDay {
+		if day.Ordinal != 0 && rule.Freq != Monthly {
+			return Rule{}, errors.New("BYDAY ordinals are only supported with FREQ=MONTHLY")
+		}
+	}
+
+	return rule, nil
+}
+
+// String formats the rule back into RFC 5545 syntax, with UNTIL in UTC.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (d Day) String() string {
+	for name, weekday := range weekdays {
+		if weekday == d.Weekday {
+			if d.Ordinal != 0 {
+				return strconv.Itoa(d.Ordinal) + name
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+// All expands the rule from dtstart and returns the occurrences in order.
+// As RFC 5545 requires, dtstart is always the first occurrence and counts
+// toward COUNT, even when it doesn't match BYDAY or BYMONTHDAY; candidates
+// before it are skipped. Occurrences keep dtstart's wall-clock time in its
+// location, so a 09:00 series stays at 09:00 across DST changes; see
+// domain.WallClock for times a change skips or repeats. It fails
+// when the rule is unbounded or yields more than limit occurrences.
+func (r Rule) All(dtstart time.Time, limit int) ([]time.Time, error) {
+	if r.Count == 0 && r.Until.IsZero() {
+		return nil, ErrUnbounded
+	}
+	if !r.Until.IsZero() && dtstart.After(r.Until) {
+		return nil, nil
+	}
+
+	occurrences := []time.Time{dtstart}
+	if r.Count == 1 {
+		return occurrences, nil
+	}
+
+	for i := 0; i < maxIterations; i++ {
+		for _, occurrence := range r.period(dtstart, i) {
+			if !occurrence.After(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && occurrence.After(r.Until) {
+				return occurrences, nil
+			}
+			if len(occurrences) == limit {
+				return nil, fmt.Errorf("recurrence rule yields more than %d occurrences", limit)
+			}
+			occurrences = append(occurrences, occurrence)
+			if r.Count > 0 && len(occurrences) == r.Count {
+				return occurrences, nil
+			}
+		}
+	}
+
+	return occurrences, nil
+}
+
+// ExDates is the set of dates, as YYYY-MM-DD, whose occurrences are left out
+// of a series. As with EXDATE in RFC 5545, excluded occurrences still count
+// toward COUNT.
+type ExDates map[string]bool
+
+func NewExDates(dates []string) ExDates {
+	exDates := ExDates{}
+	for _, date := range dates {
+		exDates[date] = true
+	}
+	return exDates
+}
+
+// Excludes reports whether the occurrence falls on an excluded date, in the
+// occurrence's own location.
+func (e ExDates) Excludes(occurrence time.Time) bool {
+	return e[occurrence.Format("2006-01-02")]
+}
+
+// period returns the candidate occurrences of the i-th interval after
+// dtstart, in order.
+func (r Rule) period(dtstart time.Time, i int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return domain.WallClock(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Location())
+	}
+
+	switch r.Freq {
+	case Daily:
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+i*r.Interval)
+		if len(r.ByDay) > 0 && !r.matchesWeekday(day.Weekday()) {
+			return nil
+		}
+		return []time.Time{day}
+
+	case Weekly:
+		// Weeks start on Monday, as with the default WKST=MO.
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+7*i*r.Interval)
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(monday.Year(), monday.Month(), monday.Day()+offset)}
+		}
+		var days []time.Time
+		for d := 0; d < 7; d++ {
+			day := at(monday.Year(), monday.Month(), monday.Day()+d)
+			if r.matchesWeekday(day.Weekday()) {
+				days = append(days, day)
+			}
+		}
+		return days
+
+	default:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(i*r.Interval), 1, 0, 0, 0, 0, dtstart.Location())
+		year, month := first.Year(), first.Month()
+		length := time.Date(year, month+1, 0, 0, 0, 0, 0, dtstart.Location()).Day()
+
+		var monthDays []int
+		switch {
+		case len(r.ByDay) > 0:
+			for _, day := range r.ByDay {
+				monthDays = append(monthDays, weekdaysInMonth(first, length, day)...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for _, day := range r.ByMonthDay {
+				if day < 0 {
+					day = length + day + 1
+				}
+				// Days the month doesn't have are skipped, as RFC 5545 requires.
+				if day >= 1 && day <= length {
+					monthDays = append(monthDays, day)
+				}
+			}
+		default:
+			if dtstart.Day() <= length {
+				monthDays = append(monthDays, dtstart.Day())
+			}
+		}
+
+		sort.Ints(monthDays)
+		var days []time.Time
+		for i, day := range monthDays {
+			if i > 0 && day == monthDays[i-1] {
+				continue
+			}
+			days = append(days, at(year, month, day))
+		}
+		return days
+	}
+}
+
+func (r Rule) matchesWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// weekdaysInMonth returns the days of the month starting on first that match
+// day, honouring its ordinal.
+func weekdaysInMonth(first time.Time, length int, day Day) []int {
+	var matches []int
+	for d := 1 + (int(day.Weekday)-int(first.Weekday())+7)%7; d <= length; d += 7 {
+		matches = append(matches, d)
+	}
+
+	switch {
+	case day.Ordinal > 0 && day.Ordinal <= len(matches):
+		return []int{matches[day.Ordinal-1]}
+	case day.Ordinal < 0 && -day.Ordinal <= len(matches):
+		return []int{matches[len(matches)+day.Ordinal]}
+	case day.Ordinal != 0:
+		return nil
+	}
+	return matches
+}
+
+func positive(name string, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, value)
+	}
+	return n, nil
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	// A date-only UNTIL includes the whole day.
+	if t, err := time.ParseInLocation("20060102", value, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q, expected YYYYMMDD or YYYYMMDDTHHMMSSZ", value)
+}
+
+func parseByDay(value string) ([]Day, error) {
+	var days []Day
+	for _, entry := range strings.Split(strings.ToUpper(value), ",") {
+		if len(entry) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY entry %q", entry)
+		}
+		weekday, ok := weekdays[entry[len(entry)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY weekday %q", entry)
+		}
+		day := Day{Weekday: weekday}
+		if ordinal := entry[:len(entry)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY ordinal %q", entry)
+			}
+			day.Ordinal = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, entry := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(entry)
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, fmt.Errorf("invalid BYMONTHDAY %q", entry)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
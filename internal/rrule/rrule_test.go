@@ -0,0 +1,333 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=12", want: "FREQ=WEEKLY;COUNT=12;BYDAY=MO,TH"},
+		{spec: "RRULE:freq=daily;interval=2;count=5", want: "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{spec: "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T235959Z", want: "FREQ=MONTHLY;UNTIL=20261231T235959Z;BYDAY=-1FR"},
+		{spec: "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=6", want: "FREQ=MONTHLY;COUNT=6;BYMONTHDAY=31"},
+		{spec: "", wantErr: true},
+		{spec: "COUNT=3", wantErr: true},
+		{spec: "FREQ=YEARLY;COUNT=3", wantErr: true},
+		{spec: "FREQ=DAILY;COUNT=0", wantErr: true},
+		{spec: "FREQ=DAILY;COUNT=3;UNTIL=20261231", wantErr: true},
+		{spec: "FREQ=WEEKLY;BYMONTHDAY=1;COUNT=3", wantErr: true},
+		{spec: "FREQ=WEEKLY;BYDAY=2TU;COUNT=3", wantErr: true},
+		{spec: "FREQ=MONTHLY;BYDAY=MO;BYMONTHDAY=1;COUNT=3", wantErr: true},
+		{spec: "FREQ=WEEKLY;BYDAY=XX;COUNT=3", wantErr: true},
+		{spec: "FREQ=MONTHLY;BYMONTHDAY=32;COUNT=3", wantErr: true},
+		{spec: "FREQ=WEEKLY;WKST=SU;COUNT=3", wantErr: true},
+		{spec: "FREQ=DAILY;BYHOUR=9;COUNT=3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			rule, err := Parse(tt.spec, time.UTC)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %q, want an error", tt.spec, rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "FREQ=DAILY;UNTIL=20260310T120000Z", want: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)},
+		{spec: "FREQ=DAILY;UNTIL=20260310T120000", want: time.Date(2026, 3, 10, 12, 0, 0, 0, loc)},
+		{spec: "FREQ=DAILY;UNTIL=20260310", want: time.Date(2026, 3, 10, 23, 59, 59, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.spec, loc)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if !rule.Until.Equal(tt.want) {
+			t.Errorf("Parse(%q).Until = %v, want %v", tt.spec, rule.Until, tt.want)
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		spec    string
+		dtstart time.Time
+		want    []time.Time
+	}{
+		{
+			name:    "daily count",
+			spec:    "FREQ=DAILY;COUNT=3",
+			dtstart: day(time.March, 30),
+			want:    []time.Time{day(time.March, 30), day(time.March, 31), day(time.April, 1)},
+		},
+		{
+			name:    "daily interval",
+			spec:    "FREQ=DAILY;INTERVAL=3;COUNT=3",
+			dtstart: day(time.January, 1),
+			want:    []time.Time{day(time.January, 1), day(time.January, 4), day(time.January, 7)},
+		},
+		{
+			name:    "until is inclusive",
+			spec:    "FREQ=DAILY;UNTIL=20260103T090000Z",
+			dtstart: day(time.January, 1),
+			want:    []time.Time{day(time.January, 1), day(time.January, 2), day(time.January, 3)},
+		},
+		{
+			name:    "date-only until covers the day",
+			spec:    "FREQ=WEEKLY;UNTIL=20260115",
+			dtstart: day(time.January, 1),
+			want:    []time.Time{day(time.January, 1), day(time.January, 8), day(time.January, 15)},
+		},
+		{
+			name:    "weekly byday",
+			spec:    "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4",
+			dtstart: day(time.January, 5),
+			want:    []time.Time{day(time.January, 5), day(time.January, 8), day(time.January, 12), day(time.January, 15)},
+		},
+		{
+			name:    "every other week",
+			spec:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=WE;COUNT=3",
+			dtstart: day(time.January, 7),
+			want:    []time.Time{day(time.January, 7), day(time.January, 21), day(time.February, 4)},
+		},
+		{
+			name:    "daily byday skips the weekend",
+			spec:    "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;COUNT=3",
+			dtstart: day(time.January, 8),
+			want:    []time.Time{day(time.January, 8), day(time.January, 9), day(time.January, 12)},
+		},
+		{
+			name:    "monthly on the 31st skips short months",
+			spec:    "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4",
+			dtstart: day(time.January, 31),
+			want:    []time.Time{day(time.January, 31), day(time.March, 31), day(time.May, 31), day(time.July, 31)},
+		},
+		{
+			name:    "monthly without byday keeps dtstart's day",
+			spec:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: day(time.August, 31),
+			want:    []time.Time{day(time.August, 31), day(time.October, 31), day(time.December, 31)},
+		},
+		{
+			name:    "last day of the month",
+			spec:    "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			dtstart: day(time.January, 31),
+			want:    []time.Time{day(time.January, 31), day(time.February, 28), day(time.March, 31)},
+		},
+		{
+			name:    "second tuesday",
+			spec:    "FREQ=MONTHLY;BYDAY=2TU;COUNT=3",
+			dtstart: day(time.January, 13),
+			want:    []time.Time{day(time.January, 13), day(time.February, 10), day(time.March, 10)},
+		},
+		{
+			name:    "dtstart off the byday pattern counts as the first occurrence",
+			spec:    "FREQ=WEEKLY;BYDAY=MO;COUNT=3",
+			dtstart: day(time.January, 7),
+			want:    []time.Time{day(time.January, 7), day(time.January, 12), day(time.January, 19)},
+		},
+		{
+			name:    "dtstart off the bymonthday pattern counts as the first occurrence",
+			spec:    "FREQ=MONTHLY;BYMONTHDAY=15;COUNT=2",
+			dtstart: day(time.January, 10),
+			want:    []time.Time{day(time.January, 10), day(time.January, 15)},
+		},
+		{
+			name:    "count of one is dtstart alone",
+			spec:    "FREQ=WEEKLY;BYDAY=MO;COUNT=1",
+			dtstart: day(time.January, 7),
+			want:    []time.Time{day(time.January, 7)},
+		},
+		{
+			name:    "until before dtstart",
+			spec:    "FREQ=DAILY;UNTIL=20251231",
+			dtstart: day(time.January, 1),
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.spec, time.UTC)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			got, err := rule.All(tt.dtstart, 100)
+			if err != nil {
+				t.Fatalf("All: %v", err)
+			}
+			assertTimes(t, got, tt.want)
+		})
+	}
+}
+
+func TestAllAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name    string
+		spec    string
+		dtstart time.Time
+		want    []string
+	}{
+		{
+			name:    "local time kept across spring forward",
+			spec:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2026, time.March, 7, 9, 0, 0, 0, newYork),
+			want:    []string{"2026-03-07T09:00:00-05:00", "2026-03-08T09:00:00-04:00", "2026-03-09T09:00:00-04:00"},
+		},
+		{
+			name:    "local time kept across fall back",
+			spec:    "FREQ=WEEKLY;COUNT=2",
+			dtstart: time.Date(2026, time.October, 25, 9, 0, 0, 0, newYork),
+			want:    []string{"2026-10-25T09:00:00-04:00", "2026-11-01T09:00:00-05:00"},
+		},
+		{
+			name:    "skipped time moves forward by the gap",
+			spec:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2026, time.March, 7, 2, 30, 0, 0, newYork),
+			want:    []string{"2026-03-07T02:30:00-05:00", "2026-03-08T03:30:00-04:00", "2026-03-09T02:30:00-04:00"},
+		},
+		{
+			name:    "repeated time is the first one",
+			spec:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2026, time.October, 31, 1, 30, 0, 0, newYork),
+			want:    []string{"2026-10-31T01:30:00-04:00", "2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		},
+		{
+			name:    "southern skipped time moves forward by the gap",
+			spec:    "FREQ=WEEKLY;BYDAY=SU;COUNT=3",
+			dtstart: time.Date(2026, time.September, 27, 2, 30, 0, 0, sydney),
+			want:    []string{"2026-09-27T02:30:00+10:00", "2026-10-04T03:30:00+11:00", "2026-10-11T02:30:00+11:00"},
+		},
+		{
+			name:    "southern repeated time is the first one",
+			spec:    "FREQ=MONTHLY;BYDAY=1SU;COUNT=2",
+			dtstart: time.Date(2026, time.March, 1, 2, 30, 0, 0, sydney),
+			want:    []string{"2026-03-01T02:30:00+11:00", "2026-04-05T02:30:00+11:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.spec, tt.dtstart.Location())
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			got, err := rule.All(tt.dtstart, 100)
+			if err != nil {
+				t.Fatalf("All: %v", err)
+			}
+			var want []time.Time
+			for _, value := range tt.want {
+				want = append(want, at(value))
+			}
+			assertTimes(t, got, want)
+		})
+	}
+}
+
+func TestAllErrors(t *testing.T) {
+	dtstart := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	rule, _ := Parse("FREQ=DAILY", time.UTC)
+	if _, err := rule.All(dtstart, 100); err != ErrUnbounded {
+		t.Errorf("All of an unbounded rule: got %v, want ErrUnbounded", err)
+	}
+
+	rule, _ = Parse("FREQ=DAILY;COUNT=10", time.UTC)
+	if _, err := rule.All(dtstart, 5); err == nil {
+		t.Error("All past the limit: got no error")
+	}
+}
+
+func TestExDates(t *testing.T) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	rule, err := Parse("FREQ=WEEKLY;COUNT=4", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	occurrences, err := rule.All(time.Date(2026, time.January, 6, 22, 0, 0, 0, loc), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 22:00 in São Paulo is already the next day in UTC: dates are compared
+	// in the occurrence's location.
+	excluded := NewExDates([]string{"2026-01-13", "2026-01-21"})
+	var kept []time.Time
+	for _, occurrence := range occurrences {
+		if !excluded.Excludes(occurrence) {
+			kept = append(kept, occurrence)
+		}
+	}
+
+	// Excluded occurrences still count toward COUNT.
+	assertTimes(t, kept, []time.Time{
+		time.Date(2026, time.January, 6, 22, 0, 0, 0, loc),
+		time.Date(2026, time.January, 20, 22, 0, 0, 0, loc),
+		time.Date(2026, time.January, 27, 22, 0, 0, 0, loc),
+	})
+}
+
+func assertTimes(t *testing.T, got []time.Time, want []time.Time) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d occurrences %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
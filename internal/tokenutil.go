@@ -99,4 +99,38 @@ func ExtractRoleFromToken(requestToken string, secret string) (string, error) {
 	}
 
 	return claims["role"].(string), nil
-}
\ No newline at end of file
+}
+func CreateReminderToken(appointment *domain.Appointment, action string, secret string) (string, error) {
+	claims := &domain.ReminderClaims{
+		AppointmentID: appointment.ID,
+		PatientID:     appointment.PatientID,
+		Sequence:      appointment.Sequence,
+		Action:        action,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(appointment.AppointmentDate.UTC()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+func ParseReminderToken(requestToken string, secret string) (*domain.ReminderClaims, error) {
+	claims := &domain.ReminderClaims{}
+	token, err := jwt.ParseWithClaims(requestToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("Invalid Token")
+	}
+
+	return claims, nil
+}
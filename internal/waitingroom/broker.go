@@ -0,0 +1,108 @@
+package waitingroom
+
+import (
+	"hms-api/domain"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHistorySize is how many events are kept for replay when no size
+	// is given.
+	DefaultHistorySize = 500
+	// subscriberBuffer is how many events a subscriber can fall behind before
+	// it's dropped and has to reconnect with Last-Event-ID.
+	subscriberBuffer = 64
+)
+
+// Broker fans waiting room events out to the open streams and keeps the most
+// recent ones so reconnecting clients can catch up. Events only live in this
+// process's memory.
+type Broker interface {
+	// Publish assigns the event the next ID and sends it to every subscriber.
+	Publish(event domain.WaitingRoomEvent) domain.WaitingRoomEvent
+	// Subscribe returns the kept events after lastEventID and a channel of
+	// new events. When lastEventID is older than every kept event, all of
+	// them are replayed.
+	Subscribe(lastEventID int64) (replay []domain.WaitingRoomEvent, events <-chan domain.WaitingRoomEvent, unsubscribe func())
+}
+
+type broker struct {
+	mu          sync.Mutex
+	lastID      int64
+	history     []domain.WaitingRoomEvent
+	size        int
+	subscribers map[chan domain.WaitingRoomEvent]struct{}
+}
+
+func NewBroker(size int) Broker {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+
+	return &broker{
+		// IDs start from the clock so they keep increasing across restarts,
+		// and a client resuming with an ID from before one doesn't skip the
+		// events published since.
+		lastID:      time.Now().UnixMicro(),
+		history:     make([]domain.WaitingRoomEvent, 0, size),
+		size:        size,
+		subscribers: make(map[chan domain.WaitingRoomEvent]struct{}),
+	}
+}
+
+func (b *broker) Publish(event domain.WaitingRoomEvent) domain.WaitingRoomEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+
+	if len(b.history) == b.size {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:b.size-1]
+	}
+	b.history = append(b.history, event)
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			// Too far behind: closing makes the client reconnect and replay
+			// what it missed instead of blocking everyone else.
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+
+	return event
+}
+
+func (b *broker) Subscribe(lastEventID int64) ([]domain.WaitingRoomEvent, <-chan domain.WaitingRoomEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []domain.WaitingRoomEvent
+	if lastEventID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastEventID {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	subscriber := make(chan domain.WaitingRoomEvent, subscriberBuffer)
+	b.subscribers[subscriber] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[subscriber]; ok {
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+
+	return replay, subscriber, unsubscribe
+}
@@ -0,0 +1,44 @@
+package waitlist
+
+import (
+	"context"
+	"hms-api/domain"
+	"log"
+	"time"
+)
+
+// Expirer closes waitlist offers whose hold ran out so the slot moves on to
+// the next patient in line.
+type Expirer interface {
+	// Start expires offers every interval until ctx is done.
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type expirer struct {
+	usecase domain.WaitlistUsecase
+}
+
+func NewExpirer(usecase domain.WaitlistUsecase) Expirer {
+	return &expirer{usecase: usecase}
+}
+
+func (e *expirer) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := e.usecase.ExpireOffers(ctx)
+		if err != nil {
+			log.Printf("[ERROR] Waitlist: %v\n", err)
+		}
+		if expired > 0 {
+			log.Printf("[WAITLIST] Expired %d offers\n", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const absenceColumns = `id, doctor_id, kind, starts_at, ends_at, COALESCE(reason, ''), created_by, created_at`
+
+type absenceRepository struct {
+	database *sql.DB
+}
+
+func NewAbsenceRepository(db *sql.DB) domain.AbsenceRepository {
+	return &absenceRepository{
+		database: db,
+	}
+}
+
+func (ar *absenceRepository) Create(c context.Context, absence *domain.DoctorAbsence) error {
+	query := `
+		INSERT INTO doctor_absences (doctor_id, kind, starts_at, ends_at, reason, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING ` + absenceColumns + `
+	`
+	err := scanAbsence(ar.database.QueryRowContext(c, query,
+		absence.DoctorID,
+		absence.Kind,
+		absence.StartsAt,
+		absence.EndsAt,
+		absence.Reason,
+		absence.CreatedBy,
+	), absence)
+	if err != nil {
+		return fmt.Errorf("error creating absence: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *absenceRepository) FetchByDoctorID(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]domain.DoctorAbsence, error) {
+	query := `
+		SELECT ` + absenceColumns + `
+		FROM doctor_absences
+		WHERE doctor_id = $1 AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at
+	`
+	rows, err := ar.database.QueryContext(c, query, doctorID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching absences: %w", err)
+	}
+	defer rows.Close()
+
+	var absences []domain.DoctorAbsence
+	for rows.Next() {
+		var absence domain.DoctorAbsence
+		if err := scanAbsence(rows, &absence); err != nil {
+			return nil, fmt.Errorf("error scanning absence: %w", err)
+		}
+		absences = append(absences, absence)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating absences: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (ar *absenceRepository) FetchByID(c context.Context, doctorID uuid.UUID, id uuid.UUID) (domain.DoctorAbsence, error) {
+	query := `
+		SELECT ` + absenceColumns + `
+		FROM doctor_absences
+		WHERE id = $1 AND doctor_id = $2
+	`
+	var absence domain.DoctorAbsence
+	err := scanAbsence(ar.database.QueryRowContext(c, query, id, doctorID), &absence)
+	if err == sql.ErrNoRows {
+		return domain.DoctorAbsence{}, domain.ErrAbsenceNotFound
+	}
+	if err != nil {
+		return domain.DoctorAbsence{}, fmt.Errorf("error fetching absence: %w", err)
+	}
+
+	return absence, nil
+}
+
+func (ar *absenceRepository) Delete(c context.Context, doctorID uuid.UUID, id uuid.UUID) error {
+	result, err := ar.database.ExecContext(c, `DELETE FROM doctor_absences WHERE id = $1 AND doctor_id = $2`, id, doctorID)
+	if err != nil {
+		return fmt.Errorf("error deleting absence: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting absence: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrAbsenceNotFound
+	}
+
+	return nil
+}
+
+func scanAbsence(row interface{ Scan(...interface{}) error }, absence *domain.DoctorAbsence) error {
+	return row.Scan(
+		&absence.ID,
+		&absence.DoctorID,
+		&absence.Kind,
+		&absence.StartsAt,
+		&absence.EndsAt,
+		&absence.Reason,
+		&absence.CreatedBy,
+		&absence.CreatedAt,
+	)
+}
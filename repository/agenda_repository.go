@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type agendaRepository struct {
+	database *sql.DB
+}
+
+func NewAgendaRepository(db *sql.DB) domain.AgendaRepository {
+	return &agendaRepository{
+		database: db,
+	}
+}
+
+// FetchByDoctorID reads the agenda in one query, each patient's allergies,
+// last visit and prescriptions being joined laterally.
+func (ar *agendaRepository) FetchByDoctorID(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time, prescribedSince time.Time) ([]domain.AgendaEntry, error) {
+	query := `
+		WITH a AS (
+			SELECT ` + appointmentColumns + `
+			FROM appointments
+			WHERE doctor_id = $1 AND status <> 'canceled' AND appointment_date >= $2 AND appointment_date < $3
+		)
+		SELECT a.*, pu.username, p.date_birth,
+			COALESCE(al.allergies, '[]'),
+			lv.appointment_date, COALESCE(lv.doctor_name, ''),
+			mr.created_at, COALESCE(mr.doctor_name, ''), COALESCE(mr.diagnosis, ''), COALESCE(mr.treatment, ''),
+			COALESCE(rx.prescriptions, '[]')
+		FROM a
+		JOIN patients p ON p.id = a.patient_id
+		JOIN users pu ON pu.id = p.user_id
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'substance', pa.substance, 'reaction', COALESCE(pa.reaction, ''), 'severity', pa.severity
+			) ORDER BY pa.substance) AS allergies
+			FROM patient_allergies pa
+			WHERE pa.patient_id = a.patient_id AND pa.status = 'active'
+		) al ON true
+		LEFT JOIN LATERAL (
+			SELECT v.appointment_date, vu.username AS doctor_name
+			FROM appointments v
+			JOIN doctors vd ON vd.id = v.doctor_id
+			JOIN users vu ON vu.id = vd.user_id
+			WHERE v.patient_id = a.patient_id AND v.status = 'completed' AND v.appointment_date < $2
+			ORDER BY v.appointment_date DESC
+			LIMIT 1
+		) lv ON true
+		LEFT JOIN LATERAL (
+			SELECT m.created_at, mu.username AS doctor_name, m.diagnosis, m.treatment
+			FROM medical_records m
+			JOIN doctors md ON md.id = m.doctor_id
+			JOIN users mu ON mu.id = md.user_id
+			WHERE m.patient_id = a.patient_id AND m.status = 'active' AND m.created_at < $2
+			ORDER BY m.created_at DESC
+			LIMIT 1
+		) mr ON true
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'prescription_id', rp.id, 'doctor_id', rp.doctor_id,
+				'medication_details', rp.medication_details, 'created_at', rp.created_at
+			) ORDER BY rp.created_at DESC) AS prescriptions
+			FROM prescriptions rp
+			WHERE rp.patient_id = a.patient_id AND rp.created_at >= $4
+		) rx ON true
+		ORDER BY a.appointment_date
+	`
+	rows, err := ar.database.QueryContext(c, query, doctorID, from, to, prescribedSince)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching agenda: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AgendaEntry
+	for rows.Next() {
+		entry, err := scanAgendaEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning agenda: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agenda: %w", err)
+	}
+
+	return entries, nil
+}
+
+func scanAgendaEntry(row interface{ Scan(...interface{}) error }) (domain.AgendaEntry, error) {
+	var (
+		entry                     domain.AgendaEntry
+		allergies, prescriptions  []byte
+		visitDate, recordDate     sql.NullTime
+		visitDoctor, recordDoctor string
+		diagnosis, treatment      string
+	)
+
+	err := scanAppointment(withExtraColumns(row,
+		&entry.PatientName,
+		&entry.PatientDateBirth,
+		&allergies,
+		&visitDate,
+		&visitDoctor,
+		&recordDate,
+		&recordDoctor,
+		&diagnosis,
+		&treatment,
+		&prescriptions,
+	), &entry.Appointment)
+	if err != nil {
+		return domain.AgendaEntry{}, err
+	}
+
+	if err := json.Unmarshal(allergies, &entry.Allergies); err != nil {
+		return domain.AgendaEntry{}, err
+	}
+	if err := json.Unmarshal(prescriptions, &entry.OutstandingPrescriptions); err != nil {
+		return domain.AgendaEntry{}, err
+	}
+
+	// Without a completed appointment, the latest record stands for the
+	// last visit.
+	switch {
+	case visitDate.Valid:
+		entry.LastVisit = &domain.AgendaLastVisit{Date: visitDate.Time, DoctorName: visitDoctor, Diagnosis: diagnosis, Treatment: treatment}
+	case recordDate.Valid:
+		entry.LastVisit = &domain.AgendaLastVisit{Date: recordDate.Time, DoctorName: recordDoctor, Diagnosis: diagnosis, Treatment: treatment}
+	}
+
+	return entry, nil
+}
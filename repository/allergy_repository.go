@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+)
+
+const allergyColumns = `id, patient_id, substance, kind, COALESCE(reaction, ''), severity, status, recorded_by, created_at, updated_at`
+
+type allergyRepository struct {
+	database *sql.DB
+}
+
+func NewAllergyRepository(db *sql.DB) domain.AllergyRepository {
+	return &allergyRepository{
+		database: db,
+	}
+}
+
+func (ar *allergyRepository) Create(c context.Context, allergy *domain.PatientAllergy) error {
+	query := `
+		INSERT INTO patient_allergies (patient_id, substance, kind, reaction, severity, status, recorded_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+		RETURNING ` + allergyColumns + `
+	`
+	err := scanAllergy(ar.database.QueryRowContext(c, query,
+		allergy.PatientID,
+		allergy.Substance,
+		allergy.Kind,
+		allergy.Reaction,
+		allergy.Severity,
+		allergy.Status,
+		allergy.RecordedBy,
+	), allergy)
+	if err != nil {
+		return fmt.Errorf("error creating allergy: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *allergyRepository) FetchByID(c context.Context, id uuid.UUID) (*domain.PatientAllergy, error) {
+	query := `
+		SELECT ` + allergyColumns + `
+		FROM patient_allergies
+		WHERE id = $1
+	`
+
+	allergy := &domain.PatientAllergy{}
+	err := scanAllergy(ar.database.QueryRowContext(c, query, id), allergy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching allergy: %w", err)
+	}
+
+	return allergy, nil
+}
+
+// FetchByPatientID lists the patient's allergies, active ones first.
+func (ar *allergyRepository) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.PatientAllergy, error) {
+	query := `
+		SELECT ` + allergyColumns + `
+		FROM patient_allergies
+		WHERE patient_id = $1
+		ORDER BY status <> 'active', substance
+	`
+	return ar.fetchAllergies(c, query, patientID)
+}
+
+func (ar *allergyRepository) FetchActiveByPatientID(c context.Context, patientID uuid.UUID) ([]domain.PatientAllergy, error) {
+	query := `
+		SELECT ` + allergyColumns + `
+		FROM patient_allergies
+		WHERE patient_id = $1 AND status = 'active'
+		ORDER BY substance
+	`
+	return ar.fetchAllergies(c, query, patientID)
+}
+
+func (ar *allergyRepository) Update(c context.Context, allergy *domain.PatientAllergy) error {
+	query := `
+		UPDATE patient_allergies
+		SET substance = $1, kind = $2, reaction = NULLIF($3, ''), severity = $4, status = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+		RETURNING ` + allergyColumns + `
+	`
+	err := scanAllergy(ar.database.QueryRowContext(c, query,
+		allergy.Substance,
+		allergy.Kind,
+		allergy.Reaction,
+		allergy.Severity,
+		allergy.Status,
+		allergy.ID,
+	), allergy)
+	if err == sql.ErrNoRows {
+		return domain.ErrAllergyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error updating allergy: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *allergyRepository) fetchAllergies(c context.Context, query string, args ...interface{}) ([]domain.PatientAllergy, error) {
+	rows, err := ar.database.QueryContext(c, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching allergies: %w", err)
+	}
+	defer rows.Close()
+
+	allergies := []domain.PatientAllergy{}
+	for rows.Next() {
+		var allergy domain.PatientAllergy
+		if err := scanAllergy(rows, &allergy); err != nil {
+			return nil, fmt.Errorf("error scanning allergy: %w", err)
+		}
+		allergies = append(allergies, allergy)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating allergies: %w", err)
+	}
+
+	return allergies, nil
+}
+
+func scanAllergy(row interface{ Scan(...interface{}) error }, allergy *domain.PatientAllergy) error {
+	return row.Scan(
+		&allergy.ID,
+		&allergy.PatientID,
+		&allergy.Substance,
+		&allergy.Kind,
+		&allergy.Reaction,
+		&allergy.Severity,
+		&allergy.Status,
+		&allergy.RecordedBy,
+		&allergy.CreatedAt,
+		&allergy.UpdatedAt,
+	)
+}
@@ -3,12 +3,35 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"hms-api/domain"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const appointmentColumns = `id, patient_id, doctor_id, appointment_date, end_date, status, COALESCE(notes, ''), series_id, type_id, COALESCE(cancel_reason, ''),
+		confirmed_at, checked_in_at, started_at, completed_at, canceled_at, no_show_at, ticket_number, sequence, created_at, updated_at, ` + appointmentTimezoneColumn
+
+// appointmentTimezoneColumn selects the timezone of the appointment's doctor's
+// facility.
+const appointmentTimezoneColumn = `COALESCE((
+		SELECT f.timezone FROM doctors d JOIN facilities f ON f.id = d.facility_id WHERE d.id = appointments.doctor_id
+	), '')`
+
+// appointmentTimestampColumns maps each status to the column recording when
+// the appointment entered it.
+var appointmentTimestampColumns = map[domain.AppointmentStatus]string{
+	domain.Confirmed:  "confirmed_at",
+	domain.CheckedIn:  "checked_in_at",
+	domain.InProgress: "started_at",
+	domain.Completed:  "completed_at",
+	domain.Canceled:   "canceled_at",
+	domain.NoShow:     "no_show_at",
+}
+
 type appointmentRepository struct {
 	database *sql.DB
 }
@@ -19,24 +42,25 @@ func NewAppointmentRepository(db *sql.DB) domain.AppointmentRepository {
 	}
 }
 
-func (ar *appointmentRepository) Create(c context.Context, appointment *domain.Appointment) error {
-	query := `
-		INSERT INTO appointments (patient_id, doctor_id, appointment_date, status, notes)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
-	err := ar.database.QueryRowContext(c, query, appointment.PatientID, appointment.DoctorID, appointment.AppointmentDate, appointment.Status, appointment.Notes).Scan(&appointment.ID)
+// Create inserts the appointment together with the first entry of its status
+// history.
+func (ar *appointmentRepository) Create(c context.Context, appointment *domain.Appointment, change *domain.AppointmentStatusChange) error {
+	tx, err := ar.database.BeginTx(c, nil)
 	if err != nil {
-		fmt.Println("Error executing query:", err)
+		return fmt.Errorf("error starting appointment creation: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = insertAppointment(c, tx, appointment, change); err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 func (ar *appointmentRepository) Fetch(c context.Context) ([]domain.Appointment, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, appointment_date, status, notes, created_at, updated_at
+		SELECT ` + appointmentColumns + `
 		FROM appointments
 	`
 	rows, err := ar.database.QueryContext(c, query)
@@ -51,16 +75,7 @@ func (ar *appointmentRepository) Fetch(c context.Context) ([]domain.Appointment,
 
 	for rows.Next() {
 		var appointment domain.Appointment
-		err = rows.Scan(
-			&appointment.ID,
-			&appointment.PatientID,
-			&appointment.DoctorID,
-			&appointment.AppointmentDate,
-			&appointment.Status,
-			&appointment.Notes,
-			&appointment.CreatedAt,
-			&appointment.UpdatedAt,
-		)
+		err = scanAppointment(rows, &appointment)
 
 		if err != nil {
 			fmt.Println("Error scanning row:", err)
@@ -80,21 +95,12 @@ func (ar *appointmentRepository) Fetch(c context.Context) ([]domain.Appointment,
 func (ar *appointmentRepository) FetchByID(c context.Context, id uuid.UUID) (domain.Appointment, error) {
 	var appointment domain.Appointment
 	query := `
-		SELECT id, patient_id, doctor_id, appointment_date, status, notes, created_at, updated_at
+		SELECT ` + appointmentColumns + `
 		FROM appointments
 		WHERE id = $1
 	`
 
-	err := ar.database.QueryRowContext(c, query, id).Scan(
-		&appointment.ID,
-		&appointment.PatientID,
-		&appointment.DoctorID,
-		&appointment.AppointmentDate,
-		&appointment.Status,
-		&appointment.Notes,
-		&appointment.CreatedAt,
-		&appointment.UpdatedAt,
-	)
+	err := scanAppointment(ar.database.QueryRowContext(c, query, id), &appointment)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -108,7 +114,7 @@ func (ar *appointmentRepository) FetchByID(c context.Context, id uuid.UUID) (dom
 
 func (ar *appointmentRepository) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.Appointment, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, appointment_date, status, notes, created_at, updated_at
+		SELECT ` + appointmentColumns + `
 		FROM appointments
 		WHERE patient_id = $1
 	` 
@@ -124,16 +130,7 @@ func (ar *appointmentRepository) FetchByPatientID(c context.Context, patientID u
 	var appointments []domain.Appointment
 	for rows.Next() {
 		var appointment domain.Appointment
-		err = rows.Scan(
-			&appointment.ID,
-			&appointment.PatientID,
-			&appointment.DoctorID,
-			&appointment.AppointmentDate,
-			&appointment.Status,
-			&appointment.Notes,
-			&appointment.CreatedAt,
-			&appointment.UpdatedAt,
-		)
+		err = scanAppointment(rows, &appointment)
 
 		if err != nil {
 			fmt.Println("Error scanning row:", err)
@@ -151,7 +148,7 @@ func (ar *appointmentRepository) FetchByPatientID(c context.Context, patientID u
 
 func (ar *appointmentRepository) FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]domain.Appointment, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, appointment_date, status, notes, created_at, updated_at
+		SELECT ` + appointmentColumns + `
 		FROM appointments
 		WHERE doctor_id = $1
 	` 
@@ -167,16 +164,7 @@ func (ar *appointmentRepository) FetchByDoctorID(c context.Context, doctorID uui
 	var appointments []domain.Appointment
 	for rows.Next() {
 		var appointment domain.Appointment
-		err = rows.Scan(
-			&appointment.ID,
-			&appointment.PatientID,
-			&appointment.DoctorID,
-			&appointment.AppointmentDate,
-			&appointment.Status,
-			&appointment.Notes,
-			&appointment.CreatedAt,
-			&appointment.UpdatedAt,
-		)
+		err = scanAppointment(rows, &appointment)
 
 		if err != nil {
 			fmt.Println("Error scanning row:", err)
@@ -192,23 +180,331 @@ func (ar *appointmentRepository) FetchByDoctorID(c context.Context, doctorID uui
 	return appointments, nil
 }
 
-func (ar *appointmentRepository) Update(c context.Context, appointment *domain.Appointment) error {
+// FetchActiveByDoctorIDBetween returns the doctor's appointments that aren't
+// canceled and overlap [from, to).
+func (ar *appointmentRepository) FetchActiveByDoctorIDBetween(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]domain.Appointment, error) {
+	return ar.fetchActiveBetween(c, "doctor_id", doctorID, from, to)
+}
+
+// FetchActiveByPatientIDBetween returns the patient's appointments that aren't
+// canceled and overlap [from, to).
+func (ar *appointmentRepository) FetchActiveByPatientIDBetween(c context.Context, patientID uuid.UUID, from time.Time, to time.Time) ([]domain.Appointment, error) {
+	return ar.fetchActiveBetween(c, "patient_id", patientID, from, to)
+}
+
+func (ar *appointmentRepository) fetchActiveBetween(c context.Context, column string, id uuid.UUID, from time.Time, to time.Time) ([]domain.Appointment, error) {
 	query := `
-		UPDATE appointments
-		SET patient_id = $1, doctor_id = $2, appointment_date = $3, status = $4, notes = $5
-		WHERE id = $6
+		SELECT ` + appointmentColumns + `
+		FROM appointments
+		WHERE ` + column + ` = $1 AND status NOT IN ('canceled', 'no_show') AND appointment_date < $3 AND end_date > $2
+		ORDER BY appointment_date
 	`
 
-	_, err := ar.database.ExecContext(c, query, appointment.PatientID, appointment.DoctorID, appointment.AppointmentDate, appointment.Status, appointment.Notes, appointment.ID)
+	rows, err := ar.database.QueryContext(c, query, id, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching appointments: %w", err)
+	}
+	defer rows.Close()
+
+	var appointments []domain.Appointment
+	for rows.Next() {
+		var appointment domain.Appointment
+		if err := scanAppointment(rows, &appointment); err != nil {
+			return nil, fmt.Errorf("error scanning appointment: %w", err)
+		}
+		appointments = append(appointments, appointment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+// Update changes the appointment's details. The status only changes through
+// UpdateStatus, so the stored one is read back into appointment.
+func (ar *appointmentRepository) UpdateNotes(c context.Context, appointment *domain.Appointment) error {
+	query := `
+		UPDATE appointments
+		SET notes = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING ` + appointmentColumns + `
+	`
 
+	err := scanAppointment(ar.database.QueryRowContext(c, query, appointment.Notes, appointment.ID), appointment)
+	if err == sql.ErrNoRows {
+		return domain.ErrAppointmentNotFound
+	}
 	if err != nil {
-		fmt.Println("Error executing update:", err)
-		return err
+		return fmt.Errorf("error updating appointment notes: %w", err)
 	}
 
 	return nil
 }
 
+// UpdateStatus moves the appointment to change.ToStatus if it is still in
+// from, stamps the matching timestamp column and appends change to its
+// history. It reports false when another request changed the status first.
+func (ar *appointmentRepository) UpdateStatus(c context.Context, appointment *domain.Appointment, from domain.AppointmentStatus, change *domain.AppointmentStatusChange) (bool, error) {
+	column, ok := appointmentTimestampColumns[change.ToStatus]
+	if !ok {
+		return false, fmt.Errorf("unknown appointment status: %s", change.ToStatus)
+	}
+
+	tx, err := ar.database.BeginTx(c, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting status update: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Check-ins get the next ticket number of the day. The lock keeps two
+	// check-ins at once from taking the same number.
+	if change.ToStatus == domain.CheckedIn {
+		if _, err = tx.ExecContext(c, `SELECT pg_advisory_xact_lock(hashtext('appointment_tickets'))`); err != nil {
+			return false, fmt.Errorf("error locking ticket numbers: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE appointments
+		SET status = $1, ` + column + ` = CURRENT_TIMESTAMP, sequence = sequence + 1, updated_at = CURRENT_TIMESTAMP,
+		    cancel_reason = CASE WHEN $1 = 'canceled' THEN $2 ELSE cancel_reason END,
+		    ticket_number = CASE WHEN $1 = 'checked_in' THEN (
+		        SELECT COALESCE(MAX(ticket_number), 0) + 1 FROM appointments WHERE checked_in_at >= date_trunc('day', CURRENT_TIMESTAMP)
+		    ) ELSE ticket_number END
+		WHERE id = $3 AND status = $4
+		RETURNING ` + appointmentColumns + `
+	`
+	err = scanAppointment(tx.QueryRowContext(c, query, change.ToStatus, change.Reason, appointment.ID, from), appointment)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error updating appointment status: %w", err)
+	}
+
+	change.AppointmentID = appointment.ID
+	change.FromStatus = from
+	if err = insertStatusChange(c, tx, change); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing status update: %w", err)
+	}
+
+	return true, nil
+}
+
+func (ar *appointmentRepository) FetchStatusHistory(c context.Context, appointmentID uuid.UUID) ([]domain.AppointmentStatusChange, error) {
+	query := `
+		SELECT id, appointment_id, COALESCE(from_status, ''), to_status, changed_by, COALESCE(changed_by_role, ''), COALESCE(reason, ''), changed_at
+		FROM appointment_status_history
+		WHERE appointment_id = $1
+		ORDER BY changed_at
+	`
+	rows, err := ar.database.QueryContext(c, query, appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching appointment history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []domain.AppointmentStatusChange
+	for rows.Next() {
+		var change domain.AppointmentStatusChange
+		var changedBy uuid.NullUUID
+		if err := rows.Scan(
+			&change.ID,
+			&change.AppointmentID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&changedBy,
+			&change.ChangedByRole,
+			&change.Reason,
+			&change.ChangedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning appointment history: %w", err)
+		}
+		change.ChangedBy = changedBy.UUID
+		history = append(history, change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating appointment history: %w", err)
+	}
+
+	return history, nil
+}
+
+// Reschedule locks the appointment, checks it still has one of statuses and
+// moves it to the new times. The reminders already sent are cleared so new
+// ones go out for the new time.
+func (ar *appointmentRepository) Reschedule(c context.Context, appointment *domain.Appointment, statuses []domain.AppointmentStatus, reschedule *domain.AppointmentReschedule) (bool, error) {
+	tx, err := ar.database.BeginTx(c, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting reschedule: %w", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := rescheduleAppointment(c, tx, appointment, statuses, reschedule)
+	if err != nil || !updated {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing reschedule: %w", err)
+	}
+
+	return true, nil
+}
+
+// rescheduleAppointment does Reschedule's work in tx, so series edits move
+// their occurrences the same way.
+func rescheduleAppointment(c context.Context, tx *sql.Tx, appointment *domain.Appointment, statuses []domain.AppointmentStatus, reschedule *domain.AppointmentReschedule) (bool, error) {
+	var current domain.AppointmentStatus
+	query := `
+		SELECT appointment_date, end_date, doctor_id, status
+		FROM appointments
+		WHERE id = $1
+		FOR UPDATE
+	`
+	err := tx.QueryRowContext(c, query, appointment.ID).Scan(&reschedule.PreviousStart, &reschedule.PreviousEnd, &reschedule.PreviousDoctorID, &current)
+	if err == sql.ErrNoRows {
+		return false, domain.ErrAppointmentNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("error locking appointment: %w", err)
+	}
+
+	allowed := false
+	for _, status := range statuses {
+		if status == current {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false, nil
+	}
+
+	if reschedule.NewDoctorID == uuid.Nil {
+		reschedule.NewDoctorID = reschedule.PreviousDoctorID
+	}
+
+	held, err := slotHeld(c, tx, &domain.Appointment{
+		PatientID:       appointment.PatientID,
+		DoctorID:        reschedule.NewDoctorID,
+		AppointmentDate: reschedule.NewStart,
+		EndDate:         reschedule.NewEnd,
+	})
+	if err != nil {
+		return false, err
+	}
+	if held {
+		return false, &domain.AppointmentConflictError{With: "doctor"}
+	}
+
+	query = `
+		UPDATE appointments
+		SET appointment_date = $1, end_date = $2, doctor_id = $3, sequence = sequence + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING ` + appointmentColumns + `
+	`
+	err = scanAppointment(tx.QueryRowContext(c, query, reschedule.NewStart, reschedule.NewEnd, reschedule.NewDoctorID, appointment.ID), appointment)
+	if err != nil {
+		return false, appointmentConflict(err)
+	}
+
+	query = `
+		INSERT INTO appointment_reschedules (appointment_id, previous_start, previous_end, new_start, new_end, previous_doctor_id, new_doctor_id, rescheduled_by, rescheduled_by_role, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''))
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(c, query,
+		appointment.ID,
+		reschedule.PreviousStart,
+		reschedule.PreviousEnd,
+		reschedule.NewStart,
+		reschedule.NewEnd,
+		reschedule.PreviousDoctorID,
+		reschedule.NewDoctorID,
+		nullableUUID(reschedule.RescheduledBy),
+		reschedule.RescheduledByRole,
+		reschedule.Reason,
+	).Scan(&reschedule.ID, &reschedule.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("error recording reschedule: %w", err)
+	}
+	reschedule.AppointmentID = appointment.ID
+
+	if _, err = tx.ExecContext(c, `DELETE FROM appointment_reminders WHERE appointment_id = $1`, appointment.ID); err != nil {
+		return false, fmt.Errorf("error clearing sent reminders: %w", err)
+	}
+
+	return true, nil
+}
+
+func (ar *appointmentRepository) FetchReschedules(c context.Context, appointmentID uuid.UUID) ([]domain.AppointmentReschedule, error) {
+	query := `
+		SELECT id, appointment_id, previous_start, previous_end, new_start, new_end, previous_doctor_id, new_doctor_id, rescheduled_by, COALESCE(rescheduled_by_role, ''), COALESCE(reason, ''), created_at
+		FROM appointment_reschedules
+		WHERE appointment_id = $1
+		ORDER BY created_at
+	`
+	rows, err := ar.database.QueryContext(c, query, appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reschedules: %w", err)
+	}
+	defer rows.Close()
+
+	var reschedules []domain.AppointmentReschedule
+	for rows.Next() {
+		var reschedule domain.AppointmentReschedule
+		var previousDoctorID, newDoctorID, rescheduledBy uuid.NullUUID
+		if err := rows.Scan(
+			&reschedule.ID,
+			&reschedule.AppointmentID,
+			&reschedule.PreviousStart,
+			&reschedule.PreviousEnd,
+			&reschedule.NewStart,
+			&reschedule.NewEnd,
+			&previousDoctorID,
+			&newDoctorID,
+			&rescheduledBy,
+			&reschedule.RescheduledByRole,
+			&reschedule.Reason,
+			&reschedule.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning reschedule: %w", err)
+		}
+		reschedule.PreviousDoctorID = previousDoctorID.UUID
+		reschedule.NewDoctorID = newDoctorID.UUID
+		reschedule.RescheduledBy = rescheduledBy.UUID
+		reschedules = append(reschedules, reschedule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reschedules: %w", err)
+	}
+
+	return reschedules, nil
+}
+
+func (ar *appointmentRepository) CountReschedules(c context.Context, appointmentID uuid.UUID, role domain.UserRole) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM appointment_reschedules
+		WHERE appointment_id = $1 AND rescheduled_by_role = $2
+	`
+	var count int
+	if err := ar.database.QueryRowContext(c, query, appointmentID, role).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting reschedules: %w", err)
+	}
+
+	return count, nil
+}
+
 func (ar *appointmentRepository) Delete(c context.Context, id uuid.UUID) error {
 	query := `
 		DELETE FROM appointments
@@ -223,3 +519,198 @@ func (ar *appointmentRepository) Delete(c context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// appointmentConflict turns a violation of the overlap exclusion constraints
+// into a domain conflict naming whose schedule clashed.
+func appointmentConflict(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23P01" {
+		return err
+	}
+
+	switch pqErr.Constraint {
+	case "appointments_patient_no_overlap":
+		return &domain.AppointmentConflictError{With: "patient"}
+	case "appointment_resources_no_overlap":
+		return &domain.AppointmentConflictError{With: "resource"}
+	}
+	return &domain.AppointmentConflictError{With: "doctor"}
+}
+
+// scanAppointment reads a row of appointmentColumns, localizing the
+// appointment to its facility's timezone.
+func scanAppointment(row interface{ Scan(...interface{}) error }, appointment *domain.Appointment) error {
+	var timezone string
+	err := row.Scan(
+		&appointment.ID,
+		&appointment.PatientID,
+		&appointment.DoctorID,
+		&appointment.AppointmentDate,
+		&appointment.EndDate,
+		&appointment.Status,
+		&appointment.Notes,
+		&appointment.SeriesID,
+		&appointment.TypeID,
+		&appointment.CancelReason,
+		&appointment.ConfirmedAt,
+		&appointment.CheckedInAt,
+		&appointment.StartedAt,
+		&appointment.CompletedAt,
+		&appointment.CanceledAt,
+		&appointment.NoShowAt,
+		&appointment.TicketNumber,
+		&appointment.Sequence,
+		&appointment.CreatedAt,
+		&appointment.UpdatedAt,
+		&timezone,
+	)
+	if err != nil {
+		return err
+	}
+
+	appointment.Localize(timezone)
+	return nil
+}
+
+// insertAppointment inserts the appointment, the resources its type needs and
+// the first entry of its status history within tx.
+func insertAppointment(c context.Context, tx *sql.Tx, appointment *domain.Appointment, change *domain.AppointmentStatusChange) error {
+	var seriesID interface{}
+	if appointment.SeriesID != nil {
+		seriesID = *appointment.SeriesID
+	}
+	var typeID interface{}
+	if appointment.TypeID != nil {
+		typeID = *appointment.TypeID
+	}
+
+	held, err := slotHeld(c, tx, appointment)
+	if err != nil {
+		return err
+	}
+	if held {
+		return &domain.AppointmentConflictError{With: "doctor"}
+	}
+
+	query := `
+		INSERT INTO appointments (patient_id, doctor_id, appointment_date, end_date, status, notes, series_id, type_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at, ` + appointmentTimezoneColumn + `
+	`
+	var timezone string
+	err = tx.QueryRowContext(c, query, appointment.PatientID, appointment.DoctorID, appointment.AppointmentDate, appointment.EndDate, appointment.Status, appointment.Notes, seriesID, typeID).Scan(&appointment.ID, &appointment.CreatedAt, &appointment.UpdatedAt, &timezone)
+	if err != nil {
+		fmt.Println("Error executing query:", err)
+		return appointmentConflict(err)
+	}
+	appointment.Localize(timezone)
+
+	if appointment.TypeID != nil {
+		if appointment.Resources, err = reserveResources(c, tx, appointment); err != nil {
+			return err
+		}
+	}
+
+	change.AppointmentID = appointment.ID
+	return insertStatusChange(c, tx, change)
+}
+
+// reserveResources reserves, for each resource category the appointment's
+// type requires, the first active resource of that category by name that is
+// free during the appointment.
+func reserveResources(c context.Context, tx *sql.Tx, appointment *domain.Appointment) ([]domain.AppointmentResource, error) {
+	var categories []string
+	err := tx.QueryRowContext(c, `SELECT required_resources FROM appointment_types WHERE id = $1`, *appointment.TypeID).Scan(pq.Array(&categories))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAppointmentTypeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching appointment type resources: %w", err)
+	}
+
+	query := `
+		INSERT INTO appointment_resources (appointment_id, resource_id, during)
+		SELECT $1, r.id, tstzrange($2, $3)
+		FROM resources r
+		WHERE r.category = $4 AND r.active
+			AND NOT EXISTS (
+				SELECT 1 FROM appointment_resources ar
+				WHERE ar.resource_id = r.id AND ar.active AND ar.during && tstzrange($2, $3)
+			)
+		ORDER BY r.name
+		LIMIT 1
+		RETURNING resource_id
+	`
+	for _, category := range categories {
+		var resourceID uuid.UUID
+		err := tx.QueryRowContext(c, query, appointment.ID, appointment.AppointmentDate, appointment.EndDate, category).Scan(&resourceID)
+		if err == sql.ErrNoRows {
+			return nil, &domain.AppointmentConflictError{With: "resource"}
+		}
+		if err != nil {
+			return nil, appointmentConflict(err)
+		}
+	}
+
+	return fetchAppointmentResources(c, tx, appointment.ID)
+}
+
+func (ar *appointmentRepository) FetchResources(c context.Context, appointmentID uuid.UUID) ([]domain.AppointmentResource, error) {
+	return fetchAppointmentResources(c, ar.database, appointmentID)
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(c context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func fetchAppointmentResources(c context.Context, db queryer, appointmentID uuid.UUID) ([]domain.AppointmentResource, error) {
+	query := `
+		SELECT r.id, r.name, r.kind, r.category
+		FROM appointment_resources ar
+		JOIN resources r ON r.id = ar.resource_id
+		WHERE ar.appointment_id = $1
+		ORDER BY r.kind DESC, r.name
+	`
+	rows, err := db.QueryContext(c, query, appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching appointment resources: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []domain.AppointmentResource
+	for rows.Next() {
+		var resource domain.AppointmentResource
+		if err := rows.Scan(&resource.ResourceID, &resource.Name, &resource.Kind, &resource.Category); err != nil {
+			return nil, fmt.Errorf("error scanning appointment resource: %w", err)
+		}
+		resources = append(resources, resource)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating appointment resources: %w", err)
+	}
+
+	return resources, nil
+}
+
+func insertStatusChange(c context.Context, tx *sql.Tx, change *domain.AppointmentStatusChange) error {
+	query := `
+		INSERT INTO appointment_status_history (appointment_id, from_status, to_status, changed_by, changed_by_role, reason)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, NULLIF($6, ''))
+		RETURNING id, changed_at
+	`
+	err := tx.QueryRowContext(c, query,
+		change.AppointmentID,
+		change.FromStatus,
+		change.ToStatus,
+		nullableUUID(change.ChangedBy),
+		change.ChangedByRole,
+		change.Reason,
+	).Scan(&change.ID, &change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("error recording appointment status change: %w", err)
+	}
+
+	return nil
+}
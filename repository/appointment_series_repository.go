@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const appointmentSeriesColumns = `id, patient_id, doctor_id, start_date, duration_minutes, rrule, exdates, COALESCE(notes, ''), status, created_by, created_at, updated_at`
+
+type appointmentSeriesRepository struct {
+	database *sql.DB
+}
+
+func NewAppointmentSeriesRepository(db *sql.DB) domain.AppointmentSeriesRepository {
+	return &appointmentSeriesRepository{
+		database: db,
+	}
+}
+
+// Create inserts the series and books its occurrences in one transaction, so
+// a clash with another appointment leaves nothing behind.
+func (sr *appointmentSeriesRepository) Create(c context.Context, series *domain.AppointmentSeries, appointments []domain.Appointment, change domain.AppointmentStatusChange) error {
+	tx, err := sr.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting series creation: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = insertSeries(c, tx, series); err != nil {
+		return err
+	}
+
+	for i := range appointments {
+		appointments[i].SeriesID = &series.ID
+		occurrenceChange := change
+		if err = insertAppointment(c, tx, &appointments[i], &occurrenceChange); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing series creation: %w", err)
+	}
+
+	return nil
+}
+
+func (sr *appointmentSeriesRepository) FetchByID(c context.Context, id uuid.UUID) (domain.AppointmentSeries, error) {
+	var series domain.AppointmentSeries
+	query := `
+		SELECT ` + appointmentSeriesColumns + `
+		FROM appointment_series
+		WHERE id = $1
+	`
+
+	err := scanSeries(sr.database.QueryRowContext(c, query, id), &series)
+	if err == sql.ErrNoRows {
+		return domain.AppointmentSeries{}, nil
+	}
+	if err != nil {
+		return domain.AppointmentSeries{}, fmt.Errorf("error fetching appointment series: %w", err)
+	}
+
+	return series, nil
+}
+
+func (sr *appointmentSeriesRepository) FetchOccurrences(c context.Context, seriesID uuid.UUID) ([]domain.Appointment, error) {
+	query := `
+		SELECT ` + appointmentColumns + `
+		FROM appointments
+		WHERE series_id = $1
+		ORDER BY appointment_date
+	`
+
+	rows, err := sr.database.QueryContext(c, query, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching series occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	var appointments []domain.Appointment
+	for rows.Next() {
+		var appointment domain.Appointment
+		if err := scanAppointment(rows, &appointment); err != nil {
+			return nil, fmt.Errorf("error scanning series occurrence: %w", err)
+		}
+		appointments = append(appointments, appointment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating series occurrences: %w", err)
+	}
+
+	return appointments, nil
+}
+
+// Update saves the series and the changed occurrences. When split is set it
+// is inserted first, for occurrences moved to it by a this-and-following edit.
+// Occurrences with a reschedule are moved through rescheduleAppointment, which
+// checks their status, keeps their history and clears their sent reminders.
+func (sr *appointmentSeriesRepository) Update(c context.Context, series *domain.AppointmentSeries, split *domain.AppointmentSeries, appointments []domain.Appointment, statuses []domain.AppointmentStatus, reschedules map[uuid.UUID]*domain.AppointmentReschedule) error {
+	tx, err := sr.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting series update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = updateSeries(c, tx, series); err != nil {
+		return err
+	}
+
+	if split != nil {
+		if err = insertSeries(c, tx, split); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE appointments
+		SET notes = $1, series_id = $2, sequence = sequence + $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+	for i := range appointments {
+		appointment := &appointments[i]
+		notes, seriesID := appointment.Notes, appointment.SeriesID
+		bump := 1
+		if reschedule := reschedules[appointment.ID]; reschedule != nil {
+			var updated bool
+			updated, err = rescheduleAppointment(c, tx, appointment, statuses, reschedule)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				return domain.ErrRescheduleNotAllowed
+			}
+			// The reschedule already bumped the sequence.
+			bump = 0
+		}
+
+		_, err = tx.ExecContext(c, query, notes, seriesID, bump, appointment.ID)
+		if err != nil {
+			return fmt.Errorf("error updating series occurrence: %w", err)
+		}
+		appointment.Notes, appointment.SeriesID = notes, seriesID
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing series update: %w", err)
+	}
+
+	return nil
+}
+
+// Cancel saves the series and cancels the given occurrences that can still be
+// canceled, recording each in its status history.
+func (sr *appointmentSeriesRepository) Cancel(c context.Context, series *domain.AppointmentSeries, appointmentIDs []uuid.UUID, change domain.AppointmentStatusChange) error {
+	tx, err := sr.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting series cancellation: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = updateSeries(c, tx, series); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(appointmentIDs))
+	for _, id := range appointmentIDs {
+		ids = append(ids, id.String())
+	}
+	var cancelable []string
+	for _, status := range domain.AppointmentTransitions["cancel"].From {
+		cancelable = append(cancelable, string(status))
+	}
+
+	query := `
+		WITH target AS (
+			SELECT id, status
+			FROM appointments
+			WHERE id = ANY($1::uuid[]) AND status = ANY($2::text[])
+			FOR UPDATE
+		), canceled AS (
+			UPDATE appointments
+			SET status = 'canceled', canceled_at = CURRENT_TIMESTAMP, sequence = sequence + 1, updated_at = CURRENT_TIMESTAMP, cancel_reason = $3
+			FROM target
+			WHERE appointments.id = target.id
+			RETURNING appointments.id, target.status AS from_status
+		)
+		INSERT INTO appointment_status_history (appointment_id, from_status, to_status, changed_by, changed_by_role, reason)
+		SELECT id, from_status, 'canceled', $4, $5, $3
+		FROM canceled
+	`
+	_, err = tx.ExecContext(c, query, pq.Array(ids), pq.Array(cancelable), change.Reason, nullableUUID(change.ChangedBy), change.ChangedByRole)
+	if err != nil {
+		return fmt.Errorf("error canceling series occurrences: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing series cancellation: %w", err)
+	}
+
+	return nil
+}
+
+func insertSeries(c context.Context, tx *sql.Tx, series *domain.AppointmentSeries) error {
+	query := `
+		INSERT INTO appointment_series (id, patient_id, doctor_id, start_date, duration_minutes, rrule, exdates, notes, status, created_by)
+		VALUES (COALESCE($1, gen_random_uuid()), $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+	err := tx.QueryRowContext(c, query,
+		nullableUUID(series.ID),
+		series.PatientID,
+		series.DoctorID,
+		series.StartDate,
+		series.DurationMinutes,
+		series.RRule,
+		pq.Array(exDates(series)),
+		series.Notes,
+		series.Status,
+		nullableUUID(series.CreatedBy),
+	).Scan(&series.ID, &series.CreatedAt, &series.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error inserting appointment series: %w", err)
+	}
+
+	return nil
+}
+
+func updateSeries(c context.Context, tx *sql.Tx, series *domain.AppointmentSeries) error {
+	query := `
+		UPDATE appointment_series
+		SET doctor_id = $1, start_date = $2, duration_minutes = $3, rrule = $4, exdates = $5, notes = $6, status = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+		RETURNING updated_at
+	`
+	err := tx.QueryRowContext(c, query,
+		series.DoctorID,
+		series.StartDate,
+		series.DurationMinutes,
+		series.RRule,
+		pq.Array(exDates(series)),
+		series.Notes,
+		series.Status,
+		series.ID,
+	).Scan(&series.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrSeriesNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error updating appointment series: %w", err)
+	}
+
+	return nil
+}
+
+// exDates keeps an empty list from being stored as NULL.
+func exDates(series *domain.AppointmentSeries) []string {
+	if series.ExDates == nil {
+		return []string{}
+	}
+	return series.ExDates
+}
+
+func scanSeries(row interface{ Scan(...interface{}) error }, series *domain.AppointmentSeries) error {
+	var createdBy uuid.NullUUID
+	err := row.Scan(
+		&series.ID,
+		&series.PatientID,
+		&series.DoctorID,
+		&series.StartDate,
+		&series.DurationMinutes,
+		&series.RRule,
+		pq.Array(&series.ExDates),
+		&series.Notes,
+		&series.Status,
+		&createdBy,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+	series.CreatedBy = createdBy.UUID
+	return err
+}
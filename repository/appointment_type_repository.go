@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const appointmentTypeColumns = `id, code, name, duration_minutes, telehealth, required_resources, active, created_at, updated_at`
+
+type appointmentTypeRepository struct {
+	database *sql.DB
+}
+
+func NewAppointmentTypeRepository(db *sql.DB) domain.AppointmentTypeRepository {
+	return &appointmentTypeRepository{
+		database: db,
+	}
+}
+
+func (tr *appointmentTypeRepository) Create(c context.Context, appointmentType *domain.AppointmentType) error {
+	query := `
+		INSERT INTO appointment_types (code, name, duration_minutes, telehealth, required_resources)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + appointmentTypeColumns + `
+	`
+	err := scanAppointmentType(tr.database.QueryRowContext(c, query,
+		appointmentType.Code,
+		appointmentType.Name,
+		appointmentType.DurationMinutes,
+		appointmentType.Telehealth,
+		pq.Array(appointmentType.RequiredResources),
+	), appointmentType)
+	if err != nil {
+		return appointmentTypeError("error creating appointment type", err)
+	}
+
+	return nil
+}
+
+func (tr *appointmentTypeRepository) Fetch(c context.Context) ([]domain.AppointmentType, error) {
+	query := `
+		SELECT ` + appointmentTypeColumns + `
+		FROM appointment_types
+		ORDER BY name
+	`
+	rows, err := tr.database.QueryContext(c, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching appointment types: %w", err)
+	}
+	defer rows.Close()
+
+	var appointmentTypes []domain.AppointmentType
+	for rows.Next() {
+		var appointmentType domain.AppointmentType
+		if err := scanAppointmentType(rows, &appointmentType); err != nil {
+			return nil, fmt.Errorf("error scanning appointment type: %w", err)
+		}
+		appointmentTypes = append(appointmentTypes, appointmentType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating appointment types: %w", err)
+	}
+
+	return appointmentTypes, nil
+}
+
+func (tr *appointmentTypeRepository) FetchByID(c context.Context, id uuid.UUID) (domain.AppointmentType, error) {
+	query := `
+		SELECT ` + appointmentTypeColumns + `
+		FROM appointment_types
+		WHERE id = $1
+	`
+	var appointmentType domain.AppointmentType
+	err := scanAppointmentType(tr.database.QueryRowContext(c, query, id), &appointmentType)
+	if err == sql.ErrNoRows {
+		return domain.AppointmentType{}, domain.ErrAppointmentTypeNotFound
+	}
+	if err != nil {
+		return domain.AppointmentType{}, fmt.Errorf("error fetching appointment type: %w", err)
+	}
+
+	return appointmentType, nil
+}
+
+// Update changes the type for future bookings. Appointments already booked
+// keep the resources reserved for them.
+func (tr *appointmentTypeRepository) Update(c context.Context, appointmentType *domain.AppointmentType) error {
+	query := `
+		UPDATE appointment_types
+		SET code = $1, name = $2, duration_minutes = $3, telehealth = $4, required_resources = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+		RETURNING ` + appointmentTypeColumns + `
+	`
+	err := scanAppointmentType(tr.database.QueryRowContext(c, query,
+		appointmentType.Code,
+		appointmentType.Name,
+		appointmentType.DurationMinutes,
+		appointmentType.Telehealth,
+		pq.Array(appointmentType.RequiredResources),
+		appointmentType.ID,
+	), appointmentType)
+	if err == sql.ErrNoRows {
+		return domain.ErrAppointmentTypeNotFound
+	}
+	if err != nil {
+		return appointmentTypeError("error updating appointment type", err)
+	}
+
+	return nil
+}
+
+func (tr *appointmentTypeRepository) Deactivate(c context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE appointment_types
+		SET active = false, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	result, err := tr.database.ExecContext(c, query, id)
+	if err != nil {
+		return false, fmt.Errorf("error deactivating appointment type: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error deactivating appointment type: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func scanAppointmentType(row interface{ Scan(...interface{}) error }, appointmentType *domain.AppointmentType) error {
+	var required []string
+	err := row.Scan(
+		&appointmentType.ID,
+		&appointmentType.Code,
+		&appointmentType.Name,
+		&appointmentType.DurationMinutes,
+		&appointmentType.Telehealth,
+		pq.Array(&required),
+		&appointmentType.Active,
+		&appointmentType.CreatedAt,
+		&appointmentType.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if required == nil {
+		required = []string{}
+	}
+	appointmentType.RequiredResources = required
+	return nil
+}
+
+func appointmentTypeError(message string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return domain.ErrDuplicateAppointmentType
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+)
+
+const attachmentColumns = `id, patient_id, medical_record_id, prescription_id, file_name, COALESCE(description, ''), content_type, size, sha256, storage_key, uploaded_by, created_at`
+
+type attachmentRepository struct {
+	database *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) domain.AttachmentRepository {
+	return &attachmentRepository{
+		database: db,
+	}
+}
+
+func (ar *attachmentRepository) Create(c context.Context, attachment *domain.Attachment) error {
+	query := `
+		INSERT INTO attachments (id, patient_id, medical_record_id, prescription_id, file_name, description, content_type, size, sha256, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11)
+		RETURNING created_at
+	`
+	err := ar.database.QueryRowContext(c, query,
+		attachment.ID,
+		attachment.PatientID,
+		attachment.MedicalRecordID,
+		attachment.PrescriptionID,
+		attachment.FileName,
+		attachment.Description,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.SHA256,
+		attachment.StorageKey,
+		attachment.UploadedBy,
+	).Scan(&attachment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating attachment: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *attachmentRepository) FetchByID(c context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE id = $1
+	`
+
+	attachment := &domain.Attachment{}
+	err := scanAttachment(ar.database.QueryRowContext(c, query, id), attachment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+func (ar *attachmentRepository) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE patient_id = $1
+		ORDER BY created_at DESC
+	`
+	return ar.fetchAttachments(c, query, patientID)
+}
+
+func (ar *attachmentRepository) FetchByMedicalRecordID(c context.Context, medicalRecordID uuid.UUID) ([]domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE medical_record_id = $1
+		ORDER BY created_at DESC
+	`
+	return ar.fetchAttachments(c, query, medicalRecordID)
+}
+
+func (ar *attachmentRepository) FetchByPrescriptionID(c context.Context, prescriptionID uuid.UUID) ([]domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE prescription_id = $1
+		ORDER BY created_at DESC
+	`
+	return ar.fetchAttachments(c, query, prescriptionID)
+}
+
+func (ar *attachmentRepository) fetchAttachments(c context.Context, query string, args ...interface{}) ([]domain.Attachment, error) {
+	rows, err := ar.database.QueryContext(c, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []domain.Attachment{}
+	for rows.Next() {
+		var attachment domain.Attachment
+		if err := scanAttachment(rows, &attachment); err != nil {
+			return nil, fmt.Errorf("error scanning attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func scanAttachment(row interface{ Scan(...interface{}) error }, attachment *domain.Attachment) error {
+	return row.Scan(
+		&attachment.ID,
+		&attachment.PatientID,
+		&attachment.MedicalRecordID,
+		&attachment.PrescriptionID,
+		&attachment.FileName,
+		&attachment.Description,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.SHA256,
+		&attachment.StorageKey,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+	)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type attendanceRepository struct {
+	database *sql.DB
+}
+
+func NewAttendanceRepository(db *sql.DB) domain.AttendanceRepository {
+	return &attendanceRepository{
+		database: db,
+	}
+}
+
+func (ar *attendanceRepository) MarkNoShows(c context.Context, cutoff time.Time, reason string) (int, error) {
+	query := `
+		WITH marked AS (
+			UPDATE appointments
+			SET status = 'no_show', no_show_at = CURRENT_TIMESTAMP, sequence = sequence + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE status = 'confirmed' AND end_date < $1
+			RETURNING id
+		)
+		INSERT INTO appointment_status_history (appointment_id, from_status, to_status, changed_by_role, reason)
+		SELECT id, 'confirmed', 'no_show', $2, $3
+		FROM marked
+	`
+	result, err := ar.database.ExecContext(c, query, cutoff, domain.SystemRole, reason)
+	if err != nil {
+		return 0, fmt.Errorf("error marking no-shows: %w", err)
+	}
+
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error counting marked no-shows: %w", err)
+	}
+
+	return int(marked), nil
+}
+
+// FetchAttendance counts the patient's appointments since since that were
+// no-shows, or that the patient canceled less than lateCancelWindow before
+// they started.
+func (ar *attendanceRepository) FetchAttendance(c context.Context, patientID uuid.UUID, since time.Time, lateCancelWindow time.Duration) (domain.PatientAttendance, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE a.status = 'no_show'),
+			COUNT(*) FILTER (WHERE a.status = 'canceled' AND EXISTS (
+				SELECT 1 FROM appointment_status_history h
+				WHERE h.appointment_id = a.id AND h.to_status = 'canceled' AND h.changed_by_role = 'patient'
+					AND h.changed_at > a.appointment_date - make_interval(secs => $3)
+			))
+		FROM appointments a
+		WHERE a.patient_id = $1 AND a.appointment_date >= $2
+	`
+	attendance := domain.PatientAttendance{PatientID: patientID, Since: since}
+
+	err := ar.database.QueryRowContext(c, query, patientID, since, lateCancelWindow.Seconds()).Scan(
+		&attendance.NoShows,
+		&attendance.LateCancellations,
+	)
+	if err != nil {
+		return domain.PatientAttendance{}, fmt.Errorf("error fetching patient attendance: %w", err)
+	}
+
+	return attendance, nil
+}
+
+// FetchNoShowRates counts, per doctor or per specialty, the appointments
+// between from and to the patient was expected at and how many were no-shows,
+// highest no-show count first.
+func (ar *attendanceRepository) FetchNoShowRates(c context.Context, groupBy string, from time.Time, to time.Time) ([]domain.NoShowRate, error) {
+	columns, group := "d.id, u.username, COALESCE(d.specialty, '')", "d.id, u.username, d.specialty"
+	if groupBy == domain.GroupBySpecialty {
+		columns, group = "NULL::uuid, '', COALESCE(d.specialty, '')", "COALESCE(d.specialty, '')"
+	}
+
+	query := `
+		SELECT ` + columns + `, COUNT(*), COUNT(*) FILTER (WHERE a.status = 'no_show') AS no_shows
+		FROM appointments a
+		JOIN doctors d ON d.id = a.doctor_id
+		JOIN users u ON u.id = d.user_id
+		WHERE a.status IN ('checked_in', 'in_progress', 'completed', 'no_show')
+			AND a.appointment_date >= $1 AND a.appointment_date < $2
+		GROUP BY ` + group + `
+		ORDER BY no_shows DESC, COUNT(*) DESC
+	`
+	rows, err := ar.database.QueryContext(c, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching no-show rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []domain.NoShowRate
+	for rows.Next() {
+		var rate domain.NoShowRate
+		var doctorID uuid.NullUUID
+		if err := rows.Scan(
+			&doctorID,
+			&rate.DoctorName,
+			&rate.Specialty,
+			&rate.Appointments,
+			&rate.NoShows,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning no-show rate: %w", err)
+		}
+		if doctorID.Valid {
+			rate.DoctorID = &doctorID.UUID
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating no-show rates: %w", err)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type auditArchiveRepository struct {
+	database *sql.DB
+}
+
+func NewAuditArchiveRepository(db *sql.DB) domain.AuditArchiveRepository {
+	return &auditArchiveRepository{
+		database: db,
+	}
+}
+
+var rxAuditPartition = regexp.MustCompile(`^audit_logs_(\d{4})_(\d{2})$`)
+
+func auditPartitionName(month time.Time) string {
+	return fmt.Sprintf("audit_logs_%04d_%02d", month.Year(), int(month.Month()))
+}
+
+func (aar *auditArchiveRepository) FetchPartitions(c context.Context) ([]domain.AuditPartition, error) {
+	query := `
+	SELECT child.relname
+	FROM pg_inherits i
+	JOIN pg_class child ON child.oid = i.inhrelid
+	JOIN pg_class parent ON parent.oid = i.inhparent
+	WHERE parent.relname = 'audit_logs'
+	ORDER BY child.relname
+`
+	rows, err := aar.database.QueryContext(c, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching audit partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []domain.AuditPartition
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning audit partition: %w", err)
+		}
+
+		// The default partition and anything not created by us is skipped.
+		match := rxAuditPartition.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+
+		partitions = append(partitions, domain.AuditPartition{
+			Name: name,
+			From: from,
+			To:   from.AddDate(0, 1, 0),
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit partitions: %w", err)
+	}
+
+	return partitions, nil
+}
+
+// CreatePartition creates the partition of a month. Rows of that month already
+// in the default partition, which Postgres would refuse to leave behind, are
+// moved into the new one in the same transaction: the default partition is
+// detached, emptied of them and attached again.
+func (aar *auditArchiveRepository) CreatePartition(c context.Context, month time.Time) error {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	name := auditPartitionName(from)
+
+	tx, err := aar.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting audit partition %s: %w", name, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err = tx.QueryRowContext(c, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking audit partition %s: %w", name, err)
+	}
+	if exists {
+		return nil
+	}
+
+	var defaultPartition sql.NullString
+	err = tx.QueryRowContext(c, `
+	SELECT partdefid::regclass::text
+	FROM pg_partitioned_table
+	WHERE partrelid = 'audit_logs'::regclass AND partdefid <> 0
+`).Scan(&defaultPartition)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error finding the default audit partition: %w", err)
+	}
+
+	moving := false
+	if defaultPartition.Valid {
+		query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE created_at >= $1 AND created_at < $2)`, defaultPartition.String)
+		if err = tx.QueryRowContext(c, query, from, to).Scan(&moving); err != nil {
+			return fmt.Errorf("error checking the default audit partition: %w", err)
+		}
+	}
+
+	if moving {
+		if _, err = tx.ExecContext(c, "ALTER TABLE audit_logs DETACH PARTITION "+defaultPartition.String); err != nil {
+			return fmt.Errorf("error detaching the default audit partition: %w", err)
+		}
+	}
+
+	query := fmt.Sprintf(
+		"CREATE TABLE %s PARTITION OF audit_logs FOR VALUES FROM (%s) TO (%s)",
+		pq.QuoteIdentifier(name),
+		pq.QuoteLiteral(from.Format(time.RFC3339)),
+		pq.QuoteLiteral(to.Format(time.RFC3339)),
+	)
+	if _, err = tx.ExecContext(c, query); err != nil {
+		return fmt.Errorf("error creating audit partition %s: %w", name, err)
+	}
+
+	if moving {
+		query = fmt.Sprintf(`
+	WITH moved AS (
+		DELETE FROM %s WHERE created_at >= $1 AND created_at < $2
+		RETURNING *
+	)
+	INSERT INTO %s SELECT * FROM moved
+`, defaultPartition.String, pq.QuoteIdentifier(name))
+		result, err := tx.ExecContext(c, query, from, to)
+		if err != nil {
+			return fmt.Errorf("error moving audit logs into partition %s: %w", name, err)
+		}
+		if _, err = tx.ExecContext(c, "ALTER TABLE audit_logs ATTACH PARTITION "+defaultPartition.String+" DEFAULT"); err != nil {
+			return fmt.Errorf("error attaching the default audit partition: %w", err)
+		}
+		if moved, err := result.RowsAffected(); err == nil {
+			log.Printf("Audit archive: moved %d entries from %s into %s\n", moved, defaultPartition.String, name)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error creating audit partition %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (aar *auditArchiveRepository) StreamPartition(c context.Context, name string, fn func(log domain.AuditLog) error) error {
+	if !rxAuditPartition.MatchString(name) {
+		return fmt.Errorf("invalid audit partition name: %s", name)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, user_id, COALESCE(user_role, ''), action, COALESCE(description, ''), COALESCE(resource_type, ''), resource_id, patient_id, COALESCE(purpose, ''), COALESCE(ip_address, ''), created_at
+	FROM %s
+	ORDER BY created_at
+`, pq.QuoteIdentifier(name))
+
+	rows, err := aar.database.QueryContext(c, query)
+	if err != nil {
+		return fmt.Errorf("error reading audit partition %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var auditLog domain.AuditLog
+		if err := rows.Scan(
+			&auditLog.ID,
+			&auditLog.UserID,
+			&auditLog.UserRole,
+			&auditLog.Action,
+			&auditLog.Description,
+			&auditLog.ResourceType,
+			&auditLog.ResourceID,
+			&auditLog.PatientID,
+			&auditLog.Purpose,
+			&auditLog.IPAddress,
+			&auditLog.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("error scanning audit partition %s: %w", name, err)
+		}
+
+		if err := fn(auditLog); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating audit partition %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (aar *auditArchiveRepository) DropPartition(c context.Context, name string) error {
+	if !rxAuditPartition.MatchString(name) {
+		return fmt.Errorf("invalid audit partition name: %s", name)
+	}
+
+	tx, err := aar.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting partition drop: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(c, "ALTER TABLE audit_logs DETACH PARTITION "+pq.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("error detaching audit partition %s: %w", name, err)
+	}
+
+	if _, err = tx.ExecContext(c, "DROP TABLE "+pq.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("error dropping audit partition %s: %w", name, err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteByCategoryBefore removes entries of a category older than before. The
+// default category matches every action not claimed by another category.
+func (aar *auditArchiveRepository) DeleteByCategoryBefore(c context.Context, category string, before time.Time) (int64, error) {
+	var patterns []string
+	for _, cat := range domain.AuditCategories {
+		if category == domain.DefaultAuditCategory || cat.Name == category {
+			for _, prefix := range cat.ActionPrefixes {
+				patterns = append(patterns, likePrefix(prefix))
+			}
+		}
+	}
+
+	var query string
+	if category == domain.DefaultAuditCategory {
+		query = `DELETE FROM audit_logs WHERE created_at < $1 AND NOT (action LIKE ANY($2))`
+	} else {
+		if len(patterns) == 0 {
+			return 0, fmt.Errorf("unknown audit category: %s", category)
+		}
+		query = `DELETE FROM audit_logs WHERE created_at < $1 AND action LIKE ANY($2)`
+	}
+
+	result, err := aar.database.ExecContext(c, query, before, pq.Array(patterns))
+	if err != nil {
+		return 0, fmt.Errorf("error purging %s audit logs: %w", category, err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (aar *auditArchiveRepository) CreateRestored(c context.Context, auditLogs []domain.AuditLog, source string) error {
+	tx, err := aar.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting audit restore: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(c, `
+	INSERT INTO audit_logs_restored (id, user_id, user_role, action, description, resource_type, resource_id, patient_id, purpose, ip_address, created_at, restored_from)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	ON CONFLICT (id) DO NOTHING
+`)
+	if err != nil {
+		return fmt.Errorf("error preparing audit restore: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range auditLogs {
+		args := append([]interface{}{auditLogs[i].ID}, auditLogArgs(&auditLogs[i])...)
+		args = append(args, source)
+		if _, err = stmt.ExecContext(c, args...); err != nil {
+			return fmt.Errorf("error restoring audit log %s: %w", auditLogs[i].ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func likePrefix(prefix string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(prefix) + "%"
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"hms-api/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,29 +20,74 @@ func NewAuditLogRepository(db *sql.DB) domain.AuditLogRepository {
 	}
 }
 
-func (alr *auditLogRepository) Create(c context.Context, auditLog *domain.AuditLog) error {
-	query := `
-	INSERT INTO audit_logs (user_id, action, description, created_at)
-	VALUES ($1, $2, $3, $4)
+const insertAuditLogQuery = `
+	INSERT INTO audit_logs (user_id, user_role, action, description, resource_type, resource_id, patient_id, purpose, ip_address, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	RETURNING id
 `
-	err := alr.database.QueryRowContext(c, query, auditLog.UserID, auditLog.Action, auditLog.Description, auditLog.CreatedAt).Scan(&auditLog.ID)
+
+func auditLogArgs(auditLog *domain.AuditLog) []interface{} {
+	return []interface{}{
+		nullableUUID(auditLog.UserID),
+		auditLog.UserRole,
+		auditLog.Action,
+		auditLog.Description,
+		auditLog.ResourceType,
+		nullableUUID(auditLog.ResourceID),
+		nullableUUID(auditLog.PatientID),
+		auditLog.Purpose,
+		auditLog.IPAddress,
+		auditLog.CreatedAt,
+	}
+}
+
+func (alr *auditLogRepository) Create(c context.Context, auditLog *domain.AuditLog) error {
+	err := alr.database.QueryRowContext(c, insertAuditLogQuery, auditLogArgs(auditLog)...).Scan(&auditLog.ID)
 	if err != nil {
-		fmt.Println("Error executing query:", err) 
+		fmt.Println("Error executing query:", err)
 		return err
 	}
 
 	return nil
 }
 
+func (alr *auditLogRepository) CreateBatch(c context.Context, auditLogs []domain.AuditLog) error {
+	tx, err := alr.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting audit log batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(c, insertAuditLogQuery)
+	if err != nil {
+		return fmt.Errorf("error preparing audit log batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range auditLogs {
+		err = stmt.QueryRowContext(c, auditLogArgs(&auditLogs[i])...).Scan(&auditLogs[i].ID)
+		if err != nil {
+			return fmt.Errorf("error inserting audit log batch: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing audit log batch: %w", err)
+	}
+
+	return nil
+}
+
 func (alr *auditLogRepository) Fetch(c context.Context) ([]domain.AuditLog, error) {
 	query := `
-	SELECT id, user_id, action, description, created_at
+	SELECT id, user_id, COALESCE(user_role, ''), action, COALESCE(description, ''), COALESCE(resource_type, ''), resource_id, patient_id, COALESCE(purpose, ''), COALESCE(ip_address, ''), created_at
 	FROM audit_logs
+	ORDER BY created_at DESC
 `
 	rows, err := alr.database.QueryContext(c, query)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
+		return nil, err
 	}
 
 	defer rows.Close()
@@ -53,7 +99,14 @@ func (alr *auditLogRepository) Fetch(c context.Context) ([]domain.AuditLog, erro
 		err = rows.Scan(
 			&auditLog.ID,
 			&auditLog.UserID,
+			&auditLog.UserRole,
 			&auditLog.Action,
+			&auditLog.Description,
+			&auditLog.ResourceType,
+			&auditLog.ResourceID,
+			&auditLog.PatientID,
+			&auditLog.Purpose,
+			&auditLog.IPAddress,
 			&auditLog.CreatedAt,
 		)
 		if err != nil {
@@ -63,21 +116,32 @@ func (alr *auditLogRepository) Fetch(c context.Context) ([]domain.AuditLog, erro
 
 		auditLogs = append(auditLogs, auditLog)
 	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return auditLogs, nil
 }
 
 func (alr *auditLogRepository) FetchByID(c context.Context, id uuid.UUID) (domain.AuditLog, error) {
 	var auditLog domain.AuditLog
 	query := `
-	SELECT id, user_id, action, description, created_at
-	FROM audit_logs 
+	SELECT id, user_id, COALESCE(user_role, ''), action, COALESCE(description, ''), COALESCE(resource_type, ''), resource_id, patient_id, COALESCE(purpose, ''), COALESCE(ip_address, ''), created_at
+	FROM audit_logs
 	WHERE id = $1
 `
 	err := alr.database.QueryRowContext(c, query, id).Scan(
 		&auditLog.ID,
 		&auditLog.UserID,
+		&auditLog.UserRole,
 		&auditLog.Action,
 		&auditLog.Description,
+		&auditLog.ResourceType,
+		&auditLog.ResourceID,
+		&auditLog.PatientID,
+		&auditLog.Purpose,
+		&auditLog.IPAddress,
 		&auditLog.CreatedAt,
 	)
 
@@ -91,13 +155,84 @@ func (alr *auditLogRepository) FetchByID(c context.Context, id uuid.UUID) (domai
 	return auditLog, nil
 }
 
+func (alr *auditLogRepository) FetchAccessLogByPatientID(c context.Context, patientID uuid.UUID) ([]domain.AccessLogEntry, error) {
+	query := `
+	SELECT al.created_at, al.user_id, COALESCE(u.username, ''), COALESCE(al.user_role, u.role::text, ''), al.action,
+	       COALESCE(al.resource_type, ''), al.resource_id, COALESCE(al.purpose, ''), COALESCE(al.description, '')
+	FROM audit_logs al
+	LEFT JOIN users u ON u.id = al.user_id
+	WHERE al.patient_id = $1
+	ORDER BY al.created_at DESC
+`
+	rows, err := alr.database.QueryContext(c, query, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching access log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AccessLogEntry
+	for rows.Next() {
+		var entry domain.AccessLogEntry
+		if err := rows.Scan(
+			&entry.AccessedAt,
+			&entry.UserID,
+			&entry.Username,
+			&entry.UserRole,
+			&entry.Action,
+			&entry.ResourceType,
+			&entry.ResourceID,
+			&entry.Purpose,
+			&entry.Description,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning access log entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating access log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FetchLoginIPs returns the distinct addresses the user logged in from
+// successfully before the given time.
+func (alr *auditLogRepository) FetchLoginIPs(c context.Context, userID uuid.UUID, before time.Time) ([]string, error) {
+	query := `
+	SELECT DISTINCT ip_address
+	FROM audit_logs
+	WHERE user_id = $1 AND action = 'USER_LOGIN_SUCCESS' AND ip_address IS NOT NULL AND ip_address <> '' AND created_at < $2
+`
+	rows, err := alr.database.QueryContext(c, query, userID, before)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching login addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var ips []string
+	for rows.Next() {
+		var ip string
+		if err := rows.Scan(&ip); err != nil {
+			return nil, fmt.Errorf("error scanning login address: %w", err)
+		}
+		ips = append(ips, ip)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating login addresses: %w", err)
+	}
+
+	return ips, nil
+}
+
 func (alr *auditLogRepository) Update(c context.Context, auditLog *domain.AuditLog) error {
 	query := `
 	UPDATE audit_logs
 	SET user_id = $1, action = $2, description = $3
 	WHERE id = $4
 `
-	_, err := alr.database.ExecContext(c, query, auditLog.UserID, auditLog.Action, auditLog.Description, auditLog.ID)
+	_, err := alr.database.ExecContext(c, query, nullableUUID(auditLog.UserID), auditLog.Action, auditLog.Description, auditLog.ID)
 
 	if err != nil {
 		fmt.Println("Error executing update:", err)
@@ -121,3 +256,12 @@ func (alr *auditLogRepository) Delete(c context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// nullableUUID stores uuid.Nil as NULL so entries without an authenticated
+// user don't violate the users foreign key.
+func nullableUUID(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
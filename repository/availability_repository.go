@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+)
+
+type availabilityRepository struct {
+	database *sql.DB
+}
+
+func NewAvailabilityRepository(db *sql.DB) domain.AvailabilityRepository {
+	return &availabilityRepository{
+		database: db,
+	}
+}
+
+func (ar *availabilityRepository) FetchWeekly(c context.Context, doctorID uuid.UUID) ([]domain.WeeklyAvailability, error) {
+	query := `
+	SELECT id, doctor_id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), slot_minutes, breaks
+	FROM doctor_availability
+	WHERE doctor_id = $1
+	ORDER BY weekday, start_time
+`
+	rows, err := ar.database.QueryContext(c, query, doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching availability: %w", err)
+	}
+	defer rows.Close()
+
+	var weekly []domain.WeeklyAvailability
+	for rows.Next() {
+		var availability domain.WeeklyAvailability
+		var breaks []byte
+		if err := rows.Scan(
+			&availability.ID,
+			&availability.DoctorID,
+			&availability.Weekday,
+			&availability.StartTime,
+			&availability.EndTime,
+			&availability.SlotMinutes,
+			&breaks,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning availability: %w", err)
+		}
+		if err := json.Unmarshal(breaks, &availability.Breaks); err != nil {
+			return nil, fmt.Errorf("error decoding availability breaks: %w", err)
+		}
+		weekly = append(weekly, availability)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating availability: %w", err)
+	}
+
+	return weekly, nil
+}
+
+// ReplaceWeekly swaps the doctor's whole weekly template in one transaction.
+func (ar *availabilityRepository) ReplaceWeekly(c context.Context, doctorID uuid.UUID, weekly []domain.WeeklyAvailability) error {
+	tx, err := ar.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting availability update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(c, `DELETE FROM doctor_availability WHERE doctor_id = $1`, doctorID); err != nil {
+		return fmt.Errorf("error clearing availability: %w", err)
+	}
+
+	for i := range weekly {
+		breaks, err := encodeBreaks(weekly[i].Breaks)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(c, `
+	INSERT INTO doctor_availability (doctor_id, weekday, start_time, end_time, slot_minutes, breaks)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id
+`, doctorID, weekly[i].Weekday, weekly[i].StartTime, weekly[i].EndTime, weekly[i].SlotMinutes, breaks).Scan(&weekly[i].ID)
+		if err != nil {
+			return fmt.Errorf("error inserting availability: %w", err)
+		}
+		weekly[i].DoctorID = doctorID
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing availability: %w", err)
+	}
+
+	return nil
+}
+
+// FetchExceptions lists the exceptions dated from..to inclusive, given as
+// YYYY-MM-DD.
+func (ar *availabilityRepository) FetchExceptions(c context.Context, doctorID uuid.UUID, from string, to string) ([]domain.AvailabilityException, error) {
+	query := `
+	SELECT id, doctor_id, to_char(date, 'YYYY-MM-DD'), available, COALESCE(to_char(start_time, 'HH24:MI'), ''), COALESCE(to_char(end_time, 'HH24:MI'), ''),
+	       COALESCE(slot_minutes, 0), breaks, COALESCE(reason, ''), created_at
+	FROM doctor_availability_exceptions
+	WHERE doctor_id = $1 AND date BETWEEN $2 AND $3
+	ORDER BY date
+`
+	rows, err := ar.database.QueryContext(c, query, doctorID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching availability exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	var exceptions []domain.AvailabilityException
+	for rows.Next() {
+		var exception domain.AvailabilityException
+		var breaks []byte
+		if err := rows.Scan(
+			&exception.ID,
+			&exception.DoctorID,
+			&exception.Date,
+			&exception.Available,
+			&exception.StartTime,
+			&exception.EndTime,
+			&exception.SlotMinutes,
+			&breaks,
+			&exception.Reason,
+			&exception.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning availability exception: %w", err)
+		}
+		if err := json.Unmarshal(breaks, &exception.Breaks); err != nil {
+			return nil, fmt.Errorf("error decoding availability exception breaks: %w", err)
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating availability exceptions: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+// SaveException creates the exception for its date or replaces the existing
+// one, since a doctor can only have one exception per day.
+func (ar *availabilityRepository) SaveException(c context.Context, exception *domain.AvailabilityException) error {
+	breaks, err := encodeBreaks(exception.Breaks)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO doctor_availability_exceptions (doctor_id, date, available, start_time, end_time, slot_minutes, breaks, reason)
+	VALUES ($1, $2, $3, NULLIF($4, '')::time, NULLIF($5, '')::time, NULLIF($6, 0), $7, $8)
+	ON CONFLICT (doctor_id, date) DO UPDATE
+	SET available = EXCLUDED.available, start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+	    slot_minutes = EXCLUDED.slot_minutes, breaks = EXCLUDED.breaks, reason = EXCLUDED.reason
+	RETURNING id, created_at
+`
+	err = ar.database.QueryRowContext(c, query,
+		exception.DoctorID,
+		exception.Date,
+		exception.Available,
+		exception.StartTime,
+		exception.EndTime,
+		exception.SlotMinutes,
+		breaks,
+		exception.Reason,
+	).Scan(&exception.ID, &exception.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving availability exception: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *availabilityRepository) DeleteException(c context.Context, doctorID uuid.UUID, id uuid.UUID) error {
+	_, err := ar.database.ExecContext(c, `DELETE FROM doctor_availability_exceptions WHERE id = $1 AND doctor_id = $2`, id, doctorID)
+	if err != nil {
+		return fmt.Errorf("error deleting availability exception: %w", err)
+	}
+
+	return nil
+}
+
+// FetchTimezone returns the timezone of the doctor's facility, or "" when the
+// doctor has none.
+func (ar *availabilityRepository) FetchTimezone(c context.Context, doctorID uuid.UUID) (string, error) {
+	query := `
+	SELECT COALESCE(f.timezone, '')
+	FROM doctors d
+	LEFT JOIN facilities f ON f.id = d.facility_id
+	WHERE d.id = $1
+`
+	var timezone string
+	err := ar.database.QueryRowContext(c, query, doctorID).Scan(&timezone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error fetching doctor timezone: %w", err)
+	}
+
+	return timezone, nil
+}
+
+func encodeBreaks(breaks []domain.TimeRange) ([]byte, error) {
+	if breaks == nil {
+		breaks = []domain.TimeRange{}
+	}
+	encoded, err := json.Marshal(breaks)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding breaks: %w", err)
+	}
+	return encoded, nil
+}
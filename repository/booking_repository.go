@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const slotHoldColumns = `id, patient_id, doctor_id, type_id, slot_start, slot_end, status, expires_at, appointment_id, created_at`
+
+type bookingRepository struct {
+	database *sql.DB
+}
+
+func NewBookingRepository(db *sql.DB) domain.BookingRepository {
+	return &bookingRepository{
+		database: db,
+	}
+}
+
+// FetchDoctors lists the doctors of the specialty, and of the facility when
+// one is given, by name. Both are matched case-insensitively.
+func (br *bookingRepository) FetchDoctors(c context.Context, specialty string, facility string) ([]domain.BookingDoctor, error) {
+	query := `
+		SELECT d.id, u.username, COALESCE(d.specialty, ''), COALESCE(f.name, ''), COALESCE(f.timezone, '')
+		FROM doctors d
+		JOIN users u ON u.id = d.user_id
+		LEFT JOIN facilities f ON f.id = d.facility_id
+		WHERE lower(d.specialty) = lower($1)
+			AND ($2 = '' OR lower(f.name) = lower($2))
+		ORDER BY u.username
+	`
+	rows, err := br.database.QueryContext(c, query, specialty, facility)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching bookable doctors: %w", err)
+	}
+	defer rows.Close()
+
+	var doctors []domain.BookingDoctor
+	for rows.Next() {
+		var doctor domain.BookingDoctor
+		if err := rows.Scan(&doctor.DoctorID, &doctor.DoctorName, &doctor.Specialty, &doctor.Facility, &doctor.Timezone); err != nil {
+			return nil, fmt.Errorf("error scanning bookable doctor: %w", err)
+		}
+		doctors = append(doctors, doctor)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookable doctors: %w", err)
+	}
+
+	return doctors, nil
+}
+
+func (br *bookingRepository) FetchActiveHolds(c context.Context, doctorIDs []uuid.UUID, from time.Time, to time.Time) ([]domain.SlotHold, error) {
+	ids := make([]string, 0, len(doctorIDs))
+	for _, id := range doctorIDs {
+		ids = append(ids, id.String())
+	}
+
+	query := `
+		SELECT ` + slotHoldColumns + `
+		FROM slot_holds
+		WHERE doctor_id = ANY($1::uuid[]) AND status = 'held' AND expires_at > CURRENT_TIMESTAMP
+			AND slot_start < $3 AND slot_end > $2
+		ORDER BY slot_start
+	`
+	rows, err := br.database.QueryContext(c, query, pq.Array(ids), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching slot holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []domain.SlotHold
+	for rows.Next() {
+		var hold domain.SlotHold
+		if err := scanSlotHold(rows, &hold); err != nil {
+			return nil, fmt.Errorf("error scanning slot hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating slot holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// CreateHold stores the hold after releasing the patient's other holds and
+// expiring the doctor's lapsed ones. The slot must not overlap an active
+// appointment or a pending waitlist offer of the doctor.
+func (br *bookingRepository) CreateHold(c context.Context, hold *domain.SlotHold) error {
+	tx, err := br.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error starting slot hold: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(c, `
+		UPDATE slot_holds
+		SET status = CASE WHEN expires_at > CURRENT_TIMESTAMP THEN 'released' ELSE 'expired' END
+		WHERE status = 'held' AND (patient_id = $1 OR (doctor_id = $2 AND expires_at <= CURRENT_TIMESTAMP))
+	`, hold.PatientID, hold.DoctorID)
+	if err != nil {
+		return fmt.Errorf("error releasing previous slot holds: %w", err)
+	}
+
+	var taken bool
+	err = tx.QueryRowContext(c, `
+		SELECT EXISTS (
+			SELECT 1 FROM appointments
+			WHERE doctor_id = $1 AND status NOT IN ('canceled', 'no_show')
+				AND tstzrange(appointment_date, end_date) && tstzrange($2, $3)
+		) OR EXISTS (
+			SELECT 1 FROM waitlist_offers
+			WHERE doctor_id = $1 AND status = 'pending' AND expires_at > CURRENT_TIMESTAMP
+				AND tstzrange(slot_start, slot_end) && tstzrange($2, $3)
+		)
+	`, hold.DoctorID, hold.SlotStart, hold.SlotEnd).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("error checking slot: %w", err)
+	}
+	if taken {
+		return domain.ErrSlotUnavailable
+	}
+
+	var typeID interface{}
+	if hold.TypeID != nil {
+		typeID = *hold.TypeID
+	}
+
+	query := `
+		INSERT INTO slot_holds (patient_id, doctor_id, type_id, slot_start, slot_end, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(c, query,
+		hold.PatientID,
+		hold.DoctorID,
+		typeID,
+		hold.SlotStart,
+		hold.SlotEnd,
+		hold.Status,
+		hold.ExpiresAt,
+	).Scan(&hold.ID, &hold.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23P01" {
+			return domain.ErrSlotUnavailable
+		}
+		return fmt.Errorf("error creating slot hold: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing slot hold: %w", err)
+	}
+
+	return nil
+}
+
+func (br *bookingRepository) FetchHoldByID(c context.Context, id uuid.UUID) (domain.SlotHold, error) {
+	query := `
+		SELECT ` + slotHoldColumns + `
+		FROM slot_holds
+		WHERE id = $1
+	`
+	var hold domain.SlotHold
+	err := scanSlotHold(br.database.QueryRowContext(c, query, id), &hold)
+	if err == sql.ErrNoRows {
+		return domain.SlotHold{}, domain.ErrHoldNotFound
+	}
+	if err != nil {
+		return domain.SlotHold{}, fmt.Errorf("error fetching slot hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+func (br *bookingRepository) CloseHold(c context.Context, hold *domain.SlotHold, status domain.HoldStatus) (bool, error) {
+	query := `
+		UPDATE slot_holds
+		SET status = $1, appointment_id = $2
+		WHERE id = $3 AND status = 'held'
+		RETURNING ` + slotHoldColumns + `
+	`
+	err := scanSlotHold(br.database.QueryRowContext(c, query, status, hold.AppointmentID, hold.ID), hold)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error closing slot hold: %w", err)
+	}
+
+	return true, nil
+}
+
+func scanSlotHold(row interface{ Scan(...interface{}) error }, hold *domain.SlotHold) error {
+	return row.Scan(
+		&hold.ID,
+		&hold.PatientID,
+		&hold.DoctorID,
+		&hold.TypeID,
+		&hold.SlotStart,
+		&hold.SlotEnd,
+		&hold.Status,
+		&hold.ExpiresAt,
+		&hold.AppointmentID,
+		&hold.CreatedAt,
+	)
+}
+
+// slotHeld reports whether the doctor's time during the appointment is held
+// for another patient who is booking it.
+func slotHeld(c context.Context, tx *sql.Tx, appointment *domain.Appointment) (bool, error) {
+	var held bool
+	err := tx.QueryRowContext(c, `
+		SELECT EXISTS (
+			SELECT 1 FROM slot_holds
+			WHERE doctor_id = $1 AND patient_id <> $2 AND status = 'held' AND expires_at > CURRENT_TIMESTAMP
+				AND tstzrange(slot_start, slot_end) && tstzrange($3, $4)
+		)
+	`, appointment.DoctorID, appointment.PatientID, appointment.AppointmentDate, appointment.EndDate).Scan(&held)
+	if err != nil {
+		return false, fmt.Errorf("error checking slot holds: %w", err)
+	}
+	return held, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+)
+
+type calendarFeedRepository struct {
+	database *sql.DB
+}
+
+func NewCalendarFeedRepository(db *sql.DB) domain.CalendarFeedRepository {
+	return &calendarFeedRepository{
+		database: db,
+	}
+}
+
+func (cr *calendarFeedRepository) Create(c context.Context, feed *domain.CalendarFeed, tokenHash string) error {
+	query := `
+		INSERT INTO calendar_feeds (user_id, token_hash)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+	err := cr.database.QueryRowContext(c, query, feed.UserID, tokenHash).Scan(&feed.ID, &feed.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating calendar feed: %w", err)
+	}
+
+	return nil
+}
+
+func (cr *calendarFeedRepository) FetchByUserID(c context.Context, userID uuid.UUID) ([]domain.CalendarFeed, error) {
+	query := `
+		SELECT id, user_id, created_at, revoked_at
+		FROM calendar_feeds
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := cr.database.QueryContext(c, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching calendar feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []domain.CalendarFeed
+	for rows.Next() {
+		var feed domain.CalendarFeed
+		if err := rows.Scan(&feed.ID, &feed.UserID, &feed.CreatedAt, &feed.RevokedAt); err != nil {
+			return nil, fmt.Errorf("error scanning calendar feed: %w", err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+func (cr *calendarFeedRepository) FetchActiveByTokenHash(c context.Context, tokenHash string) (domain.CalendarFeed, error) {
+	query := `
+		SELECT id, user_id, created_at, revoked_at
+		FROM calendar_feeds
+		WHERE token_hash = $1 AND revoked_at IS NULL
+	`
+	var feed domain.CalendarFeed
+	err := cr.database.QueryRowContext(c, query, tokenHash).Scan(&feed.ID, &feed.UserID, &feed.CreatedAt, &feed.RevokedAt)
+	if err == sql.ErrNoRows {
+		return domain.CalendarFeed{}, domain.ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return domain.CalendarFeed{}, fmt.Errorf("error fetching calendar feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// Revoke disables the user's feed, reporting false when the user has no
+// active feed with that id.
+func (cr *calendarFeedRepository) Revoke(c context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE calendar_feeds
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	result, err := cr.database.ExecContext(c, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("error revoking calendar feed: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error revoking calendar feed: %w", err)
+	}
+
+	return affected > 0, nil
+}
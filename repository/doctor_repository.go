@@ -5,10 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"hms-api/domain"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
+// doctorColumns selects a doctor joined, as f, with their facility.
+const doctorColumns = "d.id, d.user_id, d.crm, d.specialty, COALESCE(f.name, ''), d.created_at"
+
 type doctorRepository struct {
 	database *sql.DB
 }
@@ -20,8 +24,13 @@ func NewDoctorRepository(db *sql.DB) domain.DoctorRepository {
 }
 
 func (dr *doctorRepository) Create(c context.Context, doctor *domain.Doctor) error {
-	query := "INSERT INTO doctors (user_id, crm, specialty) VALUES ($1, $2, $3) RETURNING id"
-	err := dr.database.QueryRowContext(c, query, doctor.UserId, doctor.CRM, doctor.Specialty).Scan(&doctor.ID)
+	facilityID, err := dr.facilityID(c, doctor)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO doctors (user_id, crm, specialty, facility_id) VALUES ($1, $2, $3, $4) RETURNING id"
+	err = dr.database.QueryRowContext(c, query, doctor.UserId, doctor.CRM, doctor.Specialty, facilityID).Scan(&doctor.ID)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
 		return err
@@ -30,7 +39,7 @@ func (dr *doctorRepository) Create(c context.Context, doctor *domain.Doctor) err
 }
 
 func (dr *doctorRepository) Fetch(c context.Context) ([]domain.Doctor, error) {
-	query := "SELECT id, user_id, crm, specialty, created_at FROM doctors"
+	query := "SELECT " + doctorColumns + " FROM doctors d LEFT JOIN facilities f ON f.id = d.facility_id"
 	rows, err := dr.database.QueryContext(c, query)
 
 	if err != nil {
@@ -48,6 +57,7 @@ func (dr *doctorRepository) Fetch(c context.Context) ([]domain.Doctor, error) {
 			&doctor.UserId,
 			&doctor.CRM,
 			&doctor.Specialty,
+			&doctor.Facility,
 			&doctor.CreatedAt,
 		)
 
@@ -68,13 +78,37 @@ func (dr *doctorRepository) Fetch(c context.Context) ([]domain.Doctor, error) {
 
 func (dr *doctorRepository) FetchByID(c context.Context, id uuid.UUID) (domain.Doctor, error) {
 	var doctor domain.Doctor
-	query := "SELECT id, user_id, crm, specialty, created_at FROM doctors WHERE id = $1"
+	query := "SELECT " + doctorColumns + " FROM doctors d LEFT JOIN facilities f ON f.id = d.facility_id WHERE d.id = $1"
 	
 	err := dr.database.QueryRowContext(c, query, id).Scan(
 		&doctor.ID,
 		&doctor.UserId,
 		&doctor.CRM,
 		&doctor.Specialty,
+		&doctor.Facility,
+		&doctor.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Doctor{}, nil
+		}
+		return domain.Doctor{}, err
+	}
+
+	return doctor, nil
+}
+
+func (dr *doctorRepository) FetchByUserID(c context.Context, userID uuid.UUID) (domain.Doctor, error) {
+	var doctor domain.Doctor
+	query := "SELECT " + doctorColumns + " FROM doctors d LEFT JOIN facilities f ON f.id = d.facility_id WHERE d.user_id = $1"
+
+	err := dr.database.QueryRowContext(c, query, userID).Scan(
+		&doctor.ID,
+		&doctor.UserId,
+		&doctor.CRM,
+		&doctor.Specialty,
+		&doctor.Facility,
 		&doctor.CreatedAt,
 	)
 
@@ -89,8 +123,13 @@ func (dr *doctorRepository) FetchByID(c context.Context, id uuid.UUID) (domain.D
 }
 
 func (dr *doctorRepository) Update(c context.Context, doctor *domain.Doctor) error {
-	query := "UPDATE doctors SET crm = $1, specialty = $2 WHERE id = $3"
-	_, err := dr.database.ExecContext(c, query, doctor.CRM, doctor.Specialty, doctor.ID)
+	facilityID, err := dr.facilityID(c, doctor)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE doctors SET crm = $1, specialty = $2, facility_id = $3 WHERE id = $4"
+	_, err = dr.database.ExecContext(c, query, doctor.CRM, doctor.Specialty, facilityID, doctor.ID)
 	
 	if err != nil {
 		fmt.Println("Error executing update:", err)
@@ -110,4 +149,26 @@ func (dr *doctorRepository) Delete(c context.Context, id uuid.UUID) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// facilityID looks up the facility the doctor works at by name, ignoring
+// case, and sets the doctor's facility to its stored name. Doctors without a
+// facility get a NULL one, and names that match no facility are rejected.
+func (dr *doctorRepository) facilityID(c context.Context, doctor *domain.Doctor) (uuid.NullUUID, error) {
+	name := strings.TrimSpace(doctor.Facility)
+	if name == "" {
+		doctor.Facility = ""
+		return uuid.NullUUID{}, nil
+	}
+
+	var id uuid.UUID
+	err := dr.database.QueryRowContext(c, "SELECT id, name FROM facilities WHERE lower(name) = lower($1)", name).Scan(&id, &doctor.Facility)
+	if err == sql.ErrNoRows {
+		return uuid.NullUUID{}, fmt.Errorf("%w: no facility is named %q", domain.ErrFacilityNotFound, name)
+	}
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("error fetching doctor facility: %w", err)
+	}
+
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const encounterColumns = `id, patient_id, doctor_id, appointment_id, status, COALESCE(reason, ''), COALESCE(notes, ''), opened_by, opened_at, closed_by, closed_at, updated_at`
+
+type encounterRepository struct {
+	database *sql.DB
+}
+
+func NewEncounterRepository(db *sql.DB) domain.EncounterRepository {
+	return &encounterRepository{
+		database: db,
+	}
+}
+
+// Create relies on the unique index on appointment_id to keep two requests
+// from opening encounters for the same appointment.
+func (er *encounterRepository) Create(c context.Context, encounter *domain.Encounter) error {
+	query := `
+		INSERT INTO encounters (patient_id, doctor_id, appointment_id, status, reason, notes, opened_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+		RETURNING ` + encounterColumns + `
+	`
+	err := scanEncounter(er.database.QueryRowContext(c, query,
+		encounter.PatientID,
+		encounter.DoctorID,
+		encounter.AppointmentID,
+		encounter.Status,
+		encounter.Reason,
+		encounter.Notes,
+		encounter.OpenedBy,
+	), encounter)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return domain.ErrEncounterExists
+		}
+		return fmt.Errorf("error creating encounter: %w", err)
+	}
+
+	return nil
+}
+
+func (er *encounterRepository) FetchByID(c context.Context, id uuid.UUID) (*domain.Encounter, error) {
+	query := `
+		SELECT ` + encounterColumns + `
+		FROM encounters
+		WHERE id = $1
+	`
+	return er.fetchEncounter(c, query, id)
+}
+
+func (er *encounterRepository) FetchByAppointmentID(c context.Context, appointmentID uuid.UUID) (*domain.Encounter, error) {
+	query := `
+		SELECT ` + encounterColumns + `
+		FROM encounters
+		WHERE appointment_id = $1
+	`
+	return er.fetchEncounter(c, query, appointmentID)
+}
+
+func (er *encounterRepository) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.Encounter, error) {
+	query := `
+		SELECT ` + encounterColumns + `
+		FROM encounters
+		WHERE patient_id = $1
+		ORDER BY opened_at DESC
+	`
+	rows, err := er.database.QueryContext(c, query, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching encounters: %w", err)
+	}
+	defer rows.Close()
+
+	encounters := []domain.Encounter{}
+	for rows.Next() {
+		var encounter domain.Encounter
+		if err := scanEncounter(rows, &encounter); err != nil {
+			return nil, fmt.Errorf("error scanning encounter: %w", err)
+		}
+		encounters = append(encounters, encounter)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating encounters: %w", err)
+	}
+
+	return encounters, nil
+}
+
+func (er *encounterRepository) Close(c context.Context, encounter *domain.Encounter) (bool, error) {
+	query := `
+		UPDATE encounters
+		SET status = 'closed', notes = NULLIF($1, ''), closed_by = $2, closed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = 'open'
+		RETURNING ` + encounterColumns + `
+	`
+	err := scanEncounter(er.database.QueryRowContext(c, query, encounter.Notes, encounter.ClosedBy, encounter.ID), encounter)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error closing encounter: %w", err)
+	}
+
+	return true, nil
+}
+
+func (er *encounterRepository) CreateOrder(c context.Context, order *domain.EncounterOrder) error {
+	query := `
+		INSERT INTO encounter_orders (encounter_id, patient_id, kind, description, notes, ordered_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING id, created_at
+	`
+	err := er.database.QueryRowContext(c, query,
+		order.EncounterID,
+		order.PatientID,
+		order.Kind,
+		order.Description,
+		order.Notes,
+		order.OrderedBy,
+	).Scan(&order.ID, &order.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating encounter order: %w", err)
+	}
+
+	return nil
+}
+
+func (er *encounterRepository) FetchOrders(c context.Context, encounterID uuid.UUID) ([]domain.EncounterOrder, error) {
+	query := `
+		SELECT id, encounter_id, patient_id, kind, description, COALESCE(notes, ''), ordered_by, created_at
+		FROM encounter_orders
+		WHERE encounter_id = $1
+		ORDER BY created_at
+	`
+	rows, err := er.database.QueryContext(c, query, encounterID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching encounter orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []domain.EncounterOrder{}
+	for rows.Next() {
+		var order domain.EncounterOrder
+		if err := rows.Scan(
+			&order.ID,
+			&order.EncounterID,
+			&order.PatientID,
+			&order.Kind,
+			&order.Description,
+			&order.Notes,
+			&order.OrderedBy,
+			&order.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning encounter order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating encounter orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (er *encounterRepository) fetchEncounter(c context.Context, query string, args ...interface{}) (*domain.Encounter, error) {
+	encounter := &domain.Encounter{}
+	err := scanEncounter(er.database.QueryRowContext(c, query, args...), encounter)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching encounter: %w", err)
+	}
+
+	return encounter, nil
+}
+
+func scanEncounter(row interface{ Scan(...interface{}) error }, encounter *domain.Encounter) error {
+	return row.Scan(
+		&encounter.ID,
+		&encounter.PatientID,
+		&encounter.DoctorID,
+		&encounter.AppointmentID,
+		&encounter.Status,
+		&encounter.Reason,
+		&encounter.Notes,
+		&encounter.OpenedBy,
+		&encounter.OpenedAt,
+		&encounter.ClosedBy,
+		&encounter.ClosedAt,
+		&encounter.UpdatedAt,
+	)
+}
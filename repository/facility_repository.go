@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const facilityColumns = `id, name, timezone, created_at, updated_at`
+
+type facilityRepository struct {
+	database *sql.DB
+}
+
+func NewFacilityRepository(db *sql.DB) domain.FacilityRepository {
+	return &facilityRepository{
+		database: db,
+	}
+}
+
+func (fr *facilityRepository) Create(c context.Context, facility *domain.Facility) error {
+	query := `
+		INSERT INTO facilities (name, timezone)
+		VALUES ($1, $2)
+		RETURNING ` + facilityColumns + `
+	`
+	err := scanFacility(fr.database.QueryRowContext(c, query, facility.Name, facility.Timezone), facility)
+	if err != nil {
+		return facilityError("error creating facility", err)
+	}
+
+	return nil
+}
+
+func (fr *facilityRepository) Fetch(c context.Context) ([]domain.Facility, error) {
+	query := `
+		SELECT ` + facilityColumns + `
+		FROM facilities
+		ORDER BY name
+	`
+	rows, err := fr.database.QueryContext(c, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching facilities: %w", err)
+	}
+	defer rows.Close()
+
+	var facilities []domain.Facility
+	for rows.Next() {
+		var facility domain.Facility
+		if err := scanFacility(rows, &facility); err != nil {
+			return nil, fmt.Errorf("error scanning facility: %w", err)
+		}
+		facilities = append(facilities, facility)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating facilities: %w", err)
+	}
+
+	return facilities, nil
+}
+
+func (fr *facilityRepository) FetchByID(c context.Context, id uuid.UUID) (domain.Facility, error) {
+	query := `
+		SELECT ` + facilityColumns + `
+		FROM facilities
+		WHERE id = $1
+	`
+	var facility domain.Facility
+	err := scanFacility(fr.database.QueryRowContext(c, query, id), &facility)
+	if err == sql.ErrNoRows {
+		return domain.Facility{}, domain.ErrFacilityNotFound
+	}
+	if err != nil {
+		return domain.Facility{}, fmt.Errorf("error fetching facility: %w", err)
+	}
+
+	return facility, nil
+}
+
+// Update changes the facility. Its doctors refer to it by ID, so they follow
+// a rename.
+func (fr *facilityRepository) Update(c context.Context, facility *domain.Facility) error {
+	query := `
+		UPDATE facilities
+		SET name = $1, timezone = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING ` + facilityColumns + `
+	`
+	err := scanFacility(fr.database.QueryRowContext(c, query, facility.Name, facility.Timezone, facility.ID), facility)
+	if err == sql.ErrNoRows {
+		return domain.ErrFacilityNotFound
+	}
+	if err != nil {
+		return facilityError("error updating facility", err)
+	}
+
+	return nil
+}
+
+// Delete removes the facility unless doctors still work there.
+func (fr *facilityRepository) Delete(c context.Context, id uuid.UUID) error {
+	result, err := fr.database.ExecContext(c, `DELETE FROM facilities WHERE id = $1`, id)
+	if err != nil {
+		return facilityError("error deleting facility", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting facility: %w", err)
+	}
+	if deleted == 0 {
+		return domain.ErrFacilityNotFound
+	}
+
+	return nil
+}
+
+func scanFacility(row interface{ Scan(...interface{}) error }, facility *domain.Facility) error {
+	return row.Scan(
+		&facility.ID,
+		&facility.Name,
+		&facility.Timezone,
+		&facility.CreatedAt,
+		&facility.UpdatedAt,
+	)
+}
+
+func facilityError(message string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return domain.ErrDuplicateFacility
+		case "23503":
+			// doctors.facility_id still refers to it.
+			return domain.ErrFacilityInUse
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
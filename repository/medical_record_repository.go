@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"hms-api/domain"
 
 	"github.com/google/uuid"
 )
 
+const medicalRecordColumns = `id, patient_id, doctor_id, diagnosis, diagnoses, COALESCE(treatment, ''), status, version, amends_id, encounter_id, updated_by, created_at, updated_at`
+
 type medicalRecordRepository struct {
 	database *sql.DB
 }
@@ -19,70 +22,61 @@ func NewMedicalRecordRepository(db *sql.DB) domain.MedicalRecordRepository {
 	}
 }
 
-func (mr *medicalRecordRepository) Create(c context.Context, record *domain.MedicalRecord) error {
+func (mr *medicalRecordRepository) Create(c context.Context, record *domain.MedicalRecord, reason string) error {
+	diagnoses, err := json.Marshal(record.Diagnoses)
+	if err != nil {
+		return fmt.Errorf("error encoding diagnoses: %w", err)
+	}
+
+	tx, err := mr.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error creating medical record: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-        INSERT INTO medical_records (patient_id, doctor_id, diagnosis, treatment)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id, created_at
+        INSERT INTO medical_records (patient_id, doctor_id, diagnosis, diagnoses, treatment, amends_id, encounter_id, updated_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING ` + medicalRecordColumns + `
     `
-	return mr.database.QueryRowContext(c, query,
+	err = scanMedicalRecord(tx.QueryRowContext(c, query,
 		record.PatientID,
 		record.DoctorID,
 		record.Diagnosis,
+		diagnoses,
 		record.Treatment,
-	).Scan(&record.ID, &record.CreatedAt)
-}
-
-func (mr *medicalRecordRepository) Fetch(c context.Context) ([]domain.MedicalRecord, error) {
-	query := `
-        SELECT id, patient_id, doctor_id, diagnosis, treatment, created_at
-        FROM medical_records
-    `
-	rows, err := mr.database.QueryContext(c, query)
+		record.AmendsID,
+		record.EncounterID,
+		record.UpdatedBy,
+	), record)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching medical records: %w", err)
+		return fmt.Errorf("error creating medical record: %w", err)
 	}
-	defer rows.Close()
 
-	var records []domain.MedicalRecord
-	for rows.Next() {
-		var record domain.MedicalRecord
-		if err := rows.Scan(
-			&record.ID,
-			&record.PatientID,
-			&record.DoctorID,
-			&record.Diagnosis,
-			&record.Treatment,
-			&record.CreatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("error scanning medical record: %w", err)
-		}
-		records = append(records, record)
+	if err := insertMedicalRecordVersion(c, tx, record, reason); err != nil {
+		return err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating medical records: %w", err)
-	}
+	return tx.Commit()
+}
 
-	return records, nil
+func (mr *medicalRecordRepository) Fetch(c context.Context) ([]domain.MedicalRecord, error) {
+	query := `
+        SELECT ` + medicalRecordColumns + `
+        FROM medical_records
+    `
+	return mr.fetchRecords(c, query)
 }
 
 func (mr *medicalRecordRepository) FetchByID(c context.Context, id uuid.UUID) (*domain.MedicalRecord, error) {
 	query := `
-        SELECT id, patient_id, doctor_id, diagnosis, treatment, created_at
+        SELECT ` + medicalRecordColumns + `
         FROM medical_records
         WHERE id = $1
     `
 
 	record := &domain.MedicalRecord{}
-	err := mr.database.QueryRowContext(c, query, id).Scan(
-		&record.ID,
-		&record.PatientID,
-		&record.DoctorID,
-		&record.Diagnosis,
-		&record.Treatment,
-		&record.CreatedAt,
-	)
+	err := scanMedicalRecord(mr.database.QueryRowContext(c, query, id), record)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -96,140 +90,203 @@ func (mr *medicalRecordRepository) FetchByID(c context.Context, id uuid.UUID) (*
 
 func (mr *medicalRecordRepository) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.MedicalRecord, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, diagnosis, treatment, created_at
+		SELECT ` + medicalRecordColumns + `
 		FROM medical_records
 		WHERE patient_id = $1
 	`
+	return mr.fetchRecords(c, query, patientID)
+}
+
+func (mr *medicalRecordRepository) FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]domain.MedicalRecord, error) {
+	query := `
+		SELECT ` + medicalRecordColumns + `
+		FROM medical_records
+		WHERE doctor_id = $1
+		`
+	return mr.fetchRecords(c, query, doctorID)
+}
+
+// FetchByEncounterID lists the records made in the encounter, oldest first.
+func (mr *medicalRecordRepository) FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]domain.MedicalRecord, error) {
+	query := `
+		SELECT ` + medicalRecordColumns + `
+		FROM medical_records
+		WHERE encounter_id = $1
+		ORDER BY created_at
+	`
+	return mr.fetchRecords(c, query, encounterID)
+}
+
+// FetchAmendments lists the records amending the record, oldest first.
+func (mr *medicalRecordRepository) FetchAmendments(c context.Context, id uuid.UUID) ([]domain.MedicalRecord, error) {
+	query := `
+		SELECT ` + medicalRecordColumns + `
+		FROM medical_records
+		WHERE amends_id = $1
+		ORDER BY created_at
+	`
+	return mr.fetchRecords(c, query, id)
+}
 
-	rows, err := mr.database.QueryContext(c, query, patientID)
+// Revise locks the record so concurrent changes get consecutive versions.
+func (mr *medicalRecordRepository) Revise(c context.Context, record *domain.MedicalRecord, reason string) error {
+	diagnoses, err := json.Marshal(record.Diagnoses)
 	if err != nil {
-		fmt.Println("Error executing query:", err)
-		return []domain.MedicalRecord{}, err
+		return fmt.Errorf("error encoding diagnoses: %w", err)
 	}
 
-	defer rows.Close()
-	var records []domain.MedicalRecord
-	
-	for rows.Next() {
-		var record domain.MedicalRecord
-		err = rows.Scan(
-			&record.ID,
-			&record.PatientID,
-			&record.DoctorID,
-			&record.Diagnosis,
-			&record.Treatment,
-			&record.CreatedAt,
-		)
+	tx, err := mr.database.BeginTx(c, nil)
+	if err != nil {
+		return fmt.Errorf("error updating medical record: %w", err)
+	}
+	defer tx.Rollback()
 
-		if err != nil {
-			fmt.Println("Error scanning row:", err)
-			return []domain.MedicalRecord{}, err
-		}
+	var status domain.MedicalRecordStatus
+	err = tx.QueryRowContext(c, `SELECT status FROM medical_records WHERE id = $1 FOR UPDATE`, record.ID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return domain.ErrMedicalRecordNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error updating medical record: %w", err)
+	}
+	if status == domain.MedicalRecordEnteredInError {
+		return domain.ErrMedicalRecordEnteredInError
+	}
 
-		records = append(records, record)
+	query := `
+        UPDATE medical_records
+        SET diagnosis = $1, diagnoses = $2, treatment = $3, status = $4, updated_by = $5, version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $6
+        RETURNING ` + medicalRecordColumns + `
+    `
+	err = scanMedicalRecord(tx.QueryRowContext(c, query,
+		record.Diagnosis,
+		diagnoses,
+		record.Treatment,
+		record.Status,
+		record.UpdatedBy,
+		record.ID,
+	), record)
+	if err != nil {
+		return fmt.Errorf("error updating medical record: %w", err)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	if err := insertMedicalRecordVersion(c, tx, record, reason); err != nil {
+		return err
 	}
 
-	return records, nil
+	return tx.Commit()
 }
 
-func (mr *medicalRecordRepository) FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]domain.MedicalRecord, error) {
+// FetchVersions lists every version of the record, oldest first.
+func (mr *medicalRecordRepository) FetchVersions(c context.Context, id uuid.UUID) ([]domain.MedicalRecordVersion, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, diagnosis, treatment, created_at
-		FROM medical_records
-		WHERE doctor_id = $1
-		`
-
-	rows, err := mr.database.QueryContext(c, query, doctorID)
+		SELECT medical_record_id, version, diagnosis, diagnoses, COALESCE(treatment, ''), status, COALESCE(reason, ''), changed_by, created_at
+		FROM medical_record_versions
+		WHERE medical_record_id = $1
+		ORDER BY version
+	`
+	rows, err := mr.database.QueryContext(c, query, id)
 	if err != nil {
-		fmt.Println("Error executing query:", err)
-		return []domain.MedicalRecord{}, err
+		return nil, fmt.Errorf("error fetching medical record versions: %w", err)
 	}
-
 	defer rows.Close()
-	var records []domain.MedicalRecord
 
+	var versions []domain.MedicalRecordVersion
 	for rows.Next() {
-		var record domain.MedicalRecord
-		err = rows.Scan(
-			&record.ID,
-			&record.PatientID,
-			&record.DoctorID,
-			&record.Diagnosis,
-			&record.Treatment,
-			&record.CreatedAt,
+		var (
+			version   domain.MedicalRecordVersion
+			diagnoses []byte
 		)
-
-		if err != nil {
-			fmt.Println("Error scanning row:", err)
-			return []domain.MedicalRecord{}, err
+		if err := rows.Scan(
+			&version.MedicalRecordID,
+			&version.Version,
+			&version.Diagnosis,
+			&diagnoses,
+			&version.Treatment,
+			&version.Status,
+			&version.Reason,
+			&version.ChangedBy,
+			&version.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning medical record version: %w", err)
 		}
-
-		records = append(records, record)
+		if err := json.Unmarshal(diagnoses, &version.Diagnoses); err != nil {
+			return nil, fmt.Errorf("error decoding diagnoses: %w", err)
+		}
+		versions = append(versions, version)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error iterating medical record versions: %w", err)
 	}
 
-	return records, nil
-	
+	return versions, nil
 }
 
-func (mr *medicalRecordRepository) Update(c context.Context, record *domain.MedicalRecord) error {
-	query := `
-        UPDATE medical_records
-        SET patient_id = $1, doctor_id = $2, diagnosis = $3, treatment = $4
-        WHERE id = $5
-    `
-
-	result, err := mr.database.ExecContext(c, query,
-		record.PatientID,
-		record.DoctorID,
-		record.Diagnosis,
-		record.Treatment,
-		record.ID,
-	)
-
+func (mr *medicalRecordRepository) fetchRecords(c context.Context, query string, args ...interface{}) ([]domain.MedicalRecord, error) {
+	rows, err := mr.database.QueryContext(c, query, args...)
 	if err != nil {
-		return fmt.Errorf("error updating medical record: %w", err)
+		return nil, fmt.Errorf("error fetching medical records: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
+	var records []domain.MedicalRecord
+	for rows.Next() {
+		var record domain.MedicalRecord
+		if err := scanMedicalRecord(rows, &record); err != nil {
+			return nil, fmt.Errorf("error scanning medical record: %w", err)
+		}
+		records = append(records, record)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("medical record with ID %s not found", record.ID)
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating medical records: %w", err)
 	}
 
-	return nil
+	return records, nil
 }
 
-
-func (mr *medicalRecordRepository) Delete(c context.Context, id uuid.UUID) error {
+// insertMedicalRecordVersion copies the record as saved into its history
+// within tx.
+func insertMedicalRecordVersion(c context.Context, tx *sql.Tx, record *domain.MedicalRecord, reason string) error {
 	query := `
-        DELETE FROM medical_records
-        WHERE id = $1
-    `
-
-	result, err := mr.database.ExecContext(c, query, id)
+		INSERT INTO medical_record_versions (medical_record_id, version, diagnosis, diagnoses, treatment, status, reason, changed_by)
+		SELECT id, version, diagnosis, diagnoses, treatment, status, NULLIF($2, ''), updated_by
+		FROM medical_records
+		WHERE id = $1
+	`
+	_, err := tx.ExecContext(c, query,
+		record.ID,
+		reason,
+	)
 	if err != nil {
-		return fmt.Errorf("error deleting medical record: %w", err)
+		return fmt.Errorf("error saving medical record version: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
-	}
+	return nil
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("medical record with ID %s not found", id)
+func scanMedicalRecord(row interface{ Scan(...interface{}) error }, record *domain.MedicalRecord) error {
+	var diagnoses []byte
+	err := row.Scan(
+		&record.ID,
+		&record.PatientID,
+		&record.DoctorID,
+		&record.Diagnosis,
+		&diagnoses,
+		&record.Treatment,
+		&record.Status,
+		&record.Version,
+		&record.AmendsID,
+		&record.EncounterID,
+		&record.UpdatedBy,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return json.Unmarshal(diagnoses, &record.Diagnoses)
 }
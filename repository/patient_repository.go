@@ -101,6 +101,33 @@ func (pr *patientRepository) FetchByID(c context.Context, id uuid.UUID) (domain.
 	return patient, nil
 }
 
+func (pr *patientRepository) FetchByUserID(c context.Context, userID uuid.UUID) (domain.Patient, error) {
+	var patient domain.Patient
+	query := `
+		SELECT id, user_id, cpf, date_birth, phone, address, created_at
+		FROM patients WHERE user_id = $1
+	`
+
+	err := pr.database.QueryRowContext(c, query, userID).Scan(
+		&patient.ID,
+		&patient.UserId,
+		&patient.CPF,
+		&patient.DateBirth,
+		&patient.Phone,
+		&patient.Address,
+		&patient.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Patient{}, nil
+		}
+		return domain.Patient{}, err
+	}
+
+	return patient, nil
+}
+
 func (pr *patientRepository) FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]domain.Patient, error) {
 	query := `
 		SELECT p.id, p.user_id, p.cpf, p.date_birth, p.phone, p.address, p.created_at
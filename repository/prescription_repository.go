@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"hms-api/domain"
 
 	"github.com/google/uuid"
 )
 
+const prescriptionColumns = `id, patient_id, doctor_id, medical_record_id, encounter_id, medication_details, COALESCE(allergy_override_reason, ''), allergy_warnings, created_at`
+
 type prescriptionRepository struct {
 	database *sql.DB
 }
@@ -20,22 +23,30 @@ func NewPrescriptionRepository(db *sql.DB) domain.PrescriptionRepository {
 }
 
 func (pr *prescriptionRepository) Create(c context.Context, prescription *domain.Prescription) error {
+	warnings, err := json.Marshal(allergyWarnings(prescription.AllergyWarnings))
+	if err != nil {
+		return fmt.Errorf("error encoding allergy warnings: %w", err)
+	}
+
 	query := `
-		INSERT INTO prescriptions (patient_id, doctor_id, medical_record_id, medication_details)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO prescriptions (patient_id, doctor_id, medical_record_id, encounter_id, medication_details, allergy_override_reason, allergy_warnings)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
 		RETURNING id, created_at
 `
 	return pr.database.QueryRowContext(c, query,
 		prescription.PatientID,
 		prescription.DoctorID,
 		prescription.MedicalRecordID,
+		prescription.EncounterID,
 		prescription.MedicationDetails,
+		prescription.AllergyOverrideReason,
+		warnings,
 	).Scan(&prescription.ID, &prescription.CreatedAt)
 }
 
 func (pr *prescriptionRepository) Fetch(c context.Context) ([]domain.Prescription, error) {
 	query := `
-	SELECT id, patient_id, doctor_id, medical_record_id, medication_details, created_at
+	SELECT ` + prescriptionColumns + `
     FROM prescriptions
 `
 	rows, err := pr.database.QueryContext(c, query)
@@ -47,14 +58,7 @@ func (pr *prescriptionRepository) Fetch(c context.Context) ([]domain.Prescriptio
 	var prescriptions []domain.Prescription
 	for rows.Next() {
 		var prescription domain.Prescription
-		if err := rows.Scan(
-			&prescription.ID,
-			&prescription.PatientID,
-			&prescription.DoctorID,
-			&prescription.MedicalRecordID,
-			&prescription.MedicationDetails,
-			&prescription.CreatedAt,
-		); err != nil {
+		if err := scanPrescription(rows, &prescription); err != nil {
 			return nil, fmt.Errorf("error scanning prescription: %w", err)
 		}
 
@@ -70,19 +74,12 @@ func (pr *prescriptionRepository) Fetch(c context.Context) ([]domain.Prescriptio
 
 func (pr *prescriptionRepository) FetchByID(c context.Context, id uuid.UUID) (*domain.Prescription, error) {
 	query := `
-	SELECT id, patient_id, doctor_id, medical_record_id, medication_details, created_at
+	SELECT ` + prescriptionColumns + `
 	FROM prescriptions
 	WHERE id = $1
 `
 	prescription := &domain.Prescription{}
-	err := pr.database.QueryRowContext(c, query, id).Scan(
-		&prescription.ID,
-		&prescription.PatientID,
-		&prescription.DoctorID,
-		&prescription.MedicalRecordID,
-		&prescription.MedicationDetails,
-		&prescription.CreatedAt,
-	)
+	err := scanPrescription(pr.database.QueryRowContext(c, query, id), prescription)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -96,7 +93,7 @@ func (pr *prescriptionRepository) FetchByID(c context.Context, id uuid.UUID) (*d
 
 func (pr *prescriptionRepository) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.Prescription, error) {
 	query := `
-	SELECT id, patient_id, doctor_id, medical_record_id, medication_details, created_at
+	SELECT ` + prescriptionColumns + `
 	FROM prescriptions
 	WHERE patient_id = $1
 `
@@ -109,14 +106,7 @@ func (pr *prescriptionRepository) FetchByPatientID(c context.Context, patientID
 	var prescriptions []domain.Prescription
 	for rows.Next() {
 		var prescription domain.Prescription
-		if err := rows.Scan(
-			&prescription.ID,
-			&prescription.PatientID,
-			&prescription.DoctorID,
-			&prescription.MedicalRecordID,
-			&prescription.MedicationDetails,
-			&prescription.CreatedAt,
-		); err != nil {
+		if err := scanPrescription(rows, &prescription); err != nil {
 			return nil, fmt.Errorf("error scanning prescription: %w", err)
 		}
 
@@ -132,7 +122,7 @@ func (pr *prescriptionRepository) FetchByPatientID(c context.Context, patientID
 
 func (pr *prescriptionRepository) FetchByDoctorID(c context.Context, doctorID uuid.UUID) ([]domain.Prescription, error) {
 	query := `
-	SELECT id, patient_id, doctor_id, medical_record_id, medication_details, created_at
+	SELECT ` + prescriptionColumns + `
 	FROM prescriptions
 	WHERE doctor_id = $1
 `
@@ -145,14 +135,7 @@ func (pr *prescriptionRepository) FetchByDoctorID(c context.Context, doctorID uu
 	var prescriptions []domain.Prescription
 	for rows.Next() {
 		var prescription domain.Prescription
-		if err := rows.Scan(
-			&prescription.ID,
-			&prescription.PatientID,
-			&prescription.DoctorID,
-			&prescription.MedicalRecordID,
-			&prescription.MedicationDetails,
-			&prescription.CreatedAt,
-		); err != nil {
+		if err := scanPrescription(rows, &prescription); err != nil {
 			return nil, fmt.Errorf("error scanning prescription: %w", err)
 		}
 
@@ -166,31 +149,63 @@ func (pr *prescriptionRepository) FetchByDoctorID(c context.Context, doctorID uu
 	return prescriptions, nil
 }
 
-func (pr *prescriptionRepository) Update(c context.Context, prescription *domain.Prescription) error {
+func (pr *prescriptionRepository) FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]domain.Prescription, error) {
 	query := `
-	UPDATE prescriptions
-	SET patient_id = $1, doctor_id = $2, medical_record_id = $3, medication_details = $4
-	WHERE id = $5
+	SELECT ` + prescriptionColumns + `
+	FROM prescriptions
+	WHERE encounter_id = $1
+	ORDER BY created_at
 `
-	result, err := pr.database.ExecContext(c, query,
-		&prescription.PatientID,
-		&prescription.DoctorID,
-		&prescription.MedicalRecordID,
-		&prescription.MedicationDetails,
-		&prescription.ID,
-	)
-
+	rows, err := pr.database.QueryContext(c, query, encounterID)
 	if err != nil {
-		return fmt.Errorf("error updating prescription: %w", err)
+		return nil, fmt.Errorf("error fetching prescription by encounter ID: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	var prescriptions []domain.Prescription
+	for rows.Next() {
+		var prescription domain.Prescription
+		if err := scanPrescription(rows, &prescription); err != nil {
+			return nil, fmt.Errorf("error scanning prescription: %w", err)
+		}
+
+		prescriptions = append(prescriptions, prescription)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prescription: %w", err)
+	}
+
+	return prescriptions, nil
+}
+
+func (pr *prescriptionRepository) Update(c context.Context, prescription *domain.Prescription) error {
+	warnings, err := json.Marshal(allergyWarnings(prescription.AllergyWarnings))
 	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
+		return fmt.Errorf("error encoding allergy warnings: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("prescription with ID %s not found", prescription.ID)
+	query := `
+	UPDATE prescriptions
+	SET patient_id = $1, doctor_id = $2, medical_record_id = $3, medication_details = $4, allergy_override_reason = NULLIF($5, ''), allergy_warnings = $6
+	WHERE id = $7
+	RETURNING ` + prescriptionColumns + `
+`
+	err = scanPrescription(pr.database.QueryRowContext(c, query,
+		prescription.PatientID,
+		prescription.DoctorID,
+		prescription.MedicalRecordID,
+		prescription.MedicationDetails,
+		prescription.AllergyOverrideReason,
+		warnings,
+		prescription.ID,
+	), prescription)
+
+	if err == sql.ErrNoRows {
+		return domain.ErrPrescriptionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error updating prescription: %w", err)
 	}
 
 	return nil
@@ -218,3 +233,32 @@ func (pr *prescriptionRepository) Delete(c context.Context, id uuid.UUID) error
 	return nil
 
 }
+
+func scanPrescription(row interface{ Scan(...interface{}) error }, prescription *domain.Prescription) error {
+	var warnings []byte
+	err := row.Scan(
+		&prescription.ID,
+		&prescription.PatientID,
+		&prescription.DoctorID,
+		&prescription.MedicalRecordID,
+		&prescription.EncounterID,
+		&prescription.MedicationDetails,
+		&prescription.AllergyOverrideReason,
+		&warnings,
+		&prescription.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	prescription.AllergyWarnings = nil
+	return json.Unmarshal(warnings, &prescription.AllergyWarnings)
+}
+
+// allergyWarnings stores prescriptions without warnings as an empty list.
+func allergyWarnings(warnings []domain.AllergyWarning) []domain.AllergyWarning {
+	if warnings == nil {
+		return []domain.AllergyWarning{}
+	}
+	return warnings
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type reminderRepository struct {
+	database *sql.DB
+}
+
+func NewReminderRepository(db *sql.DB) domain.ReminderRepository {
+	return &reminderRepository{
+		database: db,
+	}
+}
+
+// appointmentContactQuery selects the appointments of the CTE named a along
+// with their patient's contact details and preferences.
+const appointmentContactQuery = `
+		SELECT a.*, pu.username, pu.email, COALESCE(p.phone, ''), du.username, du.email, COALESCE(d.specialty, ''),
+			np.patient_id IS NOT NULL, COALESCE(np.language, ''), COALESCE(np.channels, '{}'), COALESCE(np.push_token, ''),
+			COALESCE(pu.timezone, '')
+		FROM a
+		JOIN patients p ON p.id = a.patient_id
+		JOIN users pu ON pu.id = p.user_id
+		JOIN doctors d ON d.id = a.doctor_id
+		JOIN users du ON du.id = d.user_id
+		LEFT JOIN notification_preferences np ON np.patient_id = a.patient_id
+`
+
+// FetchDue lists requested and confirmed appointments starting after from and
+// up to to that haven't been reminded of for offsetMinutes yet, with the
+// patient's contact details and preferences.
+func (rr *reminderRepository) FetchDue(c context.Context, from time.Time, to time.Time, offsetMinutes int) ([]domain.AppointmentContact, error) {
+	query := `
+		WITH a AS (
+			SELECT ` + appointmentColumns + `
+			FROM appointments
+			WHERE status IN ('requested', 'confirmed')
+				AND appointment_date > $1 AND appointment_date <= $2
+				AND NOT EXISTS (
+					SELECT 1 FROM appointment_reminders r
+					WHERE r.appointment_id = appointments.id AND r.offset_minutes = $3
+				)
+		)` + appointmentContactQuery + `
+		ORDER BY a.appointment_date
+	`
+	rows, err := rr.database.QueryContext(c, query, from, to, offsetMinutes)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching due reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var contacts []domain.AppointmentContact
+	for rows.Next() {
+		var contact domain.AppointmentContact
+		if err := scanAppointmentContact(rows, &contact); err != nil {
+			return nil, fmt.Errorf("error scanning due reminder: %w", err)
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due reminders: %w", err)
+	}
+
+	return contacts, nil
+}
+
+func (rr *reminderRepository) FetchContact(c context.Context, appointmentID uuid.UUID) (domain.AppointmentContact, error) {
+	query := `
+		WITH a AS (
+			SELECT ` + appointmentColumns + `
+			FROM appointments
+			WHERE id = $1
+		)` + appointmentContactQuery
+
+	var contact domain.AppointmentContact
+	err := scanAppointmentContact(rr.database.QueryRowContext(c, query, appointmentID), &contact)
+	if err == sql.ErrNoRows {
+		return domain.AppointmentContact{}, domain.ErrAppointmentNotFound
+	}
+	if err != nil {
+		return domain.AppointmentContact{}, fmt.Errorf("error fetching appointment contact: %w", err)
+	}
+
+	return contact, nil
+}
+
+// FetchPatientContact returns the contact details of a patient about the
+// doctor, for notifications that come before there is an appointment, such as
+// waitlist offers. Only the patient, the doctor and the timezone of the
+// doctor's facility are set on the contact's appointment.
+func (rr *reminderRepository) FetchPatientContact(c context.Context, patientID uuid.UUID, doctorID uuid.UUID) (domain.AppointmentContact, error) {
+	query := `
+		SELECT p.id, d.id, pu.username, pu.email, COALESCE(p.phone, ''), du.username, du.email, COALESCE(d.specialty, ''),
+			np.patient_id IS NOT NULL, COALESCE(np.language, ''), COALESCE(np.channels, '{}'), COALESCE(np.push_token, ''),
+			COALESCE(pu.timezone, ''), COALESCE(f.timezone, '')
+		FROM patients p
+		JOIN users pu ON pu.id = p.user_id
+		JOIN doctors d ON d.id = $2
+		JOIN users du ON du.id = d.user_id
+		LEFT JOIN facilities f ON f.id = d.facility_id
+		LEFT JOIN notification_preferences np ON np.patient_id = p.id
+		WHERE p.id = $1
+	`
+	var contact domain.AppointmentContact
+	var channels []string
+	var timezone string
+
+	err := rr.database.QueryRowContext(c, query, patientID, doctorID).Scan(
+		&contact.Appointment.PatientID,
+		&contact.Appointment.DoctorID,
+		&contact.PatientName,
+		&contact.PatientEmail,
+		&contact.PatientPhone,
+		&contact.DoctorName,
+		&contact.DoctorEmail,
+		&contact.Specialty,
+		&contact.HasPreference,
+		&contact.Preference.Language,
+		pq.Array(&channels),
+		&contact.Preference.PushToken,
+		&contact.PatientTimezone,
+		&timezone,
+	)
+	if err == sql.ErrNoRows {
+		return domain.AppointmentContact{}, domain.ErrPatientNotFound
+	}
+	if err != nil {
+		return domain.AppointmentContact{}, fmt.Errorf("error fetching patient contact: %w", err)
+	}
+
+	contact.Appointment.Localize(timezone)
+	contact.Preference.PatientID = patientID
+	contact.Preference.Channels = toChannels(channels)
+	return contact, nil
+}
+
+// Record stores a reminder, reporting false when one was already recorded
+// for the same appointment, offset and channel.
+func (rr *reminderRepository) Record(c context.Context, reminder *domain.AppointmentReminder) (bool, error) {
+	query := `
+		INSERT INTO appointment_reminders (appointment_id, offset_minutes, channel, recipient, error)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (appointment_id, offset_minutes, channel) DO NOTHING
+		RETURNING id, sent_at
+	`
+	err := rr.database.QueryRowContext(c, query,
+		reminder.AppointmentID,
+		reminder.OffsetMinutes,
+		reminder.Channel,
+		reminder.Recipient,
+		reminder.Error,
+	).Scan(&reminder.ID, &reminder.SentAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error recording reminder: %w", err)
+	}
+
+	return true, nil
+}
+
+func (rr *reminderRepository) FetchPreference(c context.Context, patientID uuid.UUID) (domain.NotificationPreference, error) {
+	query := `
+		SELECT patient_id, language, channels, COALESCE(push_token, ''), updated_at
+		FROM notification_preferences
+		WHERE patient_id = $1
+	`
+	var preference domain.NotificationPreference
+	var channels []string
+
+	err := rr.database.QueryRowContext(c, query, patientID).Scan(
+		&preference.PatientID,
+		&preference.Language,
+		pq.Array(&channels),
+		&preference.PushToken,
+		&preference.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.NotificationPreference{}, nil
+	}
+	if err != nil {
+		return domain.NotificationPreference{}, fmt.Errorf("error fetching notification preferences: %w", err)
+	}
+
+	preference.Channels = toChannels(channels)
+	return preference, nil
+}
+
+func (rr *reminderRepository) UpsertPreference(c context.Context, preference *domain.NotificationPreference) error {
+	channels := make([]string, 0, len(preference.Channels))
+	for _, channel := range preference.Channels {
+		channels = append(channels, string(channel))
+	}
+
+	query := `
+		INSERT INTO notification_preferences (patient_id, language, channels, push_token)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		ON CONFLICT (patient_id) DO UPDATE
+		SET language = EXCLUDED.language, channels = EXCLUDED.channels, push_token = EXCLUDED.push_token, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+	err := rr.database.QueryRowContext(c, query, preference.PatientID, preference.Language, pq.Array(channels), preference.PushToken).Scan(&preference.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving notification preferences: %w", err)
+	}
+
+	return nil
+}
+
+func scanAppointmentContact(row interface{ Scan(...interface{}) error }, contact *domain.AppointmentContact) error {
+	var channels []string
+
+	err := scanAppointment(withExtraColumns(row,
+		&contact.PatientName,
+		&contact.PatientEmail,
+		&contact.PatientPhone,
+		&contact.DoctorName,
+		&contact.DoctorEmail,
+		&contact.Specialty,
+		&contact.HasPreference,
+		&contact.Preference.Language,
+		pq.Array(&channels),
+		&contact.Preference.PushToken,
+		&contact.PatientTimezone,
+	), &contact.Appointment)
+	if err != nil {
+		return err
+	}
+
+	contact.Preference.PatientID = contact.Appointment.PatientID
+	contact.Preference.Channels = toChannels(channels)
+	return nil
+}
+
+func toChannels(names []string) []domain.NotificationChannel {
+	channels := make([]domain.NotificationChannel, 0, len(names))
+	for _, name := range names {
+		channels = append(channels, domain.NotificationChannel(name))
+	}
+	return channels
+}
+
+// extraColumns scans a row whose leading columns are read by another scan
+// function, appending the destinations for the columns that follow.
+type extraColumns struct {
+	row   interface{ Scan(...interface{}) error }
+	extra []interface{}
+}
+
+func withExtraColumns(row interface{ Scan(...interface{}) error }, extra ...interface{}) extraColumns {
+	return extraColumns{row: row, extra: extra}
+}
+
+func (e extraColumns) Scan(dest ...interface{}) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
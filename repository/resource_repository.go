@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+)
+
+const resourceColumns = `id, name, kind, category, active, created_at, updated_at`
+
+type resourceRepository struct {
+	database *sql.DB
+}
+
+func NewResourceRepository(db *sql.DB) domain.ResourceRepository {
+	return &resourceRepository{
+		database: db,
+	}
+}
+
+func (rr *resourceRepository) Create(c context.Context, resource *domain.Resource) error {
+	query := `
+		INSERT INTO resources (name, kind, category)
+		VALUES ($1, $2, $3)
+		RETURNING ` + resourceColumns + `
+	`
+	err := scanResource(rr.database.QueryRowContext(c, query, resource.Name, resource.Kind, resource.Category), resource)
+	if err != nil {
+		return fmt.Errorf("error creating resource: %w", err)
+	}
+
+	return nil
+}
+
+func (rr *resourceRepository) Fetch(c context.Context) ([]domain.Resource, error) {
+	query := `
+		SELECT ` + resourceColumns + `
+		FROM resources
+		ORDER BY kind DESC, category, name
+	`
+	rows, err := rr.database.QueryContext(c, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching resources: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []domain.Resource
+	for rows.Next() {
+		var resource domain.Resource
+		if err := scanResource(rows, &resource); err != nil {
+			return nil, fmt.Errorf("error scanning resource: %w", err)
+		}
+		resources = append(resources, resource)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating resources: %w", err)
+	}
+
+	return resources, nil
+}
+
+func (rr *resourceRepository) FetchByID(c context.Context, id uuid.UUID) (domain.Resource, error) {
+	query := `
+		SELECT ` + resourceColumns + `
+		FROM resources
+		WHERE id = $1
+	`
+	var resource domain.Resource
+	err := scanResource(rr.database.QueryRowContext(c, query, id), &resource)
+	if err == sql.ErrNoRows {
+		return domain.Resource{}, domain.ErrResourceNotFound
+	}
+	if err != nil {
+		return domain.Resource{}, fmt.Errorf("error fetching resource: %w", err)
+	}
+
+	return resource, nil
+}
+
+func (rr *resourceRepository) Update(c context.Context, resource *domain.Resource) error {
+	query := `
+		UPDATE resources
+		SET name = $1, kind = $2, category = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING ` + resourceColumns + `
+	`
+	err := scanResource(rr.database.QueryRowContext(c, query, resource.Name, resource.Kind, resource.Category, resource.ID), resource)
+	if err == sql.ErrNoRows {
+		return domain.ErrResourceNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error updating resource: %w", err)
+	}
+
+	return nil
+}
+
+func (rr *resourceRepository) Deactivate(c context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE resources
+		SET active = false, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	result, err := rr.database.ExecContext(c, query, id)
+	if err != nil {
+		return false, fmt.Errorf("error deactivating resource: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error deactivating resource: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func scanResource(row interface{ Scan(...interface{}) error }, resource *domain.Resource) error {
+	return row.Scan(
+		&resource.ID,
+		&resource.Name,
+		&resource.Kind,
+		&resource.Category,
+		&resource.Active,
+		&resource.CreatedAt,
+		&resource.UpdatedAt,
+	)
+}
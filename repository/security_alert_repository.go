@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hms-api/domain"
+
+	"github.com/google/uuid"
+)
+
+type securityAlertRepository struct {
+	database *sql.DB
+}
+
+func NewSecurityAlertRepository(db *sql.DB) domain.SecurityAlertRepository {
+	return &securityAlertRepository{
+		database: db,
+	}
+}
+
+const securityAlertColumns = `
+	id, user_id, COALESCE(user_role, ''), rule, severity, description, event_count, COALESCE(ip_address, ''),
+	window_start, window_end, status, acknowledged_by, acknowledged_at, resolved_by, resolved_at, COALESCE(resolution, ''), created_at
+`
+
+func scanSecurityAlert(row interface{ Scan(...interface{}) error }, alert *domain.SecurityAlert) error {
+	return row.Scan(
+		&alert.ID,
+		&alert.UserID,
+		&alert.UserRole,
+		&alert.Rule,
+		&alert.Severity,
+		&alert.Description,
+		&alert.EventCount,
+		&alert.IPAddress,
+		&alert.WindowStart,
+		&alert.WindowEnd,
+		&alert.Status,
+		&alert.AcknowledgedBy,
+		&alert.AcknowledgedAt,
+		&alert.ResolvedBy,
+		&alert.ResolvedAt,
+		&alert.Resolution,
+		&alert.CreatedAt,
+	)
+}
+
+func (sar *securityAlertRepository) Create(c context.Context, alert *domain.SecurityAlert) error {
+	query := `
+	INSERT INTO security_alerts (user_id, user_role, rule, severity, description, event_count, ip_address, window_start, window_end)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, status, created_at
+`
+	err := sar.database.QueryRowContext(c, query,
+		nullableUUID(alert.UserID),
+		alert.UserRole,
+		alert.Rule,
+		alert.Severity,
+		alert.Description,
+		alert.EventCount,
+		alert.IPAddress,
+		alert.WindowStart,
+		alert.WindowEnd,
+	).Scan(&alert.ID, &alert.Status, &alert.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating security alert: %w", err)
+	}
+
+	return nil
+}
+
+// Fetch lists alerts newest first. An empty status lists all of them.
+func (sar *securityAlertRepository) Fetch(c context.Context, status domain.AlertStatus) ([]domain.SecurityAlert, error) {
+	query := `
+	SELECT` + securityAlertColumns + `
+	FROM security_alerts
+	WHERE $1 = '' OR status = $1
+	ORDER BY created_at DESC
+`
+	rows, err := sar.database.QueryContext(c, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching security alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []domain.SecurityAlert
+	for rows.Next() {
+		var alert domain.SecurityAlert
+		if err := scanSecurityAlert(rows, &alert); err != nil {
+			return nil, fmt.Errorf("error scanning security alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating security alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func (sar *securityAlertRepository) FetchByID(c context.Context, id uuid.UUID) (*domain.SecurityAlert, error) {
+	query := `
+	SELECT` + securityAlertColumns + `
+	FROM security_alerts
+	WHERE id = $1
+`
+	alert := &domain.SecurityAlert{}
+	err := scanSecurityAlert(sar.database.QueryRowContext(c, query, id), alert)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching security alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// Acknowledge moves an open alert to acknowledged and reports whether it did.
+func (sar *securityAlertRepository) Acknowledge(c context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	query := `
+	UPDATE security_alerts
+	SET status = 'acknowledged', acknowledged_by = $2, acknowledged_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status = 'open'
+`
+	result, err := sar.database.ExecContext(c, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("error acknowledging security alert: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Resolve closes an open or acknowledged alert and reports whether it did.
+func (sar *securityAlertRepository) Resolve(c context.Context, id uuid.UUID, userID uuid.UUID, resolution string) (bool, error) {
+	query := `
+	UPDATE security_alerts
+	SET status = 'resolved', resolved_by = $2, resolved_at = CURRENT_TIMESTAMP, resolution = $3
+	WHERE id = $1 AND status IN ('open', 'acknowledged')
+`
+	result, err := sar.database.ExecContext(c, query, id, userID, resolution)
+	if err != nil {
+		return false, fmt.Errorf("error resolving security alert: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
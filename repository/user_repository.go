@@ -35,7 +35,7 @@ func (ur *userRepository) Create(c context.Context, user *domain.User) error {
 
 func (ur *userRepository) Fetch(c context.Context) ([]domain.User, error) {
 	query := `
-		SELECT id, username, email, password, role, created_at, updated_at 
+		SELECT id, username, email, password, role, COALESCE(timezone, ''), created_at, updated_at 
 		FROM users
 	`
 
@@ -56,6 +56,7 @@ func (ur *userRepository) Fetch(c context.Context) ([]domain.User, error) {
             &user.Email,
             &user.Password,
             &user.Role,
+            &user.Timezone,
             &user.CreatedAt,
             &user.UpdatedAt,
         )
@@ -76,7 +77,7 @@ func (ur *userRepository) Fetch(c context.Context) ([]domain.User, error) {
 func (ur *userRepository) GetByEmail(c context.Context, email string) (domain.User, error) {
     var user domain.User
     query := `
-        SELECT id, username, email, password, role, created_at, updated_at
+        SELECT id, username, email, password, role, COALESCE(timezone, ''), created_at, updated_at
         FROM users WHERE email = $1
     `
     err := ur.database.QueryRowContext(c, query, email).Scan(
@@ -85,6 +86,7 @@ func (ur *userRepository) GetByEmail(c context.Context, email string) (domain.Us
         &user.Email,
         &user.Password,
         &user.Role,
+        &user.Timezone,
         &user.CreatedAt,
         &user.UpdatedAt,
     )
@@ -99,7 +101,7 @@ func (ur *userRepository) GetByEmail(c context.Context, email string) (domain.Us
 
 func (ur *userRepository) GetByID(c context.Context, id uuid.UUID) (domain.User, error){
 	query := `
-		SELECT id, username, email, password, role, created_at, updated_at
+		SELECT id, username, email, password, role, COALESCE(timezone, ''), created_at, updated_at
 		FROM users WHERE id = $1
 	`
 
@@ -110,6 +112,7 @@ func (ur *userRepository) GetByID(c context.Context, id uuid.UUID) (domain.User,
 		&user.Email,
 		&user.Password,
 		&user.Role,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -124,4 +127,25 @@ func (ur *userRepository) GetByID(c context.Context, id uuid.UUID) (domain.User,
 	return user, nil
 }
 
+// UpdateTimezone sets the user's timezone, clearing it when empty.
+func (ur *userRepository) UpdateTimezone(c context.Context, id uuid.UUID, timezone string) error {
+	query := `
+		UPDATE users
+		SET timezone = NULLIF($1, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+	result, err := ur.database.ExecContext(c, query, timezone, id)
+	if err != nil {
+		return fmt.Errorf("error updating user timezone: %w", err)
+	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating user timezone: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found with ID: %s", id)
+	}
+
+	return nil
+}
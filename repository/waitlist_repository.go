@@ -0,0 +1,435 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const waitlistEntryColumns = `id, patient_id, doctor_id, COALESCE(specialty, ''), preferred_windows, COALESCE(notes, ''), status, created_at, updated_at`
+
+const waitlistOfferColumns = `id, entry_id, patient_id, doctor_id, slot_start, slot_end, source_appointment_id, appointment_id, status, expires_at, responded_at, created_at`
+
+type waitlistRepository struct {
+	database *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) domain.WaitlistRepository {
+	return &waitlistRepository{
+		database: db,
+	}
+}
+
+func (wr *waitlistRepository) CreateEntry(c context.Context, entry *domain.WaitlistEntry) error {
+	windows, err := encodeWindows(entry.PreferredWindows)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO waitlist_entries (patient_id, doctor_id, specialty, preferred_windows, notes, status)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	err = wr.database.QueryRowContext(c, query, entry.PatientID, entry.DoctorID, entry.Specialty, windows, entry.Notes, entry.Status).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating waitlist entry: %w", err)
+	}
+
+	return nil
+}
+
+// FetchEntries lists entries oldest first, optionally limited to a patient
+// and a status.
+func (wr *waitlistRepository) FetchEntries(c context.Context, patientID uuid.UUID, status domain.WaitlistStatus) ([]domain.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistEntryColumns + `
+		FROM waitlist_entries
+		WHERE ($1::uuid IS NULL OR patient_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at
+	`
+	return wr.queryEntries(c, query, nullableUUID(patientID), status)
+}
+
+func (wr *waitlistRepository) FetchEntryByID(c context.Context, id uuid.UUID) (domain.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistEntryColumns + `
+		FROM waitlist_entries
+		WHERE id = $1
+	`
+	entries, err := wr.queryEntries(c, query, id)
+	if err != nil || len(entries) == 0 {
+		return domain.WaitlistEntry{}, err
+	}
+	return entries[0], nil
+}
+
+// FetchWaiting returns the entries waiting for the doctor, or for any doctor
+// of the specialty, in the order they joined.
+func (wr *waitlistRepository) FetchWaiting(c context.Context, doctorID uuid.UUID, specialty string) ([]domain.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistEntryColumns + `
+		FROM waitlist_entries
+		WHERE status = 'waiting'
+		  AND (doctor_id = $1 OR (doctor_id IS NULL AND $2 <> '' AND lower(specialty) = lower($2)))
+		ORDER BY created_at
+	`
+	return wr.queryEntries(c, query, doctorID, specialty)
+}
+
+func (wr *waitlistRepository) CancelEntry(c context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'canceled', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status IN ('waiting', 'offered')
+	`
+	result, err := wr.database.ExecContext(c, query, id)
+	if err != nil {
+		return false, fmt.Errorf("error canceling waitlist entry: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// CreateOffer moves the entry from waiting to offered and stores the offer.
+// It reports false when the entry is no longer waiting or the slot already
+// has a pending offer.
+func (wr *waitlistRepository) CreateOffer(c context.Context, offer *domain.WaitlistOffer) (bool, error) {
+	tx, err := wr.database.BeginTx(c, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting waitlist offer: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(c, `
+		UPDATE waitlist_entries
+		SET status = 'offered', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'waiting'
+	`, offer.EntryID)
+	if err != nil {
+		return false, fmt.Errorf("error updating waitlist entry: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO waitlist_offers (entry_id, patient_id, doctor_id, slot_start, slot_end, source_appointment_id, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(c, query,
+		offer.EntryID,
+		offer.PatientID,
+		offer.DoctorID,
+		offer.SlotStart,
+		offer.SlotEnd,
+		offer.SourceAppointmentID,
+		offer.Status,
+		offer.ExpiresAt,
+	).Scan(&offer.ID, &offer.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return false, nil
+		}
+		return false, fmt.Errorf("error creating waitlist offer: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing waitlist offer: %w", err)
+	}
+
+	return true, nil
+}
+
+// FetchOffers lists offers newest first, optionally limited to a patient and
+// a status.
+func (wr *waitlistRepository) FetchOffers(c context.Context, patientID uuid.UUID, status domain.OfferStatus) ([]domain.WaitlistOffer, error) {
+	query := `
+		SELECT ` + waitlistOfferColumns + `
+		FROM waitlist_offers
+		WHERE ($1::uuid IS NULL OR patient_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+	return wr.queryOffers(c, query, nullableUUID(patientID), status)
+}
+
+func (wr *waitlistRepository) FetchOfferByID(c context.Context, id uuid.UUID) (domain.WaitlistOffer, error) {
+	query := `
+		SELECT ` + waitlistOfferColumns + `
+		FROM waitlist_offers
+		WHERE id = $1
+	`
+	offers, err := wr.queryOffers(c, query, id)
+	if err != nil || len(offers) == 0 {
+		return domain.WaitlistOffer{}, err
+	}
+	return offers[0], nil
+}
+
+// FetchPendingOffers returns the doctor's unexpired pending offers whose slot
+// overlaps [from, to).
+func (wr *waitlistRepository) FetchPendingOffers(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]domain.WaitlistOffer, error) {
+	query := `
+		SELECT ` + waitlistOfferColumns + `
+		FROM waitlist_offers
+		WHERE doctor_id = $1 AND status = 'pending' AND expires_at > CURRENT_TIMESTAMP
+		  AND slot_start < $3 AND slot_end > $2
+		ORDER BY slot_start
+	`
+	return wr.queryOffers(c, query, doctorID, from, to)
+}
+
+func (wr *waitlistRepository) FetchPendingOfferByEntryID(c context.Context, entryID uuid.UUID) (domain.WaitlistOffer, error) {
+	query := `
+		SELECT ` + waitlistOfferColumns + `
+		FROM waitlist_offers
+		WHERE entry_id = $1 AND status = 'pending'
+	`
+	offers, err := wr.queryOffers(c, query, entryID)
+	if err != nil || len(offers) == 0 {
+		return domain.WaitlistOffer{}, err
+	}
+	return offers[0], nil
+}
+
+func (wr *waitlistRepository) FetchExpiredOffers(c context.Context, now time.Time) ([]domain.WaitlistOffer, error) {
+	query := `
+		SELECT ` + waitlistOfferColumns + `
+		FROM waitlist_offers
+		WHERE status = 'pending' AND expires_at <= $1
+		ORDER BY expires_at
+	`
+	return wr.queryOffers(c, query, now)
+}
+
+// FetchOfferedEntryIDs returns the entries that were already offered the
+// doctor's slot starting at slotStart, so it isn't offered to them again.
+func (wr *waitlistRepository) FetchOfferedEntryIDs(c context.Context, doctorID uuid.UUID, slotStart time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT entry_id
+		FROM waitlist_offers
+		WHERE doctor_id = $1 AND slot_start = $2
+	`
+	rows, err := wr.database.QueryContext(c, query, doctorID, slotStart)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching offered waitlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning offered waitlist entry: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating offered waitlist entries: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CloseOffer moves a pending offer to status and puts its entry back on the
+// waitlist. It reports false when the offer was no longer pending.
+func (wr *waitlistRepository) CloseOffer(c context.Context, offer *domain.WaitlistOffer, status domain.OfferStatus) (bool, error) {
+	tx, err := wr.database.BeginTx(c, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting waitlist offer update: %w", err)
+	}
+	defer tx.Rollback()
+
+	closed, err := closeOffer(c, tx, offer, status)
+	if err != nil || !closed {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(c, `
+		UPDATE waitlist_entries
+		SET status = 'waiting', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'offered'
+	`, offer.EntryID)
+	if err != nil {
+		return false, fmt.Errorf("error returning waitlist entry: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing waitlist offer update: %w", err)
+	}
+
+	return true, nil
+}
+
+// AcceptOffer books the appointment for a pending, unexpired offer and marks
+// the entry as booked, all in one transaction. It reports false when the offer
+// was no longer pending or had expired.
+func (wr *waitlistRepository) AcceptOffer(c context.Context, offer *domain.WaitlistOffer, appointment *domain.Appointment, change *domain.AppointmentStatusChange) (bool, error) {
+	tx, err := wr.database.BeginTx(c, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting waitlist booking: %w", err)
+	}
+	defer tx.Rollback()
+
+	var pending bool
+	err = tx.QueryRowContext(c, `
+		SELECT status = 'pending' AND expires_at > CURRENT_TIMESTAMP
+		FROM waitlist_offers
+		WHERE id = $1
+		FOR UPDATE
+	`, offer.ID).Scan(&pending)
+	if err == sql.ErrNoRows || (err == nil && !pending) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error locking waitlist offer: %w", err)
+	}
+
+	if err = insertAppointment(c, tx, appointment, change); err != nil {
+		return false, err
+	}
+
+	offer.AppointmentID = &appointment.ID
+	if _, err = closeOffer(c, tx, offer, domain.OfferAccepted); err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(c, `
+		UPDATE waitlist_entries
+		SET status = 'booked', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, offer.EntryID)
+	if err != nil {
+		return false, fmt.Errorf("error updating waitlist entry: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing waitlist booking: %w", err)
+	}
+
+	return true, nil
+}
+
+func closeOffer(c context.Context, tx *sql.Tx, offer *domain.WaitlistOffer, status domain.OfferStatus) (bool, error) {
+	query := `
+		UPDATE waitlist_offers
+		SET status = $1, appointment_id = $2, responded_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = 'pending'
+		RETURNING ` + waitlistOfferColumns + `
+	`
+	err := scanOffer(tx.QueryRowContext(c, query, status, offer.AppointmentID, offer.ID), offer)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error closing waitlist offer: %w", err)
+	}
+	return true, nil
+}
+
+func (wr *waitlistRepository) queryEntries(c context.Context, query string, args ...interface{}) ([]domain.WaitlistEntry, error) {
+	rows, err := wr.database.QueryContext(c, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching waitlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.WaitlistEntry
+	for rows.Next() {
+		var entry domain.WaitlistEntry
+		var doctorID uuid.NullUUID
+		var windows []byte
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.PatientID,
+			&doctorID,
+			&entry.Specialty,
+			&windows,
+			&entry.Notes,
+			&entry.Status,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning waitlist entry: %w", err)
+		}
+		if doctorID.Valid {
+			entry.DoctorID = &doctorID.UUID
+		}
+		if err := json.Unmarshal(windows, &entry.PreferredWindows); err != nil {
+			return nil, fmt.Errorf("error decoding waitlist windows: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating waitlist entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (wr *waitlistRepository) queryOffers(c context.Context, query string, args ...interface{}) ([]domain.WaitlistOffer, error) {
+	rows, err := wr.database.QueryContext(c, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching waitlist offers: %w", err)
+	}
+	defer rows.Close()
+
+	var offers []domain.WaitlistOffer
+	for rows.Next() {
+		var offer domain.WaitlistOffer
+		if err := scanOffer(rows, &offer); err != nil {
+			return nil, fmt.Errorf("error scanning waitlist offer: %w", err)
+		}
+		offers = append(offers, offer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating waitlist offers: %w", err)
+	}
+
+	return offers, nil
+}
+
+func scanOffer(row interface{ Scan(...interface{}) error }, offer *domain.WaitlistOffer) error {
+	return row.Scan(
+		&offer.ID,
+		&offer.EntryID,
+		&offer.PatientID,
+		&offer.DoctorID,
+		&offer.SlotStart,
+		&offer.SlotEnd,
+		&offer.SourceAppointmentID,
+		&offer.AppointmentID,
+		&offer.Status,
+		&offer.ExpiresAt,
+		&offer.RespondedAt,
+		&offer.CreatedAt,
+	)
+}
+
+func encodeWindows(windows []domain.Slot) ([]byte, error) {
+	if windows == nil {
+		windows = []domain.Slot{}
+	}
+	encoded, err := json.Marshal(windows)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding waitlist windows: %w", err)
+	}
+	return encoded, nil
+}
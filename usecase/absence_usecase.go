@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultAbsenceReason is told to patients when neither the rebooking nor the
+// absence gives a reason.
+const defaultAbsenceReason = "The doctor is unavailable"
+
+type absenceUsecase struct {
+	absenceRepository     domain.AbsenceRepository
+	appointmentRepository domain.AppointmentRepository
+	doctorRepository      domain.DoctorRepository
+	availabilityUsecase   domain.AvailabilityUsecase
+	waitlistUsecase       domain.WaitlistUsecase
+	notifier              domain.AppointmentNotifier
+	waitingRoom           domain.WaitingRoomPublisher
+	contextTimeout        time.Duration
+}
+
+func NewAbsenceUsecase(absenceRepository domain.AbsenceRepository, appointmentRepository domain.AppointmentRepository, doctorRepository domain.DoctorRepository, availabilityUsecase domain.AvailabilityUsecase, waitlistUsecase domain.WaitlistUsecase, notifier domain.AppointmentNotifier, waitingRoom domain.WaitingRoomPublisher, timeout time.Duration) domain.AbsenceUsecase {
+	return &absenceUsecase{
+		absenceRepository:     absenceRepository,
+		appointmentRepository: appointmentRepository,
+		doctorRepository:      doctorRepository,
+		availabilityUsecase:   availabilityUsecase,
+		waitlistUsecase:       waitlistUsecase,
+		notifier:              notifier,
+		waitingRoom:           waitingRoom,
+		contextTimeout:        timeout,
+	}
+}
+
+// Create records the absence and returns the appointments it falls on. They
+// are left as they are until rebooked.
+func (au *absenceUsecase) Create(c context.Context, absence *domain.DoctorAbsence) (domain.AbsenceResult, error) {
+	if absence.Kind == "" {
+		absence.Kind = domain.AbsenceOther
+	}
+	switch absence.Kind {
+	case domain.AbsenceSick, domain.AbsenceVacation, domain.AbsenceHoliday, domain.AbsenceOther:
+	default:
+		return domain.AbsenceResult{}, fmt.Errorf("%w: kind must be sick, vacation, holiday or other", domain.ErrInvalidAbsence)
+	}
+	if !absence.EndsAt.After(absence.StartsAt) {
+		return domain.AbsenceResult{}, fmt.Errorf("%w: ends_at must be after starts_at", domain.ErrInvalidAbsence)
+	}
+	absence.Reason = strings.TrimSpace(absence.Reason)
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	if err := au.absenceRepository.Create(ctx, absence); err != nil {
+		return domain.AbsenceResult{}, err
+	}
+
+	affected, err := au.affected(ctx, *absence)
+	if err != nil {
+		return domain.AbsenceResult{}, err
+	}
+
+	return domain.AbsenceResult{Absence: *absence, Affected: affected}, nil
+}
+
+func (au *absenceUsecase) FetchByDoctorID(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]domain.DoctorAbsence, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.absenceRepository.FetchByDoctorID(ctx, doctorID, from, to)
+}
+
+// Delete removes the absence. Appointments already rebooked stay as they are.
+func (au *absenceUsecase) Delete(c context.Context, doctorID uuid.UUID, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.absenceRepository.Delete(ctx, doctorID, id)
+}
+
+func (au *absenceUsecase) FetchAffected(c context.Context, doctorID uuid.UUID, id uuid.UUID) (domain.AbsenceResult, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	absence, err := au.absenceRepository.FetchByID(ctx, doctorID, id)
+	if err != nil {
+		return domain.AbsenceResult{}, err
+	}
+
+	affected, err := au.affected(ctx, absence)
+	if err != nil {
+		return domain.AbsenceResult{}, err
+	}
+
+	return domain.AbsenceResult{Absence: absence, Affected: affected}, nil
+}
+
+// Rebook cancels the appointments the absence falls on, or moves each to the
+// first doctor who works at that time and is free, telling every patient.
+// Appointments that can't be rebooked are reported and left untouched. The
+// canceled times aren't offered to the waitlist, since the doctor is away.
+func (au *absenceUsecase) Rebook(c context.Context, doctorID uuid.UUID, id uuid.UUID, request domain.RebookRequest, actorID uuid.UUID, actorRole domain.UserRole) ([]domain.RebookOutcome, error) {
+	if request.Action != domain.RebookCancel && request.Action != domain.RebookMove {
+		return nil, fmt.Errorf("%w: action must be cancel or move", domain.ErrInvalidRebook)
+	}
+	if request.DoctorID != nil && *request.DoctorID == doctorID {
+		return nil, fmt.Errorf("%w: doctor_id must be another doctor", domain.ErrInvalidRebook)
+	}
+
+	result, err := au.FetchAffected(c, doctorID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		reason = result.Absence.Reason
+	}
+	if reason == "" {
+		reason = defaultAbsenceReason
+	}
+
+	var cover []uuid.UUID
+	if request.Action == domain.RebookMove {
+		if cover, err = au.coverDoctors(c, doctorID, request.DoctorID); err != nil {
+			return nil, err
+		}
+	}
+
+	selected := map[uuid.UUID]bool{}
+	for _, appointmentID := range request.AppointmentIDs {
+		selected[appointmentID] = true
+	}
+
+	outcomes := []domain.RebookOutcome{}
+	for _, appointment := range result.Affected {
+		if len(selected) > 0 && !selected[appointment.ID] {
+			continue
+		}
+		delete(selected, appointment.ID)
+
+		outcome := domain.RebookOutcome{AppointmentID: appointment.ID, PatientID: appointment.PatientID}
+		switch request.Action {
+		case domain.RebookCancel:
+			err = au.cancel(c, appointment, reason, actorID, actorRole)
+			outcome.Outcome = domain.RebookCanceled
+		case domain.RebookMove:
+			var moved domain.Appointment
+			moved, err = au.move(c, appointment, cover, reason, actorID, actorRole)
+			outcome.Outcome = domain.RebookMoved
+			outcome.DoctorID = &moved.DoctorID
+		}
+		if err != nil {
+			outcome.Outcome, outcome.DoctorID, outcome.Error = domain.RebookFailed, nil, err.Error()
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	for appointmentID := range selected {
+		outcomes = append(outcomes, domain.RebookOutcome{
+			AppointmentID: appointmentID,
+			Outcome:       domain.RebookFailed,
+			Error:         "the appointment isn't affected by the absence",
+		})
+	}
+
+	return outcomes, nil
+}
+
+// affected lists the doctor's requested and confirmed appointments during the
+// absence.
+func (au *absenceUsecase) affected(ctx context.Context, absence domain.DoctorAbsence) ([]domain.Appointment, error) {
+	appointments, err := au.appointmentRepository.FetchActiveByDoctorIDBetween(ctx, absence.DoctorID, absence.StartsAt, absence.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	affected := []domain.Appointment{}
+	for _, appointment := range appointments {
+		if isEditable(appointment.Status) {
+			affected = append(affected, appointment)
+		}
+	}
+	return affected, nil
+}
+
+// coverDoctors returns the doctors appointments may move to: the requested
+// one, or the others of the absent doctor's specialty, those at the same
+// facility first.
+func (au *absenceUsecase) coverDoctors(c context.Context, doctorID uuid.UUID, requested *uuid.UUID) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	if requested != nil {
+		doctor, err := au.doctorRepository.FetchByID(ctx, *requested)
+		if err != nil {
+			return nil, err
+		}
+		if doctor.ID == uuid.Nil {
+			return nil, fmt.Errorf("%w: doctor_id isn't a doctor", domain.ErrInvalidRebook)
+		}
+		return []uuid.UUID{doctor.ID}, nil
+	}
+
+	absent, err := au.doctorRepository.FetchByID(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(absent.Specialty) == "" {
+		return nil, fmt.Errorf("%w: the doctor has no specialty, so doctor_id is required", domain.ErrInvalidRebook)
+	}
+
+	doctors, err := au.doctorRepository.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []domain.Doctor
+	for _, doctor := range doctors {
+		if doctor.ID != doctorID && strings.EqualFold(doctor.Specialty, absent.Specialty) {
+			candidates = append(candidates, doctor)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return sameFacility(candidates[i], absent) && !sameFacility(candidates[j], absent)
+	})
+
+	cover := make([]uuid.UUID, 0, len(candidates))
+	for _, doctor := range candidates {
+		cover = append(cover, doctor.ID)
+	}
+	return cover, nil
+}
+
+func sameFacility(doctor domain.Doctor, other domain.Doctor) bool {
+	return doctor.AtFacility(other.Facility)
+}
+
+func (au *absenceUsecase) cancel(c context.Context, appointment domain.Appointment, reason string, actorID uuid.UUID, actorRole domain.UserRole) error {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	change := &domain.AppointmentStatusChange{
+		ToStatus:      domain.Canceled,
+		ChangedBy:     actorID,
+		ChangedByRole: actorRole,
+		Reason:        reason,
+	}
+	updated, err := au.appointmentRepository.UpdateStatus(ctx, &appointment, appointment.Status, change)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return domain.ErrInvalidTransition
+	}
+
+	if au.notifier != nil {
+		if err := au.notifier.Canceled(c, appointment); err != nil {
+			log.Printf("[ERROR] Notification: failed to send cancellation of appointment %s: %v\n", appointment.ID, err)
+		}
+	}
+	publishWaitingRoom(c, au.waitingRoom, domain.AppointmentUpdatedEvent, appointment)
+	return nil
+}
+
+// move gives the appointment, at the same time, to the first of the doctors
+// who works then and is free. It's recorded in the appointment's reschedule
+// history, and fails when the appointment left the requested and confirmed
+// statuses in the meantime.
+func (au *absenceUsecase) move(c context.Context, appointment domain.Appointment, cover []uuid.UUID, reason string, actorID uuid.UUID, actorRole domain.UserRole) (domain.Appointment, error) {
+	span := []domain.Slot{{Start: appointment.AppointmentDate, End: appointment.EndDate}}
+
+	for _, doctorID := range cover {
+		outside, err := au.availabilityUsecase.OutsideWorkingHours(c, doctorID, span)
+		if err != nil {
+			return domain.Appointment{}, err
+		}
+		if len(outside) > 0 {
+			continue
+		}
+
+		moved := appointment
+		moved.DoctorID = doctorID
+		updated := false
+		err = au.waitlistUsecase.CheckHold(c, moved)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+			updated, err = au.appointmentRepository.Reschedule(ctx, &moved, reschedulableStatuses, &domain.AppointmentReschedule{
+				NewStart:          appointment.AppointmentDate,
+				NewEnd:            appointment.EndDate,
+				NewDoctorID:       doctorID,
+				RescheduledBy:     actorID,
+				RescheduledByRole: actorRole,
+				Reason:            reason,
+			})
+			cancel()
+		}
+		var conflict *domain.AppointmentConflictError
+		if errors.As(err, &conflict) {
+			continue
+		}
+		if err != nil {
+			return domain.Appointment{}, err
+		}
+		if !updated {
+			return domain.Appointment{}, domain.ErrRescheduleNotAllowed
+		}
+
+		if au.notifier != nil {
+			if err := au.notifier.Reassigned(c, moved, reason); err != nil {
+				log.Printf("[ERROR] Notification: failed to send reassignment of appointment %s: %v\n", moved.ID, err)
+			}
+		}
+		publishWaitingRoom(c, au.waitingRoom, domain.AppointmentUpdatedEvent, moved)
+		return moved, nil
+	}
+
+	return domain.Appointment{}, domain.ErrNoCoverDoctor
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type agendaUsecase struct {
+	agendaRepository       domain.AgendaRepository
+	availabilityRepository domain.AvailabilityRepository
+	contextTimeout         time.Duration
+}
+
+func NewAgendaUsecase(agendaRepository domain.AgendaRepository, availabilityRepository domain.AvailabilityRepository, timeout time.Duration) domain.AgendaUsecase {
+	return &agendaUsecase{
+		agendaRepository:       agendaRepository,
+		availabilityRepository: availabilityRepository,
+		contextTimeout:         timeout,
+	}
+}
+
+func (au *agendaUsecase) FetchByDoctorID(c context.Context, doctorID uuid.UUID, date string) (domain.Agenda, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	timezone, err := au.availabilityRepository.FetchTimezone(ctx, doctorID)
+	if err != nil {
+		return domain.Agenda{}, err
+	}
+	loc := domain.Location(timezone)
+
+	day := startOfDay(time.Now(), loc)
+	if date != "" {
+		if day, err = time.ParseInLocation("2006-01-02", date, loc); err != nil {
+			return domain.Agenda{}, domain.ErrInvalidAgendaDate
+		}
+	}
+	prescribedSince := day.AddDate(0, 0, -domain.OutstandingPrescriptionDays)
+
+	entries, err := au.agendaRepository.FetchByDoctorID(ctx, doctorID, day, day.AddDate(0, 0, 1), prescribedSince)
+	if err != nil {
+		return domain.Agenda{}, err
+	}
+
+	for i := range entries {
+		entries[i].PatientAge = domain.Age(entries[i].PatientDateBirth, day)
+		if entries[i].Allergies == nil {
+			entries[i].Allergies = []domain.AgendaAllergy{}
+		}
+		if entries[i].OutstandingPrescriptions == nil {
+			entries[i].OutstandingPrescriptions = []domain.AgendaPrescription{}
+		}
+	}
+	if entries == nil {
+		entries = []domain.AgendaEntry{}
+	}
+
+	return domain.Agenda{
+		DoctorID:     doctorID,
+		Date:         day.Format("2006-01-02"),
+		Timezone:     domain.TimezoneName(loc),
+		Appointments: entries,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type allergyUsecase struct {
+	allergyRepository domain.AllergyRepository
+	patientRepository domain.PatientRepository
+	contextTimeout    time.Duration
+}
+
+func NewAllergyUsecase(allergyRepository domain.AllergyRepository, patientRepository domain.PatientRepository, timeout time.Duration) domain.AllergyUsecase {
+	return &allergyUsecase{
+		allergyRepository: allergyRepository,
+		patientRepository: patientRepository,
+		contextTimeout:    timeout,
+	}
+}
+
+func (au *allergyUsecase) Create(c context.Context, allergy *domain.PatientAllergy) error {
+	if allergy.Kind == "" {
+		allergy.Kind = domain.Allergy
+	}
+	allergy.Status = domain.AllergyActive
+	if err := validateAllergy(allergy); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	patient, err := au.patientRepository.FetchByID(ctx, allergy.PatientID)
+	if err != nil {
+		return err
+	}
+	if patient.ID == uuid.Nil {
+		return domain.ErrPatientNotFound
+	}
+
+	return au.allergyRepository.Create(ctx, allergy)
+}
+
+func (au *allergyUsecase) FetchByID(c context.Context, id uuid.UUID) (*domain.PatientAllergy, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	allergy, err := au.allergyRepository.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if allergy == nil {
+		return nil, domain.ErrAllergyNotFound
+	}
+	return allergy, nil
+}
+
+func (au *allergyUsecase) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.PatientAllergy, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.allergyRepository.FetchByPatientID(ctx, patientID)
+}
+
+// Update applies the change to the allergy. Allergies recorded by mistake are
+// marked entered_in_error rather than deleted, so the history stays complete.
+func (au *allergyUsecase) Update(c context.Context, id uuid.UUID, change domain.AllergyChange) (*domain.PatientAllergy, error) {
+	allergy, err := au.FetchByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if change.Substance != nil {
+		allergy.Substance = *change.Substance
+	}
+	if change.Kind != nil {
+		allergy.Kind = *change.Kind
+	}
+	if change.Reaction != nil {
+		allergy.Reaction = *change.Reaction
+	}
+	if change.Severity != nil {
+		allergy.Severity = *change.Severity
+	}
+	if change.Status != nil {
+		allergy.Status = *change.Status
+	}
+	if err := validateAllergy(allergy); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	if err := au.allergyRepository.Update(ctx, allergy); err != nil {
+		return nil, err
+	}
+	return allergy, nil
+}
+
+func validateAllergy(allergy *domain.PatientAllergy) error {
+	allergy.Substance = strings.TrimSpace(allergy.Substance)
+	allergy.Reaction = strings.TrimSpace(allergy.Reaction)
+	if allergy.Substance == "" {
+		return fmt.Errorf("%w: substance is required", domain.ErrInvalidAllergy)
+	}
+
+	switch allergy.Kind {
+	case domain.Allergy, domain.Intolerance:
+	default:
+		return fmt.Errorf("%w: kind must be allergy or intolerance", domain.ErrInvalidAllergy)
+	}
+
+	switch allergy.Severity {
+	case domain.MildAllergy, domain.ModerateAllergy, domain.SevereAllergy:
+	default:
+		return fmt.Errorf("%w: severity must be mild, moderate or severe", domain.ErrInvalidAllergy)
+	}
+
+	switch allergy.Status {
+	case domain.AllergyActive, domain.AllergyResolved, domain.AllergyEnteredInError:
+	default:
+		return fmt.Errorf("%w: status must be active, resolved or entered_in_error", domain.ErrInvalidAllergy)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/ical"
+	"hms-api/internal/notification"
+	"log"
+	"time"
+)
+
+type appointmentNotifier struct {
+	reminderRepository domain.ReminderRepository
+	dispatcher         notification.Dispatcher
+	language           string
+	contextTimeout     time.Duration
+}
+
+func NewAppointmentNotifier(reminderRepository domain.ReminderRepository, dispatcher notification.Dispatcher, language string, timeout time.Duration) domain.AppointmentNotifier {
+	if !notification.SupportedLanguage(language) {
+		language = domain.LanguagePortuguese
+	}
+
+	return &appointmentNotifier{
+		reminderRepository: reminderRepository,
+		dispatcher:         dispatcher,
+		language:           language,
+		contextTimeout:     timeout,
+	}
+}
+
+func (an *appointmentNotifier) Confirmed(c context.Context, appointment domain.Appointment) error {
+	if an.dispatcher == nil || !an.dispatcher.Supports(domain.EmailChannel) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, an.contextTimeout)
+	defer cancel()
+
+	contact, err := an.reminderRepository.FetchContact(ctx, appointment.ID)
+	if err != nil {
+		return err
+	}
+	if contact.PatientEmail == "" {
+		return nil
+	}
+
+	data := notification.ConfirmationData{
+		PatientName: contact.PatientName,
+		DoctorName:  contact.DoctorName,
+		Specialty:   contact.Specialty,
+		Start:       contact.Appointment.AppointmentDate.In(contact.PatientLocation()),
+	}
+	message, err := notification.Render(contactLanguage(contact, an.language), notification.AppointmentConfirmation, data)
+	if err != nil {
+		return err
+	}
+
+	return an.dispatcher.Send(c, domain.Notification{
+		Channel:     domain.EmailChannel,
+		To:          contact.PatientEmail,
+		Subject:     message.Subject,
+		Body:        message.Body,
+		Attachments: []domain.NotificationAttachment{invitation(contact)},
+	})
+}
+
+// Rescheduled emails the doctor when the patient moved the appointment, and
+// the patient, with an updated invitation, when staff did.
+func (an *appointmentNotifier) Rescheduled(c context.Context, appointment domain.Appointment, reschedule domain.AppointmentReschedule) error {
+	if an.dispatcher == nil || !an.dispatcher.Supports(domain.EmailChannel) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, an.contextTimeout)
+	defer cancel()
+
+	contact, err := an.reminderRepository.FetchContact(ctx, appointment.ID)
+	if err != nil {
+		return err
+	}
+
+	forDoctor := reschedule.RescheduledByRole == domain.PatientRole
+	to, language, loc := contact.PatientEmail, contactLanguage(contact, an.language), contact.PatientLocation()
+	if forDoctor {
+		to, language, loc = contact.DoctorEmail, an.language, domain.Location(contact.Appointment.Timezone)
+	}
+	if to == "" {
+		return nil
+	}
+
+	message, err := notification.Render(language, notification.AppointmentRescheduled, notification.RescheduleData{
+		PatientName: contact.PatientName,
+		DoctorName:  contact.DoctorName,
+		Specialty:   contact.Specialty,
+		Previous:    reschedule.PreviousStart.In(loc),
+		Start:       contact.Appointment.AppointmentDate.In(loc),
+		Reason:      reschedule.Reason,
+		ForDoctor:   forDoctor,
+	})
+	if err != nil {
+		return err
+	}
+
+	n := domain.Notification{Channel: domain.EmailChannel, To: to, Subject: message.Subject, Body: message.Body}
+	if !forDoctor {
+		n.Attachments = []domain.NotificationAttachment{invitation(contact)}
+	}
+	return an.dispatcher.Send(c, n)
+}
+
+func (an *appointmentNotifier) Canceled(c context.Context, appointment domain.Appointment) error {
+	return an.clinicChange(c, appointment, notification.AppointmentCanceled, appointment.CancelReason)
+}
+
+func (an *appointmentNotifier) Reassigned(c context.Context, appointment domain.Appointment, reason string) error {
+	return an.clinicChange(c, appointment, notification.AppointmentReassigned, reason)
+}
+
+// clinicChange emails the patient about a change the clinic made to the
+// appointment, with the updated invitation.
+func (an *appointmentNotifier) clinicChange(c context.Context, appointment domain.Appointment, name string, reason string) error {
+	if an.dispatcher == nil || !an.dispatcher.Supports(domain.EmailChannel) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, an.contextTimeout)
+	defer cancel()
+
+	contact, err := an.reminderRepository.FetchContact(ctx, appointment.ID)
+	if err != nil {
+		return err
+	}
+	if contact.PatientEmail == "" {
+		return nil
+	}
+
+	message, err := notification.Render(contactLanguage(contact, an.language), name, notification.ClinicChangeData{
+		PatientName: contact.PatientName,
+		DoctorName:  contact.DoctorName,
+		Specialty:   contact.Specialty,
+		Start:       contact.Appointment.AppointmentDate.In(contact.PatientLocation()),
+		Reason:      reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	return an.dispatcher.Send(c, domain.Notification{
+		Channel:     domain.EmailChannel,
+		To:          contact.PatientEmail,
+		Subject:     message.Subject,
+		Body:        message.Body,
+		Attachments: []domain.NotificationAttachment{invitation(contact)},
+	})
+}
+
+// WaitlistOffered sends the offer through each of the patient's channels,
+// since the hold runs out whether they saw it or not. It only fails when no
+// channel could take it.
+func (an *appointmentNotifier) WaitlistOffered(c context.Context, offer domain.WaitlistOffer) error {
+	if an.dispatcher == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, an.contextTimeout)
+	defer cancel()
+
+	contact, err := an.reminderRepository.FetchPatientContact(ctx, offer.PatientID, offer.DoctorID)
+	if err != nil {
+		return err
+	}
+
+	loc := contact.PatientLocation()
+	message, err := notification.Render(contactLanguage(contact, an.language), notification.WaitlistOffer, notification.WaitlistOfferData{
+		PatientName: contact.PatientName,
+		DoctorName:  contact.DoctorName,
+		Specialty:   contact.Specialty,
+		Start:       offer.SlotStart.In(loc),
+		ExpiresAt:   offer.ExpiresAt.In(loc),
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	sent := 0
+	for _, channel := range patientChannels(contact) {
+		recipient := recipientFor(contact, channel)
+		if recipient == "" || !an.dispatcher.Supports(channel) {
+			continue
+		}
+
+		n := domain.Notification{Channel: channel, To: recipient, Subject: message.Subject, Body: message.Short}
+		if channel == domain.EmailChannel {
+			n.Body = message.Body
+		}
+		if err := an.dispatcher.Send(c, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+			continue
+		}
+		sent++
+	}
+
+	if sent == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// invitation attaches the appointment as an iCalendar event. Its sequence
+// grows with every change, so clients replace the copy they already have.
+func invitation(contact domain.AppointmentContact) domain.NotificationAttachment {
+	summary := contact.DoctorName
+	if contact.Specialty != "" {
+		summary = fmt.Sprintf("%s (%s)", contact.DoctorName, contact.Specialty)
+	}
+	calendar := ical.Calendar{Events: []ical.Event{ical.AppointmentEvent(contact.Appointment, summary, "")}}
+
+	return domain.NotificationAttachment{
+		Filename:    "appointment.ics",
+		ContentType: "text/calendar; charset=utf-8; method=PUBLISH",
+		Content:     calendar.Bytes(),
+	}
+}
+
+// notifyConfirmed sends the confirmation of appointment, only logging failures
+// since the appointment itself is already saved.
+func notifyConfirmed(c context.Context, notifier domain.AppointmentNotifier, appointment domain.Appointment) {
+	if notifier == nil {
+		return
+	}
+	if err := notifier.Confirmed(c, appointment); err != nil {
+		log.Printf("[ERROR] Notification: failed to send confirmation of appointment %s: %v\n", appointment.ID, err)
+	}
+}
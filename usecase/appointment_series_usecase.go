@@ -0,0 +1,643 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/rrule"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type appointmentSeriesUsecase struct {
+	seriesRepository      domain.AppointmentSeriesRepository
+	appointmentRepository domain.AppointmentRepository
+	availabilityUsecase   domain.AvailabilityUsecase
+	waitlistUsecase       domain.WaitlistUsecase
+	notifier              domain.AppointmentNotifier
+	waitingRoom           domain.WaitingRoomPublisher
+	contextTimeout        time.Duration
+}
+
+func NewAppointmentSeriesUsecase(seriesRepository domain.AppointmentSeriesRepository, appointmentRepository domain.AppointmentRepository, availabilityUsecase domain.AvailabilityUsecase, waitlistUsecase domain.WaitlistUsecase, notifier domain.AppointmentNotifier, waitingRoom domain.WaitingRoomPublisher, timeout time.Duration) domain.AppointmentSeriesUsecase {
+	return &appointmentSeriesUsecase{
+		seriesRepository:      seriesRepository,
+		appointmentRepository: appointmentRepository,
+		availabilityUsecase:   availabilityUsecase,
+		waitlistUsecase:       waitlistUsecase,
+		notifier:              notifier,
+		waitingRoom:           waitingRoom,
+		contextTimeout:        timeout,
+	}
+}
+
+// Create expands the series' rule and books every occurrence that is in the
+// future, not excluded, within the doctor's working hours and free for both
+// the doctor and the patient. The others are reported as skipped.
+func (su *appointmentSeriesUsecase) Create(c context.Context, series *domain.AppointmentSeries, actorID uuid.UUID, actorRole domain.UserRole) (domain.AppointmentSeriesResult, error) {
+	loc, err := su.availabilityUsecase.Location(c, series.DoctorID)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	rule, err := normalizeSeries(series, loc)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	occurrences, err := rule.All(series.StartDate, domain.MaxSeriesOccurrences)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, fmt.Errorf("%w: %v", domain.ErrInvalidRecurrence, err)
+	}
+
+	excluded := rrule.NewExDates(series.ExDates)
+
+	duration := time.Duration(series.DurationMinutes) * time.Minute
+	now := time.Now()
+	skipped := []domain.SkippedOccurrence{}
+	var candidates []domain.Slot
+	for _, start := range occurrences {
+		switch {
+		case excluded.Excludes(start):
+			skipped = append(skipped, domain.SkippedOccurrence{Start: start, Reason: domain.SkipExcluded})
+		case start.Before(now):
+			skipped = append(skipped, domain.SkippedOccurrence{Start: start, Reason: domain.SkipPast})
+		default:
+			candidates = append(candidates, domain.Slot{Start: start, End: start.Add(duration)})
+		}
+	}
+
+	status := initialStatus(actorRole)
+	var appointments []domain.Appointment
+	if len(candidates) > 0 {
+		outside, err := su.availabilityUsecase.OutsideWorkingHours(c, series.DoctorID, candidates)
+		if err != nil {
+			return domain.AppointmentSeriesResult{}, err
+		}
+
+		ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+		defer cancel()
+
+		from, to := candidates[0].Start, candidates[len(candidates)-1].End
+		doctorAppointments, err := su.appointmentRepository.FetchActiveByDoctorIDBetween(ctx, series.DoctorID, from, to)
+		if err != nil {
+			return domain.AppointmentSeriesResult{}, err
+		}
+		patientAppointments, err := su.appointmentRepository.FetchActiveByPatientIDBetween(ctx, series.PatientID, from, to)
+		if err != nil {
+			return domain.AppointmentSeriesResult{}, err
+		}
+
+		for _, candidate := range candidates {
+			reason := ""
+			switch {
+			case containsSlot(outside, candidate):
+				reason = domain.SkipUnavailable
+			case overlapsAppointment(candidate, doctorAppointments):
+				reason = domain.SkipDoctorBusy
+			case overlapsAppointment(candidate, patientAppointments):
+				reason = domain.SkipPatientBusy
+			}
+			appointment := domain.Appointment{
+				PatientID:       series.PatientID,
+				DoctorID:        series.DoctorID,
+				AppointmentDate: candidate.Start,
+				EndDate:         candidate.End,
+				Status:          status,
+				Notes:           series.Notes,
+			}
+			if reason == "" {
+				// The slot may be held for a waitlisted patient.
+				if err := su.waitlistUsecase.CheckHold(c, appointment); err != nil {
+					reason = domain.SkipDoctorBusy
+				}
+			}
+			if reason != "" {
+				skipped = append(skipped, domain.SkippedOccurrence{Start: candidate.Start, Reason: reason})
+				continue
+			}
+
+			appointments = append(appointments, appointment)
+		}
+	}
+
+	if len(appointments) == 0 {
+		return domain.AppointmentSeriesResult{}, fmt.Errorf("%w: none of the series' %d occurrences can be booked", domain.ErrInvalidRecurrence, len(occurrences))
+	}
+
+	series.Status = domain.SeriesActive
+	series.CreatedBy = actorID
+	change := domain.AppointmentStatusChange{
+		ToStatus:      status,
+		ChangedBy:     actorID,
+		ChangedByRole: actorRole,
+	}
+
+	ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+	defer cancel()
+	if err := su.seriesRepository.Create(ctx, series, appointments, change); err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Start.Before(skipped[j].Start) })
+
+	return domain.AppointmentSeriesResult{
+		Series:       *series,
+		Appointments: appointments,
+		Skipped:      skipped,
+	}, nil
+}
+
+func (su *appointmentSeriesUsecase) FetchByID(c context.Context, id uuid.UUID) (domain.AppointmentSeriesResult, error) {
+	series, occurrences, err := su.load(c, id)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	return domain.AppointmentSeriesResult{Series: series, Appointments: occurrences}, nil
+}
+
+// Update changes the occurrences in the request's scope that haven't taken
+// place yet. A this-and-following edit splits the series: the original ends
+// before the selected occurrence and a new series, returned in the result,
+// carries it and the ones after it. Occurrences moved to another time or
+// doctor are rescheduled like single appointments, and their patients told.
+func (su *appointmentSeriesUsecase) Update(c context.Context, id uuid.UUID, request domain.SeriesUpdateRequest, actorID uuid.UUID, actorRole domain.UserRole) (domain.AppointmentSeriesResult, error) {
+	if request.DurationMinutes < 0 {
+		return domain.AppointmentSeriesResult{}, domain.ErrInvalidAppointmentTime
+	}
+
+	series, occurrences, err := su.load(c, id)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	loc, err := su.availabilityUsecase.Location(c, series.DoctorID)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	scope, anchor, err := resolveScope(series, occurrences, request.Scope, request.AppointmentID, loc)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+	if scope == domain.ScopeSingle && !isEditable(anchor.Status) {
+		return domain.AppointmentSeriesResult{}, fmt.Errorf("%w: only requested or confirmed occurrences can be changed", domain.ErrInvalidScope)
+	}
+
+	var split *domain.AppointmentSeries
+	target := series.ID
+	if scope == domain.ScopeFollowing {
+		split, err = splitSeries(&series, anchor, actorID, loc)
+		if err != nil {
+			return domain.AppointmentSeriesResult{}, err
+		}
+		target = split.ID
+	}
+
+	// Series-wide changes apply to the series that holds the edited
+	// occurrences from now on.
+	if scope != domain.ScopeSingle {
+		holder := &series
+		if split != nil {
+			holder = split
+		}
+		if err := applySeriesChanges(holder, request, loc); err != nil {
+			return domain.AppointmentSeriesResult{}, err
+		}
+	}
+
+	now := time.Now()
+	var changed []domain.Appointment
+	spans := map[uuid.UUID][]domain.Slot{}
+	reschedules := map[uuid.UUID]*domain.AppointmentReschedule{}
+	for _, occurrence := range occurrences {
+		selected := false
+		switch scope {
+		case domain.ScopeSingle:
+			selected = occurrence.ID == anchor.ID
+		case domain.ScopeFollowing:
+			if !occurrence.AppointmentDate.Before(startOfDay(anchor.AppointmentDate, loc)) {
+				occurrence.SeriesID = &split.ID
+				selected = isEditable(occurrence.Status)
+				if !selected {
+					// Still moves to the new series, unchanged.
+					changed = append(changed, occurrence)
+				}
+			}
+		case domain.ScopeAll:
+			selected = !occurrence.AppointmentDate.Before(now) && isEditable(occurrence.Status)
+		}
+		if !selected {
+			continue
+		}
+
+		previous := occurrence
+		if err := applyOccurrenceChanges(&occurrence, request, loc); err != nil {
+			return domain.AppointmentSeriesResult{}, err
+		}
+		changed = append(changed, occurrence)
+		spans[occurrence.DoctorID] = append(spans[occurrence.DoctorID], domain.Slot{Start: occurrence.AppointmentDate, End: occurrence.EndDate})
+
+		if occurrence.AppointmentDate.Equal(previous.AppointmentDate) && occurrence.EndDate.Equal(previous.EndDate) && occurrence.DoctorID == previous.DoctorID {
+			continue
+		}
+		reschedules[occurrence.ID] = &domain.AppointmentReschedule{
+			NewStart:          occurrence.AppointmentDate,
+			NewEnd:            occurrence.EndDate,
+			NewDoctorID:       occurrence.DoctorID,
+			RescheduledBy:     actorID,
+			RescheduledByRole: actorRole,
+		}
+	}
+
+	if request.StartTime != "" || request.DurationMinutes > 0 || request.DoctorID != uuid.Nil {
+		for doctorID, doctorSpans := range spans {
+			outside, err := su.availabilityUsecase.OutsideWorkingHours(c, doctorID, doctorSpans)
+			if err != nil {
+				return domain.AppointmentSeriesResult{}, err
+			}
+			if len(outside) > 0 {
+				return domain.AppointmentSeriesResult{}, fmt.Errorf("%w: %s", domain.ErrOutsideAvailability, formatSpans(outside))
+			}
+		}
+	}
+
+	// The new slots may be held for waitlisted patients.
+	for _, occurrence := range changed {
+		if reschedules[occurrence.ID] == nil {
+			continue
+		}
+		if err := su.waitlistUsecase.CheckHold(c, occurrence); err != nil {
+			return domain.AppointmentSeriesResult{}, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+	defer cancel()
+	if err := su.seriesRepository.Update(ctx, &series, split, changed, reschedulableStatuses, reschedules); err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	for _, occurrence := range changed {
+		if reschedule := reschedules[occurrence.ID]; reschedule != nil {
+			su.moved(c, occurrence, *reschedule)
+		}
+	}
+
+	return su.FetchByID(c, target)
+}
+
+// moved offers the slot an occurrence left to the waitlist, tells its patient
+// about the change and updates the waiting room.
+func (su *appointmentSeriesUsecase) moved(c context.Context, occurrence domain.Appointment, reschedule domain.AppointmentReschedule) {
+	freed := occurrence
+	freed.DoctorID = reschedule.PreviousDoctorID
+	freed.AppointmentDate, freed.EndDate = reschedule.PreviousStart, reschedule.PreviousEnd
+	if err := su.waitlistUsecase.OfferSlot(c, freed); err != nil {
+		log.Printf("[ERROR] Appointment series: failed to offer freed slot of appointment %s: %v\n", occurrence.ID, err)
+	}
+
+	if su.notifier != nil {
+		var err error
+		if reschedule.NewStart.Equal(reschedule.PreviousStart) {
+			err = su.notifier.Reassigned(c, occurrence, reschedule.Reason)
+		} else {
+			err = su.notifier.Rescheduled(c, occurrence, reschedule)
+		}
+		if err != nil {
+			log.Printf("[ERROR] Notification: failed to send reschedule of appointment %s: %v\n", occurrence.ID, err)
+		}
+	}
+	publishWaitingRoom(c, su.waitingRoom, domain.AppointmentUpdatedEvent, occurrence)
+}
+
+// Cancel cancels the occurrences in the request's scope that can still be
+// canceled. Canceling a single occurrence also excludes its date from the
+// series, and canceling this and the following ones ends the series before
+// it.
+func (su *appointmentSeriesUsecase) Cancel(c context.Context, id uuid.UUID, request domain.SeriesCancelRequest, actorID uuid.UUID, actorRole domain.UserRole) (domain.AppointmentSeriesResult, error) {
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		return domain.AppointmentSeriesResult{}, domain.ErrReasonRequired
+	}
+
+	series, occurrences, err := su.load(c, id)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	loc, err := su.availabilityUsecase.Location(c, series.DoctorID)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	scope, anchor, err := resolveScope(series, occurrences, request.Scope, request.AppointmentID, loc)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	var ids []uuid.UUID
+	switch scope {
+	case domain.ScopeSingle:
+		if !isCancelable(anchor.Status) {
+			return domain.AppointmentSeriesResult{}, domain.ErrInvalidTransition
+		}
+		ids = append(ids, anchor.ID)
+		series.ExDates = addExDate(series.ExDates, anchor.AppointmentDate.In(loc).Format(dateLayout))
+
+	case domain.ScopeFollowing:
+		rule, err := rrule.Parse(series.RRule, loc)
+		if err != nil {
+			return domain.AppointmentSeriesResult{}, fmt.Errorf("%w: %v", domain.ErrInvalidRecurrence, err)
+		}
+		cutoff := startOfDay(anchor.AppointmentDate, loc)
+		rule.Count, rule.Until = 0, cutoff.Add(-time.Second)
+		series.RRule = rule.String()
+		for _, occurrence := range occurrences {
+			if !occurrence.AppointmentDate.Before(cutoff) {
+				ids = append(ids, occurrence.ID)
+			}
+		}
+
+	case domain.ScopeAll:
+		series.Status = domain.SeriesCanceled
+		now := time.Now()
+		for _, occurrence := range occurrences {
+			if !occurrence.AppointmentDate.Before(now) {
+				ids = append(ids, occurrence.ID)
+			}
+		}
+	}
+
+	change := domain.AppointmentStatusChange{
+		ToStatus:      domain.Canceled,
+		ChangedBy:     actorID,
+		ChangedByRole: actorRole,
+		Reason:        reason,
+	}
+
+	ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+	defer cancel()
+	if err := su.seriesRepository.Cancel(ctx, &series, ids, change); err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	result, err := su.FetchByID(c, id)
+	if err != nil {
+		return domain.AppointmentSeriesResult{}, err
+	}
+
+	// Offer the freed slots to waitlisted patients.
+	requested := map[uuid.UUID]bool{}
+	for _, appointmentID := range ids {
+		requested[appointmentID] = true
+	}
+	for _, occurrence := range result.Appointments {
+		if requested[occurrence.ID] && occurrence.Status == domain.Canceled {
+			if err := su.waitlistUsecase.OfferSlot(c, occurrence); err != nil {
+				log.Printf("[ERROR] Appointment series: failed to offer canceled slot of appointment %s: %v\n", occurrence.ID, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (su *appointmentSeriesUsecase) load(c context.Context, id uuid.UUID) (domain.AppointmentSeries, []domain.Appointment, error) {
+	ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+	defer cancel()
+
+	series, err := su.seriesRepository.FetchByID(ctx, id)
+	if err != nil {
+		return domain.AppointmentSeries{}, nil, err
+	}
+	if series.ID == uuid.Nil {
+		return domain.AppointmentSeries{}, nil, domain.ErrSeriesNotFound
+	}
+
+	occurrences, err := su.seriesRepository.FetchOccurrences(ctx, id)
+	if err != nil {
+		return domain.AppointmentSeries{}, nil, err
+	}
+	if occurrences == nil {
+		occurrences = []domain.Appointment{}
+	}
+
+	return series, occurrences, nil
+}
+
+// normalizeSeries validates a new series and puts its rule, exclusions and
+// start in canonical form. The start is moved to loc, the doctor's timezone,
+// so occurrences keep their wall-clock time across DST changes.
+func normalizeSeries(series *domain.AppointmentSeries, loc *time.Location) (rrule.Rule, error) {
+	rule, err := rrule.Parse(series.RRule, loc)
+	if err != nil {
+		return rrule.Rule{}, fmt.Errorf("%w: %v", domain.ErrInvalidRecurrence, err)
+	}
+	series.RRule = rule.String()
+	series.StartDate = series.StartDate.In(loc)
+
+	if series.DurationMinutes == 0 {
+		series.DurationMinutes = int(domain.DefaultAppointmentDuration / time.Minute)
+	}
+	if series.DurationMinutes < 0 {
+		return rrule.Rule{}, domain.ErrInvalidAppointmentTime
+	}
+
+	exDates := []string{}
+	for _, date := range series.ExDates {
+		if _, err := time.ParseInLocation(dateLayout, date, loc); err != nil {
+			return rrule.Rule{}, fmt.Errorf("%w: exdate %q must be formatted as YYYY-MM-DD", domain.ErrInvalidRecurrence, date)
+		}
+		exDates = addExDate(exDates, date)
+	}
+	series.ExDates = exDates
+
+	return rule, nil
+}
+
+// resolveScope checks the scope and finds the occurrence it is anchored on. A
+// this-and-following change from the series' first occurrence covers the
+// whole series.
+func resolveScope(series domain.AppointmentSeries, occurrences []domain.Appointment, scope domain.SeriesScope, appointmentID uuid.UUID, loc *time.Location) (domain.SeriesScope, domain.Appointment, error) {
+	if series.Status == domain.SeriesCanceled {
+		return "", domain.Appointment{}, fmt.Errorf("%w: the series is canceled", domain.ErrInvalidScope)
+	}
+
+	switch scope {
+	case domain.ScopeAll:
+		return scope, domain.Appointment{}, nil
+	case domain.ScopeSingle, domain.ScopeFollowing:
+	default:
+		return "", domain.Appointment{}, fmt.Errorf("%w: scope must be single, following or all", domain.ErrInvalidScope)
+	}
+
+	for _, occurrence := range occurrences {
+		if occurrence.ID != appointmentID {
+			continue
+		}
+		if scope == domain.ScopeFollowing && !startOfDay(occurrence.AppointmentDate, loc).After(series.StartDate) {
+			return domain.ScopeAll, occurrence, nil
+		}
+		return scope, occurrence, nil
+	}
+
+	return "", domain.Appointment{}, fmt.Errorf("%w: appointment_id must be an occurrence of the series", domain.ErrInvalidScope)
+}
+
+// splitSeries ends series before anchor's date and returns a new series with
+// the rest of the rule, starting on that date. A COUNT is shared between
+// both so the total number of occurrences doesn't change.
+func splitSeries(series *domain.AppointmentSeries, anchor domain.Appointment, actorID uuid.UUID, loc *time.Location) (*domain.AppointmentSeries, error) {
+	rule, err := rrule.Parse(series.RRule, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidRecurrence, err)
+	}
+	original, err := rule.All(series.StartDate.In(loc), domain.MaxSeriesOccurrences)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidRecurrence, err)
+	}
+
+	cutoff := startOfDay(anchor.AppointmentDate, loc)
+	start := anchor.AppointmentDate.In(loc)
+	before := 0
+	for _, occurrence := range original {
+		if occurrence.Before(cutoff) {
+			before++
+			continue
+		}
+		// Start from the rule's own time that day, in case the anchor was
+		// moved on its own.
+		if startOfDay(occurrence, loc).Equal(cutoff) {
+			start = occurrence
+		}
+		break
+	}
+
+	split := &domain.AppointmentSeries{
+		ID:              uuid.New(),
+		PatientID:       series.PatientID,
+		DoctorID:        series.DoctorID,
+		StartDate:       start,
+		DurationMinutes: series.DurationMinutes,
+		Notes:           series.Notes,
+		Status:          domain.SeriesActive,
+		CreatedBy:       actorID,
+	}
+
+	rest := rule
+	if rule.Count > 0 {
+		rest.Count = rule.Count - before
+	}
+	split.RRule = rest.String()
+
+	rule.Count, rule.Until = 0, cutoff.Add(-time.Second)
+	series.RRule = rule.String()
+
+	var kept []string
+	for _, date := range series.ExDates {
+		if date < cutoff.Format(dateLayout) {
+			kept = append(kept, date)
+		} else {
+			split.ExDates = append(split.ExDates, date)
+		}
+	}
+	series.ExDates = kept
+
+	return split, nil
+}
+
+func applySeriesChanges(series *domain.AppointmentSeries, request domain.SeriesUpdateRequest, loc *time.Location) error {
+	if request.StartTime != "" {
+		start, err := clockOn(series.StartDate.In(loc), request.StartTime)
+		if err != nil {
+			return err
+		}
+		series.StartDate = start
+	}
+	if request.DurationMinutes > 0 {
+		series.DurationMinutes = request.DurationMinutes
+	}
+	if request.DoctorID != uuid.Nil {
+		series.DoctorID = request.DoctorID
+	}
+	if request.Notes != nil {
+		series.Notes = *request.Notes
+	}
+	return nil
+}
+
+func applyOccurrenceChanges(appointment *domain.Appointment, request domain.SeriesUpdateRequest, loc *time.Location) error {
+	duration := appointment.EndDate.Sub(appointment.AppointmentDate)
+	if request.DurationMinutes > 0 {
+		duration = time.Duration(request.DurationMinutes) * time.Minute
+	}
+	if request.StartTime != "" {
+		start, err := clockOn(appointment.AppointmentDate.In(loc), request.StartTime)
+		if err != nil {
+			return err
+		}
+		appointment.AppointmentDate = start
+	}
+	appointment.EndDate = appointment.AppointmentDate.Add(duration)
+
+	if request.DoctorID != uuid.Nil {
+		appointment.DoctorID = request.DoctorID
+	}
+	if request.Notes != nil {
+		appointment.Notes = *request.Notes
+	}
+	return nil
+}
+
+func isEditable(status domain.AppointmentStatus) bool {
+	return status == domain.Requested || status == domain.Confirmed
+}
+
+func isCancelable(status domain.AppointmentStatus) bool {
+	for _, from := range domain.AppointmentTransitions["cancel"].From {
+		if status == from {
+			return true
+		}
+	}
+	return false
+}
+
+// addExDate adds date to the sorted list of excluded dates if it isn't there.
+func addExDate(dates []string, date string) []string {
+	i := sort.SearchStrings(dates, date)
+	if i < len(dates) && dates[i] == date {
+		return dates
+	}
+	dates = append(dates, "")
+	copy(dates[i+1:], dates[i:])
+	dates[i] = date
+	return dates
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+func containsSlot(slots []domain.Slot, slot domain.Slot) bool {
+	for _, s := range slots {
+		if s.Start.Equal(slot.Start) && s.End.Equal(slot.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatSpans(spans []domain.Slot) string {
+	formatted := make([]string, 0, len(spans))
+	for _, span := range spans {
+		formatted = append(formatted, span.Start.Format(time.RFC3339))
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type appointmentTypeUsecase struct {
+	typeRepository domain.AppointmentTypeRepository
+	contextTimeout time.Duration
+}
+
+func NewAppointmentTypeUsecase(typeRepository domain.AppointmentTypeRepository, timeout time.Duration) domain.AppointmentTypeUsecase {
+	return &appointmentTypeUsecase{
+		typeRepository: typeRepository,
+		contextTimeout: timeout,
+	}
+}
+
+func (tu *appointmentTypeUsecase) Create(c context.Context, appointmentType *domain.AppointmentType) error {
+	if err := normalizeAppointmentType(appointmentType); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+	return tu.typeRepository.Create(ctx, appointmentType)
+}
+
+func (tu *appointmentTypeUsecase) Fetch(c context.Context) ([]domain.AppointmentType, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+	return tu.typeRepository.Fetch(ctx)
+}
+
+func (tu *appointmentTypeUsecase) FetchByID(c context.Context, id uuid.UUID) (domain.AppointmentType, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+	return tu.typeRepository.FetchByID(ctx, id)
+}
+
+func (tu *appointmentTypeUsecase) Update(c context.Context, appointmentType *domain.AppointmentType) error {
+	if err := normalizeAppointmentType(appointmentType); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+	return tu.typeRepository.Update(ctx, appointmentType)
+}
+
+// Delete deactivates the type. Appointments already booked with it keep it.
+func (tu *appointmentTypeUsecase) Delete(c context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	deactivated, err := tu.typeRepository.Deactivate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deactivated {
+		return domain.ErrAppointmentTypeNotFound
+	}
+	return nil
+}
+
+// normalizeAppointmentType trims the type's fields and lower-cases and
+// deduplicates its required resource categories.
+func normalizeAppointmentType(appointmentType *domain.AppointmentType) error {
+	appointmentType.Code = strings.ToLower(strings.TrimSpace(appointmentType.Code))
+	appointmentType.Name = strings.TrimSpace(appointmentType.Name)
+
+	if appointmentType.Code == "" || appointmentType.Name == "" {
+		return fmt.Errorf("%w: code and name are required", domain.ErrInvalidAppointmentType)
+	}
+	if appointmentType.DurationMinutes <= 0 {
+		return fmt.Errorf("%w: duration_minutes must be positive", domain.ErrInvalidAppointmentType)
+	}
+
+	seen := make(map[string]bool, len(appointmentType.RequiredResources))
+	required := make([]string, 0, len(appointmentType.RequiredResources))
+	for _, category := range appointmentType.RequiredResources {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" {
+			return fmt.Errorf("%w: required_resources can't contain empty categories", domain.ErrInvalidAppointmentType)
+		}
+		if !seen[category] {
+			seen[category] = true
+			required = append(required, category)
+		}
+	}
+	appointmentType.RequiredResources = required
+	return nil
+}
@@ -2,28 +2,103 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"hms-api/domain"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	maxConflictSuggestions = 3
+	suggestionLookahead    = 14 * 24 * time.Hour
+)
+
 type appointmentUsecase struct {
 	appointmentRepository domain.AppointmentRepository
+	typeRepository        domain.AppointmentTypeRepository
+	availabilityUsecase   domain.AvailabilityUsecase
+	waitlistUsecase       domain.WaitlistUsecase
+	attendanceUsecase     domain.AttendanceUsecase
+	notifier              domain.AppointmentNotifier
+	waitingRoom           domain.WaitingRoomPublisher
+	reschedulePolicy      domain.ReschedulePolicy
 	contextTimeout time.Duration
 }
 
-func NewAppointmentUsecase(appointmentRepository domain.AppointmentRepository, timeout time.Duration) domain.AppointmentUsecase {
+func NewAppointmentUsecase(appointmentRepository domain.AppointmentRepository, typeRepository domain.AppointmentTypeRepository, availabilityUsecase domain.AvailabilityUsecase, waitlistUsecase domain.WaitlistUsecase, attendanceUsecase domain.AttendanceUsecase, notifier domain.AppointmentNotifier, waitingRoom domain.WaitingRoomPublisher, reschedulePolicy domain.ReschedulePolicy, timeout time.Duration) domain.AppointmentUsecase {
+	if reschedulePolicy.MinNotice <= 0 {
+		reschedulePolicy.MinNotice = domain.DefaultRescheduleMinNotice
+	}
+	if reschedulePolicy.MaxPatientReschedules <= 0 {
+		reschedulePolicy.MaxPatientReschedules = domain.DefaultMaxPatientReschedules
+	}
+
 	return &appointmentUsecase{
 		appointmentRepository: appointmentRepository,
+		typeRepository:        typeRepository,
+		availabilityUsecase:   availabilityUsecase,
+		waitlistUsecase:       waitlistUsecase,
+		attendanceUsecase:     attendanceUsecase,
+		notifier:              notifier,
+		waitingRoom:           waitingRoom,
+		reschedulePolicy:      reschedulePolicy,
 		contextTimeout: timeout,
 	}
 }
 
-func (au *appointmentUsecase) Create(c context.Context, appointment *domain.Appointment) error {
+// Create books an appointment with the initial status for the actor's role.
+// Appointments with a type default to its duration and get the resources it
+// requires reserved.
+func (au *appointmentUsecase) Create(c context.Context, appointment *domain.Appointment, actorID uuid.UUID, actorRole domain.UserRole) error {
+	appointment.Resources = nil
+	if appointment.TypeID != nil {
+		ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+		appointmentType, err := au.typeRepository.FetchByID(ctx, *appointment.TypeID)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if !appointmentType.Active {
+			return fmt.Errorf("%w: %s is no longer offered", domain.ErrAppointmentTypeNotFound, appointmentType.Name)
+		}
+		if appointment.EndDate.IsZero() {
+			appointment.EndDate = appointment.AppointmentDate.Add(appointmentType.Duration())
+		}
+	}
+
+	if err := normalizeAppointmentTime(appointment); err != nil {
+		return err
+	}
+
+	appointment.Status = initialStatus(actorRole)
+
+	change := &domain.AppointmentStatusChange{
+		ToStatus:      appointment.Status,
+		ChangedBy:     actorID,
+		ChangedByRole: actorRole,
+	}
+
+	if err := au.waitlistUsecase.CheckHold(c, *appointment); err != nil {
+		return au.withSuggestions(c, appointment, err)
+	}
+
 	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
 	defer cancel()
-	return au.appointmentRepository.Create(ctx, appointment)
+	if err := au.appointmentRepository.Create(ctx, appointment, change); err != nil {
+		return au.withSuggestions(c, appointment, err)
+	}
+
+	if appointment.Status == domain.Confirmed {
+		notifyConfirmed(c, au.notifier, *appointment)
+	}
+	publishWaitingRoom(c, au.waitingRoom, domain.AppointmentCreatedEvent, *appointment)
+
+	return nil
 }
 
 func (au *appointmentUsecase) Fetch(c context.Context) ([]domain.Appointment, error){
@@ -32,10 +107,18 @@ func (au *appointmentUsecase) Fetch(c context.Context) ([]domain.Appointment, er
 	return au.appointmentRepository.Fetch(ctx)
 }
 
+// FetchByID returns the appointment along with the resources reserved for it.
 func (au *appointmentUsecase) FetchByID(c context.Context, id uuid.UUID) (domain.Appointment, error){
 	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
 	defer cancel()
-	return au.appointmentRepository.FetchByID(ctx, id)
+
+	appointment, err := au.appointmentRepository.FetchByID(ctx, id)
+	if err != nil || appointment.TypeID == nil {
+		return appointment, err
+	}
+
+	appointment.Resources, err = au.appointmentRepository.FetchResources(ctx, id)
+	return appointment, err
 }
 
 func (au *appointmentUsecase) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.Appointment, error){
@@ -50,14 +133,340 @@ func (au *appointmentUsecase) FetchByDoctorID(c context.Context, doctorID uuid.U
 	return au.appointmentRepository.FetchByDoctorID(ctx, doctorID)
 }
 
-func (au *appointmentUsecase) Update(c context.Context, appointment *domain.Appointment) error {
+// Update changes the appointment's notes. Its time, doctor and patient are
+// locked: moving it goes through Reschedule.
+func (au *appointmentUsecase) Update(c context.Context, id uuid.UUID, change domain.AppointmentChange) (domain.Appointment, error) {
+	if change.PatientID != nil || change.DoctorID != nil || change.AppointmentDate != nil || change.EndDate != nil {
+		return domain.Appointment{}, domain.ErrAppointmentFieldLocked
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	appointment, err := au.appointmentRepository.FetchByID(ctx, id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if appointment.ID == uuid.Nil {
+		return domain.Appointment{}, domain.ErrAppointmentNotFound
+	}
+	if change.Notes == nil {
+		return appointment, nil
+	}
+
+	appointment.Notes = *change.Notes
+	if err := au.appointmentRepository.UpdateNotes(ctx, &appointment); err != nil {
+		return domain.Appointment{}, err
+	}
+
+	publishWaitingRoom(c, au.waitingRoom, domain.AppointmentUpdatedEvent, appointment)
+	return appointment, nil
+}
+
+// Transition applies one of domain.AppointmentTransitions to the appointment
+// on behalf of the actor and records it in the status history.
+func (au *appointmentUsecase) Transition(c context.Context, id uuid.UUID, action string, actorID uuid.UUID, actorRole domain.UserRole, reason string) (domain.Appointment, error) {
+	transition, ok := domain.AppointmentTransitions[action]
+	if !ok {
+		return domain.Appointment{}, domain.ErrInvalidTransition
+	}
+
+	allowed := false
+	for _, role := range transition.Roles {
+		if role == actorRole {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return domain.Appointment{}, domain.ErrTransitionForbidden
+	}
+
+	if transition.RequiresReason && strings.TrimSpace(reason) == "" {
+		return domain.Appointment{}, domain.ErrReasonRequired
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	appointment, err := au.appointmentRepository.FetchByID(ctx, id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if appointment.ID == uuid.Nil {
+		return domain.Appointment{}, domain.ErrAppointmentNotFound
+	}
+
+	from := appointment.Status
+	valid := false
+	for _, status := range transition.From {
+		if status == from {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return appointment, domain.ErrInvalidTransition
+	}
+
+	// Requests of patients under the attendance policy need an admin.
+	if transition.To == domain.Confirmed && actorRole != domain.AdminRole {
+		restricted, err := au.attendanceUsecase.RequiresApproval(c, appointment.PatientID)
+		if err != nil {
+			return domain.Appointment{}, err
+		}
+		if restricted {
+			return appointment, domain.ErrApprovalRequired
+		}
+	}
+
+	change := &domain.AppointmentStatusChange{
+		ToStatus:      transition.To,
+		ChangedBy:     actorID,
+		ChangedByRole: actorRole,
+		Reason:        strings.TrimSpace(reason),
+	}
+
+	updated, err := au.appointmentRepository.UpdateStatus(ctx, &appointment, from, change)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if !updated {
+		// Someone else moved the appointment since we read it.
+		return appointment, domain.ErrInvalidTransition
+	}
+
+	switch appointment.Status {
+	case domain.Confirmed:
+		notifyConfirmed(c, au.notifier, appointment)
+	case domain.Canceled:
+		if err := au.waitlistUsecase.OfferSlot(c, appointment); err != nil {
+			log.Printf("[ERROR] Appointment: failed to offer canceled slot of appointment %s: %v\n", appointment.ID, err)
+		}
+	}
+
+	event := domain.AppointmentUpdatedEvent
+	if appointment.Status == domain.CheckedIn {
+		event = domain.AppointmentCheckedInEvent
+	}
+	publishWaitingRoom(c, au.waitingRoom, event, appointment)
+
+	return appointment, nil
+}
+
+func (au *appointmentUsecase) FetchStatusHistory(c context.Context, appointmentID uuid.UUID) ([]domain.AppointmentStatusChange, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.appointmentRepository.FetchStatusHistory(ctx, appointmentID)
+}
+
+// reschedulableStatuses are the statuses an appointment can be moved from.
+var reschedulableStatuses = []domain.AppointmentStatus{domain.Requested, domain.Confirmed}
+
+// Reschedule moves the appointment to a new time within the doctor's working
+// hours, keeping its length when no end is given. Patients must do it at least
+// the policy's notice before the current time and only a limited number of
+// times. The freed slot goes to the waitlist and the other party is notified.
+func (au *appointmentUsecase) Reschedule(c context.Context, id uuid.UUID, request domain.RescheduleRequest, actorID uuid.UUID, actorRole domain.UserRole) (domain.Appointment, error) {
 	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
 	defer cancel()
-	return au.appointmentRepository.Update(ctx, appointment)
+
+	appointment, err := au.appointmentRepository.FetchByID(ctx, id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if appointment.ID == uuid.Nil {
+		return domain.Appointment{}, domain.ErrAppointmentNotFound
+	}
+	if !isEditable(appointment.Status) {
+		return appointment, domain.ErrRescheduleNotAllowed
+	}
+
+	now := time.Now()
+	if actorRole == domain.PatientRole {
+		if appointment.AppointmentDate.Before(now.Add(au.reschedulePolicy.MinNotice)) {
+			return appointment, domain.ErrRescheduleTooLate
+		}
+
+		count, err := au.appointmentRepository.CountReschedules(ctx, id, domain.PatientRole)
+		if err != nil {
+			return domain.Appointment{}, err
+		}
+		if count >= au.reschedulePolicy.MaxPatientReschedules {
+			return appointment, domain.ErrRescheduleLimit
+		}
+	}
+
+	moved := appointment
+	moved.AppointmentDate = request.AppointmentDate
+	moved.EndDate = request.EndDate
+	if moved.EndDate.IsZero() {
+		moved.EndDate = moved.AppointmentDate.Add(appointment.EndDate.Sub(appointment.AppointmentDate))
+	}
+	if err := normalizeAppointmentTime(&moved); err != nil {
+		return appointment, err
+	}
+	if !moved.AppointmentDate.After(now) {
+		return appointment, domain.ErrRescheduleInPast
+	}
+
+	outside, err := au.availabilityUsecase.OutsideWorkingHours(c, moved.DoctorID, []domain.Slot{{Start: moved.AppointmentDate, End: moved.EndDate}})
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if len(outside) > 0 {
+		return appointment, domain.ErrOutsideAvailability
+	}
+
+	if err := au.waitlistUsecase.CheckHold(c, moved); err != nil {
+		return appointment, au.withSuggestions(c, &moved, err)
+	}
+
+	reschedule := &domain.AppointmentReschedule{
+		NewStart:          moved.AppointmentDate,
+		NewEnd:            moved.EndDate,
+		NewDoctorID:       moved.DoctorID,
+		RescheduledBy:     actorID,
+		RescheduledByRole: actorRole,
+		Reason:            strings.TrimSpace(request.Reason),
+	}
+	updated, err := au.appointmentRepository.Reschedule(ctx, &moved, reschedulableStatuses, reschedule)
+	if err != nil {
+		return appointment, au.withSuggestions(c, &moved, err)
+	}
+	if !updated {
+		// Someone else moved the appointment out of a reschedulable status.
+		return appointment, domain.ErrRescheduleNotAllowed
+	}
+
+	freed := appointment
+	freed.AppointmentDate, freed.EndDate = reschedule.PreviousStart, reschedule.PreviousEnd
+	if err := au.waitlistUsecase.OfferSlot(c, freed); err != nil {
+		log.Printf("[ERROR] Appointment: failed to offer freed slot of appointment %s: %v\n", appointment.ID, err)
+	}
+
+	if au.notifier != nil {
+		if err := au.notifier.Rescheduled(c, moved, *reschedule); err != nil {
+			log.Printf("[ERROR] Notification: failed to send reschedule of appointment %s: %v\n", moved.ID, err)
+		}
+	}
+	publishWaitingRoom(c, au.waitingRoom, domain.AppointmentUpdatedEvent, moved)
+
+	return moved, nil
+}
+
+func (au *appointmentUsecase) FetchReschedules(c context.Context, appointmentID uuid.UUID) ([]domain.AppointmentReschedule, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.appointmentRepository.FetchReschedules(ctx, appointmentID)
 }
 
 func (au *appointmentUsecase) Delete(c context.Context, id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
 	defer cancel()
 	return au.appointmentRepository.Delete(ctx, id)
-}
\ No newline at end of file
+}
+
+// initialStatus is the status of an appointment booked by actorRole. Patients
+// request appointments for staff to confirm; staff bookings start confirmed.
+func initialStatus(actorRole domain.UserRole) domain.AppointmentStatus {
+	if actorRole == domain.AdminRole || actorRole == domain.DoctorRole {
+		return domain.Confirmed
+	}
+	return domain.Requested
+}
+
+func normalizeAppointmentTime(appointment *domain.Appointment) error {
+	if appointment.EndDate.IsZero() {
+		appointment.EndDate = appointment.AppointmentDate.Add(domain.DefaultAppointmentDuration)
+	}
+	if !appointment.EndDate.After(appointment.AppointmentDate) {
+		return domain.ErrInvalidAppointmentTime
+	}
+	return nil
+}
+
+// withSuggestions fills in the nearest free slots when err is a booking
+// conflict, and returns err unchanged otherwise.
+func (au *appointmentUsecase) withSuggestions(c context.Context, appointment *domain.Appointment, err error) error {
+	var conflict *domain.AppointmentConflictError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+
+	suggestions, suggestErr := au.suggestSlots(c, appointment)
+	if suggestErr != nil {
+		log.Printf("[ERROR] Appointment: failed to suggest slots for doctor %s: %v\n", appointment.DoctorID, suggestErr)
+	}
+	conflict.Suggestions = suggestions
+
+	return conflict
+}
+
+// suggestSlots looks for free runs of the doctor's slots long enough for the
+// appointment that don't clash with the patient's other appointments, and
+// returns those closest to the requested time.
+func (au *appointmentUsecase) suggestSlots(c context.Context, appointment *domain.Appointment) ([]domain.Slot, error) {
+	duration := appointment.EndDate.Sub(appointment.AppointmentDate)
+	from := appointment.AppointmentDate.Add(-suggestionLookahead / 2)
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	to := appointment.AppointmentDate.Add(suggestionLookahead)
+	if !to.After(from) {
+		return []domain.Slot{}, nil
+	}
+
+	free, err := au.availabilityUsecase.FetchSlots(c, appointment.DoctorID, from, to)
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	patientAppointments, err := au.appointmentRepository.FetchActiveByPatientIDBetween(ctx, appointment.PatientID, from, to)
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+
+	candidates := []domain.Slot{}
+	for i := range free {
+		candidate := domain.Slot{Start: free[i].Start, End: free[i].Start.Add(duration)}
+
+		// Consecutive free slots must cover the whole appointment.
+		covered := free[i].End
+		for j := i + 1; j < len(free) && covered.Before(candidate.End) && free[j].Start.Equal(covered); j++ {
+			covered = free[j].End
+		}
+		if covered.Before(candidate.End) {
+			continue
+		}
+
+		clashes := false
+		for _, other := range patientAppointments {
+			if other.ID != appointment.ID && other.AppointmentDate.Before(candidate.End) && other.EndDate.After(candidate.Start) {
+				clashes = true
+				break
+			}
+		}
+		if !clashes {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	distance := func(slot domain.Slot) time.Duration {
+		d := slot.Start.Sub(appointment.AppointmentDate)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return distance(candidates[i]) < distance(candidates[j]) })
+
+	if len(candidates) > maxConflictSuggestions {
+		candidates = candidates[:maxConflictSuggestions]
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Start.Before(candidates[j].Start) })
+
+	return candidates, nil
+}
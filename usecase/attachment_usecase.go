@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/blobstore"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sniffLength is how much of the content http.DetectContentType looks at.
+const sniffLength = 512
+
+type attachmentUsecase struct {
+	attachmentRepository    domain.AttachmentRepository
+	medicalRecordRepository domain.MedicalRecordRepository
+	prescriptionRepository  domain.PrescriptionRepository
+	patientRepository       domain.PatientRepository
+	store                   blobstore.Store
+	policy                  domain.AttachmentPolicy
+	contextTimeout          time.Duration
+}
+
+func NewAttachmentUsecase(attachmentRepository domain.AttachmentRepository, medicalRecordRepository domain.MedicalRecordRepository, prescriptionRepository domain.PrescriptionRepository, patientRepository domain.PatientRepository, store blobstore.Store, policy domain.AttachmentPolicy, timeout time.Duration) domain.AttachmentUsecase {
+	return &attachmentUsecase{
+		attachmentRepository:    attachmentRepository,
+		medicalRecordRepository: medicalRecordRepository,
+		prescriptionRepository:  prescriptionRepository,
+		patientRepository:       patientRepository,
+		store:                   store,
+		policy:                  policy,
+		contextTimeout:          timeout,
+	}
+}
+
+// Upload streams the content to the blob store while hashing it. The upload
+// itself isn't bound by the context timeout, which is too short for large
+// files; a blob whose attachment can't be saved is removed again.
+func (au *attachmentUsecase) Upload(c context.Context, attachment *domain.Attachment, content io.Reader) error {
+	attachment.FileName = path.Base(strings.ReplaceAll(strings.TrimSpace(attachment.FileName), "\\", "/"))
+	attachment.Description = strings.TrimSpace(attachment.Description)
+	if attachment.FileName == "" || attachment.FileName == "." || attachment.FileName == "/" {
+		return fmt.Errorf("%w: file name is required", domain.ErrInvalidAttachment)
+	}
+	if attachment.Size <= 0 {
+		return fmt.Errorf("%w: file is empty", domain.ErrInvalidAttachment)
+	}
+	if au.policy.MaxSize > 0 && attachment.Size > au.policy.MaxSize {
+		return fmt.Errorf("%w: the limit is %d bytes", domain.ErrAttachmentTooLarge, au.policy.MaxSize)
+	}
+
+	if err := au.resolvePatient(c, attachment); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(content, sniffLength)
+	head, err := reader.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return fmt.Errorf("error reading attachment: %w", err)
+	}
+	attachment.ContentType = sniffContentType(head)
+	if !au.allowed(attachment.ContentType) {
+		return fmt.Errorf("%w: %s", domain.ErrUnsupportedAttachmentType, attachment.ContentType)
+	}
+
+	attachment.ID = uuid.New()
+	attachment.StorageKey = fmt.Sprintf("attachments/%s/%s", attachment.PatientID, attachment.ID)
+
+	hash := sha256.New()
+	if err := au.store.Put(c, attachment.StorageKey, io.TeeReader(reader, hash), attachment.Size, attachment.ContentType); err != nil {
+		return err
+	}
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	if err := au.attachmentRepository.Create(ctx, attachment); err != nil {
+		au.store.Delete(context.Background(), attachment.StorageKey)
+		return err
+	}
+	return nil
+}
+
+func (au *attachmentUsecase) FetchByID(c context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.attachmentRepository.FetchByID(ctx, id)
+}
+
+func (au *attachmentUsecase) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.Attachment, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.attachmentRepository.FetchByPatientID(ctx, patientID)
+}
+
+func (au *attachmentUsecase) FetchByMedicalRecordID(c context.Context, medicalRecordID uuid.UUID) ([]domain.Attachment, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.attachmentRepository.FetchByMedicalRecordID(ctx, medicalRecordID)
+}
+
+func (au *attachmentUsecase) FetchByPrescriptionID(c context.Context, prescriptionID uuid.UUID) ([]domain.Attachment, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.attachmentRepository.FetchByPrescriptionID(ctx, prescriptionID)
+}
+
+// Open isn't bound by the context timeout either, as the content is streamed
+// to the client after it returns.
+func (au *attachmentUsecase) Open(c context.Context, attachment *domain.Attachment) (io.ReadCloser, error) {
+	content, err := au.store.Get(c, attachment.StorageKey)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return content, err
+}
+
+// resolvePatient sets the attachment's patient to the linked record's or
+// prescription's, checking a record and a prescription given together belong
+// to the same one.
+func (au *attachmentUsecase) resolvePatient(c context.Context, attachment *domain.Attachment) error {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	if attachment.MedicalRecordID != nil {
+		record, err := au.medicalRecordRepository.FetchByID(ctx, *attachment.MedicalRecordID)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return domain.ErrMedicalRecordNotFound
+		}
+		if record.Status == domain.MedicalRecordEnteredInError {
+			return domain.ErrMedicalRecordEnteredInError
+		}
+		attachment.PatientID = record.PatientID
+	}
+
+	if attachment.PrescriptionID != nil {
+		prescription, err := au.prescriptionRepository.FetchByID(ctx, *attachment.PrescriptionID)
+		if err != nil {
+			return err
+		}
+		if prescription == nil {
+			return domain.ErrPrescriptionNotFound
+		}
+		if attachment.MedicalRecordID != nil && prescription.PatientID != attachment.PatientID {
+			return fmt.Errorf("%w: the prescription and medical record belong to different patients", domain.ErrInvalidAttachment)
+		}
+		attachment.PatientID = prescription.PatientID
+	}
+
+	if attachment.MedicalRecordID == nil && attachment.PrescriptionID == nil {
+		patient, err := au.patientRepository.FetchByID(ctx, attachment.PatientID)
+		if err != nil {
+			return err
+		}
+		if patient.ID == uuid.Nil {
+			return domain.ErrPatientNotFound
+		}
+	}
+
+	return nil
+}
+
+func (au *attachmentUsecase) allowed(contentType string) bool {
+	for _, allowed := range au.policy.AllowedTypes {
+		if strings.EqualFold(strings.TrimSpace(allowed), contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffContentType returns the media type of content starting with head,
+// without parameters such as the charset.
+func sniffContentType(head []byte) string {
+	contentType := http.DetectContentType(head)
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type attendanceUsecase struct {
+	attendanceRepository domain.AttendanceRepository
+	policy               domain.AttendancePolicy
+	contextTimeout       time.Duration
+}
+
+func NewAttendanceUsecase(attendanceRepository domain.AttendanceRepository, policy domain.AttendancePolicy, timeout time.Duration) domain.AttendanceUsecase {
+	if policy.NoShowGrace <= 0 {
+		policy.NoShowGrace = domain.DefaultNoShowGrace
+	}
+	if policy.LateCancelWindow <= 0 {
+		policy.LateCancelWindow = domain.DefaultLateCancelWindow
+	}
+	if policy.NoShowLimit <= 0 {
+		policy.NoShowLimit = domain.DefaultNoShowLimit
+	}
+	if policy.LateCancelLimit <= 0 {
+		policy.LateCancelLimit = domain.DefaultLateCancelLimit
+	}
+	if policy.Lookback <= 0 {
+		policy.Lookback = domain.DefaultAttendanceLookback
+	}
+
+	return &attendanceUsecase{
+		attendanceRepository: attendanceRepository,
+		policy:               policy,
+		contextTimeout:       timeout,
+	}
+}
+
+// MarkNoShows marks confirmed appointments that ended more than the grace
+// period before now as no-shows.
+func (au *attendanceUsecase) MarkNoShows(c context.Context, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	reason := fmt.Sprintf("Not checked in %s after the appointment ended", au.policy.NoShowGrace)
+	return au.attendanceRepository.MarkNoShows(ctx, now.Add(-au.policy.NoShowGrace), reason)
+}
+
+func (au *attendanceUsecase) FetchAttendance(c context.Context, patientID uuid.UUID) (domain.PatientAttendance, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	attendance, err := au.attendanceRepository.FetchAttendance(ctx, patientID, time.Now().Add(-au.policy.Lookback), au.policy.LateCancelWindow)
+	if err != nil {
+		return domain.PatientAttendance{}, err
+	}
+
+	attendance.RequiresApproval = attendance.NoShows >= au.policy.NoShowLimit || attendance.LateCancellations >= au.policy.LateCancelLimit
+	return attendance, nil
+}
+
+func (au *attendanceUsecase) RequiresApproval(c context.Context, patientID uuid.UUID) (bool, error) {
+	attendance, err := au.FetchAttendance(c, patientID)
+	if err != nil {
+		return false, err
+	}
+	return attendance.RequiresApproval, nil
+}
+
+func (au *attendanceUsecase) FetchNoShowRates(c context.Context, groupBy string, from time.Time, to time.Time) ([]domain.NoShowRate, error) {
+	if groupBy == "" {
+		groupBy = domain.GroupByDoctor
+	}
+	if groupBy != domain.GroupByDoctor && groupBy != domain.GroupBySpecialty {
+		return nil, domain.ErrInvalidReportGroup
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	rates, err := au.attendanceRepository.FetchNoShowRates(ctx, groupBy, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rates {
+		if rates[i].Appointments > 0 {
+			rates[i].Rate = float64(rates[i].NoShows) / float64(rates[i].Appointments)
+		}
+	}
+	return rates, nil
+}
@@ -25,6 +25,12 @@ func (alu *auditLogUsecase) Create(c context.Context, auditLog *domain.AuditLog)
 	return alu.auditLogRepository.Create(ctx, auditLog)
 }
 
+func (alu *auditLogUsecase) CreateBatch(c context.Context, auditLogs []domain.AuditLog) error {
+	ctx, cancel := context.WithTimeout(c, alu.contextTimeout)
+	defer cancel()
+	return alu.auditLogRepository.CreateBatch(ctx, auditLogs)
+}
+
 func (alu *auditLogUsecase) Fetch(c context.Context) ([]domain.AuditLog, error) {
 	ctx, cancel := context.WithTimeout(c, alu.contextTimeout)
 	defer cancel()
@@ -37,6 +43,18 @@ func (alu *auditLogUsecase) FetchByID(c context.Context, id uuid.UUID) (domain.A
 	return alu.auditLogRepository.FetchByID(ctx, id)
 }
 
+func (alu *auditLogUsecase) FetchAccessLogByPatientID(c context.Context, patientID uuid.UUID) ([]domain.AccessLogEntry, error) {
+	ctx, cancel := context.WithTimeout(c, alu.contextTimeout)
+	defer cancel()
+	return alu.auditLogRepository.FetchAccessLogByPatientID(ctx, patientID)
+}
+
+func (alu *auditLogUsecase) FetchLoginIPs(c context.Context, userID uuid.UUID, before time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(c, alu.contextTimeout)
+	defer cancel()
+	return alu.auditLogRepository.FetchLoginIPs(ctx, userID, before)
+}
+
 func (alu *auditLogUsecase) Update(c context.Context, auditLog *domain.AuditLog) error {
 	ctx, cancel := context.WithTimeout(c, alu.contextTimeout)
 	defer cancel()
@@ -0,0 +1,425 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	dateLayout   = "2006-01-02"
+	maxSlotRange = 62 * 24 * time.Hour
+)
+
+type availabilityUsecase struct {
+	availabilityRepository domain.AvailabilityRepository
+	appointmentRepository  domain.AppointmentRepository
+	waitlistRepository     domain.WaitlistRepository
+	absenceRepository      domain.AbsenceRepository
+	contextTimeout         time.Duration
+}
+
+func NewAvailabilityUsecase(availabilityRepository domain.AvailabilityRepository, appointmentRepository domain.AppointmentRepository, waitlistRepository domain.WaitlistRepository, absenceRepository domain.AbsenceRepository, timeout time.Duration) domain.AvailabilityUsecase {
+	return &availabilityUsecase{
+		availabilityRepository: availabilityRepository,
+		appointmentRepository:  appointmentRepository,
+		waitlistRepository:     waitlistRepository,
+		absenceRepository:      absenceRepository,
+		contextTimeout:         timeout,
+	}
+}
+
+// FetchByDoctorID returns the weekly template and the exceptions from today on.
+func (au *availabilityUsecase) FetchByDoctorID(c context.Context, doctorID uuid.UUID) (domain.DoctorAvailability, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	loc, err := au.Location(ctx, doctorID)
+	if err != nil {
+		return domain.DoctorAvailability{}, err
+	}
+
+	weekly, err := au.availabilityRepository.FetchWeekly(ctx, doctorID)
+	if err != nil {
+		return domain.DoctorAvailability{}, err
+	}
+
+	exceptions, err := au.availabilityRepository.FetchExceptions(ctx, doctorID, time.Now().In(loc).Format(dateLayout), "9999-12-31")
+	if err != nil {
+		return domain.DoctorAvailability{}, err
+	}
+
+	if weekly == nil {
+		weekly = []domain.WeeklyAvailability{}
+	}
+	if exceptions == nil {
+		exceptions = []domain.AvailabilityException{}
+	}
+
+	return domain.DoctorAvailability{
+		DoctorID:   doctorID,
+		Timezone:   loc.String(),
+		Weekly:     weekly,
+		Exceptions: exceptions,
+	}, nil
+}
+
+func (au *availabilityUsecase) ReplaceWeekly(c context.Context, doctorID uuid.UUID, weekly []domain.WeeklyAvailability) error {
+	periodsByWeekday := map[int][]workPeriod{}
+	for _, availability := range weekly {
+		if availability.Weekday < 0 || availability.Weekday > 6 {
+			return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6 (Saturday)", domain.ErrInvalidSchedule)
+		}
+		period, err := newWorkPeriod(time.Now(), availability.StartTime, availability.EndTime, availability.SlotMinutes, availability.Breaks)
+		if err != nil {
+			return err
+		}
+		for _, other := range periodsByWeekday[availability.Weekday] {
+			if period.start.Before(other.end) && period.end.After(other.start) {
+				return fmt.Errorf("%w: periods on weekday %d overlap", domain.ErrInvalidSchedule, availability.Weekday)
+			}
+		}
+		periodsByWeekday[availability.Weekday] = append(periodsByWeekday[availability.Weekday], period)
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.availabilityRepository.ReplaceWeekly(ctx, doctorID, weekly)
+}
+
+func (au *availabilityUsecase) SaveException(c context.Context, exception *domain.AvailabilityException) error {
+	loc, err := au.Location(c, exception.DoctorID)
+	if err != nil {
+		return err
+	}
+
+	day, err := time.ParseInLocation(dateLayout, exception.Date, loc)
+	if err != nil {
+		return fmt.Errorf("%w: date must be formatted as YYYY-MM-DD", domain.ErrInvalidSchedule)
+	}
+
+	if exception.Available {
+		if _, err := newWorkPeriod(day, exception.StartTime, exception.EndTime, exception.SlotMinutes, exception.Breaks); err != nil {
+			return err
+		}
+	} else {
+		exception.StartTime, exception.EndTime, exception.SlotMinutes, exception.Breaks = "", "", 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.availabilityRepository.SaveException(ctx, exception)
+}
+
+func (au *availabilityUsecase) DeleteException(c context.Context, doctorID uuid.UUID, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+	return au.availabilityRepository.DeleteException(ctx, doctorID, id)
+}
+
+// FetchSlots lays the doctor's working periods over [from, to) and returns the
+// slots that are still in the future, not during an absence, and neither
+// taken by an active appointment nor held by a waitlist offer.
+func (au *availabilityUsecase) FetchSlots(c context.Context, doctorID uuid.UUID, from time.Time, to time.Time) ([]domain.Slot, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", domain.ErrInvalidSchedule)
+	}
+	if to.Sub(from) > maxSlotRange {
+		return nil, fmt.Errorf("%w: range can't exceed %d days", domain.ErrInvalidSchedule, int(maxSlotRange.Hours()/24))
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	loc, err := au.Location(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	weekly, err := au.availabilityRepository.FetchWeekly(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := au.availabilityRepository.FetchExceptions(ctx, doctorID, from.In(loc).Format(dateLayout), to.In(loc).Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	appointments, err := au.appointmentRepository.FetchActiveByDoctorIDBetween(ctx, doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Slots held for waitlisted patients aren't free either.
+	held, err := au.waitlistRepository.FetchPendingOffers(ctx, doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, offer := range held {
+		appointments = append(appointments, domain.Appointment{AppointmentDate: offer.SlotStart, EndDate: offer.SlotEnd})
+	}
+
+	absences, err := au.absenceRepository.FetchByDoctorID(ctx, doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, absence := range absences {
+		appointments = append(appointments, domain.Appointment{AppointmentDate: absence.StartsAt, EndDate: absence.EndsAt})
+	}
+
+	periods, err := workPeriods(weekly, exceptions, from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slots := []domain.Slot{}
+	for _, period := range periods {
+		for _, slot := range period.slots() {
+			if slot.Start.Before(from) || slot.End.After(to) || slot.Start.Before(now) {
+				continue
+			}
+			if overlapsAppointment(slot, appointments) {
+				continue
+			}
+			slots = append(slots, slot)
+		}
+	}
+
+	return slots, nil
+}
+
+// OutsideWorkingHours returns the spans that don't fall entirely within one
+// of the doctor's working periods, or that overlap a break or an absence.
+// Appointments aren't considered.
+func (au *availabilityUsecase) OutsideWorkingHours(c context.Context, doctorID uuid.UUID, spans []domain.Slot) ([]domain.Slot, error) {
+	if len(spans) == 0 {
+		return nil, nil
+	}
+
+	from, to := spans[0].Start, spans[0].End
+	for _, span := range spans {
+		if span.Start.Before(from) {
+			from = span.Start
+		}
+		if span.End.After(to) {
+			to = span.End
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	loc, err := au.Location(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	weekly, err := au.availabilityRepository.FetchWeekly(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := au.availabilityRepository.FetchExceptions(ctx, doctorID, from.In(loc).Format(dateLayout), to.In(loc).Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := workPeriods(weekly, exceptions, from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	absences, err := au.absenceRepository.FetchByDoctorID(ctx, doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var outside []domain.Slot
+	for _, span := range spans {
+		if !withinWorkPeriod(span, periods) || duringAbsence(span, absences) {
+			outside = append(outside, span)
+		}
+	}
+
+	return outside, nil
+}
+
+// Location falls back to the server's timezone for doctors without a facility
+// or whose facility has an unknown timezone.
+func (au *availabilityUsecase) Location(c context.Context, doctorID uuid.UUID) (*time.Location, error) {
+	ctx, cancel := context.WithTimeout(c, au.contextTimeout)
+	defer cancel()
+
+	timezone, err := au.availabilityRepository.FetchTimezone(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Location(timezone), nil
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// workPeriod is a weekly template entry or exception resolved to a real date.
+type workPeriod struct {
+	interval
+	slot   time.Duration
+	breaks []interval
+}
+
+func (wp workPeriod) slots() []domain.Slot {
+	var slots []domain.Slot
+	for start := wp.start; !start.Add(wp.slot).After(wp.end); start = start.Add(wp.slot) {
+		slot := domain.Slot{Start: start, End: start.Add(wp.slot)}
+
+		inBreak := false
+		for _, b := range wp.breaks {
+			if slot.Start.Before(b.end) && slot.End.After(b.start) {
+				inBreak = true
+				break
+			}
+		}
+		if !inBreak {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// workPeriods resolves the template for every date in loc touched by
+// [from, to). An exception on a date replaces the template for that day.
+// Wall-clock hours are placed in loc, so a period keeps its local hours across
+// DST changes; hours skipped by a change move forward by the gap, and hours
+// repeated by one are taken the first time.
+func workPeriods(weekly []domain.WeeklyAvailability, exceptions []domain.AvailabilityException, from time.Time, to time.Time, loc *time.Location) ([]workPeriod, error) {
+	exceptionsByDate := map[string]domain.AvailabilityException{}
+	for _, exception := range exceptions {
+		exceptionsByDate[exception.Date] = exception
+	}
+
+	var periods []workPeriod
+	localFrom := from.In(loc)
+	day := domain.WallClock(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, loc)
+	for ; day.Before(to); day = domain.WallClock(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, loc) {
+		if exception, ok := exceptionsByDate[day.Format(dateLayout)]; ok {
+			if !exception.Available {
+				continue
+			}
+			period, err := newWorkPeriod(day, exception.StartTime, exception.EndTime, exception.SlotMinutes, exception.Breaks)
+			if err != nil {
+				return nil, err
+			}
+			periods = append(periods, period)
+			continue
+		}
+
+		for _, availability := range weekly {
+			if time.Weekday(availability.Weekday) != day.Weekday() {
+				continue
+			}
+			period, err := newWorkPeriod(day, availability.StartTime, availability.EndTime, availability.SlotMinutes, availability.Breaks)
+			if err != nil {
+				return nil, err
+			}
+			periods = append(periods, period)
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool { return periods[i].start.Before(periods[j].start) })
+
+	return periods, nil
+}
+
+// newWorkPeriod validates a period's hours and places it on day.
+func newWorkPeriod(day time.Time, startTime string, endTime string, slotMinutes int, breaks []domain.TimeRange) (workPeriod, error) {
+	start, err := clockOn(day, startTime)
+	if err != nil {
+		return workPeriod{}, err
+	}
+	end, err := clockOn(day, endTime)
+	if err != nil {
+		return workPeriod{}, err
+	}
+	if !end.After(start) {
+		return workPeriod{}, fmt.Errorf("%w: end_time %s must be after start_time %s", domain.ErrInvalidSchedule, endTime, startTime)
+	}
+	if slotMinutes <= 0 || time.Duration(slotMinutes)*time.Minute > end.Sub(start) {
+		return workPeriod{}, fmt.Errorf("%w: slot_minutes must be positive and fit between %s and %s", domain.ErrInvalidSchedule, startTime, endTime)
+	}
+
+	period := workPeriod{
+		interval: interval{start: start, end: end},
+		slot:     time.Duration(slotMinutes) * time.Minute,
+	}
+	for _, b := range breaks {
+		breakStart, err := clockOn(day, b.StartTime)
+		if err != nil {
+			return workPeriod{}, err
+		}
+		breakEnd, err := clockOn(day, b.EndTime)
+		if err != nil {
+			return workPeriod{}, err
+		}
+		if !breakEnd.After(breakStart) || breakStart.Before(start) || breakEnd.After(end) {
+			return workPeriod{}, fmt.Errorf("%w: break %s-%s must fall within %s-%s", domain.ErrInvalidSchedule, b.StartTime, b.EndTime, startTime, endTime)
+		}
+		period.breaks = append(period.breaks, interval{start: breakStart, end: breakEnd})
+	}
+
+	return period, nil
+}
+
+// clockOn parses an "HH:MM" wall-clock time and places it on day's date.
+func clockOn(day time.Time, clock string) (time.Time, error) {
+	hours, minutes, ok := strings.Cut(clock, ":")
+	h, errH := strconv.Atoi(hours)
+	m, errM := strconv.Atoi(minutes)
+	if !ok || errH != nil || errM != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return time.Time{}, fmt.Errorf("%w: %q is not a valid HH:MM time", domain.ErrInvalidSchedule, clock)
+	}
+	return domain.WallClock(day.Year(), day.Month(), day.Day(), h, m, 0, day.Location()), nil
+}
+
+func withinWorkPeriod(span domain.Slot, periods []workPeriod) bool {
+	for _, period := range periods {
+		if span.Start.Before(period.start) || span.End.After(period.end) {
+			continue
+		}
+		for _, b := range period.breaks {
+			if span.Start.Before(b.end) && span.End.After(b.start) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func duringAbsence(span domain.Slot, absences []domain.DoctorAbsence) bool {
+	for _, absence := range absences {
+		if absence.Overlaps(span.Start, span.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsAppointment reports whether any appointment overlaps slot.
+func overlapsAppointment(slot domain.Slot, appointments []domain.Appointment) bool {
+	for _, appointment := range appointments {
+		if appointment.AppointmentDate.Before(slot.End) && appointment.EndDate.After(slot.Start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"errors"
+	"hms-api/domain"
+	"testing"
+	"time"
+)
+
+// The DST changes covered: New York springs forward at 02:00 on 2026-03-08
+// and falls back at 02:00 on 2026-11-01; Sydney falls back at 03:00 on
+// 2026-04-05 and springs forward at 02:00 on 2026-10-04.
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skip(err)
+	}
+	return loc
+}
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestClockOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		zone    string
+		date    string
+		clock   string
+		want    string
+		wantErr bool
+	}{
+		{name: "regular day", zone: "America/New_York", date: "2026-03-07", clock: "09:00", want: "2026-03-07T09:00:00-05:00"},
+		{name: "after spring forward", zone: "America/New_York", date: "2026-03-08", clock: "09:00", want: "2026-03-08T09:00:00-04:00"},
+		{name: "before spring forward", zone: "America/New_York", date: "2026-03-08", clock: "01:30", want: "2026-03-08T01:30:00-05:00"},
+		{name: "skipped hour moves forward", zone: "America/New_York", date: "2026-03-08", clock: "02:30", want: "2026-03-08T03:30:00-04:00"},
+		{name: "repeated hour is the first one", zone: "America/New_York", date: "2026-11-01", clock: "01:30", want: "2026-11-01T01:30:00-04:00"},
+		{name: "after fall back", zone: "America/New_York", date: "2026-11-01", clock: "09:00", want: "2026-11-01T09:00:00-05:00"},
+		{name: "southern skipped hour moves forward", zone: "Australia/Sydney", date: "2026-10-04", clock: "02:30", want: "2026-10-04T03:30:00+11:00"},
+		{name: "southern repeated hour is the first one", zone: "Australia/Sydney", date: "2026-04-05", clock: "02:30", want: "2026-04-05T02:30:00+11:00"},
+		{name: "southern after fall back", zone: "Australia/Sydney", date: "2026-04-05", clock: "09:00", want: "2026-04-05T09:00:00+10:00"},
+		{name: "midnight at the end of the day", zone: "America/New_York", date: "2026-03-08", clock: "24:00", want: "2026-03-09T00:00:00-04:00"},
+		{name: "hour out of range", zone: "America/New_York", date: "2026-03-08", clock: "25:00", wantErr: true},
+		{name: "minute out of range", zone: "America/New_York", date: "2026-03-08", clock: "09:60", wantErr: true},
+		{name: "past midnight", zone: "America/New_York", date: "2026-03-08", clock: "24:30", wantErr: true},
+		{name: "no minutes", zone: "America/New_York", date: "2026-03-08", clock: "9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := loadLocation(t, tt.zone)
+			day, err := time.ParseInLocation(dateLayout, tt.date, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := clockOn(day, tt.clock)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidSchedule) {
+					t.Fatalf("clockOn(%s, %q) error = %v, want ErrInvalidSchedule", tt.date, tt.clock, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("clockOn(%s, %q): %v", tt.date, tt.clock, err)
+			}
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Errorf("clockOn(%s, %q) = %v, want %v", tt.date, tt.clock, got, want)
+			}
+			if got.Location() != loc {
+				t.Errorf("clockOn(%s, %q) is in %v, want %v", tt.date, tt.clock, got.Location(), loc)
+			}
+		})
+	}
+}
+
+func TestWorkPeriods(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	sydney := loadLocation(t, "Australia/Sydney")
+
+	weekdays := func(start string, end string, days ...time.Weekday) []domain.WeeklyAvailability {
+		var weekly []domain.WeeklyAvailability
+		for _, day := range days {
+			weekly = append(weekly, domain.WeeklyAvailability{Weekday: int(day), StartTime: start, EndTime: end, SlotMinutes: 60})
+		}
+		return weekly
+	}
+
+	tests := []struct {
+		name       string
+		loc        *time.Location
+		weekly     []domain.WeeklyAvailability
+		exceptions []domain.AvailabilityException
+		from       string
+		to         string
+		want       [][2]string
+	}{
+		{
+			name:   "local hours kept across spring forward",
+			loc:    newYork,
+			weekly: weekdays("09:00", "17:00", time.Saturday, time.Sunday, time.Monday),
+			from:   "2026-03-07T00:00:00-05:00",
+			to:     "2026-03-10T00:00:00-04:00",
+			want: [][2]string{
+				{"2026-03-07T09:00:00-05:00", "2026-03-07T17:00:00-05:00"},
+				{"2026-03-08T09:00:00-04:00", "2026-03-08T17:00:00-04:00"},
+				{"2026-03-09T09:00:00-04:00", "2026-03-09T17:00:00-04:00"},
+			},
+		},
+		{
+			name:   "local hours kept across fall back",
+			loc:    newYork,
+			weekly: weekdays("09:00", "17:00", time.Saturday, time.Sunday),
+			from:   "2026-10-31T00:00:00-04:00",
+			to:     "2026-11-02T00:00:00-05:00",
+			want: [][2]string{
+				{"2026-10-31T09:00:00-04:00", "2026-10-31T17:00:00-04:00"},
+				{"2026-11-01T09:00:00-05:00", "2026-11-01T17:00:00-05:00"},
+			},
+		},
+		{
+			name:   "start in the skipped hour",
+			loc:    newYork,
+			weekly: weekdays("02:30", "06:00", time.Sunday),
+			from:   "2026-03-08T00:00:00-05:00",
+			to:     "2026-03-09T00:00:00-04:00",
+			want:   [][2]string{{"2026-03-08T03:30:00-04:00", "2026-03-08T06:00:00-04:00"}},
+		},
+		{
+			name:   "start in the repeated hour",
+			loc:    newYork,
+			weekly: weekdays("01:30", "06:00", time.Sunday),
+			from:   "2026-11-01T00:00:00-04:00",
+			to:     "2026-11-02T00:00:00-05:00",
+			want:   [][2]string{{"2026-11-01T01:30:00-04:00", "2026-11-01T06:00:00-05:00"}},
+		},
+		{
+			name:   "southern hemisphere spring forward",
+			loc:    sydney,
+			weekly: weekdays("09:00", "12:00", time.Saturday, time.Sunday),
+			from:   "2026-10-03T00:00:00+10:00",
+			to:     "2026-10-05T00:00:00+11:00",
+			want: [][2]string{
+				{"2026-10-03T09:00:00+10:00", "2026-10-03T12:00:00+10:00"},
+				{"2026-10-04T09:00:00+11:00", "2026-10-04T12:00:00+11:00"},
+			},
+		},
+		{
+			name:   "southern hemisphere fall back",
+			loc:    sydney,
+			weekly: weekdays("09:00", "12:00", time.Saturday, time.Sunday),
+			from:   "2026-04-04T00:00:00+11:00",
+			to:     "2026-04-06T00:00:00+10:00",
+			want: [][2]string{
+				{"2026-04-04T09:00:00+11:00", "2026-04-04T12:00:00+11:00"},
+				{"2026-04-05T09:00:00+10:00", "2026-04-05T12:00:00+10:00"},
+			},
+		},
+		{
+			name:   "exceptions replace the template on their date",
+			loc:    newYork,
+			weekly: weekdays("09:00", "17:00", time.Saturday, time.Sunday, time.Monday),
+			exceptions: []domain.AvailabilityException{
+				{Date: "2026-03-07", Available: false},
+				{Date: "2026-03-08", Available: true, StartTime: "13:00", EndTime: "15:00", SlotMinutes: 30},
+			},
+			from: "2026-03-07T00:00:00-05:00",
+			to:   "2026-03-10T00:00:00-04:00",
+			want: [][2]string{
+				{"2026-03-08T13:00:00-04:00", "2026-03-08T15:00:00-04:00"},
+				{"2026-03-09T09:00:00-04:00", "2026-03-09T17:00:00-04:00"},
+			},
+		},
+		{
+			name:   "days are those in the doctor's timezone",
+			loc:    sydney,
+			weekly: weekdays("09:00", "12:00", time.Sunday),
+			from:   "2026-10-03T14:00:00Z",
+			to:     "2026-10-04T14:00:00Z",
+			want:   [][2]string{{"2026-10-04T09:00:00+11:00", "2026-10-04T12:00:00+11:00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods, err := workPeriods(tt.weekly, tt.exceptions, mustTime(t, tt.from), mustTime(t, tt.to), tt.loc)
+			if err != nil {
+				t.Fatalf("workPeriods: %v", err)
+			}
+			if len(periods) != len(tt.want) {
+				t.Fatalf("got %d periods %v, want %d", len(periods), periods, len(tt.want))
+			}
+			for i, want := range tt.want {
+				start, end := mustTime(t, want[0]), mustTime(t, want[1])
+				if !periods[i].start.Equal(start) || !periods[i].end.Equal(end) {
+					t.Errorf("period %d = %v to %v, want %v to %v", i, periods[i].start, periods[i].end, start, end)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkPeriodsInvalidTemplate(t *testing.T) {
+	from := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name   string
+		weekly domain.WeeklyAvailability
+	}{
+		{name: "end before start", weekly: domain.WeeklyAvailability{Weekday: 1, StartTime: "17:00", EndTime: "09:00", SlotMinutes: 30}},
+		{name: "slot longer than the period", weekly: domain.WeeklyAvailability{Weekday: 1, StartTime: "09:00", EndTime: "09:30", SlotMinutes: 60}},
+		{name: "no slot length", weekly: domain.WeeklyAvailability{Weekday: 1, StartTime: "09:00", EndTime: "17:00"}},
+		{name: "break outside the period", weekly: domain.WeeklyAvailability{Weekday: 1, StartTime: "09:00", EndTime: "12:00", SlotMinutes: 30, Breaks: []domain.TimeRange{{StartTime: "12:00", EndTime: "13:00"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := workPeriods([]domain.WeeklyAvailability{tt.weekly}, nil, from, to, time.UTC)
+			if !errors.Is(err, domain.ErrInvalidSchedule) {
+				t.Errorf("workPeriods error = %v, want ErrInvalidSchedule", err)
+			}
+		})
+	}
+}
+
+func TestWorkPeriodSlots(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	sydney := loadLocation(t, "Australia/Sydney")
+
+	tests := []struct {
+		name   string
+		loc    *time.Location
+		date   string
+		start  string
+		end    string
+		slot   int
+		breaks []domain.TimeRange
+		want   []string
+	}{
+		{
+			name:   "break left out",
+			loc:    newYork,
+			date:   "2026-03-09",
+			start:  "09:00",
+			end:    "11:00",
+			slot:   30,
+			breaks: []domain.TimeRange{{StartTime: "10:00", EndTime: "10:30"}},
+			want:   []string{"2026-03-09T09:00:00-04:00", "2026-03-09T09:30:00-04:00", "2026-03-09T10:30:00-04:00"},
+		},
+		{
+			name:  "slots that don't fit are left out",
+			loc:   newYork,
+			date:  "2026-03-09",
+			start: "09:00",
+			end:   "10:40",
+			slot:  30,
+			want:  []string{"2026-03-09T09:00:00-04:00", "2026-03-09T09:30:00-04:00", "2026-03-09T10:00:00-04:00"},
+		},
+		{
+			name:  "spring forward night is an hour shorter",
+			loc:   newYork,
+			date:  "2026-03-08",
+			start: "00:00",
+			end:   "04:00",
+			slot:  60,
+			want:  []string{"2026-03-08T00:00:00-05:00", "2026-03-08T01:00:00-05:00", "2026-03-08T03:00:00-04:00"},
+		},
+		{
+			name:  "fall back night is an hour longer",
+			loc:   newYork,
+			date:  "2026-11-01",
+			start: "00:00",
+			end:   "03:00",
+			slot:  60,
+			want: []string{
+				"2026-11-01T00:00:00-04:00",
+				"2026-11-01T01:00:00-04:00",
+				"2026-11-01T01:00:00-05:00",
+				"2026-11-01T02:00:00-05:00",
+			},
+		},
+		{
+			name:  "southern spring forward night is an hour shorter",
+			loc:   sydney,
+			date:  "2026-10-04",
+			start: "01:00",
+			end:   "04:00",
+			slot:  60,
+			want:  []string{"2026-10-04T01:00:00+10:00", "2026-10-04T03:00:00+11:00"},
+		},
+		{
+			name:  "southern fall back night is an hour longer",
+			loc:   sydney,
+			date:  "2026-04-05",
+			start: "01:00",
+			end:   "04:00",
+			slot:  60,
+			want: []string{
+				"2026-04-05T01:00:00+11:00",
+				"2026-04-05T02:00:00+11:00",
+				"2026-04-05T02:00:00+10:00",
+				"2026-04-05T03:00:00+10:00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, err := time.ParseInLocation(dateLayout, tt.date, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			period, err := newWorkPeriod(day, tt.start, tt.end, tt.slot, tt.breaks)
+			if err != nil {
+				t.Fatalf("newWorkPeriod: %v", err)
+			}
+
+			slots := period.slots()
+			if len(slots) != len(tt.want) {
+				t.Fatalf("got %d slots %v, want %d", len(slots), slots, len(tt.want))
+			}
+			for i, want := range tt.want {
+				start := mustTime(t, want)
+				if !slots[i].Start.Equal(start) || !slots[i].End.Equal(start.Add(time.Duration(tt.slot)*time.Minute)) {
+					t.Errorf("slot %d = %v to %v, want it to start at %v", i, slots[i].Start, slots[i].End, start)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultBookingRange is how far ahead a booking search looks without to.
+const defaultBookingRange = 7 * 24 * time.Hour
+
+type bookingUsecase struct {
+	bookingRepository   domain.BookingRepository
+	typeRepository      domain.AppointmentTypeRepository
+	availabilityUsecase domain.AvailabilityUsecase
+	appointmentUsecase  domain.AppointmentUsecase
+	hold                time.Duration
+	contextTimeout      time.Duration
+}
+
+func NewBookingUsecase(bookingRepository domain.BookingRepository, typeRepository domain.AppointmentTypeRepository, availabilityUsecase domain.AvailabilityUsecase, appointmentUsecase domain.AppointmentUsecase, hold time.Duration, timeout time.Duration) domain.BookingUsecase {
+	if hold <= 0 {
+		hold = domain.DefaultBookingHold
+	}
+
+	return &bookingUsecase{
+		bookingRepository:   bookingRepository,
+		typeRepository:      typeRepository,
+		availabilityUsecase: availabilityUsecase,
+		appointmentUsecase:  appointmentUsecase,
+		hold:                hold,
+		contextTimeout:      timeout,
+	}
+}
+
+// Search lists the free slots of every doctor of the specialty, earliest
+// first. Slots held by other patients aren't free.
+func (bu *bookingUsecase) Search(c context.Context, search domain.BookingSearch) ([]domain.BookableSlot, error) {
+	search.Specialty = strings.TrimSpace(search.Specialty)
+	if search.Specialty == "" {
+		return nil, fmt.Errorf("%w: specialty is required", domain.ErrInvalidBookingSearch)
+	}
+	if search.From.IsZero() || search.From.Before(time.Now()) {
+		search.From = time.Now()
+	}
+	if search.To.IsZero() {
+		search.To = search.From.Add(defaultBookingRange)
+	}
+	if !search.To.After(search.From) {
+		return nil, fmt.Errorf("%w: to must be after from", domain.ErrInvalidBookingSearch)
+	}
+
+	duration, err := bu.typeDuration(c, search.TypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, bu.contextTimeout)
+	doctors, err := bu.bookingRepository.FetchDoctors(ctx, search.Specialty, strings.TrimSpace(search.Facility))
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if len(doctors) == 0 {
+		return []domain.BookableSlot{}, nil
+	}
+
+	doctorIDs := make([]uuid.UUID, 0, len(doctors))
+	for _, doctor := range doctors {
+		doctorIDs = append(doctorIDs, doctor.DoctorID)
+	}
+
+	ctx, cancel = context.WithTimeout(c, bu.contextTimeout)
+	holds, err := bu.bookingRepository.FetchActiveHolds(ctx, doctorIDs, search.From, search.To)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	held := map[uuid.UUID][]domain.Appointment{}
+	for _, hold := range holds {
+		held[hold.DoctorID] = append(held[hold.DoctorID], domain.Appointment{AppointmentDate: hold.SlotStart, EndDate: hold.SlotEnd})
+	}
+
+	bookable := []domain.BookableSlot{}
+	for _, doctor := range doctors {
+		slots, err := bu.availabilityUsecase.FetchSlots(c, doctor.DoctorID, search.From, search.To)
+		if err != nil {
+			return nil, err
+		}
+
+		free := slots[:0]
+		for _, slot := range slots {
+			if !overlapsAppointment(slot, held[doctor.DoctorID]) {
+				free = append(free, slot)
+			}
+		}
+		if duration > 0 {
+			free = startsFitting(free, duration)
+		}
+
+		for _, slot := range free {
+			bookable = append(bookable, domain.BookableSlot{BookingDoctor: doctor, Start: slot.Start, End: slot.End})
+		}
+	}
+
+	sort.SliceStable(bookable, func(i, j int) bool { return bookable[i].Start.Before(bookable[j].Start) })
+	return bookable, nil
+}
+
+// Hold reserves the requested time for the patient. Without an end the hold
+// lasts the appointment type's duration or, without a type, the slot starting
+// at the requested time. The whole span must be free.
+func (bu *bookingUsecase) Hold(c context.Context, patientID uuid.UUID, request domain.HoldRequest) (domain.SlotHold, error) {
+	if !request.Start.After(time.Now()) {
+		return domain.SlotHold{}, domain.ErrSlotUnavailable
+	}
+
+	duration, err := bu.typeDuration(c, request.TypeID)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+
+	end := request.End
+	if end.IsZero() && duration > 0 {
+		end = request.Start.Add(duration)
+	}
+	if end.IsZero() {
+		slots, err := bu.availabilityUsecase.FetchSlots(c, request.DoctorID, request.Start, request.Start.Add(24*time.Hour))
+		if err != nil {
+			return domain.SlotHold{}, err
+		}
+		if len(slots) == 0 || !slots[0].Start.Equal(request.Start) {
+			return domain.SlotHold{}, domain.ErrSlotUnavailable
+		}
+		end = slots[0].End
+	}
+	if !end.After(request.Start) {
+		return domain.SlotHold{}, fmt.Errorf("%w: end must be after start", domain.ErrInvalidAppointmentTime)
+	}
+
+	slots, err := bu.availabilityUsecase.FetchSlots(c, request.DoctorID, request.Start, end)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	if len(startsFitting(slots, end.Sub(request.Start))) == 0 || !slots[0].Start.Equal(request.Start) {
+		return domain.SlotHold{}, domain.ErrSlotUnavailable
+	}
+
+	hold := domain.SlotHold{
+		PatientID: patientID,
+		DoctorID:  request.DoctorID,
+		TypeID:    request.TypeID,
+		SlotStart: request.Start,
+		SlotEnd:   end,
+		Status:    domain.HoldActive,
+		ExpiresAt: time.Now().Add(bu.hold),
+	}
+
+	ctx, cancel := context.WithTimeout(c, bu.contextTimeout)
+	defer cancel()
+	if err := bu.bookingRepository.CreateHold(ctx, &hold); err != nil {
+		return domain.SlotHold{}, err
+	}
+
+	return hold, nil
+}
+
+// FetchHold returns one of the patient's holds, reporting lapsed ones as
+// expired.
+func (bu *bookingUsecase) FetchHold(c context.Context, patientID uuid.UUID, id uuid.UUID) (domain.SlotHold, error) {
+	ctx, cancel := context.WithTimeout(c, bu.contextTimeout)
+	defer cancel()
+
+	hold, err := bu.bookingRepository.FetchHoldByID(ctx, id)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	if hold.PatientID != patientID {
+		return domain.SlotHold{}, domain.ErrHoldNotFound
+	}
+	if hold.Status == domain.HoldActive && !hold.Active(time.Now()) {
+		hold.Status = domain.HoldExpired
+	}
+
+	return hold, nil
+}
+
+func (bu *bookingUsecase) Release(c context.Context, patientID uuid.UUID, id uuid.UUID) error {
+	hold, err := bu.FetchHold(c, patientID, id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, bu.contextTimeout)
+	defer cancel()
+
+	status := domain.HoldReleased
+	if hold.Status == domain.HoldExpired {
+		status = domain.HoldExpired
+	}
+	closed, err := bu.bookingRepository.CloseHold(ctx, &hold, status)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return domain.ErrHoldClosed
+	}
+	return nil
+}
+
+// Confirm books the held slot as a patient request, like any other booking
+// by a patient, and marks the hold as booked.
+func (bu *bookingUsecase) Confirm(c context.Context, patientID uuid.UUID, id uuid.UUID, notes string, actorID uuid.UUID) (domain.Appointment, error) {
+	hold, err := bu.FetchHold(c, patientID, id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if !hold.Active(time.Now()) {
+		return domain.Appointment{}, domain.ErrHoldClosed
+	}
+
+	appointment := domain.Appointment{
+		PatientID:       hold.PatientID,
+		DoctorID:        hold.DoctorID,
+		AppointmentDate: hold.SlotStart,
+		EndDate:         hold.SlotEnd,
+		TypeID:          hold.TypeID,
+		Notes:           strings.TrimSpace(notes),
+	}
+	if err := bu.appointmentUsecase.Create(c, &appointment, actorID, domain.PatientRole); err != nil {
+		return domain.Appointment{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, bu.contextTimeout)
+	defer cancel()
+
+	hold.AppointmentID = &appointment.ID
+	if _, err := bu.bookingRepository.CloseHold(ctx, &hold, domain.HoldBooked); err != nil {
+		return domain.Appointment{}, err
+	}
+
+	return appointment, nil
+}
+
+// typeDuration returns the duration of the appointment type, or zero without
+// one.
+func (bu *bookingUsecase) typeDuration(c context.Context, typeID *uuid.UUID) (time.Duration, error) {
+	if typeID == nil {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, bu.contextTimeout)
+	defer cancel()
+
+	appointmentType, err := bu.typeRepository.FetchByID(ctx, *typeID)
+	if err != nil {
+		return 0, err
+	}
+	if !appointmentType.Active {
+		return 0, fmt.Errorf("%w: %s is no longer offered", domain.ErrAppointmentTypeNotFound, appointmentType.Name)
+	}
+	return appointmentType.Duration(), nil
+}
+
+// startsFitting returns, for each slot that begins a run of back-to-back
+// slots lasting at least duration, the span from its start to start plus
+// duration. slots must be sorted.
+func startsFitting(slots []domain.Slot, duration time.Duration) []domain.Slot {
+	var fitting []domain.Slot
+	for i, slot := range slots {
+		end := slot.End
+		for j := i + 1; end.Sub(slot.Start) < duration && j < len(slots) && slots[j].Start.Equal(end); j++ {
+			end = slots[j].End
+		}
+		if end.Sub(slot.Start) >= duration {
+			fitting = append(fitting, domain.Slot{Start: slot.Start, End: slot.Start.Add(duration)})
+		}
+	}
+	return fitting
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hms-api/domain"
+	"hms-api/internal/ical"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const calendarTokenBytes = 32
+
+type calendarUsecase struct {
+	feedRepository        domain.CalendarFeedRepository
+	userRepository        domain.UserRepository
+	doctorRepository      domain.DoctorRepository
+	patientRepository     domain.PatientRepository
+	appointmentRepository domain.AppointmentRepository
+	baseURL               string
+	language              string
+	contextTimeout        time.Duration
+}
+
+func NewCalendarUsecase(feedRepository domain.CalendarFeedRepository, userRepository domain.UserRepository, doctorRepository domain.DoctorRepository, patientRepository domain.PatientRepository, appointmentRepository domain.AppointmentRepository, baseURL string, language string, timeout time.Duration) domain.CalendarUsecase {
+	return &calendarUsecase{
+		feedRepository:        feedRepository,
+		userRepository:        userRepository,
+		doctorRepository:      doctorRepository,
+		patientRepository:     patientRepository,
+		appointmentRepository: appointmentRepository,
+		baseURL:               strings.TrimRight(baseURL, "/"),
+		language:              language,
+		contextTimeout:        timeout,
+	}
+}
+
+// CreateFeed issues a new secret feed URL for the user. The token is only
+// returned here; afterwards the feed can only be listed or revoked.
+func (cu *calendarUsecase) CreateFeed(c context.Context, userID uuid.UUID, role domain.UserRole) (domain.CalendarFeed, error) {
+	if role != domain.DoctorRole && role != domain.PatientRole {
+		return domain.CalendarFeed{}, domain.ErrCalendarUnavailable
+	}
+
+	raw := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return domain.CalendarFeed{}, fmt.Errorf("error generating calendar token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	ctx, cancel := context.WithTimeout(c, cu.contextTimeout)
+	defer cancel()
+
+	feed := domain.CalendarFeed{UserID: userID}
+	if err := cu.feedRepository.Create(ctx, &feed, hashCalendarToken(token)); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+
+	feed.Token = token
+	feed.URL = cu.baseURL + "/calendar/" + token + ".ics"
+	return feed, nil
+}
+
+func (cu *calendarUsecase) FetchFeeds(c context.Context, userID uuid.UUID) ([]domain.CalendarFeed, error) {
+	ctx, cancel := context.WithTimeout(c, cu.contextTimeout)
+	defer cancel()
+	return cu.feedRepository.FetchByUserID(ctx, userID)
+}
+
+func (cu *calendarUsecase) RevokeFeed(c context.Context, id uuid.UUID, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, cu.contextTimeout)
+	defer cancel()
+
+	revoked, err := cu.feedRepository.Revoke(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return domain.ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// Render lists every appointment of the feed owner, canceled ones included so
+// subscribed calendars remove them. Events carry no patient names or notes,
+// since anyone holding the URL can read the feed.
+func (cu *calendarUsecase) Render(c context.Context, token string) (domain.CalendarFeed, []byte, error) {
+	ctx, cancel := context.WithTimeout(c, cu.contextTimeout)
+	defer cancel()
+
+	feed, err := cu.feedRepository.FetchActiveByTokenHash(ctx, hashCalendarToken(strings.TrimSuffix(token, ".ics")))
+	if err != nil {
+		return domain.CalendarFeed{}, nil, err
+	}
+
+	user, err := cu.userRepository.GetByID(ctx, feed.UserID)
+	if err != nil {
+		return domain.CalendarFeed{}, nil, err
+	}
+
+	var appointments []domain.Appointment
+	switch user.Role {
+	case domain.DoctorRole:
+		doctor, err := cu.doctorRepository.FetchByUserID(ctx, user.ID)
+		if err != nil {
+			return domain.CalendarFeed{}, nil, err
+		}
+		if doctor.ID == uuid.Nil {
+			return domain.CalendarFeed{}, nil, domain.ErrCalendarFeedNotFound
+		}
+		if appointments, err = cu.appointmentRepository.FetchByDoctorID(ctx, doctor.ID); err != nil {
+			return domain.CalendarFeed{}, nil, err
+		}
+	case domain.PatientRole:
+		patient, err := cu.patientRepository.FetchByUserID(ctx, user.ID)
+		if err != nil {
+			return domain.CalendarFeed{}, nil, err
+		}
+		if patient.ID == uuid.Nil {
+			return domain.CalendarFeed{}, nil, domain.ErrCalendarFeedNotFound
+		}
+		if appointments, err = cu.appointmentRepository.FetchByPatientID(ctx, patient.ID); err != nil {
+			return domain.CalendarFeed{}, nil, err
+		}
+	default:
+		return domain.CalendarFeed{}, nil, domain.ErrCalendarUnavailable
+	}
+
+	calendar := ical.Calendar{Name: "HMS"}
+	for _, appointment := range appointments {
+		calendar.Events = append(calendar.Events, ical.AppointmentEvent(appointment, appointmentSummary(cu.language), ""))
+	}
+
+	return feed, calendar.Bytes(), nil
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func appointmentSummary(language string) string {
+	if language == domain.LanguageEnglish {
+		return "Medical appointment"
+	}
+	return "Consulta médica"
+}
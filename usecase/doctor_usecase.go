@@ -38,6 +38,12 @@ func (du *doctorUsecase) FetchByID(c context.Context, id uuid.UUID) (domain.Doct
 	return du.doctorRepository.FetchByID(ctx, id)
 }
 
+func (du *doctorUsecase) FetchByUserID(c context.Context, userID uuid.UUID) (domain.Doctor, error) {
+	ctx, cancel := context.WithTimeout(c, du.contextTimeout)
+	defer cancel()
+	return du.doctorRepository.FetchByUserID(ctx, userID)
+}
+
 func (du *doctorUsecase) Update(c context.Context, doctor *domain.Doctor) error {
 	ctx, cancel := context.WithTimeout(c, du.contextTimeout)
 	defer cancel()
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type encounterUsecase struct {
+	encounterRepository  domain.EncounterRepository
+	appointmentUsecase   domain.AppointmentUsecase
+	patientRepository    domain.PatientRepository
+	medicalRecordUsecase domain.MedicalRecordUsecase
+	prescriptionUsecase  domain.PrescriptionUsecase
+	contextTimeout       time.Duration
+}
+
+func NewEncounterUsecase(encounterRepository domain.EncounterRepository, appointmentUsecase domain.AppointmentUsecase, patientRepository domain.PatientRepository, medicalRecordUsecase domain.MedicalRecordUsecase, prescriptionUsecase domain.PrescriptionUsecase, timeout time.Duration) domain.EncounterUsecase {
+	return &encounterUsecase{
+		encounterRepository:  encounterRepository,
+		appointmentUsecase:   appointmentUsecase,
+		patientRepository:    patientRepository,
+		medicalRecordUsecase: medicalRecordUsecase,
+		prescriptionUsecase:  prescriptionUsecase,
+		contextTimeout:       timeout,
+	}
+}
+
+// Open starts a checked-in appointment before opening its encounter, so the
+// appointment is in progress for as long as the encounter is open.
+func (eu *encounterUsecase) Open(c context.Context, request domain.OpenEncounterRequest, doctorID uuid.UUID, actorID uuid.UUID, actorRole domain.UserRole) (*domain.Encounter, error) {
+	encounter := &domain.Encounter{
+		DoctorID: doctorID,
+		Status:   domain.EncounterOpen,
+		Reason:   strings.TrimSpace(request.Reason),
+		Notes:    strings.TrimSpace(request.Notes),
+		OpenedBy: actorID,
+	}
+
+	if request.AppointmentID != nil {
+		appointment, err := eu.appointmentUsecase.FetchByID(c, *request.AppointmentID)
+		if err != nil {
+			return nil, err
+		}
+		if appointment.ID == uuid.Nil {
+			return nil, domain.ErrAppointmentNotFound
+		}
+		if appointment.DoctorID != doctorID {
+			return nil, domain.ErrEncounterForbidden
+		}
+
+		ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+		existing, err := eu.encounterRepository.FetchByAppointmentID(ctx, appointment.ID)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, domain.ErrEncounterExists
+		}
+
+		switch appointment.Status {
+		case domain.CheckedIn:
+			if _, err := eu.appointmentUsecase.Transition(c, appointment.ID, "start", actorID, actorRole, ""); err != nil {
+				return nil, err
+			}
+		case domain.InProgress:
+		default:
+			return nil, fmt.Errorf("%w: the appointment is %s, not checked in", domain.ErrInvalidEncounter, appointment.Status)
+		}
+
+		encounter.PatientID = appointment.PatientID
+		encounter.AppointmentID = &appointment.ID
+	} else {
+		if request.PatientID == nil {
+			return nil, fmt.Errorf("%w: appointment_id or patient_id is required", domain.ErrInvalidEncounter)
+		}
+
+		ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+		patient, err := eu.patientRepository.FetchByID(ctx, *request.PatientID)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		if patient.ID == uuid.Nil {
+			return nil, domain.ErrPatientNotFound
+		}
+
+		encounter.PatientID = patient.ID
+	}
+
+	ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+	defer cancel()
+
+	if err := eu.encounterRepository.Create(ctx, encounter); err != nil {
+		return nil, err
+	}
+	return encounter, nil
+}
+
+func (eu *encounterUsecase) FetchByID(c context.Context, id uuid.UUID) (*domain.Encounter, error) {
+	ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+	defer cancel()
+
+	encounter, err := eu.encounterRepository.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if encounter == nil {
+		return nil, domain.ErrEncounterNotFound
+	}
+
+	if encounter.MedicalRecords, err = eu.medicalRecordUsecase.FetchByEncounterID(c, id); err != nil {
+		return nil, err
+	}
+	if encounter.Prescriptions, err = eu.prescriptionUsecase.FetchByEncounterID(c, id); err != nil {
+		return nil, err
+	}
+	if encounter.Orders, err = eu.encounterRepository.FetchOrders(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return encounter, nil
+}
+
+func (eu *encounterUsecase) FetchByPatientID(c context.Context, patientID uuid.UUID) ([]domain.Encounter, error) {
+	ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+	defer cancel()
+	return eu.encounterRepository.FetchByPatientID(ctx, patientID)
+}
+
+// Close completes the appointment first, so a close that fails halfway can
+// be retried: an appointment already completed is left as it is.
+func (eu *encounterUsecase) Close(c context.Context, id uuid.UUID, notes string, doctorID uuid.UUID, actorID uuid.UUID, actorRole domain.UserRole) (*domain.Encounter, error) {
+	encounter, err := eu.openEncounter(c, id, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if encounter.AppointmentID != nil {
+		appointment, err := eu.appointmentUsecase.FetchByID(c, *encounter.AppointmentID)
+		if err != nil {
+			return nil, err
+		}
+		if appointment.Status == domain.InProgress {
+			if _, err := eu.appointmentUsecase.Transition(c, appointment.ID, "complete", actorID, actorRole, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if notes = strings.TrimSpace(notes); notes != "" {
+		encounter.Notes = notes
+	}
+	encounter.ClosedBy = &actorID
+
+	ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+	defer cancel()
+
+	closed, err := eu.encounterRepository.Close(ctx, encounter)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, domain.ErrEncounterClosed
+	}
+	return encounter, nil
+}
+
+func (eu *encounterUsecase) AddMedicalRecord(c context.Context, id uuid.UUID, record *domain.MedicalRecord, doctorID uuid.UUID, actorID uuid.UUID) error {
+	encounter, err := eu.openEncounter(c, id, doctorID)
+	if err != nil {
+		return err
+	}
+
+	record.PatientID = encounter.PatientID
+	record.DoctorID = encounter.DoctorID
+	record.EncounterID = &encounter.ID
+	record.UpdatedBy = &actorID
+	return eu.medicalRecordUsecase.Create(c, record)
+}
+
+func (eu *encounterUsecase) AddPrescription(c context.Context, id uuid.UUID, prescription *domain.Prescription, doctorID uuid.UUID) error {
+	if strings.TrimSpace(prescription.MedicationDetails) == "" {
+		return fmt.Errorf("%w: medication_details is required", domain.ErrInvalidEncounter)
+	}
+
+	encounter, err := eu.openEncounter(c, id, doctorID)
+	if err != nil {
+		return err
+	}
+
+	record, err := eu.medicalRecordUsecase.FetchByID(c, prescription.MedicalRecordID)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.EncounterID == nil || *record.EncounterID != encounter.ID {
+		return fmt.Errorf("%w: medical_record_id must be one of the encounter's records", domain.ErrInvalidEncounter)
+	}
+	if record.Status == domain.MedicalRecordEnteredInError {
+		return domain.ErrMedicalRecordEnteredInError
+	}
+
+	prescription.PatientID = encounter.PatientID
+	prescription.DoctorID = encounter.DoctorID
+	prescription.EncounterID = &encounter.ID
+	return eu.prescriptionUsecase.Create(c, prescription)
+}
+
+func (eu *encounterUsecase) AddOrder(c context.Context, id uuid.UUID, order *domain.EncounterOrder, doctorID uuid.UUID, actorID uuid.UUID) error {
+	switch order.Kind {
+	case domain.LabOrder, domain.ImagingOrder, domain.ProcedureOrder, domain.ReferralOrder:
+	default:
+		return fmt.Errorf("%w: kind must be lab, imaging, procedure or referral", domain.ErrInvalidEncounter)
+	}
+	order.Description = strings.TrimSpace(order.Description)
+	if order.Description == "" {
+		return fmt.Errorf("%w: description is required", domain.ErrInvalidEncounter)
+	}
+
+	encounter, err := eu.openEncounter(c, id, doctorID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+	defer cancel()
+
+	order.EncounterID = encounter.ID
+	order.PatientID = encounter.PatientID
+	order.Notes = strings.TrimSpace(order.Notes)
+	order.OrderedBy = actorID
+	return eu.encounterRepository.CreateOrder(ctx, order)
+}
+
+// openEncounter fetches an encounter the doctor can still change.
+func (eu *encounterUsecase) openEncounter(c context.Context, id uuid.UUID, doctorID uuid.UUID) (*domain.Encounter, error) {
+	ctx, cancel := context.WithTimeout(c, eu.contextTimeout)
+	defer cancel()
+
+	encounter, err := eu.encounterRepository.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if encounter == nil {
+		return nil, domain.ErrEncounterNotFound
+	}
+	if encounter.DoctorID != doctorID {
+		return nil, domain.ErrEncounterForbidden
+	}
+	if encounter.Status != domain.EncounterOpen {
+		return nil, domain.ErrEncounterClosed
+	}
+	return encounter, nil
+}
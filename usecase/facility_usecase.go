@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type facilityUsecase struct {
+	facilityRepository domain.FacilityRepository
+	contextTimeout     time.Duration
+}
+
+func NewFacilityUsecase(facilityRepository domain.FacilityRepository, timeout time.Duration) domain.FacilityUsecase {
+	return &facilityUsecase{
+		facilityRepository: facilityRepository,
+		contextTimeout:     timeout,
+	}
+}
+
+func (fu *facilityUsecase) Create(c context.Context, facility *domain.Facility) error {
+	if err := normalizeFacility(facility); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, fu.contextTimeout)
+	defer cancel()
+	return fu.facilityRepository.Create(ctx, facility)
+}
+
+func (fu *facilityUsecase) Fetch(c context.Context) ([]domain.Facility, error) {
+	ctx, cancel := context.WithTimeout(c, fu.contextTimeout)
+	defer cancel()
+	return fu.facilityRepository.Fetch(ctx)
+}
+
+func (fu *facilityUsecase) FetchByID(c context.Context, id uuid.UUID) (domain.Facility, error) {
+	ctx, cancel := context.WithTimeout(c, fu.contextTimeout)
+	defer cancel()
+	return fu.facilityRepository.FetchByID(ctx, id)
+}
+
+// Update changes the facility. Doctors' hours stay at the same wall-clock
+// times, so changing the timezone moves their future slots.
+func (fu *facilityUsecase) Update(c context.Context, facility *domain.Facility) error {
+	if err := normalizeFacility(facility); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, fu.contextTimeout)
+	defer cancel()
+	return fu.facilityRepository.Update(ctx, facility)
+}
+
+func (fu *facilityUsecase) Delete(c context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, fu.contextTimeout)
+	defer cancel()
+	return fu.facilityRepository.Delete(ctx, id)
+}
+
+func normalizeFacility(facility *domain.Facility) error {
+	facility.Name = strings.TrimSpace(facility.Name)
+	facility.Timezone = strings.TrimSpace(facility.Timezone)
+
+	if facility.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidFacility)
+	}
+	if !domain.ValidTimezone(facility.Timezone) {
+		return domain.ErrInvalidTimezone
+	}
+	return nil
+}
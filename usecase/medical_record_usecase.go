@@ -2,27 +2,40 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"hms-api/domain"
+	"strings"
 	"time"
 )
 
 type medicalRecordUsecase struct {
 	medicalRecordRepository domain.MedicalRecordRepository
+	catalog                 domain.ICD10Catalog
 	contextTimeout          time.Duration
 }
 
-func NewMedicalRecordUsecase(medicalRecordRepository domain.MedicalRecordRepository, timeout time.Duration) domain.MedicalRecordUsecase {
+func NewMedicalRecordUsecase(medicalRecordRepository domain.MedicalRecordRepository, catalog domain.ICD10Catalog, timeout time.Duration) domain.MedicalRecordUsecase {
 	return &medicalRecordUsecase{
 		medicalRecordRepository: medicalRecordRepository,
+		catalog:                 catalog,
 		contextTimeout:          timeout,
 	}
 }
 
 func (mu *medicalRecordUsecase) Create(c context.Context, record *domain.MedicalRecord) error {
+	diagnoses, err := mu.codeDiagnoses(record.Diagnosis, record.Diagnoses)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(c, mu.contextTimeout)
 	defer cancel()
-	return mu.medicalRecordRepository.Create(ctx, record)
+
+	record.Diagnoses = diagnoses
+	record.Status = domain.MedicalRecordActive
+	record.AmendsID = nil
+	return mu.medicalRecordRepository.Create(ctx, record, "")
 }
 
 func (mu *medicalRecordUsecase) Fetch(c context.Context) ([]domain.MedicalRecord, error) {
@@ -43,14 +56,184 @@ func (mu *medicalRecordUsecase) FetchByDoctorID(c context.Context, doctorID uuid
 	return mu.medicalRecordRepository.FetchByDoctorID(ctx, doctorID)
 }
 
-func (mu *medicalRecordUsecase) Update(c context.Context, record *domain.MedicalRecord) error {
+func (mu *medicalRecordUsecase) FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]domain.MedicalRecord, error) {
+	ctx, cancel := context.WithTimeout(c, mu.contextTimeout)
+	defer cancel()
+	return mu.medicalRecordRepository.FetchByEncounterID(ctx, encounterID)
+}
+
+// Update saves the record with the change's fields as its next version.
+func (mu *medicalRecordUsecase) Update(c context.Context, id uuid.UUID, change domain.MedicalRecordChange, changedBy uuid.UUID) (*domain.MedicalRecord, error) {
+	ctx, cancel := context.WithTimeout(c, mu.contextTimeout)
+	defer cancel()
+
+	record, err := mu.medicalRecordRepository.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, domain.ErrMedicalRecordNotFound
+	}
+	if record.Status == domain.MedicalRecordEnteredInError {
+		return nil, domain.ErrMedicalRecordEnteredInError
+	}
+
+	if err := mu.applyChange(record, change); err != nil {
+		return nil, err
+	}
+	record.UpdatedBy = &changedBy
+	if err := mu.medicalRecordRepository.Revise(ctx, record, strings.TrimSpace(change.Reason)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Amend adds a record for the same patient referencing the original, which is
+// left as it was. The amendment starts from the original's content.
+func (mu *medicalRecordUsecase) Amend(c context.Context, id uuid.UUID, change domain.MedicalRecordChange, changedBy uuid.UUID) (*domain.MedicalRecord, error) {
+	ctx, cancel := context.WithTimeout(c, mu.contextTimeout)
+	defer cancel()
+
+	original, err := mu.medicalRecordRepository.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, domain.ErrMedicalRecordNotFound
+	}
+	if original.Status == domain.MedicalRecordEnteredInError {
+		return nil, domain.ErrMedicalRecordEnteredInError
+	}
+
+	amendment := &domain.MedicalRecord{
+		PatientID:   original.PatientID,
+		DoctorID:    original.DoctorID,
+		Diagnosis:   original.Diagnosis,
+		Diagnoses:   original.Diagnoses,
+		Treatment:   original.Treatment,
+		Status:      domain.MedicalRecordActive,
+		AmendsID:    &original.ID,
+		EncounterID: original.EncounterID,
+		UpdatedBy:   &changedBy,
+	}
+	if change.DoctorID != nil {
+		amendment.DoctorID = *change.DoctorID
+	}
+	if err := mu.applyChange(amendment, change); err != nil {
+		return nil, err
+	}
+
+	if err := mu.medicalRecordRepository.Create(ctx, amendment, strings.TrimSpace(change.Reason)); err != nil {
+		return nil, err
+	}
+	return amendment, nil
+}
+
+// MarkEnteredInError retires a record made by mistake, keeping its content as
+// it was.
+func (mu *medicalRecordUsecase) MarkEnteredInError(c context.Context, id uuid.UUID, reason string, changedBy uuid.UUID) (*domain.MedicalRecord, error) {
+	ctx, cancel := context.WithTimeout(c, mu.contextTimeout)
+	defer cancel()
+
+	record, err := mu.medicalRecordRepository.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, domain.ErrMedicalRecordNotFound
+	}
+
+	record.Status = domain.MedicalRecordEnteredInError
+	record.UpdatedBy = &changedBy
+	if err := mu.medicalRecordRepository.Revise(ctx, record, strings.TrimSpace(reason)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (mu *medicalRecordUsecase) FetchVersions(c context.Context, id uuid.UUID) ([]domain.MedicalRecordVersion, error) {
 	ctx, cancel := context.WithTimeout(c, mu.contextTimeout)
 	defer cancel()
-	return mu.medicalRecordRepository.Update(ctx, record)
+
+	versions, err := mu.medicalRecordRepository.FetchVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, domain.ErrMedicalRecordNotFound
+	}
+	return versions, nil
 }
 
-func (mu *medicalRecordUsecase) Delete(c context.Context, id uuid.UUID) error {
+func (mu *medicalRecordUsecase) FetchAmendments(c context.Context, id uuid.UUID) ([]domain.MedicalRecord, error) {
 	ctx, cancel := context.WithTimeout(c, mu.contextTimeout)
 	defer cancel()
-	return mu.medicalRecordRepository.Delete(ctx, id)
+	return mu.medicalRecordRepository.FetchAmendments(ctx, id)
+}
+
+// codeDiagnoses checks the coded diagnoses against the catalog, writing their
+// codes and descriptions as it does. The first is primary unless another is,
+// and a record needs either a code or notes.
+// applyChange sets the fields the change gives on record and codes its
+// diagnoses. A change has to give at least one of them.
+func (mu *medicalRecordUsecase) applyChange(record *domain.MedicalRecord, change domain.MedicalRecordChange) error {
+	if change.Diagnosis == nil && change.Diagnoses == nil && change.Treatment == nil {
+		return fmt.Errorf("%w: diagnosis, diagnoses or treatment is required", domain.ErrInvalidMedicalRecord)
+	}
+
+	if change.Diagnosis != nil {
+		record.Diagnosis = *change.Diagnosis
+	}
+	if change.Diagnoses != nil {
+		record.Diagnoses = *change.Diagnoses
+	}
+	if change.Treatment != nil {
+		record.Treatment = *change.Treatment
+	}
+
+	diagnoses, err := mu.codeDiagnoses(record.Diagnosis, record.Diagnoses)
+	if err != nil {
+		return err
+	}
+	record.Diagnoses = diagnoses
+	return nil
+}
+
+func (mu *medicalRecordUsecase) codeDiagnoses(notes string, diagnoses []domain.CodedDiagnosis) ([]domain.CodedDiagnosis, error) {
+	if strings.TrimSpace(notes) == "" && len(diagnoses) == 0 {
+		return nil, fmt.Errorf("%w: diagnosis or diagnoses is required", domain.ErrInvalidMedicalRecord)
+	}
+
+	coded := make([]domain.CodedDiagnosis, 0, len(diagnoses))
+	seen := map[string]bool{}
+	primary := -1
+	for i, diagnosis := range diagnoses {
+		code, ok := mu.catalog.Lookup(diagnosis.Code)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown ICD-10 code %q", domain.ErrInvalidDiagnosis, diagnosis.Code)
+		}
+		if seen[code.Code] {
+			return nil, fmt.Errorf("%w: %s is given more than once", domain.ErrInvalidDiagnosis, code.Code)
+		}
+		seen[code.Code] = true
+
+		switch diagnosis.Type {
+		case domain.PrimaryDiagnosis:
+			if primary >= 0 {
+				return nil, fmt.Errorf("%w: only one diagnosis can be primary", domain.ErrInvalidDiagnosis)
+			}
+			primary = i
+		case "", domain.SecondaryDiagnosis:
+			diagnosis.Type = domain.SecondaryDiagnosis
+		default:
+			return nil, fmt.Errorf("%w: type must be primary or secondary", domain.ErrInvalidDiagnosis)
+		}
+
+		coded = append(coded, domain.CodedDiagnosis{Code: code.Code, Description: code.Description, Type: diagnosis.Type})
+	}
+
+	if primary < 0 && len(coded) > 0 {
+		coded[0].Type = domain.PrimaryDiagnosis
+	}
+	return coded, nil
 }
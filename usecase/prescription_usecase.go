@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"hms-api/domain"
+	"hms-api/internal/allergycheck"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,19 +12,29 @@ import (
 
 type prescriptionUsecase struct {
 	prescriptionRepository domain.PrescriptionRepository
+	allergyRepository      domain.AllergyRepository
 	contextTimeout         time.Duration
 }
 
-func NewPrescriptionUsecase(prescriptionRepository domain.PrescriptionRepository, timeout time.Duration) domain.PrescriptionUsecase {
+func NewPrescriptionUsecase(prescriptionRepository domain.PrescriptionRepository, allergyRepository domain.AllergyRepository, timeout time.Duration) domain.PrescriptionUsecase {
 	return &prescriptionUsecase{
 		prescriptionRepository: prescriptionRepository,
+		allergyRepository:      allergyRepository,
 		contextTimeout:         timeout,
 	}
 }
 
+// Create checks the medication against the patient's active allergies first.
+// A prescription that matches any is only created with an override reason,
+// and is saved with the warnings it overrode.
 func (pu *prescriptionUsecase) Create(c context.Context, prescription *domain.Prescription) error {
 	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
 	defer cancel()
+
+	if err := pu.checkAllergies(ctx, prescription, nil); err != nil {
+		return err
+	}
+
 	return pu.prescriptionRepository.Create(ctx, prescription)
 }
 
@@ -50,9 +62,34 @@ func (pu *prescriptionUsecase) FetchByDoctorID(c context.Context, doctorID uuid.
 	return pu.prescriptionRepository.FetchByDoctorID(ctx, doctorID)
 }
 
+func (pu *prescriptionUsecase) FetchByEncounterID(c context.Context, encounterID uuid.UUID) ([]domain.Prescription, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+	return pu.prescriptionRepository.FetchByEncounterID(ctx, encounterID)
+}
+
+// Update checks the new medication against the patient's active allergies
+// like Create does. The override already given for the prescription still
+// holds while the medication stays the same and matches no other allergy.
 func (pu *prescriptionUsecase) Update(c context.Context, prescription *domain.Prescription) error {
 	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
 	defer cancel()
+
+	current, err := pu.prescriptionRepository.FetchByID(ctx, prescription.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return domain.ErrPrescriptionNotFound
+	}
+	if prescription.PatientID == uuid.Nil {
+		prescription.PatientID = current.PatientID
+	}
+
+	if err := pu.checkAllergies(ctx, prescription, current); err != nil {
+		return err
+	}
+
 	return pu.prescriptionRepository.Update(ctx, prescription)
 }
 
@@ -61,3 +98,60 @@ func (pu *prescriptionUsecase) Delete(c context.Context, id uuid.UUID) error {
 	defer cancel()
 	return pu.prescriptionRepository.Delete(ctx, id)
 }
+
+// checkAllergies matches the prescription's medication against the patient's
+// active allergies. When any matches it needs an override reason, either
+// given now or, for the unchanged medication of current, given before for
+// the same allergies; the matched warnings are kept with it.
+func (pu *prescriptionUsecase) checkAllergies(ctx context.Context, prescription *domain.Prescription, current *domain.Prescription) error {
+	allergies, err := pu.allergyRepository.FetchActiveByPatientID(ctx, prescription.PatientID)
+	if err != nil {
+		return err
+	}
+
+	warnings := []domain.AllergyWarning{}
+	for _, allergy := range allergies {
+		if matched, ok := allergycheck.Match(allergy.Substance, prescription.MedicationDetails); ok {
+			warnings = append(warnings, domain.AllergyWarning{
+				AllergyID: allergy.ID,
+				Substance: allergy.Substance,
+				Kind:      allergy.Kind,
+				Reaction:  allergy.Reaction,
+				Severity:  allergy.Severity,
+				Matched:   matched,
+			})
+		}
+	}
+
+	prescription.AllergyOverrideReason = strings.TrimSpace(prescription.AllergyOverrideReason)
+	if len(warnings) == 0 {
+		prescription.AllergyOverrideReason = ""
+		prescription.AllergyWarnings = nil
+		return nil
+	}
+
+	if prescription.AllergyOverrideReason == "" && current != nil && current.AllergyOverrideReason != "" &&
+		current.MedicationDetails == prescription.MedicationDetails && overridden(current.AllergyWarnings, warnings) {
+		prescription.AllergyOverrideReason = current.AllergyOverrideReason
+	}
+	if prescription.AllergyOverrideReason == "" {
+		return &domain.AllergyConflictError{Warnings: warnings}
+	}
+	prescription.AllergyWarnings = warnings
+	return nil
+}
+
+// overridden reports whether every warning was among those already
+// overridden.
+func overridden(previous []domain.AllergyWarning, warnings []domain.AllergyWarning) bool {
+	seen := make(map[uuid.UUID]bool, len(previous))
+	for _, warning := range previous {
+		seen[warning.AllergyID] = true
+	}
+	for _, warning := range warnings {
+		if !seen[warning.AllergyID] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,355 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	tokenutil "hms-api/internal"
+	"hms-api/internal/notification"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderConfig sets when reminders go out and how their links are built.
+type ReminderConfig struct {
+	Offsets         []time.Duration
+	BaseURL         string
+	LinkSecret      string
+	DefaultLanguage string
+}
+
+type reminderUsecase struct {
+	reminderRepository    domain.ReminderRepository
+	appointmentRepository domain.AppointmentRepository
+	patientRepository     domain.PatientRepository
+	appointmentUsecase    domain.AppointmentUsecase
+	attendanceUsecase     domain.AttendanceUsecase
+	notifier              domain.AppointmentNotifier
+	dispatcher            notification.Dispatcher
+	config                ReminderConfig
+	contextTimeout        time.Duration
+}
+
+func NewReminderUsecase(reminderRepository domain.ReminderRepository, appointmentRepository domain.AppointmentRepository, patientRepository domain.PatientRepository, appointmentUsecase domain.AppointmentUsecase, attendanceUsecase domain.AttendanceUsecase, notifier domain.AppointmentNotifier, dispatcher notification.Dispatcher, config ReminderConfig, timeout time.Duration) domain.ReminderUsecase {
+	if len(config.Offsets) == 0 {
+		config.Offsets = domain.DefaultReminderOffsets
+	}
+	offsets := append([]time.Duration(nil), config.Offsets...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+	config.Offsets = offsets
+
+	if !notification.SupportedLanguage(config.DefaultLanguage) {
+		config.DefaultLanguage = domain.LanguagePortuguese
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &reminderUsecase{
+		reminderRepository:    reminderRepository,
+		appointmentRepository: appointmentRepository,
+		patientRepository:     patientRepository,
+		appointmentUsecase:    appointmentUsecase,
+		attendanceUsecase:     attendanceUsecase,
+		notifier:              notifier,
+		dispatcher:            dispatcher,
+		config:                config,
+		contextTimeout:        timeout,
+	}
+}
+
+// SendDue sends each offset's reminders for the appointments starting within
+// it. An offset only covers appointments beyond the next shorter one, so an
+// appointment booked at short notice gets a single reminder instead of one per
+// offset it already passed.
+func (ru *reminderUsecase) SendDue(c context.Context, now time.Time) (int, error) {
+	sent := 0
+
+	for i, offset := range ru.config.Offsets {
+		from := now
+		if i+1 < len(ru.config.Offsets) {
+			from = now.Add(ru.config.Offsets[i+1])
+		}
+		offsetMinutes := int(offset / time.Minute)
+
+		ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+		candidates, err := ru.reminderRepository.FetchDue(ctx, from, now.Add(offset), offsetMinutes)
+		cancel()
+		if err != nil {
+			return sent, err
+		}
+
+		for _, candidate := range candidates {
+			n, err := ru.remind(c, candidate, offsetMinutes)
+			if err != nil {
+				return sent, err
+			}
+			sent += n
+		}
+	}
+
+	return sent, nil
+}
+
+// remind sends one reminder through each of the patient's channels and
+// records the attempts.
+func (ru *reminderUsecase) remind(c context.Context, candidate domain.AppointmentContact, offsetMinutes int) (int, error) {
+	appointment := candidate.Appointment
+
+	confirmURL, err := ru.link(&appointment, domain.ReminderConfirm)
+	if err != nil {
+		return 0, err
+	}
+	cancelURL, err := ru.link(&appointment, domain.ReminderCancel)
+	if err != nil {
+		return 0, err
+	}
+
+	message, err := notification.Render(contactLanguage(candidate, ru.config.DefaultLanguage), notification.AppointmentReminder, notification.ReminderData{
+		PatientName: candidate.PatientName,
+		DoctorName:  candidate.DoctorName,
+		Specialty:   candidate.Specialty,
+		Start:       appointment.AppointmentDate.In(candidate.PatientLocation()),
+		ConfirmURL:  confirmURL,
+		CancelURL:   cancelURL,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, channel := range patientChannels(candidate) {
+		recipient := recipientFor(candidate, channel)
+		if recipient == "" || !ru.dispatcher.Supports(channel) {
+			continue
+		}
+
+		n := domain.Notification{Channel: channel, To: recipient, Subject: message.Subject, Body: message.Short}
+		if channel == domain.EmailChannel {
+			n.Body = message.Body
+		}
+
+		reminder := &domain.AppointmentReminder{
+			AppointmentID: appointment.ID,
+			OffsetMinutes: offsetMinutes,
+			Channel:       channel,
+			Recipient:     recipient,
+		}
+		if err := ru.dispatcher.Send(c, n); err != nil {
+			log.Printf("[ERROR] Reminder: failed to send %s reminder for appointment %s: %v\n", channel, appointment.ID, err)
+			reminder.Error = err.Error()
+		} else {
+			sent++
+		}
+
+		ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+		_, err := ru.reminderRepository.Record(ctx, reminder)
+		cancel()
+		if err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+// Preview returns the appointment a reminder link is for, without applying
+// it, so the patient can be asked before anything changes.
+func (ru *reminderUsecase) Preview(c context.Context, token string, action string) (domain.Appointment, error) {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	return ru.linkedAppointment(ctx, token, action)
+}
+
+// Respond applies a reminder link. Confirming moves a requested appointment
+// to confirmed even though patients can't confirm through the API: the signed
+// link is proof the patient got the reminder. Patients under the attendance
+// policy still need an admin to confirm. Canceling goes through the regular
+// cancel transition on the patient's behalf.
+func (ru *reminderUsecase) Respond(c context.Context, token string, action string) (domain.Appointment, error) {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	appointment, err := ru.linkedAppointment(ctx, token, action)
+	if err != nil {
+		return appointment, err
+	}
+
+	patient, err := ru.patientRepository.FetchByID(ctx, appointment.PatientID)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+
+	switch action {
+	case domain.ReminderCancel:
+		return ru.appointmentUsecase.Transition(c, appointment.ID, "cancel", patient.UserId, domain.PatientRole, "Canceled from reminder link")
+	case domain.ReminderConfirm:
+		if appointment.Status == domain.Confirmed {
+			return appointment, nil
+		}
+		if appointment.Status != domain.Requested {
+			return appointment, domain.ErrInvalidTransition
+		}
+
+		restricted, err := ru.attendanceUsecase.RequiresApproval(c, appointment.PatientID)
+		if err != nil {
+			return domain.Appointment{}, err
+		}
+		if restricted {
+			return appointment, domain.ErrApprovalRequired
+		}
+
+		change := &domain.AppointmentStatusChange{
+			ToStatus:      domain.Confirmed,
+			ChangedBy:     patient.UserId,
+			ChangedByRole: domain.PatientRole,
+			Reason:        "Confirmed from reminder link",
+		}
+		updated, err := ru.appointmentRepository.UpdateStatus(ctx, &appointment, domain.Requested, change)
+		if err != nil {
+			return domain.Appointment{}, err
+		}
+		if !updated {
+			return appointment, domain.ErrInvalidTransition
+		}
+
+		notifyConfirmed(c, ru.notifier, appointment)
+		return appointment, nil
+	}
+
+	return domain.Appointment{}, domain.ErrInvalidReminderLink
+}
+
+// PageLanguage returns the patient's language, or the default one when they
+// haven't chosen one or aren't known.
+func (ru *reminderUsecase) PageLanguage(c context.Context, patientID uuid.UUID) string {
+	if patientID == uuid.Nil {
+		return ru.config.DefaultLanguage
+	}
+
+	preference, err := ru.FetchPreference(c, patientID)
+	if err != nil || !notification.SupportedLanguage(preference.Language) {
+		return ru.config.DefaultLanguage
+	}
+	return preference.Language
+}
+
+// FetchPreference returns the patient's preferences, or the defaults when
+// they haven't set any.
+func (ru *reminderUsecase) FetchPreference(c context.Context, patientID uuid.UUID) (domain.NotificationPreference, error) {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	preference, err := ru.reminderRepository.FetchPreference(ctx, patientID)
+	if err != nil {
+		return domain.NotificationPreference{}, err
+	}
+	if preference.PatientID == uuid.Nil {
+		preference = domain.NotificationPreference{
+			PatientID: patientID,
+			Language:  ru.config.DefaultLanguage,
+			Channels:  []domain.NotificationChannel{domain.EmailChannel, domain.SMSChannel},
+		}
+	}
+
+	return preference, nil
+}
+
+func (ru *reminderUsecase) UpdatePreference(c context.Context, preference *domain.NotificationPreference) error {
+	if preference.Language == "" {
+		preference.Language = ru.config.DefaultLanguage
+	}
+	if !notification.SupportedLanguage(preference.Language) {
+		return fmt.Errorf("%w: %s, expected %s or %s", domain.ErrUnsupportedLanguage, preference.Language, domain.LanguagePortuguese, domain.LanguageEnglish)
+	}
+
+	seen := make(map[domain.NotificationChannel]bool, len(preference.Channels))
+	channels := make([]domain.NotificationChannel, 0, len(preference.Channels))
+	for _, channel := range preference.Channels {
+		switch channel {
+		case domain.EmailChannel, domain.SMSChannel, domain.PushChannel:
+		default:
+			return fmt.Errorf("%w: %s", domain.ErrInvalidNotificationChannel, channel)
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	if seen[domain.PushChannel] && strings.TrimSpace(preference.PushToken) == "" {
+		return fmt.Errorf("%w: push requires a push_token", domain.ErrInvalidNotificationChannel)
+	}
+	preference.Channels = channels
+
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+	return ru.reminderRepository.UpsertPreference(ctx, preference)
+}
+
+// linkedAppointment checks token was signed for action and returns the
+// appointment it's for, as long as the appointment hasn't changed since the
+// reminder was sent.
+func (ru *reminderUsecase) linkedAppointment(ctx context.Context, token string, action string) (domain.Appointment, error) {
+	claims, err := tokenutil.ParseReminderToken(token, ru.config.LinkSecret)
+	if err != nil || claims.Action != action {
+		return domain.Appointment{}, domain.ErrInvalidReminderLink
+	}
+
+	appointment, err := ru.appointmentRepository.FetchByID(ctx, claims.AppointmentID)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if appointment.ID == uuid.Nil {
+		return domain.Appointment{}, domain.ErrAppointmentNotFound
+	}
+	if appointment.PatientID != claims.PatientID {
+		return domain.Appointment{}, domain.ErrInvalidReminderLink
+	}
+	if appointment.Sequence != claims.Sequence {
+		return appointment, domain.ErrStaleReminderLink
+	}
+
+	return appointment, nil
+}
+
+func (ru *reminderUsecase) link(appointment *domain.Appointment, action string) (string, error) {
+	token, err := tokenutil.CreateReminderToken(appointment, action, ru.config.LinkSecret)
+	if err != nil {
+		return "", fmt.Errorf("error signing reminder link: %w", err)
+	}
+	return ru.config.BaseURL + "/reminders/" + action + "?token=" + url.QueryEscape(token), nil
+}
+
+// contactLanguage returns the patient's language, or fallback when they
+// haven't chosen one.
+func contactLanguage(contact domain.AppointmentContact, fallback string) string {
+	if contact.HasPreference && notification.SupportedLanguage(contact.Preference.Language) {
+		return contact.Preference.Language
+	}
+	return fallback
+}
+
+// patientChannels returns the patient's chosen channels, or email and SMS
+// when they haven't chosen.
+func patientChannels(candidate domain.AppointmentContact) []domain.NotificationChannel {
+	if candidate.HasPreference {
+		return candidate.Preference.Channels
+	}
+	return []domain.NotificationChannel{domain.EmailChannel, domain.SMSChannel}
+}
+
+func recipientFor(candidate domain.AppointmentContact, channel domain.NotificationChannel) string {
+	switch channel {
+	case domain.EmailChannel:
+		return candidate.PatientEmail
+	case domain.SMSChannel:
+		return candidate.PatientPhone
+	case domain.PushChannel:
+		return candidate.Preference.PushToken
+	}
+	return ""
+}
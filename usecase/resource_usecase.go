@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hms-api/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type resourceUsecase struct {
+	resourceRepository domain.ResourceRepository
+	contextTimeout     time.Duration
+}
+
+func NewResourceUsecase(resourceRepository domain.ResourceRepository, timeout time.Duration) domain.ResourceUsecase {
+	return &resourceUsecase{
+		resourceRepository: resourceRepository,
+		contextTimeout:     timeout,
+	}
+}
+
+func (ru *resourceUsecase) Create(c context.Context, resource *domain.Resource) error {
+	if err := normalizeResource(resource); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+	return ru.resourceRepository.Create(ctx, resource)
+}
+
+func (ru *resourceUsecase) Fetch(c context.Context) ([]domain.Resource, error) {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+	return ru.resourceRepository.Fetch(ctx)
+}
+
+func (ru *resourceUsecase) FetchByID(c context.Context, id uuid.UUID) (domain.Resource, error) {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+	return ru.resourceRepository.FetchByID(ctx, id)
+}
+
+func (ru *resourceUsecase) Update(c context.Context, resource *domain.Resource) error {
+	if err := normalizeResource(resource); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+	return ru.resourceRepository.Update(ctx, resource)
+}
+
+// Delete deactivates the resource. Its existing reservations are kept.
+func (ru *resourceUsecase) Delete(c context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	deactivated, err := ru.resourceRepository.Deactivate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deactivated {
+		return domain.ErrResourceNotFound
+	}
+	return nil
+}
+
+func normalizeResource(resource *domain.Resource) error {
+	resource.Name = strings.TrimSpace(resource.Name)
+	resource.Category = strings.ToLower(strings.TrimSpace(resource.Category))
+
+	if resource.Kind != domain.RoomResource && resource.Kind != domain.EquipmentResource {
+		return fmt.Errorf("%w: kind must be %s or %s", domain.ErrInvalidResource, domain.RoomResource, domain.EquipmentResource)
+	}
+	if resource.Name == "" || resource.Category == "" {
+		return fmt.Errorf("%w: name and category are required", domain.ErrInvalidResource)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"hms-api/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type securityAlertUsecase struct {
+	securityAlertRepository domain.SecurityAlertRepository
+	contextTimeout          time.Duration
+}
+
+func NewSecurityAlertUsecase(securityAlertRepository domain.SecurityAlertRepository, timeout time.Duration) domain.SecurityAlertUsecase {
+	return &securityAlertUsecase{
+		securityAlertRepository: securityAlertRepository,
+		contextTimeout:          timeout,
+	}
+}
+
+func (sau *securityAlertUsecase) Create(c context.Context, alert *domain.SecurityAlert) error {
+	ctx, cancel := context.WithTimeout(c, sau.contextTimeout)
+	defer cancel()
+	return sau.securityAlertRepository.Create(ctx, alert)
+}
+
+func (sau *securityAlertUsecase) Fetch(c context.Context, status domain.AlertStatus) ([]domain.SecurityAlert, error) {
+	ctx, cancel := context.WithTimeout(c, sau.contextTimeout)
+	defer cancel()
+	return sau.securityAlertRepository.Fetch(ctx, status)
+}
+
+func (sau *securityAlertUsecase) FetchByID(c context.Context, id uuid.UUID) (*domain.SecurityAlert, error) {
+	ctx, cancel := context.WithTimeout(c, sau.contextTimeout)
+	defer cancel()
+	return sau.securityAlertRepository.FetchByID(ctx, id)
+}
+
+func (sau *securityAlertUsecase) Acknowledge(c context.Context, id uuid.UUID, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, sau.contextTimeout)
+	defer cancel()
+
+	ok, err := sau.securityAlertRepository.Acknowledge(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrInvalidAlertTransition
+	}
+
+	return nil
+}
+
+func (sau *securityAlertUsecase) Resolve(c context.Context, id uuid.UUID, userID uuid.UUID, resolution string) error {
+	ctx, cancel := context.WithTimeout(c, sau.contextTimeout)
+	defer cancel()
+
+	ok, err := sau.securityAlertRepository.Resolve(ctx, id, userID, resolution)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrInvalidAlertTransition
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"hms-api/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type timezoneUsecase struct {
+	userRepository domain.UserRepository
+	contextTimeout time.Duration
+}
+
+func NewTimezoneUsecase(userRepository domain.UserRepository, timeout time.Duration) domain.TimezoneUsecase {
+	return &timezoneUsecase{
+		userRepository: userRepository,
+		contextTimeout: timeout,
+	}
+}
+
+func (tu *timezoneUsecase) Fetch(c context.Context, userID uuid.UUID) (string, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	user, err := tu.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Timezone, nil
+}
+
+// Update sets the user's timezone, or clears it when timezone is empty.
+func (tu *timezoneUsecase) Update(c context.Context, userID uuid.UUID, timezone string) error {
+	timezone = strings.TrimSpace(timezone)
+	if timezone != "" && !domain.ValidTimezone(timezone) {
+		return domain.ErrInvalidTimezone
+	}
+
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+	return tu.userRepository.UpdateTimezone(ctx, userID, timezone)
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"hms-api/domain"
+	"hms-api/internal/waitingroom"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type waitingRoomUsecase struct {
+	appointmentRepository domain.AppointmentRepository
+	reminderRepository    domain.ReminderRepository
+	broker                waitingroom.Broker
+	contextTimeout        time.Duration
+}
+
+func NewWaitingRoomUsecase(appointmentRepository domain.AppointmentRepository, reminderRepository domain.ReminderRepository, broker waitingroom.Broker, timeout time.Duration) domain.WaitingRoomUsecase {
+	return &waitingRoomUsecase{
+		appointmentRepository: appointmentRepository,
+		reminderRepository:    reminderRepository,
+		broker:                broker,
+		contextTimeout:        timeout,
+	}
+}
+
+func (wu *waitingRoomUsecase) Publish(c context.Context, eventType domain.WaitingRoomEventType, appointment domain.Appointment) error {
+	_, err := wu.publish(c, eventType, appointment, "")
+	return err
+}
+
+func (wu *waitingRoomUsecase) Call(c context.Context, appointmentID uuid.UUID, room string) (domain.WaitingRoomEvent, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	appointment, err := wu.appointmentRepository.FetchByID(ctx, appointmentID)
+	if err != nil {
+		return domain.WaitingRoomEvent{}, err
+	}
+	if appointment.ID == uuid.Nil {
+		return domain.WaitingRoomEvent{}, domain.ErrAppointmentNotFound
+	}
+	if appointment.Status != domain.CheckedIn {
+		return domain.WaitingRoomEvent{}, domain.ErrNotCheckedIn
+	}
+
+	room = strings.TrimSpace(room)
+	if room == "" && appointment.TypeID != nil {
+		resources, err := wu.appointmentRepository.FetchResources(ctx, appointment.ID)
+		if err != nil {
+			return domain.WaitingRoomEvent{}, err
+		}
+		for _, resource := range resources {
+			if resource.Kind == domain.RoomResource {
+				room = resource.Name
+				break
+			}
+		}
+	}
+
+	return wu.publish(c, domain.AppointmentCalledEvent, appointment, room)
+}
+
+func (wu *waitingRoomUsecase) Subscribe(lastEventID int64) ([]domain.WaitingRoomEvent, <-chan domain.WaitingRoomEvent, func()) {
+	return wu.broker.Subscribe(lastEventID)
+}
+
+// publish fills in the names shown on the boards and sends the event.
+func (wu *waitingRoomUsecase) publish(c context.Context, eventType domain.WaitingRoomEventType, appointment domain.Appointment, room string) (domain.WaitingRoomEvent, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	contact, err := wu.reminderRepository.FetchContact(ctx, appointment.ID)
+	if err != nil {
+		return domain.WaitingRoomEvent{}, err
+	}
+
+	return wu.broker.Publish(domain.WaitingRoomEvent{
+		Type:            eventType,
+		AppointmentID:   appointment.ID,
+		PatientID:       appointment.PatientID,
+		PatientName:     contact.PatientName,
+		DoctorID:        appointment.DoctorID,
+		DoctorName:      contact.DoctorName,
+		Status:          appointment.Status,
+		AppointmentDate: appointment.AppointmentDate,
+		TicketNumber:    appointment.TicketNumber,
+		Room:            room,
+		At:              time.Now(),
+	}), nil
+}
+
+// publishWaitingRoom tells the waiting room about the appointment, logging
+// instead of failing the change when it can't.
+func publishWaitingRoom(c context.Context, publisher domain.WaitingRoomPublisher, eventType domain.WaitingRoomEventType, appointment domain.Appointment) {
+	if publisher == nil {
+		return
+	}
+	if err := publisher.Publish(c, eventType, appointment); err != nil {
+		log.Printf("[ERROR] Waiting room: failed to publish %s of appointment %s: %v\n", eventType, appointment.ID, err)
+	}
+}
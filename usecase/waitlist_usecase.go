@@ -0,0 +1,365 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hms-api/domain"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type waitlistUsecase struct {
+	waitlistRepository    domain.WaitlistRepository
+	appointmentRepository domain.AppointmentRepository
+	doctorRepository      domain.DoctorRepository
+	attendanceUsecase     domain.AttendanceUsecase
+	notifier              domain.AppointmentNotifier
+	hold                  time.Duration
+	contextTimeout        time.Duration
+}
+
+func NewWaitlistUsecase(waitlistRepository domain.WaitlistRepository, appointmentRepository domain.AppointmentRepository, doctorRepository domain.DoctorRepository, attendanceUsecase domain.AttendanceUsecase, notifier domain.AppointmentNotifier, hold time.Duration, timeout time.Duration) domain.WaitlistUsecase {
+	if hold <= 0 {
+		hold = domain.DefaultWaitlistHold
+	}
+	return &waitlistUsecase{
+		waitlistRepository:    waitlistRepository,
+		appointmentRepository: appointmentRepository,
+		doctorRepository:      doctorRepository,
+		attendanceUsecase:     attendanceUsecase,
+		notifier:              notifier,
+		hold:                  hold,
+		contextTimeout:        timeout,
+	}
+}
+
+func (wu *waitlistUsecase) Join(c context.Context, entry *domain.WaitlistEntry) error {
+	entry.Specialty = strings.TrimSpace(entry.Specialty)
+	if entry.DoctorID == nil && entry.Specialty == "" {
+		return fmt.Errorf("%w: doctor_id or specialty is required", domain.ErrInvalidWaitlistEntry)
+	}
+	for _, window := range entry.PreferredWindows {
+		if !window.End.After(window.Start) {
+			return fmt.Errorf("%w: each preferred window must end after it starts", domain.ErrInvalidWaitlistEntry)
+		}
+	}
+	entry.Status = domain.WaitlistWaiting
+
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+	return wu.waitlistRepository.CreateEntry(ctx, entry)
+}
+
+func (wu *waitlistUsecase) FetchEntries(c context.Context, patientID uuid.UUID, status domain.WaitlistStatus) ([]domain.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+	return wu.waitlistRepository.FetchEntries(ctx, patientID, status)
+}
+
+func (wu *waitlistUsecase) FetchEntryByID(c context.Context, id uuid.UUID) (domain.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	entry, err := wu.waitlistRepository.FetchEntryByID(ctx, id)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	if entry.ID == uuid.Nil {
+		return domain.WaitlistEntry{}, domain.ErrWaitlistEntryNotFound
+	}
+	return entry, nil
+}
+
+// Leave takes the entry off the waitlist. A slot on hold for it is released
+// to the next patient.
+func (wu *waitlistUsecase) Leave(c context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	offer, err := wu.waitlistRepository.FetchPendingOfferByEntryID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if offer.ID != uuid.Nil {
+		if err := wu.release(ctx, offer, domain.OfferDeclined); err != nil {
+			return err
+		}
+	}
+
+	canceled, err := wu.waitlistRepository.CancelEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !canceled {
+		return domain.ErrWaitlistEntryNotFound
+	}
+	return nil
+}
+
+func (wu *waitlistUsecase) FetchOffers(c context.Context, patientID uuid.UUID, status domain.OfferStatus) ([]domain.WaitlistOffer, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+	return wu.waitlistRepository.FetchOffers(ctx, patientID, status)
+}
+
+func (wu *waitlistUsecase) FetchOfferByID(c context.Context, id uuid.UUID) (domain.WaitlistOffer, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	offer, err := wu.waitlistRepository.FetchOfferByID(ctx, id)
+	if err != nil {
+		return domain.WaitlistOffer{}, err
+	}
+	if offer.ID == uuid.Nil {
+		return domain.WaitlistOffer{}, domain.ErrOfferNotFound
+	}
+	return offer, nil
+}
+
+// Accept books the offered slot for the patient. If the slot was taken in the
+// meantime the offer is closed and the patient stays on the waitlist.
+func (wu *waitlistUsecase) Accept(c context.Context, offerID uuid.UUID, actorID uuid.UUID, actorRole domain.UserRole) (domain.Appointment, error) {
+	offer, err := wu.FetchOfferByID(c, offerID)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if offer.Status != domain.OfferPending {
+		return domain.Appointment{}, domain.ErrOfferClosed
+	}
+
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	if !time.Now().Before(offer.ExpiresAt) {
+		if err := wu.release(ctx, offer, domain.OfferExpired); err != nil {
+			log.Printf("[ERROR] Waitlist: failed to expire offer %s: %v\n", offer.ID, err)
+		}
+		return domain.Appointment{}, domain.ErrOfferExpired
+	}
+
+	// Patients under the attendance policy still get the slot, but as a
+	// request for an admin to confirm.
+	status := domain.Confirmed
+	restricted, err := wu.attendanceUsecase.RequiresApproval(c, offer.PatientID)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if restricted {
+		status = domain.Requested
+	}
+
+	appointment := domain.Appointment{
+		PatientID:       offer.PatientID,
+		DoctorID:        offer.DoctorID,
+		AppointmentDate: offer.SlotStart,
+		EndDate:         offer.SlotEnd,
+		Status:          status,
+		Notes:           "Booked from the waitlist",
+	}
+	change := &domain.AppointmentStatusChange{
+		ToStatus:      status,
+		ChangedBy:     actorID,
+		ChangedByRole: actorRole,
+		Reason:        "waitlist offer accepted",
+	}
+
+	accepted, err := wu.waitlistRepository.AcceptOffer(ctx, &offer, &appointment, change)
+	var conflict *domain.AppointmentConflictError
+	if errors.As(err, &conflict) {
+		if _, closeErr := wu.waitlistRepository.CloseOffer(ctx, &offer, domain.OfferExpired); closeErr != nil {
+			log.Printf("[ERROR] Waitlist: failed to close offer %s: %v\n", offer.ID, closeErr)
+		}
+		return domain.Appointment{}, err
+	}
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if !accepted {
+		return domain.Appointment{}, domain.ErrOfferClosed
+	}
+
+	log.Printf("[WAITLIST] Patient %s booked appointment %s from offer %s\n", offer.PatientID, appointment.ID, offer.ID)
+
+	return appointment, nil
+}
+
+// Decline releases the slot to the next patient on the waitlist. The
+// declining patient stays on it for other slots.
+func (wu *waitlistUsecase) Decline(c context.Context, offerID uuid.UUID) error {
+	offer, err := wu.FetchOfferByID(c, offerID)
+	if err != nil {
+		return err
+	}
+	if offer.Status != domain.OfferPending {
+		return domain.ErrOfferClosed
+	}
+
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+	return wu.release(ctx, offer, domain.OfferDeclined)
+}
+
+// OfferSlot offers the slot freed by a canceled appointment to the first
+// matching patient on the waitlist. Slots that already started are ignored.
+func (wu *waitlistUsecase) OfferSlot(c context.Context, canceled domain.Appointment) error {
+	if !canceled.AppointmentDate.After(time.Now()) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	source := canceled.ID
+	offer := domain.WaitlistOffer{
+		DoctorID:            canceled.DoctorID,
+		SlotStart:           canceled.AppointmentDate,
+		SlotEnd:             canceled.EndDate,
+		SourceAppointmentID: &source,
+	}
+	return wu.offerNext(ctx, offer)
+}
+
+// ExpireOffers closes the pending offers past their hold and offers their
+// slots to the next patients.
+func (wu *waitlistUsecase) ExpireOffers(c context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	offers, err := wu.waitlistRepository.FetchExpiredOffers(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, offer := range offers {
+		if err := wu.release(ctx, offer, domain.OfferExpired); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// CheckHold returns a doctor conflict when the appointment overlaps a slot
+// held for another patient by a pending offer.
+func (wu *waitlistUsecase) CheckHold(c context.Context, appointment domain.Appointment) error {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	offers, err := wu.waitlistRepository.FetchPendingOffers(ctx, appointment.DoctorID, appointment.AppointmentDate, appointment.EndDate)
+	if err != nil {
+		return err
+	}
+
+	for _, offer := range offers {
+		if offer.PatientID != appointment.PatientID {
+			return &domain.AppointmentConflictError{With: "doctor"}
+		}
+	}
+	return nil
+}
+
+// release closes a pending offer with status and passes its slot on.
+func (wu *waitlistUsecase) release(ctx context.Context, offer domain.WaitlistOffer, status domain.OfferStatus) error {
+	closed, err := wu.waitlistRepository.CloseOffer(ctx, &offer, status)
+	if err != nil || !closed {
+		return err
+	}
+
+	next := domain.WaitlistOffer{
+		DoctorID:            offer.DoctorID,
+		SlotStart:           offer.SlotStart,
+		SlotEnd:             offer.SlotEnd,
+		SourceAppointmentID: offer.SourceAppointmentID,
+	}
+	if err := wu.offerNext(ctx, next); err != nil {
+		log.Printf("[ERROR] Waitlist: failed to pass on slot %s of doctor %s: %v\n", offer.SlotStart.Format(time.RFC3339), offer.DoctorID, err)
+	}
+	return nil
+}
+
+// offerNext holds the slot in offer for the longest-waiting patient whose
+// entry matches the doctor or their specialty, whose windows fit the slot,
+// who wasn't offered it before and who is free at that time, and tells them
+// until when they can take it.
+func (wu *waitlistUsecase) offerNext(ctx context.Context, offer domain.WaitlistOffer) error {
+	if !offer.SlotStart.After(time.Now()) {
+		return nil
+	}
+
+	booked, err := wu.appointmentRepository.FetchActiveByDoctorIDBetween(ctx, offer.DoctorID, offer.SlotStart, offer.SlotEnd)
+	if err != nil {
+		return err
+	}
+	held, err := wu.waitlistRepository.FetchPendingOffers(ctx, offer.DoctorID, offer.SlotStart, offer.SlotEnd)
+	if err != nil {
+		return err
+	}
+	if len(booked) > 0 || len(held) > 0 {
+		return nil
+	}
+
+	doctor, err := wu.doctorRepository.FetchByID(ctx, offer.DoctorID)
+	if err != nil {
+		return err
+	}
+
+	entries, err := wu.waitlistRepository.FetchWaiting(ctx, offer.DoctorID, doctor.Specialty)
+	if err != nil {
+		return err
+	}
+
+	offeredIDs, err := wu.waitlistRepository.FetchOfferedEntryIDs(ctx, offer.DoctorID, offer.SlotStart)
+	if err != nil {
+		return err
+	}
+	offeredBefore := map[uuid.UUID]bool{}
+	for _, id := range offeredIDs {
+		offeredBefore[id] = true
+	}
+
+	slot := domain.Slot{Start: offer.SlotStart, End: offer.SlotEnd}
+	for _, entry := range entries {
+		if offeredBefore[entry.ID] || !entry.Accepts(slot) {
+			continue
+		}
+
+		busy, err := wu.appointmentRepository.FetchActiveByPatientIDBetween(ctx, entry.PatientID, slot.Start, slot.End)
+		if err != nil {
+			return err
+		}
+		if len(busy) > 0 {
+			continue
+		}
+
+		offer.EntryID = entry.ID
+		offer.PatientID = entry.PatientID
+		offer.Status = domain.OfferPending
+		offer.ExpiresAt = time.Now().Add(wu.hold)
+
+		created, err := wu.waitlistRepository.CreateOffer(ctx, &offer)
+		if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+
+		log.Printf("[WAITLIST] Offered doctor %s slot %s to patient %s until %s\n", offer.DoctorID, slot.Start.Format(time.RFC3339), offer.PatientID, offer.ExpiresAt.Format(time.RFC3339))
+		// The offer stands even if the patient can't be told right away;
+		// it's listed under their offers until it expires.
+		if wu.notifier != nil {
+			if err := wu.notifier.WaitlistOffered(ctx, offer); err != nil {
+				log.Printf("[ERROR] Notification: failed to send waitlist offer %s: %v\n", offer.ID, err)
+			}
+		}
+		return nil
+	}
+
+	return nil
+}